@@ -30,6 +30,7 @@ type Config struct {
 	RedisPass string
 
 	GomanipURL        string
+	GomanipAPIKey     string
 	ClassificationURL string
 	DbDSN             string
 
@@ -79,7 +80,7 @@ func parseCommandLineArgs() *Options {
 
 func InitializeApplication(conf *Config, ctx context.Context) *application.Application {
 
-	gomanip := gomanip.NewGoManip(conf.GomanipURL, conf.GomanipTimeout)
+	gomanip := gomanip.NewGoManip(conf.GomanipURL, conf.GomanipTimeout).WithAPIKey(conf.GomanipAPIKey)
 
 	classifier := Classification.NewImageClassification(conf.ClassificationTimeout, conf.ClassificationURL, Classification.SendImageEndpoint, Classification.GetClassificationEndpoint)
 
@@ -105,6 +106,7 @@ func configure() *Config {
 		BotToken:           os.Getenv("DISCORD_TOKEN"),
 		RedisPass:          os.Getenv("REDIS_PASS"),
 		GomanipURL:         os.Getenv("GOMANIP_URL"),
+		GomanipAPIKey:      os.Getenv("GOMANIP_API_KEY"),
 		ClassificationURL:  os.Getenv("CLASSIFICATION_URL"),
 		DbDSN:              os.Getenv("DATABASE_DSN"),
 		RandomWordsSetName: os.Getenv("RANDOM_WORD_SET_NAME"),
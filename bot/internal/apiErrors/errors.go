@@ -12,4 +12,6 @@ var (
 	ErrWriting       = errors.New("error writing multipart form data")
 	ErrTimedOut      = errors.New("timed out calling service")
 	ErrMakingRequest = errors.New("Error making http request")
+	ErrUnauthorized  = errors.New("not authorized to use the api")
+	ErrRateLimited   = errors.New("rate limited by the api")
 )
@@ -0,0 +1,116 @@
+package Commands
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+const (
+	defaultAnalyzeColors = 5
+	analyzeBins          = 32
+
+	swatchWidth  = 400
+	swatchHeight = 60
+	swatchName   = "swatch.png"
+
+	// below this laplacian variance an image is reported as blurry
+	blurryThreshold = 100
+)
+
+// renderSwatch draws the dominant colors side by side, each as wide as its share of the image.
+func renderSwatch(colors []gomanip.DominantColor) ([]byte, error) {
+	swatch := image.NewRGBA(image.Rect(0, 0, swatchWidth, swatchHeight))
+
+	var total float64
+	for _, c := range colors {
+		total += c.Share
+	}
+
+	x := 0
+	for idx, c := range colors {
+		width := int(float64(swatchWidth) * c.Share / total)
+		if idx == len(colors)-1 {
+			width = swatchWidth - x
+		}
+		fill := image.NewUniform(color.RGBA{R: c.R, G: c.G, B: c.B, A: 255})
+		draw.Draw(swatch, image.Rect(x, 0, x+width, swatchHeight), fill, image.Point{}, draw.Src)
+		x += width
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, swatch); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func Analyze(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+
+	colors := int64(defaultAnalyzeColors)
+	if option, ok := options["colors"]; ok {
+		colors = option.IntValue()
+	}
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	analysis, err := gomanip.Analyze(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, colors, analyzeBins)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Analyzing image failed", err.Error())
+		return err
+	}
+
+	sharpness := "sharp"
+	if analysis.LaplacianVariance < blurryThreshold {
+		sharpness = "blurry"
+	}
+
+	var palette strings.Builder
+	for _, c := range analysis.DominantColors {
+		fmt.Fprintf(&palette, "`%s` %.1f%%\n", c.Hex, c.Share*100)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: "Image analysis",
+		Color: 0x00FF00,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Size", Value: fmt.Sprintf("%dx%d, %d channel(s), %s", analysis.Width, analysis.Height, analysis.Channels, analysis.Depth), Inline: true},
+			{Name: "Brightness", Value: fmt.Sprintf("%.0f / 255", analysis.Brightness), Inline: true},
+			{Name: "Sharpness", Value: fmt.Sprintf("%s (%.1f)", sharpness, analysis.LaplacianVariance), Inline: true},
+			{Name: "Dominant colors", Value: palette.String()},
+			{Name: "Perceptual hash", Value: fmt.Sprintf("`%s`", analysis.Hashes.PHash)},
+		},
+	}
+
+	swatch, err := renderSwatch(analysis.DominantColors)
+	if err != nil {
+		Common.GomanipError(s, i, "Analyzing image failed", "could not draw the color swatch")
+		return err
+	}
+
+	Common.ReplyEmbedWithImage(embed, swatch, swatchName, s, i)
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package Commands
+
+import (
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+const (
+	// 60 characters fit the width of a message on desktop
+	defaultAsciiColumns = 60
+	// emoji are about twice as wide as characters
+	defaultEmojiColumns = 20
+	defaultAsciiRamp    = " .:-=+*#%@"
+	asciiArtFileName    = "ascii.txt"
+)
+
+func AsciiArt(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+
+	emoji := false
+	if option, ok := options["emoji"]; ok {
+		emoji = option.BoolValue()
+	}
+
+	columns := int64(defaultAsciiColumns)
+	if emoji {
+		columns = defaultEmojiColumns
+	}
+	if option, ok := options["columns"]; ok {
+		columns = option.IntValue()
+	}
+
+	ramp := defaultAsciiRamp
+	if option, ok := options["characters"]; ok {
+		ramp = option.StringValue()
+	}
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	art, err := gomanip.AsciiArt(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, columns, ramp, emoji)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Drawing ascii art failed", err.Error())
+		return err
+	}
+
+	block, truncated := util.FitCodeBlock(art, util.MessageLimit)
+	if !truncated {
+		Common.ReplyContent(block, nil, s, i)
+		return nil
+	}
+
+	// too long for a message, show what fits and attach the whole drawing
+	files := []*discordgo.File{
+		{
+			Name:        asciiArtFileName,
+			ContentType: "text/plain",
+			Reader:      strings.NewReader(art),
+		},
+	}
+	Common.ReplyContent(block, files, s, i)
+
+	return nil
+}
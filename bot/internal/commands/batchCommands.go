@@ -0,0 +1,99 @@
+package Commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+var ErrNoImages = errors.New("message has no images")
+
+// progressInterval is the least time between two edits of a reply showing progress.
+const progressInterval = time.Second
+
+// batchCommands maps the message commands to the operations they run over every image of the message.
+var batchCommands = map[string]string{
+	"Invert images":       "invert",
+	"Old photos":          "oldPhoto",
+	"Remove backgrounds":  "removeBackground",
+	"Kaleidoscope images": "kaleidoscope",
+}
+
+var batchImageTypes = []string{"image/png", "image/jpeg"}
+
+// BatchImages runs the operation of the message command on every image attached to the message in one request.
+func BatchImages(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	operation := batchCommands[applicationData.Name]
+	message := applicationData.Resolved.Messages[applicationData.TargetID]
+
+	var attachments []*discordgo.MessageAttachment
+	for _, attachment := range message.Attachments {
+		if slices.Contains(batchImageTypes, attachment.ContentType) {
+			attachments = append(attachments, attachment)
+		}
+	}
+
+	if len(attachments) == 0 {
+		Common.Reply(s, i, "That message has no png or jpeg images")
+		return ErrNoImages
+	}
+	// downloading every attachment can take longer than discord waits for a reply
+	Common.DeferReply(s, i)
+
+	images := make([]gomanip.BatchImage, len(attachments))
+	for idx, attachment := range attachments {
+		imgBytes, format, err := util.GetImageFromURL(attachment.URL)
+		if err != nil {
+			Common.GomanipError(s, i, "Batch failed", "failed to download "+attachment.Filename)
+			return err
+		}
+		images[idx] = gomanip.BatchImage{Name: attachment.Filename, Image: imgBytes, ContentType: format}
+	}
+
+	// discord rate limits edits, so progress is shown at most once per progressInterval
+	var lastProgress time.Time
+	onProgress := func(progress gomanip.Progress) {
+		if time.Since(lastProgress) < progressInterval {
+			return
+		}
+		lastProgress = time.Now()
+		Common.ReplyProgress("Processing images", progress.Done, progress.Total, s, i)
+	}
+
+	results, err := gomanip.Batch(a.Gomanip.ForGuild(i.GuildID), images, onProgress, operation)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Batch failed", err.Error())
+		return err
+	}
+
+	var files []*discordgo.File
+	var failures strings.Builder
+	for idx, result := range results {
+		if result.Error != "" {
+			fmt.Fprintf(&failures, "\n`%s`: %s", result.Name, result.Error)
+			continue
+		}
+		files = append(files, &discordgo.File{
+			Name:        fmt.Sprintf("%d.png", idx+1),
+			ContentType: "image/png",
+			Reader:      bytes.NewReader(result.Image),
+		})
+	}
+
+	content := fmt.Sprintf("Processed %d of %d images", len(files), len(results))
+	Common.ReplyContent(content+failures.String(), files, s, i)
+
+	return nil
+}
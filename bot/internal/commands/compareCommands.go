@@ -0,0 +1,113 @@
+package Commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+const (
+	heatmapName = "differences.png"
+
+	// images whose perceptual hashes differ by at most this many bits are treated as reposts,
+	// this survives recompression and resizing but not crops
+	repostHashDistance = 10
+	// above this structural similarity the images are treated as reposts whatever their hashes say
+	repostSSIM = 0.9
+)
+
+// downloadImages fetches the attachments given under the named options.
+func downloadImages(i *discordgo.InteractionCreate, names ...string) ([][]byte, []string, error) {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+
+	images := make([][]byte, len(names))
+	formats := make([]string, len(names))
+
+	for idx, name := range names {
+		attachmentID := options[name].Value.(string)
+		attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+
+		imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		images[idx] = imgBytes
+		formats[idx] = format
+	}
+
+	return images, formats, nil
+}
+
+func similarityFields(similarity *gomanip.Similarity) []*discordgo.MessageEmbedField {
+	return []*discordgo.MessageEmbedField{
+		{Name: "Similarity", Value: fmt.Sprintf("%.1f%%", similarity.SSIM*100), Inline: true},
+		{Name: "PSNR", Value: fmt.Sprintf("%.1f dB", similarity.PSNR), Inline: true},
+		{Name: "Hash distance", Value: fmt.Sprintf("%d / 64 bits", similarity.HashDistances.PHash), Inline: true},
+	}
+}
+
+func SpotTheDifference(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	images, formats, err := downloadImages(i, "first", "second")
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	similarity, err := gomanip.Compare(a.Gomanip.ForGuild(i.GuildID), images[0], formats[0], images[1], formats[1], true)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Comparing images failed", err.Error())
+		return err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Spot the difference",
+		Description: "The differences are highlighted in red",
+		Color:       0x00FF00,
+		Fields:      similarityFields(similarity),
+	}
+
+	Common.ReplyEmbedWithImage(embed, similarity.Heatmap, heatmapName, s, i)
+
+	return nil
+}
+
+func Repost(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	images, formats, err := downloadImages(i, "image", "original")
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	similarity, err := gomanip.Compare(a.Gomanip.ForGuild(i.GuildID), images[0], formats[0], images[1], formats[1], false)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Comparing images failed", err.Error())
+		return err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:  "Not a repost",
+		Color:  0x00FF00,
+		Fields: similarityFields(similarity),
+	}
+
+	if similarity.HashDistances.PHash <= repostHashDistance || similarity.SSIM >= repostSSIM {
+		embed.Title = "Repost detected"
+		embed.Color = 0xFF0000
+	}
+
+	Common.ReplyEmbed(embed, s, i)
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package Commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+const (
+	defaultEmojiFit   = "pad"
+	emojiFileName     = "emoji.png"
+	emojiExportTarget = "emoji"
+)
+
+var (
+	ErrNoEmojiPermission = errors.New("member cannot manage emoji")
+	ErrBadEmojiName      = errors.New("invalid emoji name")
+)
+
+// manageEmojiPermission limits the emoji command to members who could add the emoji themselves.
+var manageEmojiPermission int64 = discordgo.PermissionManageEmojis
+
+// emojiInDMs is false, emoji can only be added to a guild.
+var emojiInDMs = false
+
+func AddEmoji(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	// the command is hidden from members without the permission, but check again in case the guild overrides it
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionManageEmojis == 0 {
+		Common.Reply(s, i, "You need the Manage Emoji permission to add emoji")
+		return ErrNoEmojiPermission
+	}
+
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+	name := options["name"].StringValue()
+
+	fit := defaultEmojiFit
+	if option, ok := options["fit"]; ok {
+		fit = option.StringValue()
+	}
+
+	if !util.ValidEmojiName(name) {
+		Common.Reply(s, i, "Emoji names must be 2 to 32 letters, numbers or underscores")
+		return ErrBadEmojiName
+	}
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	emojiImage, err := gomanip.Export(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, emojiExportTarget, fit)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Emoji export failed", err.Error())
+		return err
+	}
+
+	emoji, err := s.GuildEmojiCreate(i.GuildID, &discordgo.EmojiParams{
+		Name:  name,
+		Image: util.ImageDataURI(emojiImage, "image/png"),
+	})
+
+	if err != nil {
+		Common.GomanipError(s, i, "Adding the emoji failed", err.Error())
+		return err
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Emoji added",
+		Description: fmt.Sprintf("%s is now available as `:%s:`", emoji.MessageFormat(), emoji.Name),
+		Color:       0x00FF00,
+	}
+	Common.ReplyEmbedWithImage(embed, emojiImage, emojiFileName, s, i)
+
+	return nil
+}
@@ -11,6 +11,14 @@ import (
 	"github.com/trollLemon/DiscordBot/internal/util"
 )
 
+const (
+	defaultStyleIntensity       = 0.5
+	defaultKaleidoscopeSegments = 6
+)
+
+// minStyleIntensity is the lowest intensity the stylize command accepts, the service rejects 0.
+var minStyleIntensity = 0.01
+
 func RandomImageFilter(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
 	applicationData := i.ApplicationCommandData()
 	attachmentID := applicationData.Options[0].Value.(string)
@@ -28,7 +36,7 @@ func RandomImageFilter(s *discordgo.Session, i *discordgo.InteractionCreate, a *
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.RandomFilter(a.Gomanip, imgBytes, format, kernelOption, lowerOption, higherOption, normalizeOption)
+	img, err := gomanip.RandomFilter(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, kernelOption, lowerOption, higherOption, normalizeOption)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Random image filter failed", err.Error())
@@ -53,7 +61,7 @@ func InvertImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *applic
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.InvertImage(a.Gomanip, imgBytes, format)
+	img, err := gomanip.InvertImage(a.Gomanip.ForGuild(i.GuildID), imgBytes, format)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Invert image failed", err.Error())
@@ -79,7 +87,7 @@ func SaturateImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *appl
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.SaturateImage(a.Gomanip, imgBytes, format, saturationMagnitude)
+	img, err := gomanip.SaturateImage(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, saturationMagnitude)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Saturate image failed", err.Error())
@@ -107,7 +115,7 @@ func EdgeDetection(s *discordgo.Session, i *discordgo.InteractionCreate, a *appl
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.EdgeDetect(a.Gomanip, imgBytes, format, lowerBound, upperBound)
+	img, err := gomanip.EdgeDetect(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, lowerBound, upperBound)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Edge detection failed", err.Error())
@@ -133,7 +141,7 @@ func Dilate(s *discordgo.Session, i *discordgo.InteractionCreate, a *application
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.DilateImage(a.Gomanip, imgBytes, format, boxSize, iterations)
+	img, err := gomanip.DilateImage(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, boxSize, iterations)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Dilating image failed", err.Error())
@@ -159,7 +167,7 @@ func Erode(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.ErodeImage(a.Gomanip, imgBytes, format, boxSize, iterations)
+	img, err := gomanip.ErodeImage(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, boxSize, iterations)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Eroding image failed", err.Error())
@@ -192,7 +200,7 @@ func AddText(s *discordgo.Session, i *discordgo.InteractionCreate, a *applicatio
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.AddText(a.Gomanip, imgBytes, format, text, fontScale, x, y)
+	img, err := gomanip.AddText(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, text, fontScale, x, y)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Adding text failed", err.Error())
@@ -232,7 +240,7 @@ func RandomText(s *discordgo.Session, i *discordgo.InteractionCreate, a *applica
 
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.AddText(a.Gomanip, imgBytes, format, text, fontScale, x, y)
+	img, err := gomanip.AddText(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, text, fontScale, x, y)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Adding random text failed", err.Error())
@@ -258,7 +266,7 @@ func ReduceImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *applic
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.Reduced(a.Gomanip, imgBytes, format, quality)
+	img, err := gomanip.Reduced(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, quality)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Image quality reduction failed", err.Error())
@@ -283,7 +291,7 @@ func ShuffleImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *appli
 	}
 	Common.DeferReply(s, i)
 
-	img, err := gomanip.Shuffle(a.Gomanip, imgBytes, format, partitionsOption)
+	img, err := gomanip.Shuffle(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, partitionsOption)
 
 	if err != nil {
 		Common.GomanipError(s, i, "Shuffling image failed", err.Error())
@@ -293,3 +301,90 @@ func ShuffleImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *appli
 
 	return err
 }
+
+func StylizeImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+	style := options["style"].StringValue()
+
+	intensity := defaultStyleIntensity
+	if option, ok := options["intensity"]; ok {
+		intensity = option.FloatValue()
+	}
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	img, err := gomanip.Stylize(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, style, intensity)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Stylizing image failed", err.Error())
+	} else {
+		Common.ReplyGomanip(img, s, i)
+	}
+
+	return err
+}
+
+func MirrorImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+	side := options["side"].StringValue()
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	img, err := gomanip.Mirror(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, side)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Mirroring image failed", err.Error())
+	} else {
+		Common.ReplyGomanip(img, s, i)
+	}
+
+	return err
+}
+
+func KaleidoscopeImage(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	options := optionsByName(applicationData.Options)
+	attachmentID := options["image"].Value.(string)
+	attachmentURL := applicationData.Resolved.Attachments[attachmentID].URL
+
+	segments := int64(defaultKaleidoscopeSegments)
+	if option, ok := options["segments"]; ok {
+		segments = option.IntValue()
+	}
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	img, err := gomanip.Kaleidoscope(a.Gomanip.ForGuild(i.GuildID), imgBytes, format, segments, 0.5, 0.5)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Kaleidoscope failed", err.Error())
+	} else {
+		Common.ReplyGomanip(img, s, i)
+	}
+
+	return err
+}
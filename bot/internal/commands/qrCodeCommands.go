@@ -0,0 +1,99 @@
+package Commands
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+
+	"github.com/trollLemon/DiscordBot/internal/application"
+	"github.com/trollLemon/DiscordBot/internal/common"
+	"github.com/trollLemon/DiscordBot/internal/gomanip"
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+const (
+	defaultQRLevel = "medium"
+	defaultQRSize  = 256
+)
+
+// optionsByName maps the options given to a command by name, so optional options can be looked up.
+func optionsByName(options []*discordgo.ApplicationCommandInteractionDataOption) map[string]*discordgo.ApplicationCommandInteractionDataOption {
+	byName := make(map[string]*discordgo.ApplicationCommandInteractionDataOption, len(options))
+	for _, option := range options {
+		byName[option.Name] = option
+	}
+	return byName
+}
+
+func QRDecode(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	applicationData := i.ApplicationCommandData()
+	attachmentID := applicationData.Options[0].Value.(string)
+	attachmentURL := i.ApplicationCommandData().Resolved.Attachments[attachmentID].URL
+
+	imgBytes, format, err := util.GetImageFromURL(attachmentURL)
+
+	if err != nil {
+		Common.Reply(s, i, "Error downloading given attachment")
+		return err
+	}
+	Common.DeferReply(s, i)
+
+	codes, err := gomanip.QRDecode(a.Gomanip.ForGuild(i.GuildID), imgBytes, format)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Reading QR code failed", err.Error())
+		return err
+	}
+
+	if len(codes) == 0 {
+		Common.GomanipError(s, i, "Reading QR code failed", "no QR codes found in the image")
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("Found %d QR code(s)", len(codes)),
+		Color: 0x00FF00,
+	}
+
+	for idx, code := range codes {
+		text := code.Text
+		if text == "" {
+			text = "(could not be decoded)"
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:  fmt.Sprintf("Code #%d", idx+1),
+			Value: text,
+		})
+	}
+
+	Common.ReplyEmbed(embed, s, i)
+
+	return nil
+}
+
+func QREncode(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+	options := optionsByName(i.ApplicationCommandData().Options)
+	text := options["text"].StringValue()
+
+	level := defaultQRLevel
+	if option, ok := options["errorcorrection"]; ok {
+		level = option.StringValue()
+	}
+
+	size := int64(defaultQRSize)
+	if option, ok := options["size"]; ok {
+		size = option.IntValue()
+	}
+
+	Common.DeferReply(s, i)
+
+	img, err := gomanip.QREncode(a.Gomanip.ForGuild(i.GuildID), text, level, size)
+
+	if err != nil {
+		Common.GomanipError(s, i, "Creating QR code failed", err.Error())
+	} else {
+		Common.ReplyGomanip(img, s, i)
+	}
+
+	return err
+}
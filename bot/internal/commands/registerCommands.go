@@ -291,6 +291,257 @@ var (
 				},
 			},
 		},
+		{
+			Name:        "stylize",
+			Description: "redraw an image in an artistic style",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to operate on",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "style",
+					Description: "the style to draw the image in",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "cartoon", Value: "cartoon"},
+						{Name: "pencil sketch", Value: "sketch"},
+						{Name: "oil paint", Value: "oilpaint"},
+						{Name: "emboss", Value: "emboss"},
+						{Name: "comic", Value: "comic"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "intensity",
+					Description: "how strong the effect is, greater than 0 and at most 1 (default 0.5)",
+					Required:    false,
+					MinValue:    &minStyleIntensity,
+					MaxValue:    1,
+				},
+			},
+		},
+		{
+			Name:        "mirror",
+			Description: "mirror one side of an image onto the other",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to operate on",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "side",
+					Description: "the side that is kept and mirrored",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "left", Value: "left"},
+						{Name: "right", Value: "right"},
+						{Name: "top", Value: "top"},
+						{Name: "bottom", Value: "bottom"},
+						{Name: "top left quadrant", Value: "topleft"},
+						{Name: "top right quadrant", Value: "topright"},
+						{Name: "bottom left quadrant", Value: "bottomleft"},
+						{Name: "bottom right quadrant", Value: "bottomright"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "kaleidoscope",
+			Description: "turn an image into a kaleidoscope",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to operate on",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "segments",
+					Description: "number of mirrored wedges, between 2 and 64 (default 6)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name: "Invert images",
+			Type: discordgo.MessageApplicationCommand,
+		},
+		{
+			Name: "Old photos",
+			Type: discordgo.MessageApplicationCommand,
+		},
+		{
+			Name: "Remove backgrounds",
+			Type: discordgo.MessageApplicationCommand,
+		},
+		{
+			Name: "Kaleidoscope images",
+			Type: discordgo.MessageApplicationCommand,
+		},
+		{
+			Name:                     "emoji",
+			Description:              "fit an image to an emoji and add it to the server",
+			DefaultMemberPermissions: &manageEmojiPermission,
+			DMPermission:             &emojiInDMs,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to turn into an emoji",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "the emoji name, 2 to 32 letters, numbers or underscores",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "fit",
+					Description: "pad the image with transparency or crop it to a square (default pad)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "pad", Value: "pad"},
+						{Name: "crop", Value: "crop"},
+					},
+				},
+			},
+		},
+		{
+			Name:        "qrdecode",
+			Description: "read the QR codes in an image",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image with QR codes",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "qrencode",
+			Description: "create a QR code",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "text",
+					Description: "text or link to put in the QR code",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "errorcorrection",
+					Description: "how much of the code can be damaged and still be read (default medium)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "low", Value: "low"},
+						{Name: "medium", Value: "medium"},
+						{Name: "high", Value: "high"},
+						{Name: "highest", Value: "highest"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "size",
+					Description: "width and height of the image in pixels, between 32 and 2048 (default 256)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "analyze",
+			Description: "show the colors, sharpness and fingerprint of an image",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to analyze",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "colors",
+					Description: "how many dominant colors to find, between 1 and 16 (default 5)",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "spotthedifference",
+			Description: "highlight the differences between two images",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "first",
+					Description: "the first image",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "second",
+					Description: "the second image",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "repost",
+			Description: "check whether an image is a repost of another",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the suspected repost",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "original",
+					Description: "the original image",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "asciiart",
+			Description: "draw an image with text characters or emoji",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionAttachment,
+					Name:        "image",
+					Description: "the image to draw",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "columns",
+					Description: "width in characters, between 1 and 500 (default 60, or 20 for emoji)",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "emoji",
+					Description: "draw with colored square emoji instead of characters",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "characters",
+					Description: "characters from the sparsest to the densest, i.e \" .:-=+*#%@\"",
+					Required:    false,
+				},
+			},
+		},
 		{
 			Name:        "classify",
 			Description: "classify an image",
@@ -347,6 +598,48 @@ var (
 		"shuffleimage": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
 			return ShuffleImage(s, i, a)
 		},
+		"stylize": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return StylizeImage(s, i, a)
+		},
+		"mirror": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return MirrorImage(s, i, a)
+		},
+		"kaleidoscope": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return KaleidoscopeImage(s, i, a)
+		},
+		"Invert images": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return BatchImages(s, i, a)
+		},
+		"Old photos": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return BatchImages(s, i, a)
+		},
+		"Remove backgrounds": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return BatchImages(s, i, a)
+		},
+		"Kaleidoscope images": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return BatchImages(s, i, a)
+		},
+		"emoji": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return AddEmoji(s, i, a)
+		},
+		"qrdecode": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return QRDecode(s, i, a)
+		},
+		"qrencode": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return QREncode(s, i, a)
+		},
+		"analyze": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return Analyze(s, i, a)
+		},
+		"spotthedifference": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return SpotTheDifference(s, i, a)
+		},
+		"repost": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return Repost(s, i, a)
+		},
+		"asciiart": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
+			return AsciiArt(s, i, a)
+		},
 		"classify": func(s *discordgo.Session, i *discordgo.InteractionCreate, a *application.Application) error {
 			return Classify(s, i, a)
 		},
@@ -2,6 +2,8 @@ package Common
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/rs/zerolog/log"
@@ -58,6 +60,72 @@ func ReplyGomanip(image []byte, s *discordgo.Session, i *discordgo.InteractionCr
 	}
 }
 
+func ReplyEmbed(embed *discordgo.MessageEmbed, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	responseEdit := &discordgo.WebhookEdit{
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, responseEdit); err != nil {
+		log.Error().Err(err).Msg("Interaction Response")
+	}
+}
+
+// ReplyEmbedWithImage attaches image under the given file name and shows it inside the embed.
+func ReplyEmbedWithImage(embed *discordgo.MessageEmbed, image []byte, name string, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	embed.Image = &discordgo.MessageEmbedImage{URL: "attachment://" + name}
+
+	responseEdit := &discordgo.WebhookEdit{
+		Files: []*discordgo.File{
+			{
+				Name:        name,
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(image),
+			},
+		},
+		Embeds: &[]*discordgo.MessageEmbed{embed},
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, responseEdit); err != nil {
+		log.Error().Err(err).Msg("Interaction Response")
+	}
+}
+
+// ReplyContent edits the deferred reply to show content, with any files attached.
+func ReplyContent(content string, files []*discordgo.File, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	responseEdit := &discordgo.WebhookEdit{
+		Content: &content,
+		Files:   files,
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, responseEdit); err != nil {
+		log.Error().Err(err).Msg("Interaction Response")
+	}
+}
+
+// progressBarWidth is how many blocks the progress bar of ReplyProgress has.
+const progressBarWidth = 10
+
+// ReplyProgress edits the deferred reply to show a progress bar, i.e while a batch runs.
+func ReplyProgress(label string, done, total int, s *discordgo.Session, i *discordgo.InteractionCreate) {
+	content := label + " " + ProgressBar(done, total, progressBarWidth)
+	responseEdit := &discordgo.WebhookEdit{
+		Content: &content,
+	}
+
+	if _, err := s.InteractionResponseEdit(i.Interaction, responseEdit); err != nil {
+		log.Error().Err(err).Msg("Interaction Response")
+	}
+}
+
+// ProgressBar draws done of total as a bar of width blocks followed by the count, i.e "▰▰▰▱▱ 3/5".
+func ProgressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = min(width, max(0, done*width/total))
+	}
+	return fmt.Sprintf("%s%s %d/%d", strings.Repeat("▰", filled), strings.Repeat("▱", width-filled), done, total)
+}
+
 func GomanipError(s *discordgo.Session, i *discordgo.InteractionCreate, errTitle, errString string) {
 	errEmbed := &discordgo.MessageEmbed{
 		Title:       errTitle,
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v5"
@@ -20,9 +21,17 @@ type ErrorResponse struct {
 	Detail string `json:"detail"`
 }
 
+// apiKeyHeader carries the key gomanip lets the bot in with.
+const apiKeyHeader = "X-API-Key"
+
+// clientHeader tells gomanip who a request is made for, so the guilds sharing the bot's key take turns.
+const clientHeader = "X-Client-ID"
+
 type GoManip struct {
 	apiEndpoint string
 	readTimeout time.Duration
+	apiKey      string
+	clientID    string
 }
 
 func NewGoManip(apiEndpoint string, readTimeout time.Duration) *GoManip {
@@ -32,11 +41,51 @@ func NewGoManip(apiEndpoint string, readTimeout time.Duration) *GoManip {
 	}
 }
 
-func (g *GoManip) try(apiURI, contentType string, imageBytesBuffer *bytes.Buffer) (*http.Response, error) {
+// WithAPIKey makes the client send apiKey with every request, for a server that needs one.
+func (g *GoManip) WithAPIKey(apiKey string) *GoManip {
+	g.apiKey = apiKey
+	return g
+}
+
+// ForGuild returns a copy of the client whose requests are made for guildID, direct messages have no guild
+// and keep the client as it is.
+func (g *GoManip) ForGuild(guildID string) *GoManip {
+	guild := *g
+	guild.clientID = guildID
+	return &guild
+}
+
+// authenticate adds the api key and the client the request is made for to req when the client has them.
+func (g *GoManip) authenticate(req *http.Request) {
+	if g.apiKey != "" {
+		req.Header.Set(apiKeyHeader, g.apiKey)
+	}
+	if g.clientID != "" {
+		req.Header.Set(clientHeader, g.clientID)
+	}
+}
+
+func (g *GoManip) try(apiURI, contentType string, imageBytesBuffer *bytes.Buffer, onProgress ProgressFunc) (*http.Response, error) {
 	client := http.Client{
 		Timeout: g.readTimeout,
 	}
-	resp, err := client.Post(apiURI, contentType, imageBytesBuffer)
+
+	req, err := http.NewRequest(http.MethodPost, apiURI, imageBytesBuffer)
+	if err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("error building request: %v; %w", err, apierrors.ErrNetwork))
+	}
+	req.Header.Set("Content-Type", contentType)
+	g.authenticate(req)
+
+	// every attempt is its own job on the server, so each one is followed under a new id
+	if onProgress != nil {
+		jobId := newJobId()
+		req.Header.Set(jobIdHeader, jobId)
+		stop := g.follow(jobId, onProgress)
+		defer stop()
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, backoff.Permanent(fmt.Errorf("error sending request: %v; %w", err, apierrors.ErrNetwork))
 
@@ -57,10 +106,29 @@ func (g *GoManip) try(apiURI, contentType string, imageBytesBuffer *bytes.Buffer
 		return nil, backoff.Permanent(fmt.Errorf("failed to unmarshal response body: %v; %w", err, apierrors.ErrResp))
 	}
 
-	if resp.StatusCode == http.StatusBadRequest {
+	// a job too expensive for its timeout fails the same way every time, like invalid parameters
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnprocessableEntity {
 		return nil, backoff.Permanent(fmt.Errorf("%s; %w", errorResponse.Detail, apierrors.ErrAPI))
 	}
 
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, backoff.Permanent(fmt.Errorf("%s; %w", errorResponse.Detail, apierrors.ErrUnauthorized))
+	}
+
+	// a short wait for the rate limit is retried, a quota that is used up for the day is not
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+		if err == nil && time.Duration(wait)*time.Second < g.readTimeout {
+			log.Warn().Int("retryAfter", wait).Msg("rate limited by gomanip, attempting to retry http call")
+			return nil, backoff.RetryAfter(wait)
+		}
+		return nil, backoff.Permanent(fmt.Errorf("%s; %w", errorResponse.Detail, apierrors.ErrRateLimited))
+	}
+
+	if resp.StatusCode == http.StatusGatewayTimeout {
+		return nil, backoff.Permanent(fmt.Errorf("%s; %w", errorResponse.Detail, apierrors.ErrTimedOut))
+	}
+
 	if resp.StatusCode >= 500 {
 		return nil, backoff.Permanent(fmt.Errorf("server reported error in response: %s; %w", errorResponse.Detail, apierrors.ErrServer))
 	}
@@ -71,6 +139,12 @@ func (g *GoManip) try(apiURI, contentType string, imageBytesBuffer *bytes.Buffer
 }
 
 func (g *GoManip) Do(image []byte, contentType, endpoint, queries string) ([]byte, error) {
+	return g.DoWithProgress(image, contentType, endpoint, queries, nil)
+}
+
+// DoWithProgress works like Do, onProgress is told how far the server got while the request runs.
+// It is not called anymore once DoWithProgress returned.
+func (g *GoManip) DoWithProgress(image []byte, contentType, endpoint, queries string, onProgress ProgressFunc) ([]byte, error) {
 	apiURI := fmt.Sprintf("%s/%s/%s", g.apiEndpoint, endpoint, queries)
 
 	var imageBytesBuffer *bytes.Buffer
@@ -83,7 +157,7 @@ func (g *GoManip) Do(image []byte, contentType, endpoint, queries string) ([]byt
 		timeoutCtx,
 		func() (*http.Response, error) {
 			imageBytesBuffer = bytes.NewBuffer(image)
-			return g.try(apiURI, contentType, imageBytesBuffer)
+			return g.try(apiURI, contentType, imageBytesBuffer, onProgress)
 		},
 		backoff.WithBackOff(backoff.NewExponentialBackOff()),
 	)
@@ -1,27 +1,41 @@
 package gomanip
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
 
 	"github.com/trollLemon/DiscordBot/internal/apiErrors"
 	"github.com/trollLemon/DiscordBot/internal/util"
 )
 
 var (
-	ErrTimedOut  = errors.New("the service timed out")
-	ErrBadParams = errors.New("the parameters are invalid")
-	ErrGeneral   = errors.New("something went wrong, try the command again")
+	ErrTimedOut     = errors.New("the service timed out")
+	ErrBadParams    = errors.New("the parameters are invalid")
+	ErrGeneral      = errors.New("something went wrong, try the command again")
+	ErrLimited      = errors.New("too many images were edited, try again later")
+	ErrUnauthorized = errors.New("the image service refused the bot's api key, check GOMANIP_API_KEY in its config")
 )
 
 func errorChecker(err error) error {
-	if errors.Is(err, apierrors.ErrRetry) {
+	if errors.Is(err, apierrors.ErrRetry) || errors.Is(err, apierrors.ErrTimedOut) {
 		return ErrTimedOut
 	}
 	// this error contains the error response from the json, so we can have the error message here and let the user know.
 	if errors.Is(err, apierrors.ErrAPI) {
 		return fmt.Errorf("%v, %w", err, ErrBadParams)
 	}
+	if errors.Is(err, apierrors.ErrRateLimited) {
+		return ErrLimited
+	}
+	if errors.Is(err, apierrors.ErrUnauthorized) {
+		return ErrUnauthorized
+	}
 	if err != nil {
 		return ErrGeneral 
 	}
@@ -82,3 +96,299 @@ func Shuffle(gomanipClient *GoManip, image []byte, contentType string, partition
 	return bytes, errorChecker(err)
 
 }
+
+func Stylize(gomanipClient *GoManip, image []byte, contentType, style string, intensity float64) ([]byte, error) {
+	queries := util.StylizeQuery(style, intensity)
+	bytes, err := gomanipClient.Do(image, contentType, "stylize", queries)
+	return bytes, errorChecker(err)
+}
+
+func Mirror(gomanipClient *GoManip, image []byte, contentType, side string) ([]byte, error) {
+	queries := util.MirrorQuery(side)
+	bytes, err := gomanipClient.Do(image, contentType, "mirror", queries)
+	return bytes, errorChecker(err)
+}
+
+func Kaleidoscope(gomanipClient *GoManip, image []byte, contentType string, segments int64, xPerc, yPerc float64) ([]byte, error) {
+	queries := util.KaleidoscopeQuery(segments, xPerc, yPerc)
+	bytes, err := gomanipClient.Do(image, contentType, "kaleidoscope", queries)
+	return bytes, errorChecker(err)
+}
+
+// Export fits an image to an emoji or sticker target, returning a png within its size and byte limits.
+func Export(gomanipClient *GoManip, image []byte, contentType, target, fit string) ([]byte, error) {
+	queries := util.ExportQuery(target, fit)
+	bytes, err := gomanipClient.Do(image, contentType, "export", queries)
+	return bytes, errorChecker(err)
+}
+
+type QRPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+type QRCode struct {
+	Text   string    `json:"text"`
+	Points []QRPoint `json:"points"`
+}
+
+type qrDecodeResponse struct {
+	Codes []QRCode `json:"codes"`
+}
+
+func QRDecode(gomanipClient *GoManip, image []byte, contentType string) ([]QRCode, error) {
+	body, err := gomanipClient.Do(image, contentType, "qr/decode", "")
+	if err != nil {
+		return nil, errorChecker(err)
+	}
+
+	var response qrDecodeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, errorChecker(fmt.Errorf("failed to unmarshal qr codes: %v; %w", err, apierrors.ErrResp))
+	}
+
+	return response.Codes, nil
+}
+
+func QREncode(gomanipClient *GoManip, text, level string, size int64) ([]byte, error) {
+	queries := util.QREncodeQuery(text, level, size)
+	bytes, err := gomanipClient.Do(nil, "", "qr/encode", queries)
+	return bytes, errorChecker(err)
+}
+
+type ChannelHistogram struct {
+	Channel string `json:"channel"`
+	Bins    []int  `json:"bins"`
+}
+
+type DominantColor struct {
+	Hex   string  `json:"hex"`
+	R     uint8   `json:"r"`
+	G     uint8   `json:"g"`
+	B     uint8   `json:"b"`
+	Share float64 `json:"share"`
+}
+
+type ImageHashes struct {
+	AHash string `json:"aHash"`
+	DHash string `json:"dHash"`
+	PHash string `json:"pHash"`
+}
+
+type Analysis struct {
+	Width             int                `json:"width"`
+	Height            int                `json:"height"`
+	Channels          int                `json:"channels"`
+	Depth             string             `json:"depth"`
+	Histograms        []ChannelHistogram `json:"histograms"`
+	DominantColors    []DominantColor    `json:"dominantColors"`
+	Brightness        float64            `json:"brightness"`
+	LaplacianVariance float64            `json:"laplacianVariance"`
+	Hashes            ImageHashes        `json:"hashes"`
+}
+
+func Analyze(gomanipClient *GoManip, image []byte, contentType string, colors, bins int64) (*Analysis, error) {
+	queries := util.AnalyzeQuery(colors, bins)
+	body, err := gomanipClient.Do(image, contentType, "analyze", queries)
+	if err != nil {
+		return nil, errorChecker(err)
+	}
+
+	var analysis Analysis
+	if err := json.Unmarshal(body, &analysis); err != nil {
+		return nil, errorChecker(fmt.Errorf("failed to unmarshal analysis: %v; %w", err, apierrors.ErrResp))
+	}
+
+	return &analysis, nil
+}
+
+type HashDistances struct {
+	AHash int `json:"aHash"`
+	DHash int `json:"dHash"`
+	PHash int `json:"pHash"`
+}
+
+type Similarity struct {
+	SSIM          float64       `json:"ssim"`
+	PSNR          float64       `json:"psnr"`
+	MSE           float64       `json:"mse"`
+	HashDistances HashDistances `json:"hashDistances"`
+	// Heatmap is only set when it was requested.
+	Heatmap []byte `json:"heatmap"`
+}
+
+// compareForm builds the multipart body holding both images for the compare endpoint.
+func compareForm(first []byte, firstType string, second []byte, secondType string) ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		name        string
+		image       []byte
+		contentType string
+	}{
+		{name: "first", image: first, contentType: firstType},
+		{name: "second", image: second, contentType: secondType},
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, part.name, part.name))
+		header.Set("Content-Type", part.contentType)
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := partWriter.Write(part.image); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+func Compare(gomanipClient *GoManip, first []byte, firstType string, second []byte, secondType string, heatmap bool) (*Similarity, error) {
+	form, contentType, err := compareForm(first, firstType, second, secondType)
+	if err != nil {
+		return nil, ErrGeneral
+	}
+
+	queries := util.CompareQuery(heatmap)
+	body, err := gomanipClient.Do(form, contentType, "compare", queries)
+	if err != nil {
+		return nil, errorChecker(err)
+	}
+
+	var similarity Similarity
+	if err := json.Unmarshal(body, &similarity); err != nil {
+		return nil, errorChecker(fmt.Errorf("failed to unmarshal similarity: %v; %w", err, apierrors.ErrResp))
+	}
+
+	return &similarity, nil
+}
+
+func AsciiArt(gomanipClient *GoManip, image []byte, contentType string, columns int64, ramp string, emoji bool) (string, error) {
+	queries := util.AsciiArtQuery(columns, ramp, emoji)
+	text, err := gomanipClient.Do(image, contentType, "ascii", queries)
+	return string(text), errorChecker(err)
+}
+
+// BatchImage is one image sent to the batch endpoint.
+type BatchImage struct {
+	Name        string
+	Image       []byte
+	ContentType string
+}
+
+// BatchResult is the processed image for one image of a batch, or the reason it failed in Error.
+type BatchResult struct {
+	Name  string
+	Image []byte
+	Error string
+}
+
+type batchManifestEntry struct {
+	Name  string `json:"name"`
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+// batchManifest is the file in the returned archive that lists the result of every image.
+const batchManifest = "results.json"
+
+// batchForm builds the multipart body holding every image for the batch endpoint.
+func batchForm(images []BatchImage) ([]byte, string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for _, image := range images {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="images"; filename="%s"`, image.Name))
+		header.Set("Content-Type", image.ContentType)
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := partWriter.Write(image.Image); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// readBatchArchive unpacks the zip returned by the batch endpoint into one result per image, in upload order.
+func readBatchArchive(body []byte) ([]BatchResult, error) {
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte, len(archive.File))
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[file.Name] = contents
+	}
+
+	var manifest []batchManifestEntry
+	if err := json.Unmarshal(files[batchManifest], &manifest); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(manifest))
+	for idx, entry := range manifest {
+		results[idx] = BatchResult{Name: entry.Name, Error: entry.Error}
+		if entry.Error != "" {
+			continue
+		}
+
+		image, ok := files[entry.File]
+		if !ok {
+			return nil, fmt.Errorf("archive is missing %s", entry.File)
+		}
+		results[idx].Image = image
+	}
+
+	return results, nil
+}
+
+// Batch runs the operations, in order, over every image in a single request.
+// Images that fail do not fail the batch, their result holds the error instead.
+// onProgress is told about every finished image when it is not nil.
+func Batch(gomanipClient *GoManip, images []BatchImage, onProgress ProgressFunc, operations ...string) ([]BatchResult, error) {
+	form, contentType, err := batchForm(images)
+	if err != nil {
+		return nil, ErrGeneral
+	}
+
+	queries := util.BatchQuery(operations...)
+	body, err := gomanipClient.DoWithProgress(form, contentType, "batch", queries, onProgress)
+	if err != nil {
+		return nil, errorChecker(err)
+	}
+
+	results, err := readBatchArchive(body)
+	if err != nil {
+		return nil, errorChecker(fmt.Errorf("failed to read batch results: %v; %w", err, apierrors.ErrResp))
+	}
+
+	return results, nil
+}
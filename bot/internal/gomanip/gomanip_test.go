@@ -1,6 +1,7 @@
 package gomanip_test
 
 import (
+	"archive/zip"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -135,6 +136,78 @@ func testEndpoint(t *testing.T, do func(*gomanip.GoManip, []byte, string) ([]byt
 			},
 		},
 
+		{
+			name:        "Unauthorized",
+			contentType: "image/png",
+			image:       image.NewRGBA(image.Rect(0, 0, 100, 100)),
+			wantErr:     gomanip.ErrUnauthorized,
+			genHandlerFunc: func(img *image.Image, contentType string, t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnauthorized)
+					w.Write([]byte(`{"status":"401","detail":"missing api key"}`))
+				}
+			},
+		},
+
+		{
+			name:        "Forbidden",
+			contentType: "image/png",
+			image:       image.NewRGBA(image.Rect(0, 0, 100, 100)),
+			wantErr:     gomanip.ErrUnauthorized,
+			genHandlerFunc: func(img *image.Image, contentType string, t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusForbidden)
+					w.Write([]byte(`{"status":"403","detail":"randomFilter: the api key does not allow this operation"}`))
+				}
+			},
+		},
+
+		{
+			name:        "Quota used up",
+			contentType: "image/png",
+			image:       image.NewRGBA(image.Rect(0, 0, 100, 100)),
+			wantErr:     gomanip.ErrLimited,
+			genHandlerFunc: func(img *image.Image, contentType string, t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					// longer than the read timeout, so it is not retried
+					w.Header().Set("Retry-After", "3600")
+					w.WriteHeader(http.StatusTooManyRequests)
+					w.Write([]byte(`{"status":"429","detail":"daily pixel quota exceeded"}`))
+				}
+			},
+		},
+
+		{
+			name:        "Too expensive",
+			contentType: "image/png",
+			image:       image.NewRGBA(image.Rect(0, 0, 100, 100)),
+			wantErr:     gomanip.ErrBadParams,
+			genHandlerFunc: func(img *image.Image, contentType string, t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					w.Write([]byte(`{"status":"422","detail":"job is expected to take longer than its timeout"}`))
+				}
+			},
+		},
+
+		{
+			name:        "Job timed out",
+			contentType: "image/png",
+			image:       image.NewRGBA(image.Rect(0, 0, 100, 100)),
+			wantErr:     gomanip.ErrTimedOut,
+			genHandlerFunc: func(img *image.Image, contentType string, t *testing.T) func(w http.ResponseWriter, r *http.Request) {
+				return func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusGatewayTimeout)
+					w.Write([]byte(`{"status":"504","detail":"job cancelled due to timeout"}`))
+				}
+			},
+		},
+
 		{
 			name:        "TCP error",
 			contentType: "image/png",
@@ -243,12 +316,51 @@ func TestEndpoints(t *testing.T) {
 			},
 		},
 
+		{
+			about: "Export Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				return gomanip.Export(g, bytes, contentType, "emoji", "pad")
+			},
+		},
+
+		{
+			about: "QREncode Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				return gomanip.QREncode(g, "bleh", "medium", 256)
+			},
+		},
+
 		{
 			about: "ReduceImage Endpoint",
 			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
 				return gomanip.Reduced(g, bytes, contentType, 0.1)
 			},
 		},
+		{
+			about: "Stylize Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				return gomanip.Stylize(g, bytes, contentType, "cartoon", 0.5)
+			},
+		},
+		{
+			about: "Mirror Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				return gomanip.Mirror(g, bytes, contentType, "left")
+			},
+		},
+		{
+			about: "Kaleidoscope Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				return gomanip.Kaleidoscope(g, bytes, contentType, 6, 0.5, 0.5)
+			},
+		},
+		{
+			about: "AsciiArt Endpoint",
+			do: func(g *gomanip.GoManip, bytes []byte, contentType string) ([]byte, error) {
+				text, err := gomanip.AsciiArt(g, bytes, contentType, 60, " .:#", false)
+				return []byte(text), err
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.about, func(t *testing.T) {
@@ -256,3 +368,361 @@ func TestEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestQRDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		wantErr  error
+		expected []gomanip.QRCode
+	}{
+		{
+			name:   "Success",
+			body:   `{"codes": [{"text": "hello", "points": [{"x": 1, "y": 2}, {"x": 3, "y": 2}, {"x": 3, "y": 4}, {"x": 1, "y": 4}]}]}`,
+			status: http.StatusOK,
+			expected: []gomanip.QRCode{
+				{Text: "hello", Points: []gomanip.QRPoint{{X: 1, Y: 2}, {X: 3, Y: 2}, {X: 3, Y: 4}, {X: 1, Y: 4}}},
+			},
+		},
+		{
+			name:     "No codes",
+			body:     `{"codes": []}`,
+			status:   http.StatusOK,
+			expected: []gomanip.QRCode{},
+		},
+		{
+			name:    "Malformed response",
+			body:    `not json`,
+			status:  http.StatusOK,
+			wantErr: gomanip.ErrGeneral,
+		},
+		{
+			name:    "Bad Request",
+			body:    `{"detail": "bad image"}`,
+			status:  http.StatusBadRequest,
+			wantErr: gomanip.ErrBadParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/qr/decode/", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			codes, err := gomanip.QRDecode(gomanip.NewGoManip(mockServer.URL, readTimeout), []byte{}, "image/png")
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "returned wrong type of error, want \" %s \", got \" %s \"", tt.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, codes)
+		})
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		wantErr  error
+		expected *gomanip.Analysis
+	}{
+		{
+			name: "Success",
+			body: `{"width": 2, "height": 1, "channels": 3, "depth": "8U",
+				"histograms": [{"channel": "blue", "bins": [1, 1]}],
+				"dominantColors": [{"hex": "#ff0000", "r": 255, "g": 0, "b": 0, "share": 0.5}],
+				"brightness": 76.5, "laplacianVariance": 12.25,
+				"hashes": {"aHash": "ff00000000000000", "dHash": "0000000000000000", "pHash": "8000000000000000"}}`,
+			status: http.StatusOK,
+			expected: &gomanip.Analysis{
+				Width:             2,
+				Height:            1,
+				Channels:          3,
+				Depth:             "8U",
+				Histograms:        []gomanip.ChannelHistogram{{Channel: "blue", Bins: []int{1, 1}}},
+				DominantColors:    []gomanip.DominantColor{{Hex: "#ff0000", R: 255, Share: 0.5}},
+				Brightness:        76.5,
+				LaplacianVariance: 12.25,
+				Hashes:            gomanip.ImageHashes{AHash: "ff00000000000000", DHash: "0000000000000000", PHash: "8000000000000000"},
+			},
+		},
+		{
+			name:    "Malformed response",
+			body:    `not json`,
+			status:  http.StatusOK,
+			wantErr: gomanip.ErrGeneral,
+		},
+		{
+			name:    "Bad Request",
+			body:    `{"detail": "bad image"}`,
+			status:  http.StatusBadRequest,
+			wantErr: gomanip.ErrBadParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/analyze/", r.URL.Path)
+				assert.Equal(t, "5", r.URL.Query().Get("colors"))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			analysis, err := gomanip.Analyze(gomanip.NewGoManip(mockServer.URL, readTimeout), []byte{}, "image/png", 5, 32)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "returned wrong type of error, want \" %s \", got \" %s \"", tt.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, analysis)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		wantErr  error
+		expected *gomanip.Similarity
+	}{
+		{
+			name:   "Success",
+			body:   `{"ssim": 0.75, "psnr": 21.5, "mse": 460.25, "hashDistances": {"aHash": 3, "dHash": 5, "pHash": 2}, "heatmap": "aGVhdG1hcA=="}`,
+			status: http.StatusOK,
+			expected: &gomanip.Similarity{
+				SSIM:          0.75,
+				PSNR:          21.5,
+				MSE:           460.25,
+				HashDistances: gomanip.HashDistances{AHash: 3, DHash: 5, PHash: 2},
+				Heatmap:       []byte("heatmap"),
+			},
+		},
+		{
+			name:    "Malformed response",
+			body:    `not json`,
+			status:  http.StatusOK,
+			wantErr: gomanip.ErrGeneral,
+		},
+		{
+			name:    "Bad Request",
+			body:    `{"detail": "image/gif files are not supported"}`,
+			status:  http.StatusBadRequest,
+			wantErr: gomanip.ErrBadParams,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/compare/", r.URL.Path)
+				assert.Equal(t, "true", r.URL.Query().Get("heatmap"))
+
+				first, header, err := r.FormFile("first")
+				assert.Nil(t, err)
+				first.Close()
+				assert.Equal(t, "image/png", header.Header.Get("Content-Type"))
+
+				second, header, err := r.FormFile("second")
+				assert.Nil(t, err)
+				second.Close()
+				assert.Equal(t, "image/jpeg", header.Header.Get("Content-Type"))
+
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer mockServer.Close()
+
+			similarity, err := gomanip.Compare(gomanip.NewGoManip(mockServer.URL, readTimeout), []byte("first"), "image/png", []byte("second"), "image/jpeg", true)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "returned wrong type of error, want \" %s \", got \" %s \"", tt.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, similarity)
+		})
+	}
+}
+
+func batchArchive(t *testing.T, files map[string]string) []byte {
+	var body bytes.Buffer
+	archive := zip.NewWriter(&body)
+	for name, contents := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(contents))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.Bytes()
+}
+
+func TestBatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     []byte
+		status   int
+		wantErr  error
+		expected []gomanip.BatchResult
+	}{
+		{
+			name: "Success",
+			body: batchArchive(t, map[string]string{
+				"results.json": `[{"name": "cat.png", "file": "001-cat.png"}, {"name": "dog.jpg", "error": "could not decode image"}]`,
+				"001-cat.png":  "cat",
+			}),
+			status: http.StatusOK,
+			expected: []gomanip.BatchResult{
+				{Name: "cat.png", Image: []byte("cat")},
+				{Name: "dog.jpg", Error: "could not decode image"},
+			},
+		},
+		{
+			name:    "Missing result file",
+			body:    batchArchive(t, map[string]string{"results.json": `[{"name": "cat.png", "file": "001-cat.png"}]`}),
+			status:  http.StatusOK,
+			wantErr: gomanip.ErrGeneral,
+		},
+		{
+			name:    "Not a zip",
+			body:    []byte("not a zip"),
+			status:  http.StatusOK,
+			wantErr: gomanip.ErrGeneral,
+		},
+		{
+			name:    "Bad Request",
+			body:    []byte(`{"detail": "operation detect can not be used in a batch"}`),
+			status:  http.StatusBadRequest,
+			wantErr: gomanip.ErrBadParams,
+		},
+	}
+
+	images := []gomanip.BatchImage{
+		{Name: "cat.png", Image: []byte("cat"), ContentType: "image/png"},
+		{Name: "dog.jpg", Image: []byte("dog"), ContentType: "image/jpeg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/batch/", r.URL.Path)
+				assert.Equal(t, "reduction,filmGrain", r.URL.Query().Get("operation"))
+				assert.Equal(t, "zip", r.URL.Query().Get("format"))
+
+				assert.Nil(t, r.ParseMultipartForm(1<<20))
+				headers := r.MultipartForm.File["images"]
+				assert.Len(t, headers, len(images))
+				for idx, header := range headers {
+					assert.Equal(t, images[idx].Name, header.Filename)
+					assert.Equal(t, images[idx].ContentType, header.Header.Get("Content-Type"))
+				}
+
+				w.WriteHeader(tt.status)
+				w.Write(tt.body)
+			}))
+			defer mockServer.Close()
+
+			results, err := gomanip.Batch(gomanip.NewGoManip(mockServer.URL, readTimeout), images, nil, "reduction", "filmGrain")
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "returned wrong type of error, want \" %s \", got \" %s \"", tt.wantErr, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, results)
+		})
+	}
+}
+
+func TestBatchProgress(t *testing.T) {
+	reported := make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.PathValue("id"))
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: queued\ndata: {\"job\":1}\n\n" +
+			": keep-alive\n\n" +
+			"event: progress\ndata: {\"progress\":{\"done\":1,\"total\":2,\"unit\":\"image\"}}\n\n" +
+			"event: progress\ndata: {\"progress\":{\"done\":2,\"total\":2,\"unit\":\"image\"}}\n\n" +
+			"event: done\ndata: {\"status\":200}\n\n"))
+	})
+	mux.HandleFunc("POST /batch/", func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("X-Job-ID"))
+		// the batch only returns once all progress arrived, later progress is not reported
+		select {
+		case <-reported:
+		case <-time.After(readTimeout / 2):
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(batchArchive(t, map[string]string{"results.json": `[]`}))
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	var progress []gomanip.Progress
+	_, err := gomanip.Batch(gomanip.NewGoManip(mockServer.URL, readTimeout), nil, func(p gomanip.Progress) {
+		progress = append(progress, p)
+		if p.Done == p.Total {
+			close(reported)
+		}
+	}, "invert")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []gomanip.Progress{
+		{Done: 1, Total: 2, Unit: "image"},
+		{Done: 2, Total: 2, Unit: "image"},
+	}, progress)
+}
+
+func TestAPIKey(t *testing.T) {
+	attempts := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /invert/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bot-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "guild", r.Header.Get("X-Client-ID"))
+		attempts++
+		// the first attempt hits the rate limit and is retried after the wait the server asks for
+		if attempts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"429","detail":"rate limit exceeded"}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("image"))
+	})
+	mux.HandleFunc("GET /jobs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bot-key", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "guild", r.Header.Get("X-Client-ID"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("event: done\ndata: {\"status\":200}\n\n"))
+	})
+	mockServer := httptest.NewServer(mux)
+	defer mockServer.Close()
+
+	manip := gomanip.NewGoManip(mockServer.URL, readTimeout).WithAPIKey("bot-key").ForGuild("guild")
+	result, err := manip.DoWithProgress([]byte("image"), "image/png", "invert", "", func(gomanip.Progress) {})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("image"), result)
+	assert.Equal(t, 2, attempts)
+}
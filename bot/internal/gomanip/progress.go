@@ -0,0 +1,103 @@
+package gomanip
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// jobIdHeader names a request so its events can be followed while it runs.
+const jobIdHeader = "X-Job-ID"
+
+// Progress is how far the server got with a request, i.e frame 3 of 20 or image 2 of 5.
+type Progress struct {
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	Unit  string `json:"unit"`
+}
+
+// ProgressFunc is told about the progress of a request, calls never overlap.
+type ProgressFunc func(progress Progress)
+
+type progressEvent struct {
+	Progress *Progress `json:"progress"`
+}
+
+func newJobId() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// follow reads the events of the job with the given id and reports its progress to onProgress until stop is called.
+// Progress is only nice to have, so failing to follow a job is logged rather than failing the request.
+func (g *GoManip) follow(jobId string, onProgress ProgressFunc) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		if err := g.readEvents(ctx, jobId, onProgress); err != nil && ctx.Err() == nil {
+			log.Warn().Err(err).Str("job", jobId).Msg("failed to follow gomanip job")
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// readEvents reads the server-sent events of a job until its done event.
+func (g *GoManip) readEvents(ctx context.Context, jobId string, onProgress ProgressFunc) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/jobs/%s/events", g.apiEndpoint, jobId), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	g.authenticate(req)
+
+	// the stream lasts as long as the job, the context ends it
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status not OK, got: %d", resp.StatusCode)
+	}
+
+	var name, data string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		case line == "":
+			// a blank line ends an event
+			if name == "done" {
+				return nil
+			}
+			if name == "progress" {
+				var event progressEvent
+				if err := json.Unmarshal([]byte(data), &event); err == nil && event.Progress != nil {
+					onProgress(*event.Progress)
+				}
+			}
+			name, data = "", ""
+		}
+	}
+
+	return scanner.Err()
+}
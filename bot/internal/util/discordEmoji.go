@@ -0,0 +1,20 @@
+package util
+
+import (
+	"encoding/base64"
+	"regexp"
+)
+
+// emojiName matches the names Discord accepts for custom emoji.
+var emojiName = regexp.MustCompile(`^[A-Za-z0-9_]{2,32}$`)
+
+// ValidEmojiName reports whether name can be used for a custom emoji,
+// which takes 2 to 32 letters, digits or underscores.
+func ValidEmojiName(name string) bool {
+	return emojiName.MatchString(name)
+}
+
+// ImageDataURI encodes an image as a base64 data URI, the format Discord expects for uploaded emoji.
+func ImageDataURI(image []byte, contentType string) string {
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(image)
+}
@@ -0,0 +1,33 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+func TestValidEmojiName(t *testing.T) {
+	tests := []struct {
+		name     string
+		emoji    string
+		expected bool
+	}{
+		{name: "Letters digits and underscores", emoji: "gopher_2", expected: true},
+		{name: "Too short", emoji: "g", expected: false},
+		{name: "Too long", emoji: "abcdefghijklmnopqrstuvwxyz1234567", expected: false},
+		{name: "Spaces", emoji: "go pher", expected: false},
+		{name: "Dashes", emoji: "go-pher", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, util.ValidEmojiName(tt.emoji))
+		})
+	}
+}
+
+func TestImageDataURI(t *testing.T) {
+	assert.Equal(t, "data:image/png;base64,AQID", util.ImageDataURI([]byte{1, 2, 3}, "image/png"))
+}
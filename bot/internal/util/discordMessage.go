@@ -0,0 +1,39 @@
+package util
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MessageLimit is the most characters Discord allows in a message.
+const MessageLimit = 2000
+
+const codeFence = "```"
+
+// FitCodeBlock wraps text in a code block of at most limit characters. When the text does not fit,
+// whole lines are dropped from the end and truncated is set.
+func FitCodeBlock(text string, limit int) (block string, truncated bool) {
+	// the opening and closing fences each take their own line
+	budget := limit - 2*(utf8.RuneCountInString(codeFence)+1)
+
+	lines := strings.Split(text, "\n")
+	used := 0
+	kept := 0
+
+	for _, line := range lines {
+		length := utf8.RuneCountInString(line)
+		if kept > 0 {
+			// the newline separating this line from the previous one
+			length++
+		}
+		if used+length > budget {
+			break
+		}
+		used += length
+		kept++
+	}
+
+	body := strings.Join(lines[:kept], "\n")
+
+	return codeFence + "\n" + body + "\n" + codeFence, kept < len(lines)
+}
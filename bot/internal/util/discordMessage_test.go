@@ -0,0 +1,59 @@
+package util_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/trollLemon/DiscordBot/internal/util"
+)
+
+func TestFitCodeBlock(t *testing.T) {
+	tests := []struct {
+		name              string
+		text              string
+		limit             int
+		expected          string
+		expectedTruncated bool
+	}{
+		{
+			name:     "Fits",
+			text:     "ab\ncd",
+			limit:    util.MessageLimit,
+			expected: "```\nab\ncd\n```",
+		},
+		{
+			name:              "Drops lines that do not fit",
+			text:              "ab\ncd\nef",
+			limit:             13,
+			expected:          "```\nab\ncd\n```",
+			expectedTruncated: true,
+		},
+		{
+			name:              "Counts characters rather than bytes",
+			text:              "🟥🟥\n🟦🟦\n🟩🟩",
+			limit:             13,
+			expected:          "```\n🟥🟥\n🟦🟦\n```",
+			expectedTruncated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			block, truncated := util.FitCodeBlock(tt.text, tt.limit)
+			assert.Equal(t, tt.expected, block)
+			assert.Equal(t, tt.expectedTruncated, truncated)
+			assert.LessOrEqual(t, utf8.RuneCountInString(block), tt.limit)
+		})
+	}
+}
+
+func TestFitCodeBlockLargeText(t *testing.T) {
+	text := strings.Repeat(strings.Repeat("#", 80)+"\n", 100)
+
+	block, truncated := util.FitCodeBlock(text, util.MessageLimit)
+	assert.True(t, truncated)
+	assert.LessOrEqual(t, utf8.RuneCountInString(block), util.MessageLimit)
+}
@@ -3,6 +3,7 @@ package util
 import (
 	"fmt"
 	"net/url"
+	"strings"
 )
 
 func SaturateQuery(saturationValue float32) string {
@@ -45,3 +46,51 @@ func RandomFilterQuery(kernelSize, minVal, maxVal int64, normalize bool) string
 func ShuffleQuery(partitions int64) string {
 	return fmt.Sprintf("?partitions=%d", partitions)
 }
+
+func QREncodeQuery(text, level string, size int64) string {
+
+	uriText := url.QueryEscape(text)
+
+	return fmt.Sprintf("?text=%s&level=%s&size=%d", uriText, level, size)
+}
+
+func AnalyzeQuery(colors, bins int64) string {
+
+	return fmt.Sprintf("?colors=%d&bins=%d", colors, bins)
+}
+
+func CompareQuery(heatmap bool) string {
+
+	return fmt.Sprintf("?heatmap=%t", heatmap)
+}
+
+func AsciiArtQuery(columns int64, ramp string, emoji bool) string {
+
+	return fmt.Sprintf("?columns=%d&ramp=%s&emoji=%t&format=text", columns, url.QueryEscape(ramp), emoji)
+}
+
+func StylizeQuery(style string, intensity float64) string {
+
+	return fmt.Sprintf("?style=%s&intensity=%0.2f", style, intensity)
+}
+
+func MirrorQuery(side string) string {
+
+	return fmt.Sprintf("?side=%s", side)
+}
+
+func KaleidoscopeQuery(segments int64, xPerc, yPerc float64) string {
+
+	return fmt.Sprintf("?segments=%d&xPerc=%0.2f&yPerc=%0.2f", segments, xPerc, yPerc)
+}
+
+func ExportQuery(target, fit string) string {
+
+	return fmt.Sprintf("?target=%s&fit=%s", target, fit)
+}
+
+// BatchQuery asks for a zip of the results, the operations are run in order on every image.
+func BatchQuery(operations ...string) string {
+
+	return fmt.Sprintf("?operation=%s&format=zip", url.QueryEscape(strings.Join(operations, ",")))
+}
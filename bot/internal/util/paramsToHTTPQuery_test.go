@@ -225,3 +225,234 @@ func TestShuffleQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestQREncodeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		level    string
+		size     int64
+		expected string
+	}{
+		{
+			name:     "Test qr encode query",
+			text:     "text",
+			level:    "medium",
+			size:     256,
+			expected: "?text=text&level=medium&size=256",
+		},
+		{
+			name:     "Test qr encode query with a url",
+			text:     "https://example.com/?a=b",
+			level:    "high",
+			size:     512,
+			expected: "?text=https%3A%2F%2Fexample.com%2F%3Fa%3Db&level=high&size=512",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.QREncodeQuery(tt.text, tt.level, tt.size)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestAnalyzeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		colors   int64
+		bins     int64
+		expected string
+	}{
+		{
+			name:     "Test analyze query",
+			colors:   5,
+			bins:     32,
+			expected: "?colors=5&bins=32",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.AnalyzeQuery(tt.colors, tt.bins)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestCompareQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		heatmap  bool
+		expected string
+	}{
+		{
+			name:     "Test compare query with heatmap",
+			heatmap:  true,
+			expected: "?heatmap=true",
+		},
+		{
+			name:     "Test compare query without heatmap",
+			heatmap:  false,
+			expected: "?heatmap=false",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.CompareQuery(tt.heatmap)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestAsciiArtQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		columns  int64
+		ramp     string
+		emoji    bool
+		expected string
+	}{
+		{
+			name:     "Test ascii art query",
+			columns:  60,
+			ramp:     " .:#",
+			emoji:    false,
+			expected: "?columns=60&ramp=+.%3A%23&emoji=false&format=text",
+		},
+		{
+			name:     "Test emoji art query",
+			columns:  20,
+			ramp:     "",
+			emoji:    true,
+			expected: "?columns=20&ramp=&emoji=true&format=text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.AsciiArtQuery(tt.columns, tt.ramp, tt.emoji)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestStylizeQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		style     string
+		intensity float64
+		expected  string
+	}{
+		{
+			name:      "Test stylize query",
+			style:     "cartoon",
+			intensity: 0.5,
+			expected:  "?style=cartoon&intensity=0.50",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.StylizeQuery(tt.style, tt.intensity)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestMirrorQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		side     string
+		expected string
+	}{
+		{
+			name:     "Test mirror query",
+			side:     "topleft",
+			expected: "?side=topleft",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.MirrorQuery(tt.side)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestKaleidoscopeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		segments int64
+		xPerc    float64
+		yPerc    float64
+		expected string
+	}{
+		{
+			name:     "Test kaleidoscope query",
+			segments: 8,
+			xPerc:    0.5,
+			yPerc:    0.25,
+			expected: "?segments=8&xPerc=0.50&yPerc=0.25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.KaleidoscopeQuery(tt.segments, tt.xPerc, tt.yPerc)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestExportQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		fit      string
+		expected string
+	}{
+		{
+			name:     "Test export query",
+			target:   "emoji",
+			fit:      "crop",
+			expected: "?target=emoji&fit=crop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.ExportQuery(tt.target, tt.fit)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
+
+func TestBatchQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []string
+		expected   string
+	}{
+		{
+			name:       "Test single operation",
+			operations: []string{"invert"},
+			expected:   "?operation=invert&format=zip",
+		},
+		{
+			name:       "Test pipeline",
+			operations: []string{"reduction", "filmGrain"},
+			expected:   "?operation=reduction%2CfilmGrain&format=zip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queryStr := util.BatchQuery(tt.operations...)
+			assert.Equal(t, tt.expected, queryStr)
+		})
+	}
+}
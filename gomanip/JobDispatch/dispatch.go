@@ -1,65 +1,231 @@
 package JobDispatch
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"goManip/jobs"
 	"gocv.io/x/gocv"
+	"image/color"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
+// DefaultClient is the client of jobs from requests that do not identify one.
+const DefaultClient = "default"
+
+// JobDispatcher queues jobs for the workers. Views made with ForClient share the queue with
+// the dispatcher they came from, their jobs are scheduled for the given client and priority.
 type JobDispatcher struct {
-	jobId       uint32
-	jobRequests chan<- *jobs.JobRequest
-	maxTime     time.Duration
+	jobId     *atomic.Uint32
+	scheduler *Scheduler
+	timeouts  Timeouts
+	client    string
+	// priority overrides the priority of the operations when set
+	priority *jobs.Priority
+	// timeout is the timeout the client asked for, limited by the max timeout of each operation
+	timeout time.Duration
+	// onEvent is told about the state of the view's jobs when set
+	onEvent func(JobEvent)
+	// quota is charged the pixels of the view's jobs when set
+	quota PixelQuota
 }
 
-func NewJobDispatcher(jobRequests chan<- *jobs.JobRequest, maxTime time.Duration) *JobDispatcher {
-	return &JobDispatcher{jobId: 0, jobRequests: jobRequests, maxTime: maxTime}
+// PixelQuota limits how many pixels the jobs of a dispatcher view may process.
+type PixelQuota interface {
+	// Charge takes the pixels of a job before it is queued, the job is not run when it fails.
+	Charge(pixels int64) error
+	// Refund gives back the pixels of a job that was charged but never ran.
+	Refund(pixels int64)
 }
 
-func (j *JobDispatcher) awaitResult(jobRequest *jobs.JobRequest, ctx context.Context) (*gocv.NativeByteBuffer, error) {
+// NewJobDispatcher starts dispatching jobs to the workers reading jobRequests, queueing at most queueDepth jobs.
+func NewJobDispatcher(jobRequests chan<- *jobs.JobRequest, queueDepth int, timeouts Timeouts) *JobDispatcher {
+	return &JobDispatcher{
+		jobId:     &atomic.Uint32{},
+		scheduler: NewScheduler(jobRequests, queueDepth),
+		timeouts:  timeouts,
+		client:    DefaultClient,
+	}
+}
+
+// ForClient returns a view of the dispatcher queueing jobs for client, a nil priority keeps the priority of each operation.
+func (j *JobDispatcher) ForClient(client string, priority *jobs.Priority) *JobDispatcher {
+	view := *j
+	view.client = client
+	view.priority = priority
+	return &view
+}
+
+// Client is who the view queues jobs for.
+func (j *JobDispatcher) Client() string {
+	return j.client
+}
+
+// WithTimeout returns a view of the dispatcher giving jobs up to timeout instead of the default timeout of their operation.
+func (j *JobDispatcher) WithTimeout(timeout time.Duration) *JobDispatcher {
+	view := *j
+	view.timeout = timeout
+	return &view
+}
+
+// WithPixelQuota returns a view of the dispatcher that charges the pixels of each job's images to quota before the job
+// is queued, a job the quota turns down is not run and fails with its error. Jobs that could not be queued, or timed out
+// before a worker started them, are refunded.
+func (j *JobDispatcher) WithPixelQuota(quota PixelQuota) *JobDispatcher {
+	view := *j
+	view.quota = quota
+	return &view
+}
+
+func (j *JobDispatcher) awaitResult(jobRequest *jobs.JobRequest, ctx context.Context) (*gocv.Mat, error) {
 
 	select {
 	case result := <-jobRequest.Result:
+		return result.Image, result.Error
+	case <-ctx.Done():
+		return nil, ErrTimeout
+	}
+}
 
-		image, err := result.Image, result.Error
+// QueueWait is how long the longest waiting job has been waiting for a worker.
+func (j *JobDispatcher) QueueWait() time.Duration {
+	return j.scheduler.OldestWait()
+}
 
-		if err != nil {
-			return nil, err
-		}
+// queueDepth is how many requests the worker queue holds before dispatching blocks.
+func (j *JobDispatcher) queueDepth() int {
+	return j.scheduler.Depth()
+}
 
-		imageBytes, err := gocv.IMEncode(".png", *image)
+func (j *JobDispatcher) getNewJobId() uint32 {
+	return j.jobId.Add(1)
+}
 
-		if err != nil {
+func (j *JobDispatcher) priorityOf(job *jobs.Job) jobs.Priority {
+	if j.priority != nil {
+		return *j.priority
+	}
+	return jobs.PriorityOf(job.GetOperation())
+}
+
+func (j *JobDispatcher) timeoutOf(job *jobs.Job) time.Duration {
+	timeout := j.timeouts.of(job.GetOperation())
+	if j.timeout > 0 {
+		return min(j.timeout, timeout.Max)
+	}
+	return timeout.Default
+}
+
+// Close stops accepting jobs, the workers' channel is closed once the queued jobs were handed out.
+func (j *JobDispatcher) Close() {
+	j.scheduler.Close()
+}
+
+func (j *JobDispatcher) dispatch(job *jobs.Job) (*gocv.Mat, error) {
+	timeout := j.timeoutOf(job)
+
+	// turn away jobs that would only run into their timeout before they take up a worker
+	if estimate := job.Estimate(); estimate > timeout {
+		return nil, fmt.Errorf("%w: %s is expected to take %s on this image, the limit is %s",
+			ErrTooExpensive, jobs.NameOf(job.GetOperation()), estimate.Round(time.Millisecond), timeout)
+	}
+
+	images := job.Mats()
+	pixels := pixelsOf(images)
+	if j.quota != nil {
+		if err := j.quota.Charge(pixels); err != nil {
 			return nil, err
 		}
+	}
 
-		return imageBytes, nil
-
-	case <-ctx.Done():
-		return nil, errors.New("job cancelled due to timeout")
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	jobRequest := jobs.NewJobRequest(job, ctx)
 
+	acquireImages(images)
+	events := j.eventsOf(job)
+	err := events.queue(func() error {
+		return j.scheduler.Submit(jobRequest, j.client, j.priorityOf(job))
+	})
+	if err != nil {
+		finishImages(images)
+		j.refund(pixels)
+		return nil, err
 	}
+	watched := events.watchStart(jobRequest)
 
+	result, err := j.awaitResult(jobRequest, ctx)
+	<-watched
+	// a job that timed out may still report progress from its worker
+	events.close()
+	// once the job is done it either ran or was dropped from the queue for its timeout
+	done := func() {
+		finishImages(images)
+		select {
+		case <-jobRequest.Started:
+		default:
+			j.refund(pixels)
+		}
+	}
+	select {
+	case <-jobRequest.Done:
+		done()
+	default:
+		// the job timed out but is still queued or running, its images are released once it is done
+		go func() {
+			<-jobRequest.Done
+			done()
+		}()
+	}
+	return result, err
 }
 
-func (j *JobDispatcher) getNewJobId() uint32 {
-	return atomic.AddUint32(&j.jobId, 1)
+func (j *JobDispatcher) refund(pixels int64) {
+	if j.quota != nil {
+		j.quota.Refund(pixels)
+	}
 }
 
-func (j *JobDispatcher) Close() {
-	close(j.jobRequests)
+func pixelsOf(images []*gocv.Mat) int64 {
+	var pixels int64
+	for _, image := range images {
+		pixels += int64(image.Rows()) * int64(image.Cols())
+	}
+	return pixels
 }
 
 func (j *JobDispatcher) DispatchJob(job *jobs.Job) (*gocv.NativeByteBuffer, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), j.maxTime)
-	jobRequest := jobs.NewJobRequest(job, ctx)
-	j.jobRequests <- jobRequest
-	defer cancel()
-	return j.awaitResult(jobRequest, ctx)
+	image, err := j.dispatch(job)
+	if err != nil {
+		return nil, err
+	}
 
+	if image == nil {
+		return nil, errors.New("operation did not produce an image")
+	}
+	if !job.HoldsMat(image) {
+		defer image.Close()
+	}
+
+	imageBytes, err := gocv.IMEncode(".png", *image)
+	if err != nil {
+		return nil, err
+	}
+
+	return imageBytes, nil
+}
+
+// DispatchReportJob runs a job whose operation reports its results through its own fields
+// rather than an image, any image the operation returns is released.
+func (j *JobDispatcher) DispatchReportJob(job *jobs.Job) error {
+	image, err := j.dispatch(job)
+	if image != nil && !job.HoldsMat(image) {
+		image.Close()
+	}
+	return err
 }
 
 func EnqueueInvertImage(dispatcher *JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
@@ -94,12 +260,211 @@ func EnqueueAddText(dispatcher *JobDispatcher, image *gocv.Mat, text string, fon
 
 }
 
-func EnqueueRandomFilter(dispatcher *JobDispatcher, image *gocv.Mat, min, max, kernelSize int, normalize bool) (*gocv.NativeByteBuffer, error) {
-	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewRandomFilter(kernelSize, min, max, normalize), image)
+// EnqueueRandomFilter runs the given filter, once it returns without error filter.Kernels holds the generated kernels.
+func EnqueueRandomFilter(dispatcher *JobDispatcher, image *gocv.Mat, filter *jobs.RandomFilter) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), filter, image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueConvolve(dispatcher *JobDispatcher, image *gocv.Mat, kernels []jobs.Kernel, preset string) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewConvolve(kernels, preset), image)
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueShuffle runs the given shuffle, once it returns without error shuffle.Layout describes the result.
+func EnqueueShuffle(dispatcher *JobDispatcher, image *gocv.Mat, shuffle *jobs.Shuffle) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), shuffle, image)
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueDetect runs the given detection, once it returns without error detect.Boxes holds the detected regions.
+// Without an action only the boxes are produced and the returned buffer is nil.
+func EnqueueDetect(dispatcher *JobDispatcher, image *gocv.Mat, detect *jobs.Detect) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), detect, image)
+	if detect.Action == jobs.DetectNone {
+		return nil, dispatcher.DispatchReportJob(job)
+	}
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueQRDecode runs the given decode, once it returns without error decode.Codes holds the decoded codes.
+func EnqueueQRDecode(dispatcher *JobDispatcher, image *gocv.Mat, decode *jobs.QRDecode) error {
+	job := jobs.NewJob(dispatcher.getNewJobId(), decode, image)
+	return dispatcher.DispatchReportJob(job)
+}
+
+// EnqueueAnalyze runs the given analysis, once it returns without error analyze.Result holds the report.
+func EnqueueAnalyze(dispatcher *JobDispatcher, image *gocv.Mat, analyze *jobs.Analyze) error {
+	job := jobs.NewJob(dispatcher.getNewJobId(), analyze, image)
+	return dispatcher.DispatchReportJob(job)
+}
+
+// EnqueueCompare runs the given comparison, once it returns without error compare.Result holds the scores.
+// The heatmap image is only returned when compare.Heatmap is set.
+func EnqueueCompare(dispatcher *JobDispatcher, image *gocv.Mat, compare *jobs.Compare) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), compare, image)
+	if !compare.Heatmap {
+		return nil, dispatcher.DispatchReportJob(job)
+	}
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueAsciiArt runs the given rendering, once it returns without error art.Lines holds the text.
+func EnqueueAsciiArt(dispatcher *JobDispatcher, image *gocv.Mat, art *jobs.AsciiArt) error {
+	job := jobs.NewJob(dispatcher.getNewJobId(), art, image)
+	return dispatcher.DispatchReportJob(job)
+}
+
+func EnqueueStylize(dispatcher *JobDispatcher, image *gocv.Mat, style jobs.Style, intensity float64) (*gocv.NativeByteBuffer, error) {
+	op, err := jobs.NewStylization(style, intensity)
+	if err != nil {
+		return nil, err
+	}
+	job := jobs.NewJob(dispatcher.getNewJobId(), op, image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueSwirl(dispatcher *JobDispatcher, image *gocv.Mat, xPerc, yPerc, radius, strength float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewSwirl(xPerc, yPerc, radius, strength), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueBulge(dispatcher *JobDispatcher, image *gocv.Mat, xPerc, yPerc, radius, strength float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewBulge(xPerc, yPerc, radius, strength), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueWave(dispatcher *JobDispatcher, image *gocv.Mat, amplitude, wavelength float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewWave(amplitude, wavelength), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueFisheye(dispatcher *JobDispatcher, image *gocv.Mat, xPerc, yPerc, strength float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewFisheye(xPerc, yPerc, strength), image)
 	return dispatcher.DispatchJob(job)
 }
 
-func EnqueueShuffle(dispatcher *JobDispatcher, image *gocv.Mat, partitions int) (*gocv.NativeByteBuffer, error) {
-	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewShuffle(partitions), image)
+func EnqueueMirror(dispatcher *JobDispatcher, image *gocv.Mat, side jobs.MirrorSide) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewMirror(side), image)
 	return dispatcher.DispatchJob(job)
 }
+
+func EnqueueKaleidoscope(dispatcher *JobDispatcher, image *gocv.Mat, segments int, xPerc, yPerc float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewKaleidoscope(segments, xPerc, yPerc), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueGaussianNoise(dispatcher *JobDispatcher, image *gocv.Mat, sigma float64, seed int64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewGaussianNoise(sigma, seed), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueSaltAndPepper(dispatcher *JobDispatcher, image *gocv.Mat, amount float64, seed int64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewSaltAndPepper(amount, seed), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueFilmGrain(dispatcher *JobDispatcher, image *gocv.Mat, strength float64, seed int64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewFilmGrain(strength, seed), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueVignette(dispatcher *JobDispatcher, image *gocv.Mat, strength, radius float64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewVignette(strength, radius), image)
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueOldPhoto reduces the quality of the image, then adds film grain and a vignette in a single job.
+func EnqueueOldPhoto(dispatcher *JobDispatcher, image *gocv.Mat, quality float32, grain, vignette float64, seed int64) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewOldPhoto(quality, grain, vignette, seed), image)
+	return dispatcher.DispatchJob(job)
+}
+
+func EnqueueColorKey(dispatcher *JobDispatcher, image *gocv.Mat, key color.RGBA, auto bool, tolerance int) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), jobs.NewColorKey(key, auto, tolerance), image)
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueOperation runs any operation producing an image, i.e one built from the typed parameters of the gRPC API.
+func EnqueueOperation(dispatcher *JobDispatcher, image *gocv.Mat, op jobs.Operation) (*gocv.NativeByteBuffer, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), op, image)
+	return dispatcher.DispatchJob(job)
+}
+
+// EnqueueAnimation renders an animated GIF from the image and returns its bytes.
+func EnqueueAnimation(dispatcher *JobDispatcher, image *gocv.Mat, animation jobs.Animated) ([]byte, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), animation, image)
+	if err := dispatcher.DispatchReportJob(job); err != nil {
+		return nil, err
+	}
+	return animation.Encoded(), nil
+}
+
+// EnqueueExport fits the image to the export's target and returns the encoded png.
+func EnqueueExport(dispatcher *JobDispatcher, image *gocv.Mat, export *jobs.Export) ([]byte, error) {
+	job := jobs.NewJob(dispatcher.getNewJobId(), export, image)
+	if err := dispatcher.DispatchReportJob(job); err != nil {
+		return nil, err
+	}
+	return export.PNG, nil
+}
+
+// BatchResult is the outcome for one image of a batch, Error is set instead of Image when the job failed.
+type BatchResult struct {
+	Image []byte
+	Error error
+}
+
+// EnqueueBatch runs an operation from newOperation over every image, each image gets its own operation
+// since operations may keep state from a run. The jobs are spread across the workers, but no more than
+// the request queue holds are in flight at once so a batch cannot crowd out other requests.
+// The results are in the same order as the images. The progress of a batch is reported in finished images
+// rather than the progress of each image.
+func EnqueueBatch(dispatcher *JobDispatcher, images []*gocv.Mat, newOperation func() jobs.Operation) []BatchResult {
+	results := make([]BatchResult, len(images))
+	inFlight := make(chan struct{}, dispatcher.queueDepth())
+	wg := sync.WaitGroup{}
+
+	jobDispatcher := dispatcher
+	progress := sync.Mutex{}
+	finished := 0
+	if dispatcher.onEvent != nil {
+		jobDispatcher = dispatcher.WithEvents(func(event JobEvent) {
+			if event.State != JobProgress {
+				dispatcher.onEvent(event)
+			}
+		})
+	}
+
+	for idx, image := range images {
+		inFlight <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			defer func() {
+				if dispatcher.onEvent == nil {
+					return
+				}
+				progress.Lock()
+				defer progress.Unlock()
+				finished++
+				dispatcher.onEvent(JobEvent{State: JobProgress, Progress: &jobs.Progress{Done: finished, Total: len(images), Unit: jobs.UnitImage}})
+			}()
+
+			job := jobs.NewJob(jobDispatcher.getNewJobId(), newOperation(), image)
+			imageBytes, err := jobDispatcher.DispatchJob(job)
+			if err != nil {
+				results[idx].Error = err
+				return
+			}
+			defer imageBytes.Close()
+
+			// the buffer's bytes live in native memory that is freed on close
+			results[idx].Image = bytes.Clone(imageBytes.GetBytes())
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
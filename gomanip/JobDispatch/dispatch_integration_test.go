@@ -8,6 +8,7 @@ import (
 	"goManip/jobs"
 	"goManip/worker"
 	"gocv.io/x/gocv"
+	"image/color"
 	"sync"
 	"testing"
 	"time"
@@ -123,28 +124,140 @@ func TestDispatchIntegration(t *testing.T) {
 			name:    "Test Random Filter",
 			wantErr: false,
 			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-				return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, -1, 1, 3, true)
+				return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, jobs.NewRandomFilter(3, -1, 1, true))
 			},
 		},
 		{
 			name:    "Test Random Filter Error",
 			wantErr: true,
 			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-				return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, -1, 1, 0, true)
+				return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, jobs.NewRandomFilter(0, -1, 1, true))
+			},
+		},
+		{
+			name:    "Test Convolve",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueConvolve(jobDispatcher, image, []jobs.Kernel{{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}}}, "")
+			},
+		},
+		{
+			name:    "Test Convolve Preset",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueConvolve(jobDispatcher, image, nil, "sharpen")
+			},
+		},
+		{
+			name:    "Test Convolve Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueConvolve(jobDispatcher, image, nil, "")
+			},
+		},
+		{
+			name:    "Test Detect Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueDetect(jobDispatcher, image, jobs.NewDetect("", jobs.DetectDraw, 1.1, 3))
 			},
 		},
 		{
 			name:    "Test Shuffle",
 			wantErr: false,
 			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-				return JobDispatch.EnqueueShuffle(jobDispatcher, image, 64)
+				return JobDispatch.EnqueueShuffle(jobDispatcher, image, &jobs.Shuffle{Partitions: 64})
+			},
+		},
+		{
+			name:    "Test Grid Shuffle",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueShuffle(jobDispatcher, image, jobs.NewGridShuffle(3, 5, 0, true, true))
+			},
+		},
+		{
+			name:    "Test Grid Shuffle Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueShuffle(jobDispatcher, image, jobs.NewGridShuffle(0, 5, 0, false, false))
 			},
 		},
 		{
 			name:    "Test Shuffle Error",
 			wantErr: true,
 			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-				return JobDispatch.EnqueueShuffle(jobDispatcher, image, 0)
+				return JobDispatch.EnqueueShuffle(jobDispatcher, image, &jobs.Shuffle{Partitions: 0})
+			},
+		},
+		{
+			name:    "Test Stylize",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueStylize(jobDispatcher, image, jobs.StyleComic, 0.5)
+			},
+		},
+		{
+			name:    "Test Stylize Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueStylize(jobDispatcher, image, "watercolor", 0.5)
+			},
+		},
+		{
+			name:    "Test Compare Heatmap",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueCompare(jobDispatcher, image, jobs.NewCompare(image, true))
+			},
+		},
+		{
+			name:    "Test Compare Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueCompare(jobDispatcher, image, jobs.NewCompare(nil, true))
+			},
+		},
+		{
+			name:    "Test Film Grain",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueFilmGrain(jobDispatcher, image, 0.5, 42)
+			},
+		},
+		{
+			name:    "Test Vignette Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueVignette(jobDispatcher, image, 0.5, 1.0)
+			},
+		},
+		{
+			name:    "Test Old Photo",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueOldPhoto(jobDispatcher, image, 0.5, 0.4, 0.6, 7)
+			},
+		},
+		{
+			name:    "Test Old Photo Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueOldPhoto(jobDispatcher, image, 0.5, 0.0, 0.6, 7)
+			},
+		},
+		{
+			name:    "Test Remove Background",
+			wantErr: false,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueColorKey(jobDispatcher, image, color.RGBA{}, true, 30)
+			},
+		},
+		{
+			name:    "Test Remove Background Error",
+			wantErr: true,
+			fn: func(jobDispatcher *JobDispatch.JobDispatcher, image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+				return JobDispatch.EnqueueColorKey(jobDispatcher, image, color.RGBA{}, false, 300)
 			},
 		},
 	}
@@ -152,9 +265,9 @@ func TestDispatchIntegration(t *testing.T) {
 	defer goleak.VerifyNone(t)
 	numWorkers := 10
 
-	requestChan := make(chan *jobs.JobRequest, numWorkers)
+	requestChan := make(chan *jobs.JobRequest)
 	maxTime := time.Second * 1
-	jobDispatcher := JobDispatch.NewJobDispatcher(requestChan, maxTime)
+	jobDispatcher := JobDispatch.NewJobDispatcher(requestChan, numWorkers, fixedTimeouts(maxTime))
 	wg := new(sync.WaitGroup)
 	timeOutContext, cancel := context.WithTimeout(context.Background(), maxTime)
 	defer cancel()
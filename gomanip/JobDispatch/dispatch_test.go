@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/goleak"
 	"sync"
+	"sync/atomic"
 
 	"context"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"goManip/jobs"
 	"goManip/worker"
 	"gocv.io/x/gocv"
+	"slices"
 	"testing"
 	"time"
 )
@@ -38,6 +40,11 @@ func (m MockOperationSuccess) Run(input *gocv.Mat) (*gocv.Mat, error) {
 	return input, nil
 }
 
+// fixedTimeouts gives every operation the same timeout.
+func fixedTimeouts(timeout time.Duration) JobDispatch.Timeouts {
+	return JobDispatch.Timeouts{Default: JobDispatch.Timeout{Default: timeout, Max: timeout}}
+}
+
 func TestDispatchJob(t *testing.T) {
 
 	testImage := gocv.NewMatWithSize(1920, 1080, gocv.MatTypeCV8UC3)
@@ -72,7 +79,7 @@ func TestDispatchJob(t *testing.T) {
 	requests := make(chan *jobs.JobRequest)
 	ctx, cancel := context.WithCancel(context.Background())
 
-	jobDispatcher := JobDispatch.NewJobDispatcher(requests, time.Millisecond*2)
+	jobDispatcher := JobDispatch.NewJobDispatcher(requests, 1, fixedTimeouts(time.Millisecond*2))
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
@@ -96,3 +103,224 @@ func TestDispatchJob(t *testing.T) {
 	testImage.Close()
 
 }
+
+// mockOperationCounting tracks how many runs overlap, so tests can check how many jobs are in flight.
+type mockOperationCounting struct {
+	running     *atomic.Int32
+	maxRunning  *atomic.Int32
+	failOnWidth int
+}
+
+func (m mockOperationCounting) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	running := m.running.Add(1)
+	defer m.running.Add(-1)
+
+	for {
+		seen := m.maxRunning.Load()
+		if running <= seen || m.maxRunning.CompareAndSwap(seen, running) {
+			break
+		}
+	}
+
+	time.Sleep(time.Millisecond * 5)
+
+	if input.Cols() == m.failOnWidth {
+		return nil, errors.New("error processing job")
+	}
+
+	result := input.Clone()
+	return &result, nil
+}
+
+func TestEnqueueBatch(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	const queueDepth = 2
+	widths := []int{10, 20, 30, 40, 50, 60}
+
+	images := make([]*gocv.Mat, len(widths))
+	for idx, width := range widths {
+		image := gocv.NewMatWithSize(8, width, gocv.MatTypeCV8UC3)
+		defer image.Close()
+		images[idx] = &image
+	}
+
+	requests := make(chan *jobs.JobRequest)
+	ctx, cancel := context.WithCancel(context.Background())
+	jobDispatcher := JobDispatch.NewJobDispatcher(requests, queueDepth, fixedTimeouts(time.Second))
+
+	// more workers than the queue holds, so only the batch limits how many jobs run at once
+	wg := &sync.WaitGroup{}
+	for workerId := range 4 {
+		wg.Add(1)
+		go worker.Worker(ctx, workerId, requests, wg)
+	}
+
+	running, maxRunning := &atomic.Int32{}, &atomic.Int32{}
+	results := JobDispatch.EnqueueBatch(jobDispatcher, images, func() jobs.Operation {
+		return mockOperationCounting{running: running, maxRunning: maxRunning, failOnWidth: 30}
+	})
+
+	assert.Len(t, results, len(widths))
+	for idx, result := range results {
+		if widths[idx] == 30 {
+			assert.Error(t, result.Error)
+			assert.Nil(t, result.Image)
+			continue
+		}
+
+		assert.NoError(t, result.Error)
+		decoded, err := gocv.IMDecode(result.Image, gocv.IMReadUnchanged)
+		assert.NoError(t, err)
+		// results keep the order of the images
+		assert.Equal(t, widths[idx], decoded.Cols())
+		decoded.Close()
+	}
+
+	assert.LessOrEqual(t, maxRunning.Load(), int32(queueDepth))
+	assert.Greater(t, maxRunning.Load(), int32(1), "the batch should be spread across workers")
+
+	cancel()
+	jobDispatcher.Close()
+	wg.Wait()
+}
+
+// testQuota records what it charged and refunded, it has room for limit jobs when limit is set.
+type testQuota struct {
+	mu       sync.Mutex
+	limit    int
+	charged  []int64
+	refunded []int64
+}
+
+var errQuota = errors.New("quota exceeded")
+
+func (q *testQuota) Charge(pixels int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.limit > 0 && len(q.charged) == q.limit {
+		return errQuota
+	}
+	q.charged = append(q.charged, pixels)
+	return nil
+}
+
+func (q *testQuota) Refund(pixels int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.refunded = append(q.refunded, pixels)
+}
+
+func (q *testQuota) refunds() []int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.refunded)
+}
+
+func TestDispatchPixelQuota(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+
+	images := make([]*gocv.Mat, 3)
+	for idx := range images {
+		image := gocv.NewMatWithSize(10, 20, gocv.MatTypeCV8UC3)
+		defer image.Close()
+		images[idx] = &image
+	}
+
+	// room for two of the images
+	quota := &testQuota{limit: 2}
+	results := JobDispatch.EnqueueBatch(jobDispatcher.WithPixelQuota(quota), images, func() jobs.Operation {
+		return MockOperationSuccess{}
+	})
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			assert.ErrorIs(t, result.Error, errQuota)
+			failed++
+		}
+	}
+	assert.Equal(t, 1, failed)
+	assert.Equal(t, []int64{200, 200}, quota.charged)
+	assert.Empty(t, quota.refunds())
+
+	// the quota is a view, the dispatcher it came from is not charged
+	result, err := JobDispatch.EnqueueOperation(jobDispatcher, images[0], MockOperationSuccess{})
+	assert.NoError(t, err)
+	result.Close()
+}
+
+// mockOperationBlocking keeps its worker busy until release is closed.
+type mockOperationBlocking struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m mockOperationBlocking) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	close(m.started)
+	<-m.release
+	result := input.Clone()
+	return &result, nil
+}
+
+func TestDispatchPixelQuotaRefund(t *testing.T) {
+	t.Run("Closed dispatcher", func(t *testing.T) {
+		jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+			return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+		}, 1)
+		jobDispatcher.Close()
+
+		image := gocv.NewMatWithSize(10, 20, gocv.MatTypeCV8UC3)
+		defer image.Close()
+		quota := &testQuota{}
+		_, err := JobDispatch.EnqueueOperation(jobDispatcher.WithPixelQuota(quota), &image, MockOperationSuccess{})
+		assert.ErrorIs(t, err, JobDispatch.ErrDispatcherClosed)
+		assert.Equal(t, []int64{200}, quota.charged)
+		assert.Equal(t, []int64{200}, quota.refunds())
+	})
+
+	t.Run("Timed out in the queue", func(t *testing.T) {
+		jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+			return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+		}, 1)
+
+		busy := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+		defer busy.Close()
+		blocking := mockOperationBlocking{started: make(chan struct{}), release: make(chan struct{})}
+		busyDone := make(chan struct{})
+		go func() {
+			defer close(busyDone)
+			result, err := JobDispatch.EnqueueOperation(jobDispatcher, &busy, blocking)
+			if assert.NoError(t, err) {
+				result.Close()
+			}
+		}()
+		<-blocking.started
+
+		images := make([]*gocv.Mat, 2)
+		for idx := range images {
+			image := gocv.NewMatWithSize(10, 20, gocv.MatTypeCV8UC3)
+			defer image.Close()
+			images[idx] = &image
+		}
+
+		// one job waits to be handed to the busy worker and still runs once it is free, the other one is dropped from the queue
+		quota := &testQuota{}
+		results := JobDispatch.EnqueueBatch(jobDispatcher.WithTimeout(20*time.Millisecond).WithPixelQuota(quota), images, func() jobs.Operation {
+			return MockOperationSuccess{}
+		})
+		for _, result := range results {
+			assert.ErrorIs(t, result.Error, JobDispatch.ErrTimeout)
+		}
+		assert.Empty(t, quota.refunds())
+
+		close(blocking.release)
+		<-busyDone
+		assert.Eventually(t, func() bool {
+			return slices.Equal(quota.refunds(), []int64{200})
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, []int64{200, 200}, quota.charged)
+	})
+}
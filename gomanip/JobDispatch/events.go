@@ -0,0 +1,137 @@
+package JobDispatch
+
+import (
+	"sync"
+
+	"goManip/jobs"
+)
+
+// JobState is how far a dispatched job got.
+type JobState string
+
+const (
+	// JobQueued jobs wait for a worker.
+	JobQueued JobState = "queued"
+	// JobStarted jobs run on a worker.
+	JobStarted JobState = "started"
+	// JobProgress jobs report how much of their operation is done.
+	JobProgress JobState = "progress"
+	// JobDone is reported by the Tracker once the request that made the jobs finished, not by the dispatcher.
+	JobDone JobState = "done"
+)
+
+// JobEvent reports that a dispatched job reached a new state.
+type JobEvent struct {
+	// JobId is 0 for events about a whole batch rather than one of its jobs
+	JobId uint32   `json:"job,omitempty"`
+	State JobState `json:"-"`
+	// Progress is only set on progress events
+	Progress *jobs.Progress `json:"progress,omitempty"`
+	// Status is the HTTP status of the request on done events
+	Status int `json:"status,omitempty"`
+}
+
+// WithEvents returns a view of the dispatcher reporting the state of its jobs to onEvent. The events of a job
+// arrive in order, but onEvent is called from more than one goroutine when jobs run at the same time.
+// Progress is reported from the worker running the job, so onEvent must not block.
+func (j *JobDispatcher) WithEvents(onEvent func(JobEvent)) *JobDispatcher {
+	view := *j
+	view.onEvent = onEvent
+	return &view
+}
+
+// jobEvents reports the events of one job in order, nothing is reported once it is closed.
+type jobEvents struct {
+	onEvent func(JobEvent)
+	jobId   uint32
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+// eventsOf returns the reporter for the events of job, it does nothing when the view has no onEvent.
+func (j *JobDispatcher) eventsOf(job *jobs.Job) *jobEvents {
+	events := &jobEvents{onEvent: j.onEvent, jobId: job.GetJobId()}
+	if j.onEvent != nil {
+		job.ReportProgress(events.progress)
+	}
+	return events
+}
+
+// queue submits the job and reports it as queued, anything the worker reports waits until it is.
+func (e *jobEvents) queue(submit func() error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := submit(); err != nil {
+		e.closed = true
+		return err
+	}
+	e.send(JobEvent{State: JobQueued})
+	return nil
+}
+
+func (e *jobEvents) start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.send(JobEvent{State: JobStarted})
+}
+
+func (e *jobEvents) progress(progress jobs.Progress) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.send(JobEvent{State: JobProgress, Progress: &progress})
+}
+
+func (e *jobEvents) close() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+}
+
+// send reports event unless the job is closed, it has to be called with mu held.
+func (e *jobEvents) send(event JobEvent) {
+	if e.onEvent == nil || e.closed {
+		return
+	}
+
+	if event.State != JobQueued {
+		if e.started && event.State == JobStarted {
+			return
+		}
+		// the worker may report progress before the start of the job was noticed
+		if !e.started && event.State != JobStarted {
+			e.onEvent(JobEvent{JobId: e.jobId, State: JobStarted})
+		}
+		e.started = true
+	}
+
+	event.JobId = e.jobId
+	e.onEvent(event)
+}
+
+// watchStart reports the job once a worker starts it, the returned channel is closed once the
+// job started or gave up waiting.
+func (e *jobEvents) watchStart(jobRequest *jobs.JobRequest) <-chan struct{} {
+	watched := make(chan struct{})
+	if e.onEvent == nil {
+		close(watched)
+		return watched
+	}
+
+	go func() {
+		defer close(watched)
+		select {
+		case <-jobRequest.Started:
+		case <-jobRequest.Ctx.Done():
+			// a job that started right before its timeout still reports it
+			select {
+			case <-jobRequest.Started:
+			default:
+				return
+			}
+		}
+		e.start()
+	}()
+	return watched
+}
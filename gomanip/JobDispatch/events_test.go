@@ -0,0 +1,160 @@
+package JobDispatch_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+
+	"goManip/JobDispatch"
+	"goManip/jobs"
+	"goManip/worker"
+)
+
+// mockOperationProgress reports steps of progress before returning its input.
+type mockOperationProgress struct {
+	steps  int
+	report jobs.ProgressFunc
+}
+
+func (m *mockOperationProgress) ReportProgress(report jobs.ProgressFunc) {
+	m.report = report
+}
+
+func (m *mockOperationProgress) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	for step := range m.steps {
+		if m.report != nil {
+			m.report(jobs.Progress{Done: step + 1, Total: m.steps, Unit: jobs.UnitStep})
+		}
+	}
+	return input, nil
+}
+
+// eventRecorder collects the events of a dispatcher view.
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []JobDispatch.JobEvent
+}
+
+func (r *eventRecorder) record(event JobDispatch.JobEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) states() []JobDispatch.JobState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	states := make([]JobDispatch.JobState, len(r.events))
+	for idx, event := range r.events {
+		states[idx] = event.State
+	}
+	return states
+}
+
+// startWorkers runs workers for the dispatcher made by dispatcher, they are stopped when the test ends and checked for leaks after that.
+func startWorkers(t *testing.T, dispatcher func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher, workers int) *JobDispatch.JobDispatcher {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	requests := make(chan *jobs.JobRequest)
+	jobDispatcher := dispatcher(requests)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	for workerId := range workers {
+		wg.Add(1)
+		go worker.Worker(ctx, workerId, requests, wg)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		jobDispatcher.Close()
+		wg.Wait()
+	})
+	return jobDispatcher
+}
+
+func TestDispatchEvents(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+
+	image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	recorder := &eventRecorder{}
+	result, err := JobDispatch.EnqueueOperation(jobDispatcher.WithEvents(recorder.record), &image, &mockOperationProgress{steps: 3})
+	assert.NoError(t, err)
+	result.Close()
+
+	assert.Equal(t, []JobDispatch.JobState{
+		JobDispatch.JobQueued, JobDispatch.JobStarted, JobDispatch.JobProgress, JobDispatch.JobProgress, JobDispatch.JobProgress,
+	}, recorder.states())
+
+	for idx, event := range recorder.events {
+		assert.Equal(t, recorder.events[0].JobId, event.JobId)
+		if event.State == JobDispatch.JobProgress {
+			assert.Equal(t, &jobs.Progress{Done: idx - 1, Total: 3, Unit: jobs.UnitStep}, event.Progress)
+		}
+	}
+}
+
+func TestDispatchWithoutEvents(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+
+	image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	// nobody asked for progress, so the operation is never told to report it
+	operation := &mockOperationProgress{steps: 2}
+	result, err := JobDispatch.EnqueueOperation(jobDispatcher, &image, operation)
+	assert.NoError(t, err)
+	result.Close()
+	assert.Nil(t, operation.report)
+}
+
+func TestEnqueueBatchEvents(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 2)
+
+	images := make([]*gocv.Mat, 3)
+	for idx := range images {
+		image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+		defer image.Close()
+		images[idx] = &image
+	}
+
+	recorder := &eventRecorder{}
+	results := JobDispatch.EnqueueBatch(jobDispatcher.WithEvents(recorder.record), images, func() jobs.Operation {
+		return &mockOperationProgress{steps: 4}
+	})
+	for _, result := range results {
+		assert.NoError(t, result.Error)
+	}
+
+	// a batch reports finished images, not the progress of each image
+	var progress []jobs.Progress
+	queued := 0
+	for _, event := range recorder.events {
+		switch event.State {
+		case JobDispatch.JobQueued:
+			queued++
+		case JobDispatch.JobProgress:
+			assert.Zero(t, event.JobId)
+			progress = append(progress, *event.Progress)
+		}
+	}
+	assert.Equal(t, len(images), queued)
+	assert.Equal(t, []jobs.Progress{
+		{Done: 1, Total: 3, Unit: jobs.UnitImage},
+		{Done: 2, Total: 3, Unit: jobs.UnitImage},
+		{Done: 3, Total: 3, Unit: jobs.UnitImage},
+	}, progress)
+}
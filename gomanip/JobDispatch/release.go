@@ -0,0 +1,71 @@
+package JobDispatch
+
+import (
+	"sync"
+
+	"gocv.io/x/gocv"
+)
+
+// lease counts the jobs reading an image, released is set once its owner is done with it.
+type lease struct {
+	jobs     int
+	released bool
+}
+
+// leases tracks the images of dispatched jobs, a job that timed out keeps running on its worker
+// so its images can only be closed once the worker is done with them.
+var leases = struct {
+	sync.Mutex
+	images map[*gocv.Mat]*lease
+}{images: map[*gocv.Mat]*lease{}}
+
+func acquireImages(images []*gocv.Mat) {
+	leases.Lock()
+	defer leases.Unlock()
+
+	for _, image := range images {
+		l, ok := leases.images[image]
+		if !ok {
+			l = &lease{}
+			leases.images[image] = l
+		}
+		l.jobs++
+	}
+}
+
+func finishImages(images []*gocv.Mat) {
+	leases.Lock()
+	defer leases.Unlock()
+
+	for _, image := range images {
+		l, ok := leases.images[image]
+		if !ok {
+			continue
+		}
+		l.jobs--
+		if l.jobs > 0 {
+			continue
+		}
+		delete(leases.images, image)
+		if l.released {
+			image.Close()
+		}
+	}
+}
+
+// ReleaseImage closes an image its owner is done with, right away when no job reads it or
+// once the last job reading it finished. Handlers release the images they decoded with it instead of closing them.
+func ReleaseImage(image *gocv.Mat) {
+	if image == nil {
+		return
+	}
+
+	leases.Lock()
+	defer leases.Unlock()
+
+	if l, ok := leases.images[image]; ok {
+		l.released = true
+		return
+	}
+	image.Close()
+}
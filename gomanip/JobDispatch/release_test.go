@@ -0,0 +1,85 @@
+package JobDispatch_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+
+	"goManip/JobDispatch"
+	"goManip/jobs"
+	"goManip/worker"
+)
+
+// mockOperationRelease keeps reading its input after its job timed out.
+type mockOperationRelease struct {
+	delay time.Duration
+}
+
+func (m mockOperationRelease) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	time.Sleep(m.delay)
+	result := input.Clone()
+	return &result, nil
+}
+
+func TestReleaseImageWithoutJobs(t *testing.T) {
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	JobDispatch.ReleaseImage(&image)
+	assert.True(t, image.Closed())
+
+	// releasing nothing is a no-op
+	JobDispatch.ReleaseImage(nil)
+}
+
+func TestReleaseImageAfterTimeout(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	requests := make(chan *jobs.JobRequest)
+	ctx, cancel := context.WithCancel(context.Background())
+	jobDispatcher := JobDispatch.NewJobDispatcher(requests, 1, fixedTimeouts(5*time.Millisecond))
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go worker.Worker(ctx, 0, requests, wg)
+
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	_, err := jobDispatcher.DispatchJob(jobs.NewJob(1, mockOperationRelease{delay: 50 * time.Millisecond}, &image))
+	assert.ErrorIs(t, err, JobDispatch.ErrTimeout)
+
+	// the worker still reads the image, it is only closed once the job is done
+	JobDispatch.ReleaseImage(&image)
+	assert.False(t, image.Closed())
+	assert.Eventually(t, image.Closed, time.Second, 5*time.Millisecond)
+
+	jobDispatcher.Close()
+	cancel()
+	wg.Wait()
+}
+
+// mockOperationCrash panics the way a bad region in an operation would.
+type mockOperationCrash struct{}
+
+func (m mockOperationCrash) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	var regions []int
+	_ = regions[1]
+	return input, nil
+}
+
+func TestReleaseImageAfterPanic(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 1, fixedTimeouts(time.Second))
+	}, 1)
+
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	_, err := jobDispatcher.DispatchJob(jobs.NewJob(1, mockOperationCrash{}, &image))
+	assert.ErrorIs(t, err, jobs.ErrInternal)
+
+	// the job left its input to the owner, which releases it once
+	assert.False(t, image.Closed())
+	JobDispatch.ReleaseImage(&image)
+	assert.True(t, image.Closed())
+}
@@ -0,0 +1,231 @@
+package JobDispatch
+
+import (
+	"errors"
+	"goManip/jobs"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTimeout          = errors.New("job cancelled due to timeout")
+	ErrDispatcherClosed = errors.New("job dispatcher is closed")
+)
+
+// laneWeights is how many jobs each lane gets per round when every lane is busy,
+// low priority jobs are slowed down by a flood of higher ones but never starved.
+var laneWeights = map[jobs.Priority]int{
+	jobs.PriorityLow:    1,
+	jobs.PriorityNormal: 2,
+	jobs.PriorityHigh:   4,
+}
+
+// lane queues the jobs of one priority per client, clients take turns so one of them
+// flooding the lane only delays its own jobs.
+type lane struct {
+	weight int
+	// current is the lane's credit in the smooth weighted round-robin between lanes
+	current int
+	clients []string
+	queues  map[string][]*jobs.JobRequest
+}
+
+func (l *lane) empty() bool {
+	return len(l.clients) == 0
+}
+
+func (l *lane) push(client string, request *jobs.JobRequest) {
+	if _, ok := l.queues[client]; !ok {
+		l.clients = append(l.clients, client)
+	}
+	l.queues[client] = append(l.queues[client], request)
+}
+
+// pop takes the oldest job of the next client in turn, who goes to the back of the line if it has more.
+func (l *lane) pop() *jobs.JobRequest {
+	client := l.clients[0]
+	queue := l.queues[client]
+	request := queue[0]
+	l.clients = l.clients[1:]
+
+	if len(queue) == 1 {
+		delete(l.queues, client)
+	} else {
+		l.queues[client] = queue[1:]
+		l.clients = append(l.clients, client)
+	}
+
+	return request
+}
+
+// Scheduler holds queued jobs and hands them to the workers one at a time, picking between
+// priority lanes by weight and between clients in a lane in round-robin order.
+type Scheduler struct {
+	mu    sync.Mutex
+	lanes []*lane
+	// slots bounds how many jobs can be queued, submitting blocks while it is full
+	slots chan struct{}
+	wake  chan struct{}
+	done  chan struct{}
+	once  sync.Once
+	out   chan<- *jobs.JobRequest
+	// sending is the request handed to the workers right now, it waits for a worker like the queued ones
+	sending *jobs.JobRequest
+	closed  bool
+}
+
+// NewScheduler starts a scheduler feeding out, at most depth jobs are queued at once.
+// out is closed once the scheduler is closed and every queued job was handed out.
+func NewScheduler(out chan<- *jobs.JobRequest, depth int) *Scheduler {
+	s := &Scheduler{
+		slots: make(chan struct{}, max(depth, 1)),
+		wake:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+		out:   out,
+	}
+
+	for _, priority := range jobs.Priorities {
+		s.lanes = append(s.lanes, &lane{weight: laneWeights[priority], queues: map[string][]*jobs.JobRequest{}})
+	}
+
+	go s.run()
+	return s
+}
+
+// Depth is how many jobs the scheduler queues at most.
+func (s *Scheduler) Depth() int {
+	return cap(s.slots)
+}
+
+// Submit queues request for client in the lane of priority. It blocks while the queue is full,
+// until the request's context is done or the scheduler is closed.
+func (s *Scheduler) Submit(request *jobs.JobRequest, client string, priority jobs.Priority) error {
+	select {
+	case s.slots <- struct{}{}:
+	case <-request.Ctx.Done():
+		return ErrTimeout
+	case <-s.done:
+		return ErrDispatcherClosed
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.slots
+		return ErrDispatcherClosed
+	}
+	s.lanes[priority].push(client, request)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// OldestWait is how long the longest waiting job has been waiting for a worker, 0 when no job waits.
+func (s *Scheduler) OldestWait() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var oldest time.Time
+	consider := func(request *jobs.JobRequest) {
+		if oldest.IsZero() || request.Queued.Before(oldest) {
+			oldest = request.Queued
+		}
+	}
+
+	if s.sending != nil {
+		consider(s.sending)
+	}
+	// the first job of every client is its oldest
+	for _, l := range s.lanes {
+		for _, queue := range l.queues {
+			consider(queue[0])
+		}
+	}
+
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest)
+}
+
+// Close stops accepting jobs, the jobs already queued are still handed to the workers.
+func (s *Scheduler) Close() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		close(s.done)
+
+		select {
+		case s.wake <- struct{}{}:
+		default:
+		}
+	})
+}
+
+// next picks the next job, or returns nil when nothing is queued. closed is read under the same lock,
+// so a job submitted before the scheduler closed is never left behind.
+func (s *Scheduler) next() (request *jobs.JobRequest, closed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// smooth weighted round-robin, every busy lane earns its weight and the richest lane
+	// pays back the total, which spreads each lane's picks evenly over a round
+	var chosen *lane
+	total := 0
+	for _, l := range s.lanes {
+		// idle lanes do not save up credit for later
+		if l.empty() {
+			l.current = 0
+			continue
+		}
+		l.current += l.weight
+		total += l.weight
+		if chosen == nil || l.current > chosen.current {
+			chosen = l
+		}
+	}
+
+	if chosen == nil {
+		return nil, s.closed
+	}
+
+	chosen.current -= total
+	return chosen.pop(), s.closed
+}
+
+func (s *Scheduler) setSending(request *jobs.JobRequest) {
+	s.mu.Lock()
+	s.sending = request
+	s.mu.Unlock()
+}
+
+func (s *Scheduler) run() {
+	defer close(s.out)
+
+	for {
+		request, closed := s.next()
+		if request == nil {
+			if closed {
+				return
+			}
+			<-s.wake
+			continue
+		}
+
+		// nobody waits for jobs that timed out while queued
+		if request.Ctx.Err() == nil {
+			s.setSending(request)
+			s.out <- request
+			s.setSending(nil)
+		} else {
+			request.Finish()
+		}
+		<-s.slots
+	}
+}
@@ -0,0 +1,239 @@
+package JobDispatch_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+
+	"goManip/JobDispatch"
+	"goManip/jobs"
+)
+
+type queuedJob struct {
+	client   string
+	priority jobs.Priority
+}
+
+// newQueuedRequest makes a request whose job id identifies it in the scheduled order.
+func newQueuedRequest(id uint32) *jobs.JobRequest {
+	return jobs.NewJobRequest(jobs.NewJob(id, MockOperationSuccess{}, nil), context.Background())
+}
+
+// schedule submits every job in order before a single one is taken, then returns the
+// indices of the jobs in the order the scheduler hands them out.
+func schedule(t *testing.T, queued []queuedJob) []int {
+	out := make(chan *jobs.JobRequest)
+	scheduler := JobDispatch.NewScheduler(out, len(queued))
+
+	// the scheduler grabs the first job as soon as it is submitted, hold it back so
+	// every job is queued before any scheduling decision is made
+	blocker := newQueuedRequest(0)
+	assert.NoError(t, scheduler.Submit(blocker, "blocker", jobs.PriorityHigh))
+
+	for idx, job := range queued {
+		assert.NoError(t, scheduler.Submit(newQueuedRequest(uint32(idx+1)), job.client, job.priority))
+	}
+	scheduler.Close()
+
+	assert.Equal(t, blocker, <-out)
+
+	var order []int
+	for request := range out {
+		order = append(order, int(request.Job.GetJobId())-1)
+	}
+	return order
+}
+
+func positionOf(order []int, idx int) int {
+	for position, queued := range order {
+		if queued == idx {
+			return position
+		}
+	}
+	return -1
+}
+
+func TestSchedulerClientFairness(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// one client floods the queue, then a second client sends a single job
+	var queued []queuedJob
+	for range 20 {
+		queued = append(queued, queuedJob{client: "flood", priority: jobs.PriorityNormal})
+	}
+	queued = append(queued, queuedJob{client: "quiet", priority: jobs.PriorityNormal})
+
+	order := schedule(t, queued)
+	assert.Len(t, order, len(queued))
+
+	// the quiet client's job goes second, right after the flood's first turn
+	assert.Equal(t, 1, positionOf(order, 20))
+
+	// every client's jobs keep their own order
+	previous := -1
+	for _, idx := range order {
+		if idx == 20 {
+			continue
+		}
+		assert.Greater(t, idx, previous)
+		previous = idx
+	}
+}
+
+func TestSchedulerRoundRobin(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	queued := []queuedJob{
+		{client: "a", priority: jobs.PriorityNormal},
+		{client: "a", priority: jobs.PriorityNormal},
+		{client: "a", priority: jobs.PriorityNormal},
+		{client: "b", priority: jobs.PriorityNormal},
+		{client: "b", priority: jobs.PriorityNormal},
+		{client: "c", priority: jobs.PriorityNormal},
+	}
+
+	assert.Equal(t, []int{0, 3, 5, 1, 4, 2}, schedule(t, queued))
+}
+
+func TestSchedulerPriorities(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	var queued []queuedJob
+	for range 2 {
+		queued = append(queued, queuedJob{client: "a", priority: jobs.PriorityLow})
+	}
+	for range 20 {
+		queued = append(queued, queuedJob{client: "a", priority: jobs.PriorityHigh})
+	}
+
+	order := schedule(t, queued)
+	assert.Len(t, order, len(queued))
+
+	// high priority jobs go first most of the time
+	highFirst := 0
+	for _, idx := range order[:5] {
+		if queued[idx].priority == jobs.PriorityHigh {
+			highFirst++
+		}
+	}
+	assert.Equal(t, 4, highFirst)
+
+	// but the flood of high priority jobs cannot starve the low ones
+	assert.Less(t, positionOf(order, 0), 5)
+	assert.Less(t, positionOf(order, 1), 10)
+}
+
+func TestSchedulerPriorityAndClients(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// a client flooding expensive jobs does not hold up cheap jobs of others, or its own cheap jobs
+	var queued []queuedJob
+	for range 10 {
+		queued = append(queued, queuedJob{client: "flood", priority: jobs.PriorityLow})
+	}
+	queued = append(queued, queuedJob{client: "flood", priority: jobs.PriorityNormal})
+	queued = append(queued, queuedJob{client: "quiet", priority: jobs.PriorityNormal})
+
+	order := schedule(t, queued)
+	assert.Less(t, positionOf(order, 10), 3)
+	assert.Less(t, positionOf(order, 11), 3)
+}
+
+func TestSchedulerSkipsExpiredJobs(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	out := make(chan *jobs.JobRequest)
+	scheduler := JobDispatch.NewScheduler(out, 2)
+
+	blocker := newQueuedRequest(1)
+	assert.NoError(t, scheduler.Submit(blocker, "a", jobs.PriorityNormal))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	expired := jobs.NewJobRequest(jobs.NewJob(2, MockOperationSuccess{}, nil), ctx)
+	assert.NoError(t, scheduler.Submit(expired, "a", jobs.PriorityNormal))
+	cancel()
+	scheduler.Close()
+
+	var handedOut []*jobs.JobRequest
+	for request := range out {
+		handedOut = append(handedOut, request)
+	}
+	assert.Equal(t, []*jobs.JobRequest{blocker}, handedOut)
+}
+
+func TestSchedulerFullQueue(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	out := make(chan *jobs.JobRequest)
+	scheduler := JobDispatch.NewScheduler(out, 1)
+
+	assert.NoError(t, scheduler.Submit(newQueuedRequest(1), "a", jobs.PriorityNormal))
+
+	// nobody takes the queued job, so the next one waits until its context is done
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*5)
+	defer cancel()
+	err := scheduler.Submit(jobs.NewJobRequest(jobs.NewJob(2, MockOperationSuccess{}, nil), ctx), "a", jobs.PriorityNormal)
+	assert.ErrorIs(t, err, JobDispatch.ErrTimeout)
+
+	scheduler.Close()
+	assert.ErrorIs(t, scheduler.Submit(newQueuedRequest(3), "a", jobs.PriorityNormal), JobDispatch.ErrDispatcherClosed)
+
+	for range out {
+	}
+}
+
+func TestSchedulerCloseWhileSubmitting(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// a job that was queued before the scheduler closed is handed out, however the two race
+	for range 200 {
+		out := make(chan *jobs.JobRequest)
+		scheduler := JobDispatch.NewScheduler(out, 4)
+
+		submitted := make(chan bool)
+		go func() {
+			submitted <- scheduler.Submit(newQueuedRequest(1), "a", jobs.PriorityNormal) == nil
+		}()
+		scheduler.Close()
+
+		handedOut := 0
+		for range out {
+			handedOut++
+		}
+		if <-submitted {
+			assert.Equal(t, 1, handedOut)
+		} else {
+			assert.Zero(t, handedOut)
+		}
+	}
+}
+
+func TestSchedulerOldestWait(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	out := make(chan *jobs.JobRequest)
+	scheduler := JobDispatch.NewScheduler(out, 4)
+	assert.Zero(t, scheduler.OldestWait())
+
+	first := newQueuedRequest(1)
+	first.Queued = time.Now().Add(-time.Second)
+	assert.NoError(t, scheduler.Submit(first, "first", jobs.PriorityNormal))
+	assert.NoError(t, scheduler.Submit(newQueuedRequest(2), "second", jobs.PriorityHigh))
+
+	// the first job is held by the scheduler until a worker takes it, it still counts as waiting
+	assert.GreaterOrEqual(t, scheduler.OldestWait(), time.Second)
+
+	assert.Equal(t, first, <-out)
+	assert.Eventually(t, func() bool {
+		return scheduler.OldestWait() < time.Second
+	}, time.Second, time.Millisecond)
+
+	<-out
+	scheduler.Close()
+	_, open := <-out
+	assert.False(t, open)
+	assert.Zero(t, scheduler.OldestWait())
+}
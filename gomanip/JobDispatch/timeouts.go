@@ -0,0 +1,63 @@
+package JobDispatch
+
+import (
+	"errors"
+	"fmt"
+	"goManip/jobs"
+	"time"
+)
+
+var ErrTooExpensive = errors.New("job is expected to take longer than its timeout")
+
+// Timeout is how long an operation may run when the client does not ask for a timeout,
+// and the longest it may run when the client does.
+type Timeout struct {
+	Default time.Duration
+	Max     time.Duration
+}
+
+func (t Timeout) validate() error {
+	if t.Default <= 0 {
+		return fmt.Errorf("expected the default timeout to be positive, got %s", t.Default)
+	}
+	if t.Max < t.Default {
+		return fmt.Errorf("expected the max timeout %s to be at least the default timeout %s", t.Max, t.Default)
+	}
+	return nil
+}
+
+// Timeouts holds the timeouts of the operations named in Operations, every other operation uses Default.
+type Timeouts struct {
+	Default    Timeout
+	Operations map[string]Timeout
+}
+
+func (t Timeouts) Validate() error {
+	if err := t.Default.validate(); err != nil {
+		return err
+	}
+	for name, timeout := range t.Operations {
+		if err := timeout.validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// of returns the timeout of op, a pipeline gets the time of all of its steps together.
+func (t Timeouts) of(op jobs.Operation) Timeout {
+	if pipeline, ok := op.(*jobs.Pipeline); ok {
+		var total Timeout
+		for _, step := range pipeline.Steps {
+			stepTimeout := t.of(step)
+			total.Default += stepTimeout.Default
+			total.Max += stepTimeout.Max
+		}
+		return total
+	}
+
+	if timeout, ok := t.Operations[jobs.NameOf(op)]; ok {
+		return timeout
+	}
+	return t.Default
+}
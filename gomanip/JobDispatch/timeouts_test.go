@@ -0,0 +1,131 @@
+package JobDispatch_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+
+	"goManip/JobDispatch"
+	"goManip/jobs"
+	"goManip/worker"
+)
+
+// mockOperationEstimated runs instantly but claims to take estimate.
+type mockOperationEstimated struct {
+	estimate time.Duration
+}
+
+func (m mockOperationEstimated) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	result := input.Clone()
+	return &result, nil
+}
+
+func (m mockOperationEstimated) Estimate(rows, cols int) time.Duration {
+	return m.estimate
+}
+
+// mockOperationUnlisted has no timeout of its own.
+type mockOperationUnlisted struct {
+	mockOperationEstimated
+}
+
+func TestTimeoutsValidate(t *testing.T) {
+	valid := JobDispatch.Timeouts{Default: JobDispatch.Timeout{Default: time.Second, Max: time.Minute}}
+	assert.NoError(t, valid.Validate())
+
+	invalidDefault := JobDispatch.Timeouts{Default: JobDispatch.Timeout{Default: time.Minute, Max: time.Second}}
+	assert.Error(t, invalidDefault.Validate())
+
+	invalidOperation := valid
+	invalidOperation.Operations = map[string]JobDispatch.Timeout{"zoom": {}}
+	assert.Error(t, invalidOperation.Validate())
+}
+
+func TestDispatchTimeouts(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	timeouts := JobDispatch.Timeouts{
+		Default: JobDispatch.Timeout{Default: 100 * time.Millisecond, Max: time.Second},
+		Operations: map[string]JobDispatch.Timeout{
+			"mockOperationEstimated": {Default: 200 * time.Millisecond, Max: 2 * time.Second},
+		},
+	}
+
+	requests := make(chan *jobs.JobRequest)
+	ctx, cancel := context.WithCancel(context.Background())
+	jobDispatcher := JobDispatch.NewJobDispatcher(requests, 1, timeouts)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go worker.Worker(ctx, 0, requests, wg)
+
+	tests := []struct {
+		name       string
+		operation  jobs.Operation
+		timeout    time.Duration
+		wantReject bool
+	}{
+		{
+			name:      "Within the operation's default",
+			operation: mockOperationEstimated{estimate: 150 * time.Millisecond},
+		},
+		{
+			name:       "Longer than the operation's default",
+			operation:  mockOperationEstimated{estimate: 500 * time.Millisecond},
+			wantReject: true,
+		},
+		{
+			name:      "Within the requested timeout",
+			operation: mockOperationEstimated{estimate: 500 * time.Millisecond},
+			timeout:   time.Second,
+		},
+		{
+			name:       "Requested timeout is limited by the operation's max",
+			operation:  mockOperationEstimated{estimate: 5 * time.Second},
+			timeout:    10 * time.Second,
+			wantReject: true,
+		},
+		{
+			name:       "Operations without their own timeout use the default",
+			operation:  mockOperationUnlisted{mockOperationEstimated{estimate: 150 * time.Millisecond}},
+			wantReject: true,
+		},
+		{
+			name: "Pipelines get the time of all of their steps",
+			operation: jobs.NewPipeline(
+				mockOperationEstimated{estimate: 150 * time.Millisecond},
+				mockOperationEstimated{estimate: 150 * time.Millisecond},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := jobDispatcher
+			if tt.timeout > 0 {
+				dispatcher = dispatcher.WithTimeout(tt.timeout)
+			}
+
+			result, err := dispatcher.DispatchJob(jobs.NewJob(1, tt.operation, &image))
+			if tt.wantReject {
+				assert.ErrorIs(t, err, JobDispatch.ErrTooExpensive)
+				return
+			}
+
+			assert.NoError(t, err)
+			result.Close()
+		})
+	}
+
+	cancel()
+	jobDispatcher.Close()
+	wg.Wait()
+}
@@ -0,0 +1,205 @@
+package JobDispatch
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// maxJobIdLength limits the ids clients pick for the requests they track.
+const maxJobIdLength = 64
+
+// subscriptionBuffer is how many events a subscriber may fall behind before progress is dropped,
+// the last slot is kept for the done event.
+const subscriptionBuffer = 16
+
+var (
+	// ErrJobIdInUse is returned when a request asks to be tracked under the id of another request.
+	ErrJobIdInUse = errors.New("job id is already in use")
+	// ErrJobNotFound is returned when a client subscribes to a request of another client.
+	ErrJobNotFound = errors.New("job not found")
+)
+
+// trackedStates are the states a subscriber that comes late is told about, in the order they happen.
+var trackedStates = []JobState{JobQueued, JobStarted, JobProgress, JobDone}
+
+// Tracker keeps the events of requests that asked to be followed, by an id the client picked,
+// so they can be streamed while the request runs.
+type Tracker struct {
+	mu       sync.Mutex
+	requests map[string]*trackedRequest
+	// retention is how long the events of a finished request are kept for late subscribers
+	retention time.Duration
+}
+
+// trackedRequest exists while its request runs or someone subscribed to it, it may be subscribed to before the request arrives.
+type trackedRequest struct {
+	// owner is the client the request was made by, only it may follow its events
+	owner   string
+	running bool
+	done    bool
+	// latest holds the last event of each state, they are replayed to new subscribers
+	latest      map[JobState]JobEvent
+	subscribers map[*Subscription]struct{}
+}
+
+// Subscription receives the events of a tracked request.
+type Subscription struct {
+	// Events delivers the events of the request, it is closed after the done event
+	Events <-chan JobEvent
+	events chan JobEvent
+
+	tracker *Tracker
+	id      string
+	// client is who subscribed, it only gets the events of its own request
+	client string
+}
+
+func NewTracker(retention time.Duration) *Tracker {
+	return &Tracker{requests: map[string]*trackedRequest{}, retention: retention}
+}
+
+// ValidateJobId checks an id a client picked to track its request, i.e a uuid.
+func ValidateJobId(id string) error {
+	if id == "" || len(id) > maxJobIdLength {
+		return fmt.Errorf("expected a job id of 1 to %d characters", maxJobIdLength)
+	}
+
+	for _, char := range id {
+		if !(char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || char >= '0' && char <= '9' || char == '-' || char == '_' || char == '.') {
+			return fmt.Errorf("invalid job id %s, expected only letters, digits, '-', '_' and '.'", id)
+		}
+	}
+	return nil
+}
+
+func (t *Tracker) request(id string) *trackedRequest {
+	request, ok := t.requests[id]
+	if !ok {
+		request = &trackedRequest{latest: map[JobState]JobEvent{}, subscribers: map[*Subscription]struct{}{}}
+		t.requests[id] = request
+	}
+	return request
+}
+
+// Track follows the request with the given id, the returned view of dispatcher reports the events of its jobs.
+// The request is owned by the client of dispatcher, other clients that subscribed to the id are dropped.
+// finish has to be called with the request's status once it is done.
+func (t *Tracker) Track(dispatcher *JobDispatcher, id string) (*JobDispatcher, func(status int), error) {
+	if err := ValidateJobId(id); err != nil {
+		return nil, nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	request := t.request(id)
+	if request.running || request.done {
+		return nil, nil, fmt.Errorf("%w: %s", ErrJobIdInUse, id)
+	}
+	request.running = true
+	request.owner = dispatcher.Client()
+	for subscription := range request.subscribers {
+		if subscription.client != request.owner {
+			close(subscription.events)
+			delete(request.subscribers, subscription)
+		}
+	}
+
+	view := dispatcher.WithEvents(func(event JobEvent) {
+		t.publish(request, event)
+	})
+
+	finish := func(status int) {
+		t.publish(request, JobEvent{State: JobDone, Status: status})
+
+		// the events stay around for clients that subscribe right after the request finished
+		time.AfterFunc(t.retention, func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.requests[id] == request {
+				delete(t.requests, id)
+			}
+		})
+	}
+
+	return view, finish, nil
+}
+
+// publish sends event to every subscriber of request, events of a request that is done are dropped.
+func (t *Tracker) publish(request *trackedRequest, event JobEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if request.done {
+		return
+	}
+	request.latest[event.State] = event
+
+	for subscription := range request.subscribers {
+		subscription.send(event)
+	}
+
+	if event.State == JobDone {
+		request.running = false
+		request.done = true
+		clear(request.subscribers)
+	}
+}
+
+// Subscribe returns a subscription of client to the events of the request with the given id, it starts with the latest
+// event of each state so far. The request does not have to have arrived yet, if it turns out to be another client's
+// the subscription ends without events. Subscribing to a request of another client fails with ErrJobNotFound.
+func (t *Tracker) Subscribe(id, client string) (*Subscription, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if request, ok := t.requests[id]; ok && request.owner != "" && request.owner != client {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, id)
+	}
+
+	events := make(chan JobEvent, subscriptionBuffer)
+	subscription := &Subscription{Events: events, events: events, tracker: t, id: id, client: client}
+
+	request := t.request(id)
+	for _, state := range trackedStates {
+		if event, ok := request.latest[state]; ok {
+			subscription.send(event)
+		}
+	}
+	if !request.done {
+		request.subscribers[subscription] = struct{}{}
+	}
+
+	return subscription, nil
+}
+
+// send delivers event without blocking, only the done event is guaranteed to arrive since the last slot is kept for it.
+func (s *Subscription) send(event JobEvent) {
+	if event.State == JobDone {
+		s.events <- event
+		close(s.events)
+		return
+	}
+
+	if len(s.events) < cap(s.events)-1 {
+		s.events <- event
+	}
+}
+
+// Close stops the subscription, a request nobody waits for anymore is forgotten unless it runs.
+func (s *Subscription) Close() {
+	s.tracker.mu.Lock()
+	defer s.tracker.mu.Unlock()
+
+	request, ok := s.tracker.requests[s.id]
+	if !ok {
+		return
+	}
+
+	delete(request.subscribers, s)
+	if len(request.subscribers) == 0 && !request.running && !request.done {
+		delete(s.tracker.requests, s.id)
+	}
+}
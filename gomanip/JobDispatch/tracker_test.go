@@ -0,0 +1,212 @@
+package JobDispatch_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+
+	"goManip/JobDispatch"
+	"goManip/jobs"
+)
+
+// collect reads the events of subscription until it is closed.
+func collect(t *testing.T, subscription *JobDispatch.Subscription) []JobDispatch.JobEvent {
+	t.Helper()
+
+	var events []JobDispatch.JobEvent
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		case <-timeout:
+			t.Fatal("subscription was not closed")
+		}
+	}
+}
+
+func statesOf(events []JobDispatch.JobEvent) []JobDispatch.JobState {
+	states := make([]JobDispatch.JobState, len(events))
+	for idx, event := range events {
+		states[idx] = event.State
+	}
+	return states
+}
+
+func TestValidateJobId(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr bool
+	}{
+		{name: "uuid", id: "0b5e9e2c-5a43-4a8a-9d4c-0e1e7d1f3c2a"},
+		{name: "snowflake", id: "1183392412335075328"},
+		{name: "dots and underscores", id: "guild_1.job_2"},
+		{name: "Handle empty id", id: "", wantErr: true},
+		{name: "Handle long id", id: strings.Repeat("a", 65), wantErr: true},
+		{name: "Handle path characters", id: "../jobs", wantErr: true},
+		{name: "Handle spaces", id: "my job", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := JobDispatch.ValidateJobId(tt.id)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func subscribe(t *testing.T, tracker *JobDispatch.Tracker, id string) *JobDispatch.Subscription {
+	t.Helper()
+	subscription, err := tracker.Subscribe(id, JobDispatch.DefaultClient)
+	assert.NoError(t, err)
+	return subscription
+}
+
+func TestTracker(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+	tracker := JobDispatch.NewTracker(time.Minute)
+
+	image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	// clients may subscribe before their request arrives
+	early := subscribe(t, tracker, "job-1")
+	defer early.Close()
+
+	dispatcher, finish, err := tracker.Track(jobDispatcher, "job-1")
+	assert.NoError(t, err)
+
+	_, _, err = tracker.Track(jobDispatcher, "job-1")
+	assert.ErrorIs(t, err, JobDispatch.ErrJobIdInUse)
+
+	result, err := JobDispatch.EnqueueOperation(dispatcher, &image, &mockOperationProgress{steps: 2})
+	assert.NoError(t, err)
+	result.Close()
+	finish(200)
+
+	events := collect(t, early)
+	assert.Equal(t, []JobDispatch.JobState{
+		JobDispatch.JobQueued, JobDispatch.JobStarted, JobDispatch.JobProgress, JobDispatch.JobProgress, JobDispatch.JobDone,
+	}, statesOf(events))
+	assert.Equal(t, 200, events[len(events)-1].Status)
+
+	// late subscribers get the latest event of each state
+	late := subscribe(t, tracker, "job-1")
+	defer late.Close()
+	events = collect(t, late)
+	assert.Equal(t, []JobDispatch.JobState{
+		JobDispatch.JobQueued, JobDispatch.JobStarted, JobDispatch.JobProgress, JobDispatch.JobDone,
+	}, statesOf(events))
+	assert.Equal(t, &jobs.Progress{Done: 2, Total: 2, Unit: jobs.UnitStep}, events[2].Progress)
+
+	// the id stays taken while its events are kept
+	_, _, err = tracker.Track(jobDispatcher, "job-1")
+	assert.ErrorIs(t, err, JobDispatch.ErrJobIdInUse)
+}
+
+func TestTrackerRetention(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	tracker := JobDispatch.NewTracker(10 * time.Millisecond)
+	jobDispatcher := JobDispatch.NewJobDispatcher(make(chan *jobs.JobRequest), 1, fixedTimeouts(time.Second))
+	defer jobDispatcher.Close()
+
+	_, finish, err := tracker.Track(jobDispatcher, "job-1")
+	assert.NoError(t, err)
+	finish(400)
+
+	assert.Eventually(t, func() bool {
+		_, finish, err := tracker.Track(jobDispatcher, "job-1")
+		if err == nil {
+			finish(200)
+		}
+		return err == nil
+	}, time.Second, 5*time.Millisecond, "the id should be free once the events expired")
+}
+
+func TestTrackerInvalidId(t *testing.T) {
+	tracker := JobDispatch.NewTracker(time.Minute)
+	jobDispatcher := JobDispatch.NewJobDispatcher(make(chan *jobs.JobRequest), 1, fixedTimeouts(time.Second))
+	defer jobDispatcher.Close()
+
+	_, _, err := tracker.Track(jobDispatcher, "not a job id")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, JobDispatch.ErrJobIdInUse)
+}
+
+func TestTrackerSlowSubscriber(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+	tracker := JobDispatch.NewTracker(time.Minute)
+
+	image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	subscription := subscribe(t, tracker, "job-1")
+	defer subscription.Close()
+
+	// progress is reported on the worker, a subscriber that does not read must not hold it up
+	dispatcher, finish, err := tracker.Track(jobDispatcher, "job-1")
+	assert.NoError(t, err)
+	result, err := JobDispatch.EnqueueOperation(dispatcher, &image, &mockOperationProgress{steps: 100})
+	assert.NoError(t, err)
+	result.Close()
+	finish(200)
+
+	// progress that did not fit was dropped, but the done event always arrives
+	events := collect(t, subscription)
+	assert.Less(t, len(events), 100)
+	assert.Equal(t, JobDispatch.JobDone, events[len(events)-1].State)
+}
+
+func TestTrackerOwner(t *testing.T) {
+	jobDispatcher := startWorkers(t, func(requests chan *jobs.JobRequest) *JobDispatch.JobDispatcher {
+		return JobDispatch.NewJobDispatcher(requests, 2, fixedTimeouts(time.Second))
+	}, 1)
+	tracker := JobDispatch.NewTracker(time.Minute)
+
+	image := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	// another client subscribing before the request arrives is dropped once it does
+	early, err := tracker.Subscribe("job-1", "other")
+	assert.NoError(t, err)
+	defer early.Close()
+
+	dispatcher, finish, err := tracker.Track(jobDispatcher.ForClient("owner", nil), "job-1")
+	assert.NoError(t, err)
+	assert.Empty(t, collect(t, early))
+
+	_, err = tracker.Subscribe("job-1", "other")
+	assert.ErrorIs(t, err, JobDispatch.ErrJobNotFound)
+
+	owner, err := tracker.Subscribe("job-1", "owner")
+	assert.NoError(t, err)
+	defer owner.Close()
+
+	result, err := JobDispatch.EnqueueOperation(dispatcher, &image, MockOperationSuccess{})
+	assert.NoError(t, err)
+	result.Close()
+	finish(200)
+
+	assert.Equal(t, []JobDispatch.JobState{JobDispatch.JobQueued, JobDispatch.JobStarted, JobDispatch.JobDone}, statesOf(collect(t, owner)))
+
+	// the events kept after the request finished are the owner's as well
+	_, err = tracker.Subscribe("job-1", "other")
+	assert.ErrorIs(t, err, JobDispatch.ErrJobNotFound)
+}
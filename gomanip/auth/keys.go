@@ -0,0 +1,221 @@
+// Package auth identifies clients by their api key and holds each key's rate limit, daily pixel quota and the
+// operations it may use. Keys are only known by their sha256 hash, so the config holds no secrets.
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+var (
+	ErrMissingKey    = errors.New("missing api key")
+	ErrUnknownKey    = errors.New("unknown api key")
+	ErrForbidden     = errors.New("the api key does not allow this operation")
+	ErrPriority      = errors.New("the api key may not choose a priority")
+	ErrRateLimited   = errors.New("rate limit exceeded")
+	ErrQuotaExceeded = errors.New("daily pixel quota exceeded")
+)
+
+// KeyConfig describes a key and what it may do, the limits are off when they are 0.
+type KeyConfig struct {
+	Name string
+	// Hash is the hex encoded sha256 hash of the key.
+	Hash string
+	// RateLimit is how many requests per second the key may make, Burst how many it may make at once.
+	// Without a burst it may make as many at once as it may make in a second.
+	RateLimit float64
+	Burst     int
+	// DailyPixels is how many pixels the images of the key may have together per UTC day.
+	DailyPixels int64
+	// Operations are the names of the endpoints the key may use, it may use every endpoint when empty.
+	Operations []string
+	// AllowPriority lets the key choose the priority of its jobs instead of that of their operation.
+	AllowPriority bool
+}
+
+func (k KeyConfig) Validate() error {
+	if k.Name == "" {
+		return errors.New("expected a name")
+	}
+	if _, err := parseHash(k.Hash); err != nil {
+		return err
+	}
+	if k.RateLimit < 0 {
+		return fmt.Errorf("expected a rate limit of 0 or more, got %g", k.RateLimit)
+	}
+	if k.Burst < 0 {
+		return fmt.Errorf("expected a burst of 0 or more, got %d", k.Burst)
+	}
+	if k.DailyPixels < 0 {
+		return fmt.Errorf("expected daily pixels of 0 or more, got %d", k.DailyPixels)
+	}
+	return nil
+}
+
+func parseHash(hash string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != sha256.Size {
+		return sum, fmt.Errorf("expected a hex encoded sha256 hash, got %q", hash)
+	}
+	copy(sum[:], decoded)
+	return sum, nil
+}
+
+// Hash returns the hash a key is configured by.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Keys are the keys clients may use.
+type Keys struct {
+	byHash map[[sha256.Size]byte]*Key
+}
+
+// NewKeys makes the keys of configs, they have to be validated first. now is the clock the daily quotas are counted by.
+func NewKeys(configs []KeyConfig, now func() time.Time) *Keys {
+	keys := &Keys{byHash: make(map[[sha256.Size]byte]*Key, len(configs))}
+	for _, config := range configs {
+		hash, _ := parseHash(config.Hash)
+		keys.byHash[hash] = newKey(config, now)
+	}
+	return keys
+}
+
+// Lookup returns the key a client sent.
+func (k *Keys) Lookup(key string) (*Key, error) {
+	if key == "" {
+		return nil, ErrMissingKey
+	}
+	found, ok := k.byHash[sha256.Sum256([]byte(key))]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return found, nil
+}
+
+// Key is a client's key with the requests and pixels it used up.
+type Key struct {
+	Name        string
+	operations  []string
+	dailyPixels int64
+	priority    bool
+	// limiter is nil when the key is not rate limited
+	limiter *rate.Limiter
+	now     func() time.Time
+
+	mu sync.Mutex
+	// day is the UTC day usedPixels were counted on
+	day        time.Time
+	usedPixels int64
+}
+
+func newKey(config KeyConfig, now func() time.Time) *Key {
+	key := &Key{
+		Name:        config.Name,
+		operations:  config.Operations,
+		dailyPixels: config.DailyPixels,
+		priority:    config.AllowPriority,
+		now:         now,
+	}
+	if config.RateLimit > 0 {
+		burst := config.Burst
+		if burst == 0 {
+			burst = max(1, int(math.Ceil(config.RateLimit)))
+		}
+		key.limiter = rate.NewLimiter(rate.Limit(config.RateLimit), burst)
+	}
+	return key
+}
+
+// Allows reports whether the key may use the endpoint named operation.
+func (k *Key) Allows(operation string) bool {
+	return len(k.operations) == 0 || slices.Contains(k.operations, operation)
+}
+
+// AllowsPriority reports whether the key may choose the priority of its jobs.
+func (k *Key) AllowsPriority() bool {
+	return k.priority
+}
+
+// Allow takes a request from the key's rate limit, when it is used up it returns how long until the next request is allowed.
+func (k *Key) Allow() (time.Duration, bool) {
+	if k.limiter == nil {
+		return 0, true
+	}
+
+	now := k.now()
+	reservation := k.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return delay, false
+	}
+	return 0, true
+}
+
+// Charge counts pixels against the key's daily quota, images that do not fit into what is left of it fail with ErrQuotaExceeded.
+func (k *Key) Charge(pixels int64) error {
+	if k.dailyPixels == 0 {
+		return nil
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.rollOver()
+	if left := k.dailyPixels - k.usedPixels; pixels > left {
+		return fmt.Errorf("%w: the image has %d pixels, %d of %d are left today", ErrQuotaExceeded, pixels, left, k.dailyPixels)
+	}
+	k.usedPixels += pixels
+	return nil
+}
+
+// Refund gives back pixels that were charged for an image that was not processed after all.
+func (k *Key) Refund(pixels int64) {
+	if k.dailyPixels == 0 {
+		return
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.rollOver()
+	// pixels charged yesterday are not given back to today
+	k.usedPixels = max(0, k.usedPixels-pixels)
+}
+
+// Exhausted reports whether the key used up its daily quota.
+func (k *Key) Exhausted() bool {
+	if k.dailyPixels == 0 {
+		return false
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.rollOver()
+	return k.usedPixels >= k.dailyPixels
+}
+
+// QuotaReset is when the daily quota starts over, at the next UTC midnight.
+func (k *Key) QuotaReset() time.Time {
+	return today(k.now()).AddDate(0, 0, 1)
+}
+
+// rollOver starts counting the pixels of a new day, it has to be called with mu held.
+func (k *Key) rollOver() {
+	if day := today(k.now()); !day.Equal(k.day) {
+		k.day = day
+		k.usedPixels = 0
+	}
+}
+
+func today(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,209 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"goManip/auth"
+)
+
+// testClock is a clock the test moves by hand.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func newTestKeys(configs ...auth.KeyConfig) (*auth.Keys, *testClock) {
+	clock := &testClock{now: time.Date(2026, 3, 14, 22, 0, 0, 0, time.UTC)}
+	return auth.NewKeys(configs, clock.Now), clock
+}
+
+func TestKeyConfigValidate(t *testing.T) {
+	valid := auth.KeyConfig{Name: "bot", Hash: auth.Hash("secret")}
+	assert.NoError(t, valid.Validate())
+
+	tests := []struct {
+		name    string
+		modify  func(config *auth.KeyConfig)
+		wantErr string
+	}{
+		{
+			name:    "No name",
+			modify:  func(config *auth.KeyConfig) { config.Name = "" },
+			wantErr: "name",
+		},
+		{
+			name:    "Plain key instead of hash",
+			modify:  func(config *auth.KeyConfig) { config.Hash = "secret" },
+			wantErr: "sha256",
+		},
+		{
+			name:    "Short hash",
+			modify:  func(config *auth.KeyConfig) { config.Hash = config.Hash[:32] },
+			wantErr: "sha256",
+		},
+		{
+			name:    "Negative rate limit",
+			modify:  func(config *auth.KeyConfig) { config.RateLimit = -1 },
+			wantErr: "rate limit",
+		},
+		{
+			name:    "Negative burst",
+			modify:  func(config *auth.KeyConfig) { config.Burst = -1 },
+			wantErr: "burst",
+		},
+		{
+			name:    "Negative daily pixels",
+			modify:  func(config *auth.KeyConfig) { config.DailyPixels = -1 },
+			wantErr: "daily pixels",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+			assert.ErrorContains(t, config.Validate(), tt.wantErr)
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	keys, _ := newTestKeys(
+		auth.KeyConfig{Name: "bot", Hash: auth.Hash("bot-secret")},
+		// hashes are read case insensitive, as sha256sum and other tools write them differently
+		auth.KeyConfig{Name: "admin", Hash: strings.ToUpper(auth.Hash("admin-secret"))},
+	)
+
+	key, err := keys.Lookup("bot-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "bot", key.Name)
+
+	key, err = keys.Lookup("admin-secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", key.Name)
+
+	_, err = keys.Lookup("")
+	assert.ErrorIs(t, err, auth.ErrMissingKey)
+
+	_, err = keys.Lookup(auth.Hash("bot-secret"))
+	assert.ErrorIs(t, err, auth.ErrUnknownKey)
+}
+
+func TestAllows(t *testing.T) {
+	keys, _ := newTestKeys(
+		auth.KeyConfig{Name: "all", Hash: auth.Hash("all")},
+		auth.KeyConfig{Name: "some", Hash: auth.Hash("some"), Operations: []string{"invert", "animate/zoom"}},
+	)
+
+	all, _ := keys.Lookup("all")
+	assert.True(t, all.Allows("invert"))
+	assert.True(t, all.Allows("batch"))
+
+	some, _ := keys.Lookup("some")
+	assert.True(t, some.Allows("animate/zoom"))
+	assert.False(t, some.Allows("batch"))
+}
+
+func TestAllowsPriority(t *testing.T) {
+	keys, _ := newTestKeys(
+		auth.KeyConfig{Name: "bot", Hash: auth.Hash("bot")},
+		auth.KeyConfig{Name: "admin", Hash: auth.Hash("admin"), AllowPriority: true},
+	)
+
+	bot, _ := keys.Lookup("bot")
+	assert.False(t, bot.AllowsPriority())
+	admin, _ := keys.Lookup("admin")
+	assert.True(t, admin.AllowsPriority())
+}
+
+func TestAllow(t *testing.T) {
+	keys, clock := newTestKeys(
+		auth.KeyConfig{Name: "limited", Hash: auth.Hash("limited"), RateLimit: 2, Burst: 3},
+		auth.KeyConfig{Name: "unlimited", Hash: auth.Hash("unlimited")},
+	)
+
+	limited, _ := keys.Lookup("limited")
+	for range 3 {
+		_, ok := limited.Allow()
+		assert.True(t, ok)
+	}
+	retryAfter, ok := limited.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// a rejected request does not take up the next one
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	_, ok = limited.Allow()
+	assert.True(t, ok)
+	_, ok = limited.Allow()
+	assert.False(t, ok)
+
+	unlimited, _ := keys.Lookup("unlimited")
+	for range 100 {
+		_, ok := unlimited.Allow()
+		assert.True(t, ok)
+	}
+}
+
+func TestAllowDefaultBurst(t *testing.T) {
+	keys, _ := newTestKeys(auth.KeyConfig{Name: "slow", Hash: auth.Hash("slow"), RateLimit: 0.5})
+
+	// less than a request per second still allows one
+	key, _ := keys.Lookup("slow")
+	_, ok := key.Allow()
+	assert.True(t, ok)
+	retryAfter, ok := key.Allow()
+	assert.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+}
+
+func TestCharge(t *testing.T) {
+	keys, clock := newTestKeys(auth.KeyConfig{Name: "bot", Hash: auth.Hash("bot"), DailyPixels: 1000})
+	key, _ := keys.Lookup("bot")
+
+	assert.NoError(t, key.Charge(600))
+	assert.False(t, key.Exhausted())
+
+	// an image that does not fit is not charged
+	assert.ErrorIs(t, key.Charge(500), auth.ErrQuotaExceeded)
+	assert.NoError(t, key.Charge(400))
+	assert.True(t, key.Exhausted())
+	assert.ErrorIs(t, key.Charge(1), auth.ErrQuotaExceeded)
+
+	// the quota starts over at UTC midnight
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), key.QuotaReset())
+	clock.now = key.QuotaReset()
+	assert.False(t, key.Exhausted())
+	assert.NoError(t, key.Charge(1000))
+}
+
+func TestChargeUnlimited(t *testing.T) {
+	keys, _ := newTestKeys(auth.KeyConfig{Name: "bot", Hash: auth.Hash("bot")})
+	key, _ := keys.Lookup("bot")
+
+	assert.NoError(t, key.Charge(1<<40))
+	assert.False(t, key.Exhausted())
+}
+
+func TestRefund(t *testing.T) {
+	keys, clock := newTestKeys(auth.KeyConfig{Name: "bot", Hash: auth.Hash("bot"), DailyPixels: 1000})
+	key, _ := keys.Lookup("bot")
+
+	assert.NoError(t, key.Charge(1000))
+	key.Refund(400)
+	assert.False(t, key.Exhausted())
+	assert.NoError(t, key.Charge(400))
+
+	// pixels charged yesterday are not given back to the next day
+	clock.now = key.QuotaReset()
+	key.Refund(1000)
+	assert.NoError(t, key.Charge(1000))
+	assert.ErrorIs(t, key.Charge(1), auth.ErrQuotaExceeded)
+}
@@ -0,0 +1,435 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/bytes"
+	"gopkg.in/yaml.v3"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/worker"
+)
+
+// poolInterval is how often the worker pool checks the queue wait.
+const poolInterval = 100 * time.Millisecond
+
+// EnvPrefix starts the name of every environment variable overriding the config file, i.e GOMANIP_WORKERS.
+const EnvPrefix = "GOMANIP_"
+
+// Config holds the settings of the server, read from a yaml file and overridden by environment variables.
+type Config struct {
+	// ListenAddress is the host and port the server listens on, i.e :8080.
+	ListenAddress string `yaml:"listenAddress"`
+	// GRPCListenAddress is the host and port the gRPC api listens on, it is not served when empty.
+	GRPCListenAddress string `yaml:"grpcListenAddress"`
+	TLS               TLS    `yaml:"tls"`
+	// Workers is the most workers the pool grows to, it starts with MinWorkers.
+	Workers    int `yaml:"workers"`
+	MinWorkers int `yaml:"minWorkers"`
+	// ScaleUpWait adds a worker when a job waited longer than this for one.
+	ScaleUpWait time.Duration `yaml:"scaleUpWait"`
+	// IdleTimeout removes a worker that had no job for this long.
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+	// OpenCVThreads is how many threads a worker's OpenCV calls may use, 0 splits the cores between the workers.
+	OpenCVThreads int `yaml:"openCVThreads"`
+	// QueueDepth is how many jobs can wait for a worker, 0 queues 4 per worker.
+	QueueDepth int      `yaml:"queueDepth"`
+	Timeouts   Timeouts `yaml:"timeouts"`
+	// BodyLimit is the largest request body accepted, i.e 32M.
+	BodyLimit   string     `yaml:"bodyLimit"`
+	Operations  Operations `yaml:"operations"`
+	CascadeDir  string     `yaml:"cascadeDir"`
+	OverlayDir  string     `yaml:"overlayDir"`
+	PrettyPrint bool       `yaml:"prettyPrint"`
+	// APIKeys are the keys clients have to send, every client is let in when there are none.
+	APIKeys []APIKey `yaml:"apiKeys"`
+}
+
+// TLS names the certificate and key the server uses for https, it serves plain http when both are empty.
+type TLS struct {
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Timeout is the timeout of a single operation, without a max it may be given up to the global max,
+// or its default if that is longer.
+type Timeout struct {
+	Default time.Duration `yaml:"default"`
+	Max     time.Duration `yaml:"max"`
+}
+
+// Timeouts are the default and max time a job may take, Operations overrides them per operation.
+type Timeouts struct {
+	Default    time.Duration      `yaml:"default"`
+	Max        time.Duration      `yaml:"max"`
+	Operations map[string]Timeout `yaml:"operations"`
+}
+
+// Dispatch converts the timeouts into the ones used by the job dispatcher.
+func (t Timeouts) Dispatch() JobDispatch.Timeouts {
+	timeouts := JobDispatch.Timeouts{
+		Default:    JobDispatch.Timeout{Default: t.Default, Max: t.Max},
+		Operations: map[string]JobDispatch.Timeout{},
+	}
+	for name, timeout := range t.Operations {
+		maxTimeout := timeout.Max
+		if maxTimeout == 0 {
+			maxTimeout = max(t.Max, timeout.Default)
+		}
+		timeouts.Operations[name] = JobDispatch.Timeout{Default: timeout.Default, Max: maxTimeout}
+	}
+	return timeouts
+}
+
+// Operations turns endpoints on and off by name, the name is the endpoint's path without slashes, i.e invert or animate/zoom.
+// When Enabled is not empty only the operations in it are served, Disabled operations are never served.
+type Operations struct {
+	Enabled  []string `yaml:"enabled"`
+	Disabled []string `yaml:"disabled"`
+}
+
+func (o Operations) IsEnabled(name string) bool {
+	if slices.Contains(o.Disabled, name) {
+		return false
+	}
+	return len(o.Enabled) == 0 || slices.Contains(o.Enabled, name)
+}
+
+// APIKey lets a client in and limits what it may do, the limits are off when they are 0. Only the key's hash is
+// configured, i.e from printf '%s' "$KEY" | sha256sum.
+type APIKey struct {
+	Name string `yaml:"name"`
+	Hash string `yaml:"hash"`
+	// RateLimit is how many requests per second the key may make, Burst how many it may make at once.
+	RateLimit float64 `yaml:"rateLimit"`
+	Burst     int     `yaml:"burst"`
+	// DailyPixels is how many pixels the key's images may have together per UTC day.
+	DailyPixels int64 `yaml:"dailyPixels"`
+	// Operations are the endpoints the key may use by name, like the enabled operations. It may use all of them when empty.
+	Operations []string `yaml:"operations"`
+	// AllowPriority lets the key's requests choose their priority lane, otherwise the priority they ask for is refused.
+	AllowPriority bool `yaml:"allowPriority"`
+}
+
+// Auth converts the key into the one used for authentication.
+func (k APIKey) Auth() auth.KeyConfig {
+	return auth.KeyConfig{
+		Name:          k.Name,
+		Hash:          k.Hash,
+		RateLimit:     k.RateLimit,
+		Burst:         k.Burst,
+		DailyPixels:   k.DailyPixels,
+		Operations:    k.Operations,
+		AllowPriority: k.AllowPriority,
+	}
+}
+
+// Keys returns the keys clients may use, nil when every client is let in. They have to be validated first.
+func (c *Config) Keys(now func() time.Time) *auth.Keys {
+	if len(c.APIKeys) == 0 {
+		return nil
+	}
+	configs := make([]auth.KeyConfig, len(c.APIKeys))
+	for idx, key := range c.APIKeys {
+		configs[idx] = key.Auth()
+	}
+	return auth.NewKeys(configs, now)
+}
+
+// Default returns the settings used for anything neither the config file nor the environment sets.
+func Default() *Config {
+	return &Config{
+		ListenAddress: ":8080",
+		Workers:       runtime.NumCPU(),
+		MinWorkers:    1,
+		ScaleUpWait:   250 * time.Millisecond,
+		IdleTimeout:   30 * time.Second,
+		Timeouts: Timeouts{
+			Default: 10 * time.Second,
+			Max:     30 * time.Second,
+		},
+		BodyLimit:  "32M",
+		CascadeDir: "/cascades",
+		OverlayDir: "/overlays",
+	}
+}
+
+// Load reads the config file at path on top of the defaults and applies the environment overrides,
+// an empty path only uses the defaults and the environment. The result still has to be validated.
+func Load(path string) (*Config, error) {
+	config := Default()
+
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		defer file.Close()
+
+		decoder := yaml.NewDecoder(file)
+		// a misspelled setting would otherwise be silently ignored
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	if err := config.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// ApplyEnv overrides the settings with the environment variables found by lookup. Lists are comma separated
+// and GOMANIP_OPERATION_TIMEOUTS uses the same name=default:max format as the operation_timeouts flag.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	var errs []error
+	env := func(name string, apply func(value string) error) {
+		value, ok := lookup(EnvPrefix + name)
+		if !ok {
+			return
+		}
+		if err := apply(value); err != nil {
+			errs = append(errs, fmt.Errorf("%s%s: %w", EnvPrefix, name, err))
+		}
+	}
+
+	env("LISTEN_ADDRESS", setString(&c.ListenAddress))
+	env("GRPC_LISTEN_ADDRESS", setString(&c.GRPCListenAddress))
+	env("TLS_CERT_FILE", setString(&c.TLS.CertFile))
+	env("TLS_KEY_FILE", setString(&c.TLS.KeyFile))
+	env("WORKERS", setInt(&c.Workers))
+	env("MIN_WORKERS", setInt(&c.MinWorkers))
+	env("SCALE_UP_WAIT", setDuration(&c.ScaleUpWait))
+	env("IDLE_TIMEOUT", setDuration(&c.IdleTimeout))
+	env("OPENCV_THREADS", setInt(&c.OpenCVThreads))
+	env("QUEUE_DEPTH", setInt(&c.QueueDepth))
+	env("TIMEOUT", setDuration(&c.Timeouts.Default))
+	env("MAX_TIMEOUT", setDuration(&c.Timeouts.Max))
+	env("OPERATION_TIMEOUTS", func(value string) error {
+		timeouts, err := ParseOperationTimeouts(value)
+		if err != nil {
+			return err
+		}
+		c.SetOperationTimeouts(timeouts)
+		return nil
+	})
+	env("BODY_LIMIT", setString(&c.BodyLimit))
+	env("ENABLED_OPERATIONS", setList(&c.Operations.Enabled))
+	env("DISABLED_OPERATIONS", setList(&c.Operations.Disabled))
+	env("CASCADE_DIR", setString(&c.CascadeDir))
+	env("OVERLAY_DIR", setString(&c.OverlayDir))
+	env("PRETTY_PRINT", func(value string) error {
+		prettyPrint, err := strconv.ParseBool(value)
+		c.PrettyPrint = prettyPrint
+		return err
+	})
+
+	return errors.Join(errs...)
+}
+
+// Pool returns the settings of the worker pool, queueWait reports how long the oldest queued job has been waiting.
+func (c *Config) Pool(queueWait func() time.Duration) worker.PoolConfig {
+	return worker.PoolConfig{
+		MinWorkers:    c.MinWorkers,
+		MaxWorkers:    c.Workers,
+		ScaleUpWait:   c.ScaleUpWait,
+		IdleTimeout:   c.IdleTimeout,
+		Interval:      poolInterval,
+		OpenCVThreads: c.OpenCVThreads,
+		QueueWait:     queueWait,
+	}
+}
+
+// BodyLimitBytes is the body limit in bytes, it has to be validated first.
+func (c *Config) BodyLimitBytes() int64 {
+	limit, _ := bytes.Parse(c.BodyLimit)
+	return limit
+}
+
+// SetOperationTimeouts adds timeouts, replacing those of the same operations.
+func (c *Config) SetOperationTimeouts(timeouts map[string]Timeout) {
+	if c.Timeouts.Operations == nil {
+		c.Timeouts.Operations = map[string]Timeout{}
+	}
+	for name, timeout := range timeouts {
+		c.Timeouts.Operations[name] = timeout
+	}
+}
+
+// ParseOperationTimeouts parses a comma separated list of per operation timeouts, each written as
+// name=default or name=default:max, i.e "randomFilter=20s:1m,zoom=30s". The max is left 0 when it is not given.
+func ParseOperationTimeouts(spec string) (map[string]Timeout, error) {
+	timeouts := map[string]Timeout{}
+	if strings.TrimSpace(spec) == "" {
+		return timeouts, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		name, durations, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=timeout, got %q", entry)
+		}
+
+		defaultString, maxString, hasMax := strings.Cut(durations, ":")
+		var timeout Timeout
+		var err error
+		if timeout.Default, err = time.ParseDuration(defaultString); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if hasMax {
+			if timeout.Max, err = time.ParseDuration(maxString); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		timeouts[name] = timeout
+	}
+
+	return timeouts, nil
+}
+
+// Validate checks every setting and reports all problems at once. operations are the names of the
+// endpoints the server has, the enabled and disabled lists may only name those.
+func (c *Config) Validate(operations []string) error {
+	var errs []error
+	invalid := func(setting string, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", setting, fmt.Sprintf(format, args...)))
+	}
+
+	if err := validateAddress(c.ListenAddress); err != nil {
+		invalid("listenAddress", "%s", err)
+	}
+	if c.GRPCListenAddress != "" {
+		if err := validateAddress(c.GRPCListenAddress); err != nil {
+			invalid("grpcListenAddress", "%s", err)
+		} else if c.GRPCListenAddress == c.ListenAddress {
+			invalid("grpcListenAddress", "has to differ from the listen address %s", c.ListenAddress)
+		}
+	}
+
+	if c.TLS.Enabled() {
+		for setting, path := range map[string]string{"tls.certFile": c.TLS.CertFile, "tls.keyFile": c.TLS.KeyFile} {
+			if path == "" {
+				invalid(setting, "is required when tls is used")
+			} else if _, err := os.Stat(path); err != nil {
+				invalid(setting, "%s", err)
+			}
+		}
+	}
+
+	if err := c.Pool(nil).Validate(); err != nil {
+		invalid("workers", "%s", err)
+	}
+	if c.QueueDepth < 0 {
+		invalid("queueDepth", "expected 0 or more, got %d", c.QueueDepth)
+	}
+
+	if err := c.Timeouts.Dispatch().Validate(); err != nil {
+		invalid("timeouts", "%s", err)
+	}
+
+	if limit, err := bytes.Parse(c.BodyLimit); err != nil {
+		invalid("bodyLimit", "%s", err)
+	} else if limit <= 0 {
+		invalid("bodyLimit", "expected a positive size, got %s", c.BodyLimit)
+	}
+
+	for setting, names := range map[string][]string{"operations.enabled": c.Operations.Enabled, "operations.disabled": c.Operations.Disabled} {
+		for _, name := range names {
+			if !slices.Contains(operations, name) {
+				invalid(setting, "unknown operation %q, expected one of %s", name, strings.Join(operations, ", "))
+			}
+		}
+	}
+
+	names, hashes := map[string]bool{}, map[string]bool{}
+	for idx, key := range c.APIKeys {
+		setting := fmt.Sprintf("apiKeys[%d]", idx)
+		if err := key.Auth().Validate(); err != nil {
+			invalid(setting, "%s", err)
+			continue
+		}
+		// hashes are hex, written in either case
+		hash := strings.ToLower(key.Hash)
+		if names[key.Name] {
+			invalid(setting, "the name %s is used by another key", key.Name)
+		}
+		if hashes[hash] {
+			invalid(setting, "%s has the same hash as another key", key.Name)
+		}
+		names[key.Name], hashes[hash] = true, true
+		for _, name := range key.Operations {
+			if !slices.Contains(operations, name) {
+				invalid(setting+".operations", "unknown operation %q, expected one of %s", name, strings.Join(operations, ", "))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateAddress(address string) error {
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("invalid port %q", port)
+	}
+	return nil
+}
+
+func setString(setting *string) func(string) error {
+	return func(value string) error {
+		*setting = value
+		return nil
+	}
+}
+
+func setInt(setting *int) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*setting = parsed
+		return nil
+	}
+}
+
+func setDuration(setting *time.Duration) func(string) error {
+	return func(value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*setting = parsed
+		return nil
+	}
+}
+
+func setList(setting *[]string) func(string) error {
+	return func(value string) error {
+		*setting = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*setting = append(*setting, item)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,403 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/config"
+)
+
+var testOperations = []string{"invert", "animate/zoom", "batch"}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "gomanip.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+listenAddress: 127.0.0.1:9000
+workers: 3
+queueDepth: 20
+bodyLimit: 8M
+timeouts:
+  default: 5s
+  max: 1m
+  operations:
+    randomFilter:
+      default: 20s
+      max: 2m
+operations:
+  disabled: [batch]
+apiKeys:
+  - name: bot
+    hash: 2bb80d537b1da3e38bd30361aa855686bde0eacd7162fef6a25fe97bf527a25b
+    rateLimit: 2.5
+    burst: 5
+    dailyPixels: 100000000
+    operations: [invert, animate/zoom]
+`)
+
+	cfg, err := config.Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9000", cfg.ListenAddress)
+	assert.Equal(t, 3, cfg.Workers)
+	assert.Equal(t, 20, cfg.QueueDepth)
+	assert.Equal(t, "8M", cfg.BodyLimit)
+	assert.EqualValues(t, 8<<20, cfg.BodyLimitBytes())
+	assert.Equal(t, 5*time.Second, cfg.Timeouts.Default)
+	assert.Equal(t, time.Minute, cfg.Timeouts.Max)
+	assert.Equal(t, config.Timeout{Default: 20 * time.Second, Max: 2 * time.Minute}, cfg.Timeouts.Operations["randomFilter"])
+	assert.Equal(t, []string{"batch"}, cfg.Operations.Disabled)
+	assert.Equal(t, []config.APIKey{{
+		Name:        "bot",
+		Hash:        auth.Hash("secret"),
+		RateLimit:   2.5,
+		Burst:       5,
+		DailyPixels: 100_000_000,
+		Operations:  []string{"invert", "animate/zoom"},
+	}}, cfg.APIKeys)
+	// settings missing from the file keep their defaults
+	assert.Equal(t, "/cascades", cfg.CascadeDir)
+	assert.NoError(t, cfg.Validate(testOperations))
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Missing file", path: filepath.Join(t.TempDir(), "missing.yaml")},
+		{name: "Unknown setting", path: writeConfig(t, "listenAdress: :9000\n")},
+		{name: "Wrong type", path: writeConfig(t, "workers: many\n")},
+		{name: "Invalid duration", path: writeConfig(t, "timeouts:\n  default: soon\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.Load(tt.path)
+			assert.Error(t, err)
+			assert.Nil(t, cfg)
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	cfg := config.Default()
+	cfg.Operations.Enabled = []string{"batch"}
+
+	err := cfg.ApplyEnv(envLookup(map[string]string{
+		"GOMANIP_LISTEN_ADDRESS":      ":9090",
+		"GOMANIP_GRPC_LISTEN_ADDRESS": ":9091",
+		"GOMANIP_WORKERS":             "2",
+		"GOMANIP_MIN_WORKERS":         "2",
+		"GOMANIP_IDLE_TIMEOUT":        "1m",
+		"GOMANIP_MAX_TIMEOUT":         "45s",
+		"GOMANIP_OPERATION_TIMEOUTS":  "randomFilter=20s:1m, zoom=5s",
+		"GOMANIP_ENABLED_OPERATIONS":  "invert, animate/zoom",
+		"GOMANIP_PRETTY_PRINT":        "true",
+	}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, ":9090", cfg.ListenAddress)
+	assert.Equal(t, ":9091", cfg.GRPCListenAddress)
+	assert.Equal(t, 2, cfg.Workers)
+	assert.Equal(t, 2, cfg.MinWorkers)
+	assert.Equal(t, time.Minute, cfg.IdleTimeout)
+	assert.Equal(t, 45*time.Second, cfg.Timeouts.Max)
+	assert.Equal(t, []string{"invert", "animate/zoom"}, cfg.Operations.Enabled)
+	assert.True(t, cfg.PrettyPrint)
+
+	// an operation without a max follows the global max
+	assert.Equal(t, map[string]JobDispatch.Timeout{
+		"randomFilter": {Default: 20 * time.Second, Max: time.Minute},
+		"zoom":         {Default: 5 * time.Second, Max: 45 * time.Second},
+	}, cfg.Timeouts.Dispatch().Operations)
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	cfg := config.Default()
+	err := cfg.ApplyEnv(envLookup(map[string]string{
+		"GOMANIP_WORKERS":            "many",
+		"GOMANIP_TIMEOUT":            "soon",
+		"GOMANIP_OPERATION_TIMEOUTS": "zoom",
+	}))
+
+	// every invalid variable is reported, not only the first
+	assert.ErrorContains(t, err, "GOMANIP_WORKERS")
+	assert.ErrorContains(t, err, "GOMANIP_TIMEOUT")
+	assert.ErrorContains(t, err, "GOMANIP_OPERATION_TIMEOUTS")
+}
+
+func TestParseOperationTimeouts(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected map[string]config.Timeout
+		wantErr  bool
+	}{
+		{
+			name:     "Empty",
+			spec:     "",
+			expected: map[string]config.Timeout{},
+		},
+		{
+			name: "Default and max",
+			spec: "randomFilter=20s:1m, zoom=5s",
+			expected: map[string]config.Timeout{
+				"randomFilter": {Default: 20 * time.Second, Max: time.Minute},
+				"zoom":         {Default: 5 * time.Second},
+			},
+		},
+		{
+			name:    "Missing timeout",
+			spec:    "zoom",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid duration",
+			spec:    "zoom=soon",
+			wantErr: true,
+		},
+		{
+			name:    "Invalid max",
+			spec:    "zoom=5s:later",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeouts, err := config.ParseOperationTimeouts(tt.spec)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.expected, timeouts)
+			}
+		})
+	}
+}
+
+func TestTimeoutsDispatch(t *testing.T) {
+	timeouts := config.Timeouts{
+		Default: 10 * time.Second,
+		Max:     30 * time.Second,
+		Operations: map[string]config.Timeout{
+			"zoom":     {Default: 5 * time.Second},
+			"oilPaint": {Default: 45 * time.Second},
+		},
+	}
+
+	assert.Equal(t, JobDispatch.Timeouts{
+		Default: JobDispatch.Timeout{Default: 10 * time.Second, Max: 30 * time.Second},
+		Operations: map[string]JobDispatch.Timeout{
+			"zoom": {Default: 5 * time.Second, Max: 30 * time.Second},
+			// a default longer than the global max is its own max
+			"oilPaint": {Default: 45 * time.Second, Max: 45 * time.Second},
+		},
+	}, timeouts.Dispatch())
+}
+
+func TestValidate(t *testing.T) {
+	certFile := writeConfig(t, "cert")
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *config.Config)
+		wantErr string
+	}{
+		{
+			name:   "Defaults",
+			modify: func(cfg *config.Config) {},
+		},
+		{
+			name: "TLS",
+			modify: func(cfg *config.Config) {
+				cfg.TLS = config.TLS{CertFile: certFile, KeyFile: certFile}
+			},
+		},
+		{
+			name:    "Invalid listen address",
+			modify:  func(cfg *config.Config) { cfg.ListenAddress = "8080" },
+			wantErr: "listenAddress",
+		},
+		{
+			name:    "Invalid port",
+			modify:  func(cfg *config.Config) { cfg.ListenAddress = ":http-alt" },
+			wantErr: "listenAddress",
+		},
+		{
+			name:   "gRPC",
+			modify: func(cfg *config.Config) { cfg.GRPCListenAddress = ":9090" },
+		},
+		{
+			name:    "Invalid gRPC listen address",
+			modify:  func(cfg *config.Config) { cfg.GRPCListenAddress = "9090" },
+			wantErr: "grpcListenAddress",
+		},
+		{
+			name:    "gRPC on the HTTP address",
+			modify:  func(cfg *config.Config) { cfg.GRPCListenAddress = cfg.ListenAddress },
+			wantErr: "grpcListenAddress",
+		},
+		{
+			name:    "TLS key missing",
+			modify:  func(cfg *config.Config) { cfg.TLS.CertFile = certFile },
+			wantErr: "tls.keyFile",
+		},
+		{
+			name: "TLS cert does not exist",
+			modify: func(cfg *config.Config) {
+				cfg.TLS = config.TLS{CertFile: certFile + ".missing", KeyFile: certFile}
+			},
+			wantErr: "tls.certFile",
+		},
+		{
+			name:    "No workers",
+			modify:  func(cfg *config.Config) { cfg.MinWorkers = 0 },
+			wantErr: "workers",
+		},
+		{
+			name:    "Fewer max than min workers",
+			modify:  func(cfg *config.Config) { cfg.MinWorkers, cfg.Workers = 4, 2 },
+			wantErr: "workers",
+		},
+		{
+			name:    "No idle timeout",
+			modify:  func(cfg *config.Config) { cfg.IdleTimeout = 0 },
+			wantErr: "workers",
+		},
+		{
+			name:    "Negative queue depth",
+			modify:  func(cfg *config.Config) { cfg.QueueDepth = -1 },
+			wantErr: "queueDepth",
+		},
+		{
+			name:    "Max timeout shorter than default",
+			modify:  func(cfg *config.Config) { cfg.Timeouts.Max = time.Second },
+			wantErr: "timeouts",
+		},
+		{
+			name: "Operation max timeout shorter than default",
+			modify: func(cfg *config.Config) {
+				cfg.Timeouts.Operations = map[string]config.Timeout{"zoom": {Default: 10 * time.Second, Max: 5 * time.Second}}
+			},
+			wantErr: "zoom",
+		},
+		{
+			name:    "Invalid body limit",
+			modify:  func(cfg *config.Config) { cfg.BodyLimit = "lots" },
+			wantErr: "bodyLimit",
+		},
+		{
+			name:    "Unknown enabled operation",
+			modify:  func(cfg *config.Config) { cfg.Operations.Enabled = []string{"invert", "sharpen"} },
+			wantErr: `"sharpen"`,
+		},
+		{
+			name:    "Unknown disabled operation",
+			modify:  func(cfg *config.Config) { cfg.Operations.Disabled = []string{"zoom"} },
+			wantErr: "operations.disabled",
+		},
+		{
+			name: "API keys",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys = []config.APIKey{
+					{Name: "bot", Hash: auth.Hash("bot"), RateLimit: 5, DailyPixels: 1 << 30, Operations: []string{"invert"}},
+					{Name: "admin", Hash: auth.Hash("admin")},
+				}
+			},
+		},
+		{
+			name:    "API key without hash",
+			modify:  func(cfg *config.Config) { cfg.APIKeys = []config.APIKey{{Name: "bot"}} },
+			wantErr: "apiKeys[0]",
+		},
+		{
+			name: "API key with a negative quota",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys = []config.APIKey{{Name: "bot", Hash: auth.Hash("bot"), DailyPixels: -1}}
+			},
+			wantErr: "daily pixels",
+		},
+		{
+			name: "API keys with the same name",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys = []config.APIKey{{Name: "bot", Hash: auth.Hash("bot")}, {Name: "bot", Hash: auth.Hash("other")}}
+			},
+			wantErr: "apiKeys[1]",
+		},
+		{
+			name: "API keys with the same hash",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys = []config.APIKey{{Name: "bot", Hash: auth.Hash("bot")}, {Name: "other", Hash: strings.ToUpper(auth.Hash("bot"))}}
+			},
+			wantErr: "same hash",
+		},
+		{
+			name: "API key with an unknown operation",
+			modify: func(cfg *config.Config) {
+				cfg.APIKeys = []config.APIKey{{Name: "bot", Hash: auth.Hash("bot"), Operations: []string{"zoom"}}}
+			},
+			wantErr: "apiKeys[0].operations",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			tt.modify(cfg)
+
+			err := cfg.Validate(testOperations)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOperationsIsEnabled(t *testing.T) {
+	all := config.Operations{}
+	assert.True(t, all.IsEnabled("invert"))
+
+	disabled := config.Operations{Disabled: []string{"batch"}}
+	assert.True(t, disabled.IsEnabled("invert"))
+	assert.False(t, disabled.IsEnabled("batch"))
+
+	// disabling wins over enabling
+	enabled := config.Operations{Enabled: []string{"invert", "batch"}, Disabled: []string{"batch"}}
+	assert.True(t, enabled.IsEnabled("invert"))
+	assert.False(t, enabled.IsEnabled("animate/zoom"))
+	assert.False(t, enabled.IsEnabled("batch"))
+}
+
+func TestKeys(t *testing.T) {
+	cfg := config.Default()
+	assert.Nil(t, cfg.Keys(time.Now))
+
+	cfg.APIKeys = []config.APIKey{{Name: "bot", Hash: auth.Hash("secret"), Operations: []string{"invert"}}}
+	key, err := cfg.Keys(time.Now).Lookup("secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "bot", key.Name)
+	assert.True(t, key.Allows("invert"))
+	assert.False(t, key.Allows("batch"))
+	assert.False(t, key.AllowsPriority())
+}
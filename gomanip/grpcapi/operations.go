@@ -0,0 +1,269 @@
+package grpcapi
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+
+	"goManip/jobs"
+	"goManip/proto/gomanippb"
+	"goManip/util"
+)
+
+// errDisabled is returned for operations the config turned off, like their endpoints they are not served.
+var errDisabled = errors.New("operation is disabled")
+
+// operationName is the name of the HTTP endpoint an operation mirrors, the config turns both on and off together.
+func operationName(op *gomanippb.Operation) string {
+	switch op.GetOperation().(type) {
+	case *gomanippb.Operation_Invert:
+		return "invert"
+	case *gomanippb.Operation_Saturate:
+		return "saturate"
+	case *gomanippb.Operation_EdgeDetection:
+		return "edgeDetection"
+	case *gomanippb.Operation_Morphology:
+		return "morphology"
+	case *gomanippb.Operation_Reduction:
+		return "reduction"
+	case *gomanippb.Operation_Text:
+		return "text"
+	case *gomanippb.Operation_RandomFilter:
+		return "randomFilter"
+	case *gomanippb.Operation_Convolve:
+		return "convolve"
+	case *gomanippb.Operation_Stylize:
+		return "stylize"
+	case *gomanippb.Operation_Swirl:
+		return "swirl"
+	case *gomanippb.Operation_Bulge:
+		return "bulge"
+	case *gomanippb.Operation_Wave:
+		return "wave"
+	case *gomanippb.Operation_Fisheye:
+		return "fisheye"
+	case *gomanippb.Operation_Mirror:
+		return "mirror"
+	case *gomanippb.Operation_Kaleidoscope:
+		return "kaleidoscope"
+	case *gomanippb.Operation_GaussianNoise:
+		return "gaussianNoise"
+	case *gomanippb.Operation_SaltAndPepper:
+		return "saltAndPepper"
+	case *gomanippb.Operation_FilmGrain:
+		return "filmGrain"
+	case *gomanippb.Operation_Vignette:
+		return "vignette"
+	case *gomanippb.Operation_OldPhoto:
+		return "oldPhoto"
+	case *gomanippb.Operation_RemoveBackground:
+		return "removeBackground"
+	case *gomanippb.Operation_Shuffle:
+		return "shuffle"
+	default:
+		return ""
+	}
+}
+
+// valueOr returns the value of an optional field, or fallback when it is not set.
+func valueOr[T any](value *T, fallback T) T {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+// newStep builds the operation for a single step, unset optional parameters take the defaults of the HTTP API.
+func newStep(op *gomanippb.Operation) (func() jobs.Operation, error) {
+	switch op := op.GetOperation().(type) {
+	case *gomanippb.Operation_Invert:
+		return jobs.NewInvert, nil
+
+	case *gomanippb.Operation_Saturate:
+		saturation := op.Saturate.GetSaturation()
+		return func() jobs.Operation { return jobs.NewSaturate(saturation) }, nil
+
+	case *gomanippb.Operation_EdgeDetection:
+		lower, higher := op.EdgeDetection.GetLower(), op.EdgeDetection.GetHigher()
+		return func() jobs.Operation { return jobs.NewEdgeDetection(lower, higher) }, nil
+
+	case *gomanippb.Operation_Morphology:
+		params := op.Morphology
+		if params.GetType() == "" {
+			return nil, errors.New("morphology type is required")
+		}
+		return func() jobs.Operation {
+			return jobs.NewMorphology(int(params.GetKernelSize()), int(params.GetIterations()), jobs.Choice(params.GetType()))
+		}, nil
+
+	case *gomanippb.Operation_Reduction:
+		quality := op.Reduction.GetQuality()
+		return func() jobs.Operation { return jobs.NewReduce(quality) }, nil
+
+	case *gomanippb.Operation_Text:
+		params := op.Text
+		return func() jobs.Operation {
+			return jobs.NewAddText(params.GetText(), params.GetFontScale(), params.GetXPerc(), params.GetYPerc())
+		}, nil
+
+	case *gomanippb.Operation_RandomFilter:
+		params := op.RandomFilter
+		return func() jobs.Operation {
+			return jobs.NewRandomFilter(int(params.GetKernelSize()), int(params.GetMinVal()), int(params.GetMaxVal()), params.GetNormalize())
+		}, nil
+
+	case *gomanippb.Operation_Convolve:
+		kernels := make([]jobs.Kernel, len(op.Convolve.GetKernels()))
+		for idx, kernel := range op.Convolve.GetKernels() {
+			for _, row := range kernel.GetRows() {
+				kernels[idx] = append(kernels[idx], row.GetValues())
+			}
+			if err := jobs.ValidateKernel(kernels[idx]); err != nil {
+				return nil, err
+			}
+		}
+		preset := op.Convolve.GetPreset()
+		if len(kernels) == 0 && preset == "" {
+			return nil, errors.New("one of kernels or preset is required")
+		}
+		return func() jobs.Operation { return jobs.NewConvolve(kernels, preset) }, nil
+
+	case *gomanippb.Operation_Stylize:
+		style, intensity := jobs.Style(op.Stylize.GetStyle()), valueOr(op.Stylize.Intensity, 0.5)
+		// build one up front so an unknown style is reported before any image is processed
+		if _, err := jobs.NewStylization(style, intensity); err != nil {
+			return nil, err
+		}
+		return func() jobs.Operation {
+			stylization, _ := jobs.NewStylization(style, intensity)
+			return stylization
+		}, nil
+
+	case *gomanippb.Operation_Swirl:
+		params := op.Swirl
+		xPerc, yPerc := valueOr(params.XPerc, 0.5), valueOr(params.YPerc, 0.5)
+		radius, strength := valueOr(params.Radius, 0.5), valueOr(params.Strength, 4)
+		return func() jobs.Operation { return jobs.NewSwirl(xPerc, yPerc, radius, strength) }, nil
+
+	case *gomanippb.Operation_Bulge:
+		params := op.Bulge
+		xPerc, yPerc := valueOr(params.XPerc, 0.5), valueOr(params.YPerc, 0.5)
+		radius, strength := valueOr(params.Radius, 0.5), valueOr(params.Strength, 0.5)
+		return func() jobs.Operation { return jobs.NewBulge(xPerc, yPerc, radius, strength) }, nil
+
+	case *gomanippb.Operation_Wave:
+		amplitude, wavelength := valueOr(op.Wave.Amplitude, 10), valueOr(op.Wave.Wavelength, 60)
+		return func() jobs.Operation { return jobs.NewWave(amplitude, wavelength) }, nil
+
+	case *gomanippb.Operation_Fisheye:
+		params := op.Fisheye
+		xPerc, yPerc, strength := valueOr(params.XPerc, 0.5), valueOr(params.YPerc, 0.5), valueOr(params.Strength, 0.5)
+		return func() jobs.Operation { return jobs.NewFisheye(xPerc, yPerc, strength) }, nil
+
+	case *gomanippb.Operation_Mirror:
+		side := jobs.MirrorSide(op.Mirror.GetSide())
+		if side == "" {
+			return nil, errors.New("side is required")
+		}
+		return func() jobs.Operation { return jobs.NewMirror(side) }, nil
+
+	case *gomanippb.Operation_Kaleidoscope:
+		params := op.Kaleidoscope
+		segments := int(valueOr(params.Segments, 6))
+		xPerc, yPerc := valueOr(params.XPerc, 0.5), valueOr(params.YPerc, 0.5)
+		return func() jobs.Operation { return jobs.NewKaleidoscope(segments, xPerc, yPerc) }, nil
+
+	case *gomanippb.Operation_GaussianNoise:
+		sigma, seed := valueOr(op.GaussianNoise.Sigma, 20), op.GaussianNoise.GetSeed()
+		return func() jobs.Operation { return jobs.NewGaussianNoise(sigma, seed) }, nil
+
+	case *gomanippb.Operation_SaltAndPepper:
+		amount, seed := valueOr(op.SaltAndPepper.Amount, 0.05), op.SaltAndPepper.GetSeed()
+		return func() jobs.Operation { return jobs.NewSaltAndPepper(amount, seed) }, nil
+
+	case *gomanippb.Operation_FilmGrain:
+		strength, seed := valueOr(op.FilmGrain.Strength, 0.5), op.FilmGrain.GetSeed()
+		return func() jobs.Operation { return jobs.NewFilmGrain(strength, seed) }, nil
+
+	case *gomanippb.Operation_Vignette:
+		strength, radius := valueOr(op.Vignette.Strength, 0.6), valueOr(op.Vignette.Radius, jobs.DefaultVignetteRadius)
+		return func() jobs.Operation { return jobs.NewVignette(strength, radius) }, nil
+
+	case *gomanippb.Operation_OldPhoto:
+		params := op.OldPhoto
+		quality, grain, vignette := valueOr(params.Quality, 0.5), valueOr(params.Grain, 0.4), valueOr(params.Vignette, 0.6)
+		seed := params.GetSeed()
+		return func() jobs.Operation { return jobs.NewOldPhoto(quality, grain, vignette, seed) }, nil
+
+	case *gomanippb.Operation_RemoveBackground:
+		params := op.RemoveBackground
+		tolerance := int(valueOr(params.Tolerance, 30))
+		if params.GetColor() == "" {
+			return func() jobs.Operation { return jobs.NewColorKey(color.RGBA{}, true, tolerance) }, nil
+		}
+		key, err := util.ParseHexColor(params.GetColor())
+		if err != nil {
+			return nil, err
+		}
+		return func() jobs.Operation { return jobs.NewColorKey(key, false, tolerance) }, nil
+
+	case *gomanippb.Operation_Shuffle:
+		params := op.Shuffle
+		rows, cols, swaps := int(params.GetRows()), int(params.GetCols()), int(params.GetSwaps())
+		partitions := 0
+		if rows == 0 && cols == 0 {
+			if params.GetPartitions() == 0 {
+				return nil, errors.New("partitions, or rows and cols are required")
+			}
+			partitions = int(params.GetPartitions())
+		}
+		return func() jobs.Operation {
+			shuffle := jobs.NewGridShuffle(rows, cols, swaps, params.GetRotate(), params.GetFlip())
+			shuffle.Partitions = partitions
+			return shuffle
+		}, nil
+
+	default:
+		return nil, errors.New("operation is not set")
+	}
+}
+
+// newOperation builds the operation for the steps of a request, more than one step runs as a pipeline.
+// allowed decides which operations may be used, it returns why an operation may not. A new operation is built for
+// every image since operations may keep state from a run.
+func newOperation(steps []*gomanippb.Operation, allowed func(name string) error) (func() jobs.Operation, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("at least one operation is required")
+	}
+
+	factories := make([]func() jobs.Operation, len(steps))
+	for idx, step := range steps {
+		name := operationName(step)
+		if name != "" {
+			if err := allowed(name); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+
+		factory, err := newStep(step)
+		if err != nil {
+			if name == "" {
+				return nil, fmt.Errorf("operation %d: %w", idx+1, err)
+			}
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		factories[idx] = factory
+	}
+
+	if len(factories) == 1 {
+		return factories[0], nil
+	}
+
+	return func() jobs.Operation {
+		pipeline := make([]jobs.Operation, len(factories))
+		for idx, factory := range factories {
+			pipeline[idx] = factory()
+		}
+		return jobs.NewPipeline(pipeline...)
+	}, nil
+}
@@ -0,0 +1,366 @@
+// Package grpcapi serves the image operations over gRPC, next to the HTTP API and through the same job dispatcher.
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gocv.io/x/gocv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/jobs"
+	"goManip/proto/gomanippb"
+	"goManip/util"
+)
+
+const (
+	// ClientMetadata identifies who a call is made for like the X-Client-ID header, calls without it are grouped by their address
+	// and calls with an api key by their key.
+	ClientMetadata = "x-client-id"
+	// PriorityMetadata overrides the priority lane of the call's jobs like the X-Priority header.
+	PriorityMetadata = "x-priority"
+	// APIKeyMetadata carries the client's api key like the X-API-Key header, authorization: Bearer works as well.
+	APIKeyMetadata = "x-api-key"
+)
+
+// Server implements the GoManip service on top of the job dispatcher shared with the HTTP server.
+type Server struct {
+	gomanippb.UnimplementedGoManipServer
+	dispatcher *JobDispatch.JobDispatcher
+	// enabled reports whether the config serves an operation, by the name of its endpoint
+	enabled func(name string) bool
+	// keys are the api keys calls need, every call is let in when nil
+	keys *auth.Keys
+}
+
+func NewServer(dispatcher *JobDispatch.JobDispatcher, enabled func(name string) bool) *Server {
+	return &Server{dispatcher: dispatcher, enabled: enabled}
+}
+
+// WithKeys makes calls send one of keys and holds them to its limits like the HTTP server, nil lets every call in.
+func (s *Server) WithKeys(keys *auth.Keys) *Server {
+	s.keys = keys
+	return s
+}
+
+// Register adds the service to server.
+func (s *Server) Register(server *grpc.Server) {
+	gomanippb.RegisterGoManipServer(server, s)
+}
+
+// dispatcherFor returns the view of the dispatcher for the client and priority in the call's metadata,
+// the call's deadline is the timeout its jobs ask for. With keys the call is authenticated, its jobs are scheduled
+// for the client within its key and charged to the key, which is returned as well. Only keys allowing it may choose a priority.
+func (s *Server) dispatcherFor(ctx context.Context) (*JobDispatch.JobDispatcher, *auth.Key, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var key *auth.Key
+	if s.keys != nil {
+		var err error
+		if key, err = s.authenticate(md); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	client := firstValue(md, ClientMetadata)
+	if key != nil && client != "" {
+		client = key.Name + ":" + client
+	} else if key != nil {
+		client = key.Name
+	} else if client == "" {
+		client = JobDispatch.DefaultClient
+		if p, ok := peer.FromContext(ctx); ok {
+			client = p.Addr.String()
+			if host, _, err := net.SplitHostPort(client); err == nil {
+				client = host
+			}
+		}
+	}
+
+	var priority *jobs.Priority
+	if name := firstValue(md, PriorityMetadata); name != "" {
+		if key != nil && !key.AllowsPriority() {
+			return nil, nil, status.Error(codes.PermissionDenied, auth.ErrPriority.Error())
+		}
+		parsed, err := jobs.ParsePriority(name)
+		if err != nil {
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		priority = &parsed
+	}
+
+	dispatcher := s.dispatcher.ForClient(client, priority)
+	if deadline, ok := ctx.Deadline(); ok {
+		dispatcher = dispatcher.WithTimeout(time.Until(deadline))
+	}
+	if key != nil {
+		dispatcher = dispatcher.WithPixelQuota(key)
+	}
+	return dispatcher, key, nil
+}
+
+// authenticate returns the key of a call, it fails when the key is unknown or used up its rate limit or daily pixels.
+func (s *Server) authenticate(md metadata.MD) (*auth.Key, error) {
+	sent := firstValue(md, APIKeyMetadata)
+	if sent == "" {
+		if scheme, token, _ := strings.Cut(firstValue(md, "authorization"), " "); strings.EqualFold(scheme, "Bearer") {
+			sent = strings.TrimSpace(token)
+		}
+	}
+
+	key, err := s.keys.Lookup(sent)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if _, ok := key.Allow(); !ok {
+		return nil, status.Error(codes.ResourceExhausted, auth.ErrRateLimited.Error())
+	}
+	if key.Exhausted() {
+		return nil, status.Error(codes.ResourceExhausted, auth.ErrQuotaExceeded.Error())
+	}
+	return key, nil
+}
+
+// allowed returns what the operations of a call are checked with, the config has to serve them and the key to allow them.
+func (s *Server) allowed(key *auth.Key) func(name string) error {
+	return func(name string) error {
+		if !s.enabled(name) {
+			return errDisabled
+		}
+		if key != nil && !key.Allows(name) {
+			return auth.ErrForbidden
+		}
+		return nil
+	}
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// statusOf converts the error of a job into the status the call fails with.
+func statusOf(err error) error {
+	switch {
+	case errors.Is(err, JobDispatch.ErrTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, JobDispatch.ErrTooExpensive), errors.Is(err, auth.ErrQuotaExceeded):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, JobDispatch.ErrDispatcherClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, jobs.ErrInternal):
+		return status.Error(codes.Internal, err.Error())
+	case errors.Is(err, errDisabled):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, auth.ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+}
+
+// prepare builds the operation and decodes the image of a single image request, the image has to be released.
+func (s *Server) prepare(request *gomanippb.ProcessRequest, key *auth.Key) (jobs.Operation, *gocv.Mat, error) {
+	makeOperation, err := newOperation(request.GetOperations(), s.allowed(key))
+	if err != nil {
+		return nil, nil, statusOf(err)
+	}
+
+	image, err := util.DecodeImage(request.GetImage())
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, "failed to read image: "+err.Error())
+	}
+
+	return makeOperation(), image, nil
+}
+
+func (s *Server) Process(ctx context.Context, request *gomanippb.ProcessRequest) (*gomanippb.ProcessResponse, error) {
+	dispatcher, key, err := s.dispatcherFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, image, err := s.prepare(request, key)
+	if err != nil {
+		return nil, err
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	result, err := JobDispatch.EnqueueOperation(dispatcher, image, operation)
+	if err != nil {
+		log.Error().Err(err).Msg("Image processing failed")
+		return nil, statusOf(err)
+	}
+	defer result.Close()
+
+	// the buffer's bytes live in native memory that is freed on close, the response is sent after that
+	return &gomanippb.ProcessResponse{Image: bytes.Clone(result.GetBytes())}, nil
+}
+
+func (s *Server) Batch(stream grpc.ClientStreamingServer[gomanippb.BatchRequest, gomanippb.BatchResponse]) error {
+	dispatcher, key, err := s.dispatcherFor(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	var makeOperation func() jobs.Operation
+	var files []util.BatchFile
+	defer func() {
+		for _, file := range files {
+			JobDispatch.ReleaseImage(file.Image)
+		}
+	}()
+
+	for {
+		request, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if makeOperation == nil {
+			if makeOperation, err = newOperation(request.GetOperations(), s.allowed(key)); err != nil {
+				return statusOf(err)
+			}
+		}
+
+		if len(files) == util.MaxBatchImages {
+			return status.Errorf(codes.InvalidArgument, "batches hold at most %d images", util.MaxBatchImages)
+		}
+
+		file := util.BatchFile{Name: request.GetName()}
+		if file.Name == "" {
+			file.Name = strconv.Itoa(len(files) + 1)
+		}
+		file.Image, file.Error = util.DecodeImage(request.GetImage())
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return status.Error(codes.InvalidArgument, "the batch has no images")
+	}
+
+	// only the images that could be read are processed, the rest keep their read error
+	images := make([]*gocv.Mat, 0, len(files))
+	for _, file := range files {
+		if file.Error == nil {
+			images = append(images, file.Image)
+		}
+	}
+	results := JobDispatch.EnqueueBatch(dispatcher, images, makeOperation)
+
+	response := &gomanippb.BatchResponse{Results: make([]*gomanippb.BatchResult, len(files))}
+	processed := 0
+	for idx, file := range files {
+		result := &gomanippb.BatchResult{Name: file.Name}
+		response.Results[idx] = result
+
+		err := file.Error
+		if err == nil {
+			err = results[processed].Error
+			if err == nil {
+				result.Outcome = &gomanippb.BatchResult_Image{Image: results[processed].Image}
+			}
+			processed++
+		}
+		if err != nil {
+			result.Outcome = &gomanippb.BatchResult_Error{Error: err.Error()}
+		}
+	}
+
+	return stream.SendAndClose(response)
+}
+
+// eventStates maps the states reported by the dispatcher to the states of the api.
+var eventStates = map[JobDispatch.JobState]gomanippb.JobEvent_State{
+	JobDispatch.JobQueued:   gomanippb.JobEvent_QUEUED,
+	JobDispatch.JobStarted:  gomanippb.JobEvent_STARTED,
+	JobDispatch.JobProgress: gomanippb.JobEvent_PROGRESS,
+}
+
+// eventBuffer is how many events of a job may wait for a slow client before its progress is dropped.
+const eventBuffer = 16
+
+// toEvent converts an event reported by the dispatcher.
+func toEvent(event JobDispatch.JobEvent) *gomanippb.JobEvent {
+	converted := &gomanippb.JobEvent{JobId: event.JobId, State: eventStates[event.State]}
+	if progress := event.Progress; progress != nil {
+		converted.Progress = &gomanippb.Progress{Done: uint32(progress.Done), Total: uint32(progress.Total), Unit: progress.Unit}
+	}
+	return converted
+}
+
+func (s *Server) Submit(request *gomanippb.ProcessRequest, stream grpc.ServerStreamingServer[gomanippb.JobEvent]) error {
+	dispatcher, key, err := s.dispatcherFor(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	operation, image, err := s.prepare(request, key)
+	if err != nil {
+		return err
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	// a job is queued and started once, progress is reported from the worker and dropped rather than
+	// making it wait on a slow client
+	events := make(chan JobDispatch.JobEvent, eventBuffer)
+	dispatcher = dispatcher.WithEvents(func(event JobDispatch.JobEvent) {
+		if event.State != JobDispatch.JobProgress {
+			events <- event
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+
+	var result *gocv.NativeByteBuffer
+	var jobErr error
+	go func() {
+		defer close(events)
+		result, jobErr = JobDispatch.EnqueueOperation(dispatcher, image, operation)
+	}()
+
+	var jobId uint32
+	var sendErr error
+	for event := range events {
+		jobId = event.JobId
+		// keep draining once the client is gone, the job still has to finish
+		if sendErr == nil {
+			sendErr = stream.Send(toEvent(event))
+		}
+	}
+
+	if jobErr != nil {
+		log.Error().Err(jobErr).Uint32("job", jobId).Msg("Image processing failed")
+		if sendErr != nil {
+			return sendErr
+		}
+		return stream.Send(&gomanippb.JobEvent{JobId: jobId, State: gomanippb.JobEvent_FAILED, Error: jobErr.Error()})
+	}
+	defer result.Close()
+
+	if sendErr != nil {
+		return sendErr
+	}
+	return stream.Send(&gomanippb.JobEvent{JobId: jobId, State: gomanippb.JobEvent_DONE, Image: result.GetBytes()})
+}
@@ -0,0 +1,355 @@
+package grpcapi_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/grpcapi"
+	"goManip/jobs"
+	"goManip/proto/gomanippb"
+	"goManip/worker"
+)
+
+// newTestClient serves the api on an in memory connection with a single worker, everything is stopped when the test ends
+// and checked for leaks after that.
+func newTestClient(t *testing.T, enabled func(name string) bool, keys *auth.Keys) gomanippb.GoManipClient {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+
+	requests := make(chan *jobs.JobRequest)
+	dispatcher := JobDispatch.NewJobDispatcher(requests, 4, JobDispatch.Timeouts{
+		Default: JobDispatch.Timeout{Default: 5 * time.Second, Max: 10 * time.Second},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go worker.Worker(ctx, 0, requests, wg)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	grpcapi.NewServer(dispatcher, enabled).WithKeys(keys).Register(server)
+	go server.Serve(listener)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+		dispatcher.Close()
+		cancel()
+		wg.Wait()
+	})
+
+	return gomanippb.NewGoManipClient(conn)
+}
+
+func allEnabled(string) bool {
+	return true
+}
+
+func encodedTestImage(t *testing.T, rows, cols int) []byte {
+	image := gocv.NewMatWithSize(rows, cols, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoded.Close()
+	return append([]byte(nil), encoded.GetBytes()...)
+}
+
+func decodedSize(t *testing.T, data []byte) (int, int) {
+	image, err := gocv.IMDecode(data, gocv.IMReadUnchanged)
+	assert.NoError(t, err)
+	defer image.Close()
+	return image.Rows(), image.Cols()
+}
+
+var invert = &gomanippb.Operation{Operation: &gomanippb.Operation_Invert{Invert: &gomanippb.Invert{}}}
+
+func TestProcess(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	swirl := &gomanippb.Operation{Operation: &gomanippb.Operation_Swirl{Swirl: &gomanippb.Swirl{}}}
+	shuffle := &gomanippb.Operation{Operation: &gomanippb.Operation_Shuffle{Shuffle: &gomanippb.Shuffle{Rows: 2, Cols: 3, Swaps: 2}}}
+	response, err := client.Process(context.Background(), &gomanippb.ProcessRequest{
+		Image:      encodedTestImage(t, 40, 60),
+		Operations: []*gomanippb.Operation{invert, swirl, shuffle},
+	})
+	assert.NoError(t, err)
+
+	rows, cols := decodedSize(t, response.GetImage())
+	assert.Equal(t, 40, rows)
+	assert.Equal(t, 60, cols)
+}
+
+func TestProcessErrors(t *testing.T) {
+	client := newTestClient(t, func(name string) bool { return name != "mirror" }, nil)
+	image := encodedTestImage(t, 20, 20)
+
+	tests := []struct {
+		name     string
+		request  *gomanippb.ProcessRequest
+		metadata metadata.MD
+		wantCode codes.Code
+	}{
+		{
+			name:     "No operations",
+			request:  &gomanippb.ProcessRequest{Image: image},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Empty operation",
+			request:  &gomanippb.ProcessRequest{Image: image, Operations: []*gomanippb.Operation{{}}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Disabled operation",
+			request: &gomanippb.ProcessRequest{Image: image, Operations: []*gomanippb.Operation{
+				{Operation: &gomanippb.Operation_Mirror{Mirror: &gomanippb.Mirror{Side: "left"}}},
+			}},
+			wantCode: codes.Unimplemented,
+		},
+		{
+			name: "Unknown style",
+			request: &gomanippb.ProcessRequest{Image: image, Operations: []*gomanippb.Operation{
+				{Operation: &gomanippb.Operation_Stylize{Stylize: &gomanippb.Stylize{Style: "cubism"}}},
+			}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "Shuffle without a grid",
+			request: &gomanippb.ProcessRequest{Image: image, Operations: []*gomanippb.Operation{
+				{Operation: &gomanippb.Operation_Shuffle{Shuffle: &gomanippb.Shuffle{}}},
+			}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Invalid image",
+			request:  &gomanippb.ProcessRequest{Image: []byte("not an image"), Operations: []*gomanippb.Operation{invert}},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Invalid priority",
+			request:  &gomanippb.ProcessRequest{Image: image, Operations: []*gomanippb.Operation{invert}},
+			metadata: metadata.Pairs(grpcapi.PriorityMetadata, "urgent"),
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), tt.metadata)
+			_, err := client.Process(ctx, tt.request)
+			assert.Equal(t, tt.wantCode, status.Code(err), err)
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	stream, err := client.Batch(context.Background())
+	assert.NoError(t, err)
+
+	// only the first message names the operations
+	requests := []*gomanippb.BatchRequest{
+		{Operations: []*gomanippb.Operation{invert}, Name: "first.png", Image: encodedTestImage(t, 10, 20)},
+		{Name: "broken.png", Image: []byte("not an image")},
+		{Image: encodedTestImage(t, 30, 40)},
+	}
+	for _, request := range requests {
+		assert.NoError(t, stream.Send(request))
+	}
+
+	response, err := stream.CloseAndRecv()
+	assert.NoError(t, err)
+
+	results := response.GetResults()
+	assert.Len(t, results, 3)
+	assert.Equal(t, "first.png", results[0].GetName())
+	rows, cols := decodedSize(t, results[0].GetImage())
+	assert.Equal(t, 10, rows)
+	assert.Equal(t, 20, cols)
+
+	assert.Equal(t, "broken.png", results[1].GetName())
+	assert.NotEmpty(t, results[1].GetError())
+	assert.Nil(t, results[1].GetImage())
+
+	// unnamed images are named by their position
+	assert.Equal(t, "3", results[2].GetName())
+	assert.NotNil(t, results[2].GetImage())
+}
+
+func TestBatchWithoutOperations(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	stream, err := client.Batch(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&gomanippb.BatchRequest{Image: encodedTestImage(t, 10, 10)}))
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSubmit(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	stream, err := client.Submit(context.Background(), &gomanippb.ProcessRequest{
+		Image:      encodedTestImage(t, 40, 60),
+		Operations: []*gomanippb.Operation{invert},
+	})
+	assert.NoError(t, err)
+
+	var events []*gomanippb.JobEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		events = append(events, event)
+	}
+
+	states := make([]gomanippb.JobEvent_State, len(events))
+	for idx, event := range events {
+		states[idx] = event.GetState()
+		assert.Equal(t, events[0].GetJobId(), event.GetJobId())
+	}
+	assert.Equal(t, []gomanippb.JobEvent_State{gomanippb.JobEvent_QUEUED, gomanippb.JobEvent_STARTED, gomanippb.JobEvent_DONE}, states)
+
+	rows, cols := decodedSize(t, events[len(events)-1].GetImage())
+	assert.Equal(t, 40, rows)
+	assert.Equal(t, 60, cols)
+}
+
+func TestSubmitProgress(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	mirror := &gomanippb.Operation{Operation: &gomanippb.Operation_Mirror{Mirror: &gomanippb.Mirror{Side: "left"}}}
+	stream, err := client.Submit(context.Background(), &gomanippb.ProcessRequest{
+		Image:      encodedTestImage(t, 20, 20),
+		Operations: []*gomanippb.Operation{invert, mirror},
+	})
+	assert.NoError(t, err)
+
+	var progress []*gomanippb.Progress
+	var last *gomanippb.JobEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if event.GetState() == gomanippb.JobEvent_PROGRESS {
+			progress = append(progress, event.GetProgress())
+		}
+		last = event
+	}
+
+	// the operations run as a pipeline reporting every step
+	assert.Len(t, progress, 2)
+	for idx, step := range progress {
+		assert.Equal(t, uint32(idx+1), step.GetDone())
+		assert.Equal(t, uint32(2), step.GetTotal())
+		assert.Equal(t, jobs.UnitStep, step.GetUnit())
+	}
+	assert.Equal(t, gomanippb.JobEvent_DONE, last.GetState())
+}
+
+func TestSubmitFailure(t *testing.T) {
+	client := newTestClient(t, allEnabled, nil)
+
+	// a negative saturation is only rejected by the operation itself
+	saturate := &gomanippb.Operation{Operation: &gomanippb.Operation_Saturate{Saturate: &gomanippb.Saturate{Saturation: -1}}}
+	stream, err := client.Submit(context.Background(), &gomanippb.ProcessRequest{
+		Image:      encodedTestImage(t, 20, 20),
+		Operations: []*gomanippb.Operation{saturate},
+	})
+	assert.NoError(t, err)
+
+	var last *gomanippb.JobEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		last = event
+	}
+
+	assert.Equal(t, gomanippb.JobEvent_FAILED, last.GetState())
+	assert.NotEmpty(t, last.GetError())
+}
+
+func TestAuthentication(t *testing.T) {
+	keys := auth.NewKeys([]auth.KeyConfig{
+		{Name: "bot", Hash: auth.Hash("bot-key"), DailyPixels: 1000, Operations: []string{"invert"}},
+		{Name: "slow", Hash: auth.Hash("slow-key"), RateLimit: 0.01},
+		{Name: "plain", Hash: auth.Hash("plain-key")},
+		{Name: "admin", Hash: auth.Hash("admin-key"), AllowPriority: true},
+	}, time.Now)
+	client := newTestClient(t, allEnabled, keys)
+
+	mirror := &gomanippb.Operation{Operation: &gomanippb.Operation_Mirror{Mirror: &gomanippb.Mirror{Side: "left"}}}
+	process := func(md metadata.MD, operation *gomanippb.Operation) error {
+		ctx := metadata.NewOutgoingContext(context.Background(), md)
+		_, err := client.Process(ctx, &gomanippb.ProcessRequest{
+			Image:      encodedTestImage(t, 20, 20),
+			Operations: []*gomanippb.Operation{operation},
+		})
+		return err
+	}
+
+	tests := []struct {
+		name     string
+		metadata metadata.MD
+		op       *gomanippb.Operation
+		wantCode codes.Code
+	}{
+		{name: "No key", op: invert, wantCode: codes.Unauthenticated},
+		{name: "Unknown key", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "guess"), op: invert, wantCode: codes.Unauthenticated},
+		{name: "Key", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "bot-key"), op: invert, wantCode: codes.OK},
+		{name: "Bearer token", metadata: metadata.Pairs("authorization", "Bearer bot-key"), op: invert, wantCode: codes.OK},
+		{name: "Operation the key does not allow", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "bot-key"), op: mirror, wantCode: codes.PermissionDenied},
+		// the key's daily pixels fit two of the images
+		{name: "Quota exceeded", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "bot-key"), op: invert, wantCode: codes.ResourceExhausted},
+		{name: "First request of a rate limited key", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "slow-key"), op: mirror, wantCode: codes.OK},
+		{name: "Rate limit exceeded", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "slow-key"), op: mirror, wantCode: codes.ResourceExhausted},
+		{name: "Priority the key does not allow", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "plain-key", grpcapi.PriorityMetadata, "high"), op: invert, wantCode: codes.PermissionDenied},
+		{name: "Priority the key allows", metadata: metadata.Pairs(grpcapi.APIKeyMetadata, "admin-key", grpcapi.PriorityMetadata, "high"), op: invert, wantCode: codes.OK},
+	}
+
+	// the cases use up the keys' limits in order
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := process(tt.metadata, tt.op)
+			assert.Equal(t, tt.wantCode, status.Code(err), err)
+		})
+	}
+}
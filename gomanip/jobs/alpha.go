@@ -0,0 +1,177 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image/color"
+)
+
+// AlphaBehaviour describes what an operation does with the alpha channel of a 4 channel image.
+// Images with 1 or 3 channels have no alpha channel and are unaffected by it.
+type AlphaBehaviour int
+
+const (
+	// AlphaPreserve leaves the alpha channel exactly as it was, only the color channels are changed.
+	AlphaPreserve AlphaBehaviour = iota
+	// AlphaTransform moves the alpha channel along with the pixels, i.e for distortions, resizing and shuffling.
+	AlphaTransform
+	// AlphaReplace writes a new alpha channel, i.e drawn text is opaque and keyed out backgrounds are transparent.
+	AlphaReplace
+	// AlphaDiscard returns an image without an alpha channel, or no image at all for operations that only report data.
+	AlphaDiscard
+)
+
+func (a AlphaBehaviour) String() string {
+	switch a {
+	case AlphaPreserve:
+		return "preserve"
+	case AlphaTransform:
+		return "transform"
+	case AlphaReplace:
+		return "replace"
+	case AlphaDiscard:
+		return "discard"
+	}
+	return fmt.Sprintf("AlphaBehaviour(%d)", int(a))
+}
+
+// AlphaAware is implemented by operations that define their alpha behaviour.
+type AlphaAware interface {
+	Alpha() AlphaBehaviour
+}
+
+// AlphaOf returns the alpha behaviour of op, operations that do not define one are expected to preserve alpha.
+func AlphaOf(op Operation) AlphaBehaviour {
+	if aware, ok := op.(AlphaAware); ok {
+		return aware.Alpha()
+	}
+	return AlphaPreserve
+}
+
+// preserveAlpha runs op on the color channels of a 4 channel image and puts the original alpha channel back
+// onto its result, which must have the same size. Other images are passed to op as they are.
+func preserveAlpha(input *gocv.Mat, op func(color *gocv.Mat) (*gocv.Mat, error)) (*gocv.Mat, error) {
+
+	if input == nil || input.Channels() != 4 {
+		return op(input)
+	}
+
+	bgr, err := convertChannels(*input, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	alpha := gocv.NewMat()
+	defer alpha.Close()
+	if err := gocv.ExtractChannel(*input, &alpha, 3); err != nil {
+		return nil, err
+	}
+
+	result, err := op(&bgr)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	if result.Rows() != alpha.Rows() || result.Cols() != alpha.Cols() {
+		return nil, fmt.Errorf("expected a %d by %d result to put the alpha channel back onto, got %d by %d", alpha.Cols(), alpha.Rows(), result.Cols(), result.Rows())
+	}
+
+	return withAlpha(*result, alpha)
+}
+
+// withAlpha returns a 4 channel copy of colors (1 or 3 channels) using alpha as its alpha channel.
+func withAlpha(colors gocv.Mat, alpha gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := convertChannels(colors, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	channels := gocv.Split(bgr)
+	defer func() {
+		for _, channel := range channels {
+			channel.Close()
+		}
+	}()
+
+	merged := gocv.NewMat()
+	if err := gocv.Merge(append(channels, alpha), &merged); err != nil {
+		merged.Close()
+		return nil, err
+	}
+
+	return &merged, nil
+}
+
+// ColorKey removes a solid background by making every pixel within Tolerance of Key transparent.
+// Tolerance is the largest difference allowed in each color channel (0 to 255). With Auto set the key is
+// taken from the top left pixel instead. The result always has 4 channels.
+type ColorKey struct {
+	Key       color.RGBA
+	Auto      bool
+	Tolerance int
+}
+
+func (k *ColorKey) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	if gocv.MatType(input.Type()&7) != gocv.MatTypeCV8U {
+		return nil, errors.New("expected an 8 bit image")
+	}
+
+	if k.Tolerance < 0 || k.Tolerance > 255 {
+		return nil, fmt.Errorf("expected tolerance to be between 0 and 255, got %d", k.Tolerance)
+	}
+
+	bgr, err := convertChannels(*input, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	key := k.Key
+	if k.Auto {
+		corner := bgr.GetVecbAt(0, 0)
+		key = color.RGBA{R: corner[2], G: corner[1], B: corner[0], A: 255}
+	}
+
+	tolerance := float64(k.Tolerance)
+	lower := gocv.NewScalar(float64(key.B)-tolerance, float64(key.G)-tolerance, float64(key.R)-tolerance, 0)
+	upper := gocv.NewScalar(float64(key.B)+tolerance, float64(key.G)+tolerance, float64(key.R)+tolerance, 0)
+
+	background := gocv.NewMat()
+	defer background.Close()
+	if err := gocv.InRangeWithScalar(bgr, lower, upper, &background); err != nil {
+		return nil, err
+	}
+
+	// images that already have an alpha channel keep their transparent pixels
+	alpha := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 0, 0, 0), input.Rows(), input.Cols(), gocv.MatTypeCV8UC1)
+	defer alpha.Close()
+	if input.Channels() == 4 {
+		if err := gocv.ExtractChannel(*input, &alpha, 3); err != nil {
+			return nil, err
+		}
+	}
+
+	foreground := gocv.NewMat()
+	defer foreground.Close()
+	if err := gocv.BitwiseNot(background, &foreground); err != nil {
+		return nil, err
+	}
+
+	if err := gocv.BitwiseAnd(alpha, foreground, &alpha); err != nil {
+		return nil, err
+	}
+
+	return withAlpha(bgr, alpha)
+}
+
+func (_ *ColorKey) Alpha() AlphaBehaviour { return AlphaReplace }
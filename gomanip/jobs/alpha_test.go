@@ -0,0 +1,219 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// transparentImage returns a 4 channel gradient whose alpha is 200, with a fully transparent square in the top left.
+func transparentImage(width, height int) gocv.Mat {
+
+	bgr := gradientImage(width, height)
+	defer bgr.Close()
+
+	bgra := gocv.NewMat()
+	gocv.CvtColor(bgr, &bgra, gocv.ColorBGRToBGRA)
+
+	alpha := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(200, 0, 0, 0), height, width, gocv.MatTypeCV8UC1)
+	defer alpha.Close()
+	gocv.Rectangle(&alpha, image.Rect(0, 0, width/4, height/4), color.RGBA{}, -1)
+
+	channels := gocv.Split(bgra)
+	channels[3].Close()
+	channels[3] = alpha.Clone()
+	gocv.Merge(channels, &bgra)
+	for _, channel := range channels {
+		channel.Close()
+	}
+
+	return bgra
+}
+
+// alphaOf extracts the alpha channel of a 4 channel image.
+func alphaOf(t *testing.T, mat *gocv.Mat) gocv.Mat {
+	t.Helper()
+
+	alpha := gocv.NewMat()
+	assert.NoError(t, gocv.ExtractChannel(*mat, &alpha, 3))
+	return alpha
+}
+
+func TestOperationsAlpha(t *testing.T) {
+
+	other := gradientImage(64, 64)
+	defer other.Close()
+
+	tests := []struct {
+		name  string
+		op    jobs.Operation
+		alpha jobs.AlphaBehaviour
+	}{
+		{name: "invert", op: jobs.NewInvert(), alpha: jobs.AlphaPreserve},
+		{name: "saturate", op: jobs.NewSaturate(1.5), alpha: jobs.AlphaPreserve},
+		{name: "edge detection", op: jobs.NewEdgeDetection(100, 200), alpha: jobs.AlphaPreserve},
+		{name: "morphology", op: jobs.NewMorphology(3, 1, jobs.Dilate), alpha: jobs.AlphaTransform},
+		{name: "reduce", op: jobs.NewReduce(0.5), alpha: jobs.AlphaTransform},
+		{name: "add text", op: jobs.NewAddText("alpha", 1, 0.2, 0.5), alpha: jobs.AlphaReplace},
+		{name: "random filter", op: jobs.NewRandomFilter(3, -1, 1, true), alpha: jobs.AlphaPreserve},
+		{name: "convolve", op: &jobs.Convolve{Preset: "sharpen"}, alpha: jobs.AlphaPreserve},
+		{name: "shuffle", op: &jobs.Shuffle{Partitions: 4}, alpha: jobs.AlphaTransform},
+		{name: "cartoon", op: &jobs.Cartoon{Intensity: 0.5}, alpha: jobs.AlphaPreserve},
+		{name: "pencil sketch", op: &jobs.PencilSketch{Intensity: 0.5}, alpha: jobs.AlphaPreserve},
+		{name: "oil paint", op: &jobs.OilPaint{Intensity: 0.5}, alpha: jobs.AlphaPreserve},
+		{name: "emboss", op: &jobs.Emboss{Intensity: 0.5}, alpha: jobs.AlphaPreserve},
+		{name: "comic", op: &jobs.Comic{Intensity: 0.5}, alpha: jobs.AlphaPreserve},
+		{name: "swirl", op: jobs.NewSwirl(0.5, 0.5, 0.5, 4), alpha: jobs.AlphaTransform},
+		{name: "bulge", op: jobs.NewBulge(0.5, 0.5, 0.5, 0.5), alpha: jobs.AlphaTransform},
+		{name: "wave", op: jobs.NewWave(4, 20), alpha: jobs.AlphaTransform},
+		{name: "fisheye", op: jobs.NewFisheye(0.5, 0.5, 0.5), alpha: jobs.AlphaTransform},
+		{name: "mirror", op: jobs.NewMirror(jobs.MirrorBottomRight), alpha: jobs.AlphaTransform},
+		{name: "kaleidoscope", op: jobs.NewKaleidoscope(6, 0.5, 0.5), alpha: jobs.AlphaTransform},
+		{name: "gaussian noise", op: jobs.NewGaussianNoise(20, 1), alpha: jobs.AlphaPreserve},
+		{name: "salt and pepper", op: jobs.NewSaltAndPepper(0.1, 1), alpha: jobs.AlphaPreserve},
+		{name: "film grain", op: jobs.NewFilmGrain(0.5, 1), alpha: jobs.AlphaPreserve},
+		{name: "vignette", op: jobs.NewVignette(0.6, 0.4), alpha: jobs.AlphaPreserve},
+		{name: "pipeline", op: jobs.NewPipeline(jobs.NewInvert(), jobs.NewSwirl(0.5, 0.5, 0.5, 4)), alpha: jobs.AlphaTransform},
+		{name: "color key", op: jobs.NewColorKey(color.RGBA{}, true, 10), alpha: jobs.AlphaReplace},
+		{name: "analyze", op: jobs.NewAnalyze(3, 16), alpha: jobs.AlphaDiscard},
+		{name: "ascii art", op: jobs.NewAsciiArt(20, jobs.DefaultRamp, false), alpha: jobs.AlphaDiscard},
+		{name: "qr decode", op: jobs.NewQRDecode(), alpha: jobs.AlphaDiscard},
+		{name: "compare", op: jobs.NewCompare(&other, true), alpha: jobs.AlphaDiscard},
+	}
+
+	transparent := transparentImage(64, 64)
+	defer transparent.Close()
+
+	inputAlpha := alphaOf(t, &transparent)
+	defer inputAlpha.Close()
+
+	inputs := map[int]*gocv.Mat{4: &transparent}
+	for _, channels := range []int{1, 3} {
+		converted := gocv.NewMat()
+		defer converted.Close()
+		code := gocv.ColorBGRAToGray
+		if channels == 3 {
+			code = gocv.ColorBGRAToBGR
+		}
+		gocv.CvtColor(transparent, &converted, code)
+		inputs[channels] = &converted
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.alpha, jobs.AlphaOf(tt.op))
+
+			for _, channels := range []int{1, 3, 4} {
+				// some operations draw onto their input, so every run gets its own copy
+				input := inputs[channels].Clone()
+
+				result, err := tt.op.Run(&input)
+				assert.NoError(t, err, "%d channels", channels)
+
+				if result == nil {
+					assert.Equal(t, jobs.AlphaDiscard, tt.alpha, "%d channels", channels)
+					input.Close()
+					continue
+				}
+
+				assert.Equal(t, input.Rows(), result.Rows())
+				assert.Equal(t, input.Cols(), result.Cols())
+
+				if channels == 4 {
+					switch tt.alpha {
+					case jobs.AlphaPreserve:
+						assert.Equal(t, 4, result.Channels())
+						resultAlpha := alphaOf(t, result)
+						assert.True(t, identical(t, &inputAlpha, &resultAlpha))
+						resultAlpha.Close()
+					case jobs.AlphaTransform:
+						// alpha moves with the pixels, but is never made more opaque than it was
+						assert.Equal(t, 4, result.Channels())
+						resultAlpha := alphaOf(t, result)
+						_, maxVal, _, _ := gocv.MinMaxLoc(resultAlpha)
+						assert.LessOrEqual(t, maxVal, float32(200))
+						resultAlpha.Close()
+					case jobs.AlphaReplace:
+						assert.Equal(t, 4, result.Channels())
+					case jobs.AlphaDiscard:
+						assert.LessOrEqual(t, result.Channels(), 3)
+					}
+				} else if tt.alpha != jobs.AlphaReplace {
+					// only operations writing their own alpha channel may add one
+					assert.LessOrEqual(t, result.Channels(), 3, "%d channels", channels)
+				}
+
+				if result != &input {
+					result.Close()
+				}
+				input.Close()
+			}
+		})
+	}
+}
+
+func TestColorKey(t *testing.T) {
+
+	green := color.RGBA{G: 255, A: 255}
+
+	background := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 255, 0, 0), 40, 40, gocv.MatTypeCV8UC3)
+	defer background.Close()
+	gocv.Rectangle(&background, image.Rect(10, 10, 30, 30), color.RGBA{R: 200, G: 30, B: 30, A: 255}, -1)
+	// a slightly different green is still keyed out within the tolerance
+	gocv.Rectangle(&background, image.Rect(0, 35, 40, 40), color.RGBA{R: 10, G: 240, B: 5, A: 255}, -1)
+
+	for _, op := range []jobs.Operation{jobs.NewColorKey(green, false, 20), jobs.NewColorKey(color.RGBA{}, true, 20)} {
+		result, err := op.Run(&background)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, result.Channels())
+
+		alpha := alphaOf(t, result)
+		assert.Equal(t, uint8(0), alpha.GetUCharAt(0, 0))
+		assert.Equal(t, uint8(0), alpha.GetUCharAt(38, 20))
+		assert.Equal(t, uint8(255), alpha.GetUCharAt(20, 20))
+		assert.Equal(t, 20*20, gocv.CountNonZero(alpha))
+
+		alpha.Close()
+		result.Close()
+	}
+
+	// pixels that were already transparent stay transparent
+	transparent := transparentImage(64, 64)
+	defer transparent.Close()
+
+	result, err := jobs.NewColorKey(color.RGBA{R: 1, G: 2, B: 3, A: 255}, false, 0).Run(&transparent)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	resultAlpha := alphaOf(t, result)
+	defer resultAlpha.Close()
+	inputAlpha := alphaOf(t, &transparent)
+	defer inputAlpha.Close()
+	assert.True(t, identical(t, &inputAlpha, &resultAlpha))
+
+	_, err = jobs.NewColorKey(green, false, -1).Run(&background)
+	assert.Error(t, err)
+
+	_, err = jobs.NewColorKey(green, false, 20).Run(nil)
+	assert.Error(t, err)
+}
+
+func TestInvertKeepsTransparency(t *testing.T) {
+
+	transparent := transparentImage(32, 32)
+	defer transparent.Close()
+
+	result, err := jobs.NewInvert().Run(&transparent)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	pixel := transparent.GetVecbAt(20, 20)
+	inverted := result.GetVecbAt(20, 20)
+	assert.Equal(t, 255-pixel[0], inverted[0])
+	assert.Equal(t, 255-pixel[2], inverted[2])
+	assert.Equal(t, uint8(200), inverted[3])
+	assert.Equal(t, uint8(0), result.GetVecbAt(0, 0)[3])
+}
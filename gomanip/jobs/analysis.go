@@ -0,0 +1,252 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"sort"
+	"strings"
+)
+
+const (
+	maxDominantColors = 16
+	// images are shrunk to at most this many pixels per side before clustering colors
+	dominantColorSampleSize = 100
+)
+
+var channelNames = map[int][]string{
+	1: {"gray"},
+	3: {"blue", "green", "red"},
+	4: {"blue", "green", "red", "alpha"},
+}
+
+type ChannelHistogram struct {
+	Channel string `json:"channel"`
+	Bins    []int  `json:"bins"`
+}
+
+// DominantColor is one of the main colors of an image, Share is the fraction of pixels closest to it.
+type DominantColor struct {
+	Hex   string  `json:"hex"`
+	R     uint8   `json:"r"`
+	G     uint8   `json:"g"`
+	B     uint8   `json:"b"`
+	Share float64 `json:"share"`
+}
+
+type Analysis struct {
+	Width          int                `json:"width"`
+	Height         int                `json:"height"`
+	Channels       int                `json:"channels"`
+	Depth          string             `json:"depth"`
+	Histograms     []ChannelHistogram `json:"histograms"`
+	DominantColors []DominantColor    `json:"dominantColors"`
+	// Brightness is the mean gray level between 0 and 255.
+	Brightness float64 `json:"brightness"`
+	// LaplacianVariance measures sharpness, the lower it is the blurrier the image.
+	LaplacianVariance float64     `json:"laplacianVariance"`
+	Hashes            ImageHashes `json:"hashes"`
+}
+
+// Analyze gathers statistics about an image. Run only fills in Result and returns a nil image.
+type Analyze struct {
+	Colors int
+	Bins   int
+
+	// Result is filled in by Run.
+	Result Analysis
+}
+
+func (a *Analyze) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil {
+		return nil, errors.New("input image is empty")
+	}
+
+	if a.Colors <= 0 || a.Colors > maxDominantColors {
+		return nil, fmt.Errorf("expected colors to be between 1 and %d, got %d", maxDominantColors, a.Colors)
+	}
+
+	if a.Bins <= 0 || a.Bins > 256 {
+		return nil, fmt.Errorf("expected bins to be between 1 and 256, got %d", a.Bins)
+	}
+
+	names, ok := channelNames[input.Channels()]
+	if !ok {
+		return nil, fmt.Errorf("cannot analyze a %d channel image", input.Channels())
+	}
+
+	a.Result = Analysis{
+		Width:    input.Cols(),
+		Height:   input.Rows(),
+		Channels: input.Channels(),
+		Depth:    strings.TrimPrefix(gocv.MatType(input.Type()&7).String(), "CV"),
+	}
+
+	histograms, err := histograms(*input, a.Bins, names)
+	if err != nil {
+		return nil, err
+	}
+	a.Result.Histograms = histograms
+
+	colors, err := dominantColors(*input, a.Colors)
+	if err != nil {
+		return nil, err
+	}
+	a.Result.DominantColors = colors
+
+	gray, err := convertChannels(*input, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	a.Result.Brightness = gray.Mean().Val1
+
+	laplacianVariance, err := laplacianVariance(gray)
+	if err != nil {
+		return nil, err
+	}
+	a.Result.LaplacianVariance = laplacianVariance
+
+	hashes, err := computeHashes(*input)
+	if err != nil {
+		return nil, err
+	}
+	a.Result.Hashes = hashes
+
+	return nil, nil
+}
+
+func (_ *Analyze) Alpha() AlphaBehaviour { return AlphaDiscard }
+
+func (_ *Analyze) Priority() Priority { return PriorityLow }
+
+// histograms counts the values of each channel in bins evenly covering 0 to 256.
+func histograms(input gocv.Mat, bins int, names []string) ([]ChannelHistogram, error) {
+
+	mask := gocv.NewMat()
+	defer mask.Close()
+
+	result := make([]ChannelHistogram, len(names))
+
+	for channel, name := range names {
+		hist := gocv.NewMat()
+
+		err := gocv.CalcHist([]gocv.Mat{input}, []int{channel}, mask, &hist, []int{bins}, []float64{0, 256}, false)
+		if err != nil {
+			hist.Close()
+			return nil, err
+		}
+
+		counts := make([]int, bins)
+		for bin := range counts {
+			counts[bin] = int(hist.GetFloatAt(bin, 0))
+		}
+		hist.Close()
+
+		result[channel] = ChannelHistogram{Channel: name, Bins: counts}
+	}
+
+	return result, nil
+}
+
+// dominantColors clusters the colors of a downscaled copy of the input with k-means,
+// returning the cluster centers ordered by how many pixels belong to them.
+func dominantColors(input gocv.Mat, k int) ([]DominantColor, error) {
+
+	bgr, err := convertChannels(input, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	scale := 1.0
+	if longest := max(bgr.Rows(), bgr.Cols()); longest > dominantColorSampleSize {
+		scale = float64(dominantColorSampleSize) / float64(longest)
+	}
+	sample := gocv.NewMat()
+	defer sample.Close()
+	if err := gocv.Resize(bgr, &sample, image.Point{}, scale, scale, gocv.InterpolationArea); err != nil {
+		return nil, err
+	}
+
+	pixels := sample.Reshape(1, sample.Rows()*sample.Cols())
+	defer pixels.Close()
+
+	data := gocv.NewMat()
+	defer data.Close()
+	if err := pixels.ConvertTo(&data, gocv.MatTypeCV32F); err != nil {
+		return nil, err
+	}
+
+	// there cannot be more clusters than pixels
+	k = min(k, data.Rows())
+
+	labels := gocv.NewMat()
+	defer labels.Close()
+	centers := gocv.NewMat()
+	defer centers.Close()
+
+	criteria := gocv.NewTermCriteria(gocv.Count+gocv.EPS, 20, 1.0)
+	attempts := 3
+	gocv.KMeans(data, k, &labels, criteria, attempts, gocv.KMeansPPCenters, &centers)
+
+	counts := make([]int, k)
+	for row := range labels.Rows() {
+		counts[labels.GetIntAt(row, 0)]++
+	}
+
+	colors := make([]DominantColor, k)
+	for cluster := range colors {
+		b := clampUint8(centers.GetFloatAt(cluster, 0))
+		g := clampUint8(centers.GetFloatAt(cluster, 1))
+		r := clampUint8(centers.GetFloatAt(cluster, 2))
+		colors[cluster] = DominantColor{
+			Hex:   fmt.Sprintf("#%02x%02x%02x", r, g, b),
+			R:     r,
+			G:     g,
+			B:     b,
+			Share: float64(counts[cluster]) / float64(labels.Rows()),
+		}
+	}
+
+	sort.SliceStable(colors, func(i, j int) bool { return colors[i].Share > colors[j].Share })
+
+	return colors, nil
+}
+
+// laplacianVariance is the variance of the Laplacian of a gray image, sharp edges give a high variance.
+func laplacianVariance(gray gocv.Mat) (float64, error) {
+
+	laplacian := gocv.NewMat()
+	defer laplacian.Close()
+
+	if err := gocv.Laplacian(gray, &laplacian, gocv.MatTypeCV64F, 1, 1, 0, gocv.BorderDefault); err != nil {
+		return 0, err
+	}
+
+	mean := gocv.NewMat()
+	defer mean.Close()
+	stdDev := gocv.NewMat()
+	defer stdDev.Close()
+
+	if err := gocv.MeanStdDev(laplacian, &mean, &stdDev); err != nil {
+		return 0, err
+	}
+
+	deviation := stdDev.GetDoubleAt(0, 0)
+
+	return deviation * deviation, nil
+}
+
+func clampUint8(value float32) uint8 {
+	switch {
+	case value <= 0:
+		return 0
+	case value >= 255:
+		return 255
+	}
+	return uint8(value + 0.5)
+}
@@ -0,0 +1,107 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.Analyze
+	}{
+		{
+			name:      "test with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Analyze{Colors: 5, Bins: 32},
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.Analyze{Colors: 5, Bins: 32},
+		},
+		{
+			name:      "Handle too many colors",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Analyze{Colors: 100, Bins: 32},
+		},
+		{
+			name:      "Handle invalid bins",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Analyze{Colors: 5, Bins: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+				op := tt.op
+				result, err := op.Run(image)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				assert.Equal(t, image.Cols(), op.Result.Width)
+				assert.Equal(t, image.Rows(), op.Result.Height)
+				assert.Equal(t, 3, op.Result.Channels)
+				assert.Equal(t, "8U", op.Result.Depth)
+				assert.Len(t, op.Result.Histograms, 3)
+				for _, histogram := range op.Result.Histograms {
+					assert.Len(t, histogram.Bins, tt.op.Bins)
+				}
+				assert.LessOrEqual(t, len(op.Result.DominantColors), tt.op.Colors)
+			}
+		})
+	}
+}
+
+func TestAnalyzeReport(t *testing.T) {
+
+	// left half pure red, right quarter blue and the rest green
+	mat := gocv.NewMatWithSize(100, 200, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+	gocv.Rectangle(&mat, image.Rect(0, 0, 100, 100), color.RGBA{R: 255}, -1)
+	gocv.Rectangle(&mat, image.Rect(100, 0, 150, 100), color.RGBA{G: 255}, -1)
+	gocv.Rectangle(&mat, image.Rect(150, 0, 200, 100), color.RGBA{B: 255}, -1)
+
+	op := jobs.NewAnalyze(3, 2)
+	_, err := op.Run(&mat)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "blue", op.Result.Histograms[0].Channel)
+	assert.Equal(t, []int{15000, 5000}, op.Result.Histograms[0].Bins)
+	assert.Equal(t, []int{10000, 10000}, op.Result.Histograms[2].Bins)
+
+	assert.Len(t, op.Result.DominantColors, 3)
+	assert.Equal(t, "#ff0000", op.Result.DominantColors[0].Hex)
+	assert.InDelta(t, 0.5, op.Result.DominantColors[0].Share, 0.01)
+
+	sharp := gradientImage(320, 240)
+	defer sharp.Close()
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+	gocv.GaussianBlur(sharp, &blurred, image.Pt(15, 15), 0, 0, gocv.BorderDefault)
+
+	sharpReport := jobs.NewAnalyze(1, 8)
+	_, err = sharpReport.Run(&sharp)
+	assert.NoError(t, err)
+	blurredReport := jobs.NewAnalyze(1, 8)
+	_, err = blurredReport.Run(&blurred)
+	assert.NoError(t, err)
+
+	assert.Greater(t, sharpReport.Result.LaplacianVariance, blurredReport.Result.LaplacianVariance)
+	assert.Len(t, sharpReport.Result.Hashes.PHash, 16)
+}
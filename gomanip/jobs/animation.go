@@ -0,0 +1,376 @@
+package jobs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"image/gif"
+	"math"
+	"slices"
+)
+
+const (
+	minAnimationFrames = 2
+	maxAnimationFrames = 60
+	// GIF delays are given in hundredths of a second
+	maxAnimationDelay = 100
+	// frames are scaled down to fit within this size to keep the GIF small
+	maxAnimationSide = 480
+
+	maxZoomScale       = 8
+	maxSpinTurns       = 10
+	maxShakeAmount     = 0.25
+	minTweenSaturation = 0.01
+)
+
+// levels of red, green and blue in the GIF palette, green gets an extra level since the eye is most sensitive to it
+var paletteLevels = [3]int{6, 7, 6}
+
+// animationPalette is the color cube every frame is quantized to.
+var animationPalette = func() color.Palette {
+	palette := make(color.Palette, 0, paletteLevels[0]*paletteLevels[1]*paletteLevels[2])
+	level := func(idx, levels int) uint8 { return uint8(idx * 255 / (levels - 1)) }
+
+	for r := range paletteLevels[0] {
+		for g := range paletteLevels[1] {
+			for b := range paletteLevels[2] {
+				palette = append(palette, color.RGBA{R: level(r, paletteLevels[0]), G: level(g, paletteLevels[1]), B: level(b, paletteLevels[2]), A: 255})
+			}
+		}
+	}
+
+	return palette
+}()
+
+// bayer4 is an ordered dithering matrix, it hides the banding of the small palette without the cost of error diffusion.
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// Animation holds the settings shared by operations that render an animated GIF from a single image.
+// These operations fill in GIF and return a nil image, they report every rendered frame as progress.
+type Animation struct {
+	progressReporter
+	Frames int
+	// Delay is the time between frames in hundredths of a second.
+	Delay int
+
+	// GIF is filled in by Run with the encoded animation.
+	GIF []byte
+}
+
+// Animated is implemented by operations that render an animated GIF.
+type Animated interface {
+	Operation
+	Encoded() []byte
+}
+
+func (a *Animation) Encoded() []byte { return a.GIF }
+
+// Alpha is AlphaDiscard, frames are rendered without transparency.
+func (_ *Animation) Alpha() AlphaBehaviour { return AlphaDiscard }
+
+func (_ *Animation) Priority() Priority { return PriorityLow }
+
+// progress returns how far into the animation a frame is, from 0 for the first frame to 1 for the last.
+func (a *Animation) progress(idx int) float64 {
+	return float64(idx) / float64(a.Frames-1)
+}
+
+// render encodes the frames returned by frame into GIF. frame is called with the index of each frame and
+// the input as an 8 bit, 3 channel image scaled down to fit the GIF, which it must not modify.
+func (a *Animation) render(input *gocv.Mat, frame func(base gocv.Mat, idx int) (*gocv.Mat, error)) error {
+
+	if input == nil || input.Empty() {
+		return errors.New("input image is empty")
+	}
+
+	if a.Frames < minAnimationFrames || a.Frames > maxAnimationFrames {
+		return fmt.Errorf("expected frames to be between %d and %d, got %d", minAnimationFrames, maxAnimationFrames, a.Frames)
+	}
+
+	if a.Delay < 1 || a.Delay > maxAnimationDelay {
+		return fmt.Errorf("expected delay to be between 1 and %d, got %d", maxAnimationDelay, a.Delay)
+	}
+
+	base, err := animationBase(*input)
+	if err != nil {
+		return err
+	}
+	defer base.Close()
+
+	animation := &gif.GIF{}
+
+	for idx := range a.Frames {
+		mat, err := frame(base, idx)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", idx+1, err)
+		}
+
+		if mat == nil {
+			return fmt.Errorf("frame %d was not rendered", idx+1)
+		}
+
+		paletted, err := quantize(*mat)
+		mat.Close()
+		if err != nil {
+			return err
+		}
+
+		animation.Image = append(animation.Image, paletted)
+		animation.Delay = append(animation.Delay, a.Delay)
+		a.advance(idx+1, a.Frames, UnitFrame)
+	}
+
+	var encoded bytes.Buffer
+	if err := gif.EncodeAll(&encoded, animation); err != nil {
+		return err
+	}
+
+	a.GIF = encoded.Bytes()
+
+	return nil
+}
+
+// animationBase converts the input to 3 channels and scales it down to fit within maxAnimationSide.
+func animationBase(input gocv.Mat) (gocv.Mat, error) {
+
+	if gocv.MatType(input.Type()&7) != gocv.MatTypeCV8U {
+		return gocv.Mat{}, errors.New("expected an 8 bit image")
+	}
+
+	bgr, err := convertChannels(input, 3)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+
+	scale := float64(maxAnimationSide) / float64(max(input.Rows(), input.Cols()))
+	if scale >= 1 {
+		return bgr, nil
+	}
+	defer bgr.Close()
+
+	size := image.Pt(max(1, int(float64(input.Cols())*scale)), max(1, int(float64(input.Rows())*scale)))
+
+	scaled := gocv.NewMat()
+	if err := gocv.Resize(bgr, &scaled, size, 0, 0, gocv.InterpolationArea); err != nil {
+		scaled.Close()
+		return gocv.Mat{}, err
+	}
+
+	return scaled, nil
+}
+
+// quantize maps a frame onto animationPalette with ordered dithering.
+func quantize(frame gocv.Mat) (*image.Paletted, error) {
+
+	bgr, err := convertChannels(frame, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	data, err := bgr.DataPtrUint8()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := bgr.Rows(), bgr.Cols()
+	paletted := image.NewPaletted(image.Rect(0, 0, cols, rows), animationPalette)
+
+	level := func(value uint8, levels int, threshold float64) int {
+		scaled := float64(value)*float64(levels-1)/255 + threshold
+		return max(0, min(levels-1, int(math.Round(scaled))))
+	}
+
+	for row := range rows {
+		for col := range cols {
+			threshold := (bayer4[row%4][col%4]+0.5)/16 - 0.5
+			pixel := data[(row*cols+col)*3:]
+
+			r := level(pixel[2], paletteLevels[0], threshold)
+			g := level(pixel[1], paletteLevels[1], threshold)
+			b := level(pixel[0], paletteLevels[2], threshold)
+
+			paletted.Pix[row*paletted.Stride+col] = uint8((r*paletteLevels[1]+g)*paletteLevels[2] + b)
+		}
+	}
+
+	return paletted, nil
+}
+
+// warp applies a 2x3 affine matrix to base, keeping its size.
+func warp(base gocv.Mat, matrix gocv.Mat, border gocv.BorderType) (*gocv.Mat, error) {
+
+	warped := gocv.NewMat()
+	if err := gocv.WarpAffineWithParams(base, &warped, matrix, image.Pt(base.Cols(), base.Rows()), gocv.InterpolationLinear, border, color.RGBA{}); err != nil {
+		warped.Close()
+		return nil, err
+	}
+
+	return &warped, nil
+}
+
+// Zoom slowly zooms into the point at X and Y (percentages of the width and height) until it is Scale times as large.
+type Zoom struct {
+	Animation
+	Scale float64
+	X     float64
+	Y     float64
+}
+
+func (z *Zoom) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateCenter(input, z.X, z.Y); err != nil {
+		return nil, err
+	}
+
+	if z.Scale <= 1 || z.Scale > maxZoomScale {
+		return nil, fmt.Errorf("expected scale to be greater than 1 and at most %d, got %0.2f", maxZoomScale, z.Scale)
+	}
+
+	return nil, z.render(input, func(base gocv.Mat, idx int) (*gocv.Mat, error) {
+		center := image.Pt(int(z.X*float64(base.Cols()-1)), int(z.Y*float64(base.Rows()-1)))
+
+		// growing exponentially makes the zoom look like it has a constant speed
+		matrix := gocv.GetRotationMatrix2D(center, 0, math.Pow(z.Scale, z.progress(idx)))
+		defer matrix.Close()
+
+		return warp(base, matrix, gocv.BorderReplicate)
+	})
+}
+
+// Spin turns the image around its center, Turns full rotations over the animation (negative values turn counterclockwise).
+// The last frame leads back into the first, so the GIF loops seamlessly.
+type Spin struct {
+	Animation
+	Turns float64
+}
+
+func (s *Spin) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if s.Turns == 0 || math.Abs(s.Turns) > maxSpinTurns {
+		return nil, fmt.Errorf("expected turns to be between -%d and %d and not 0, got %0.2f", maxSpinTurns, maxSpinTurns, s.Turns)
+	}
+
+	return nil, s.render(input, func(base gocv.Mat, idx int) (*gocv.Mat, error) {
+		center := image.Pt(base.Cols()/2, base.Rows()/2)
+
+		// OpenCV turns counterclockwise for positive angles
+		angle := -360 * s.Turns * float64(idx) / float64(s.Frames)
+		matrix := gocv.GetRotationMatrix2D(center, angle, 1)
+		defer matrix.Close()
+
+		return warp(base, matrix, gocv.BorderConstant)
+	})
+}
+
+// Shake jitters the image around by up to Amount (a percentage of the smaller side) in every frame,
+// with an optional Caption drawn steadily along the bottom, like the "[x intensifies]" meme.
+// The same Seed gives the same shaking, a zero Seed picks a random one.
+type Shake struct {
+	Animation
+	Amount  float64
+	Caption string
+	Seed    int64
+}
+
+func (s *Shake) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if s.Amount <= 0 || s.Amount > maxShakeAmount {
+		return nil, fmt.Errorf("expected amount to be greater than 0 and at most %0.2f, got %0.2f", maxShakeAmount, s.Amount)
+	}
+
+	rng := noiseRNG(s.Seed)
+
+	return nil, s.render(input, func(base gocv.Mat, idx int) (*gocv.Mat, error) {
+		reach := s.Amount * float64(min(base.Rows(), base.Cols()))
+
+		// a slight zoom keeps the replicated border from showing as much
+		matrix := gocv.GetRotationMatrix2D(image.Pt(base.Cols()/2, base.Rows()/2), 0, 1+s.Amount)
+		defer matrix.Close()
+		matrix.SetDoubleAt(0, 2, matrix.GetDoubleAt(0, 2)+(rng.Float64()*2-1)*reach)
+		matrix.SetDoubleAt(1, 2, matrix.GetDoubleAt(1, 2)+(rng.Float64()*2-1)*reach)
+
+		shaken, err := warp(base, matrix, gocv.BorderReplicate)
+		if err != nil {
+			return nil, err
+		}
+
+		if s.Caption != "" {
+			drawCaption(shaken, s.Caption)
+		}
+
+		return shaken, nil
+	})
+}
+
+// drawCaption writes white text with a black outline centered along the bottom of mat, as large as fits.
+func drawCaption(mat *gocv.Mat, caption string) {
+
+	font := gocv.FontHersheyDuplex
+	unit := gocv.GetTextSize(caption, font, 1, 2)
+
+	scale := math.Min(2, 0.9*float64(mat.Cols())/float64(max(unit.X, 1)))
+	thickness := max(1, int(math.Round(scale*2)))
+	size := gocv.GetTextSize(caption, font, scale, thickness)
+
+	origin := image.Pt((mat.Cols()-size.X)/2, mat.Rows()-max(mat.Rows()/20, thickness*2))
+
+	gocv.PutTextWithParams(mat, caption, origin, font, scale, color.RGBA{A: 255}, thickness*3, gocv.LineAA, false)
+	gocv.PutTextWithParams(mat, caption, origin, font, scale, color.RGBA{R: 255, G: 255, B: 255, A: 255}, thickness, gocv.LineAA, false)
+}
+
+// tweenOperations build an animatable operation from the value of its animated parameter,
+// every other parameter is left at the default of its endpoint.
+var tweenOperations = map[string]func(value float64) Operation{
+	// a saturation of 0 is not allowed, but the smallest one is already gray
+	"saturate":      func(value float64) Operation { return NewSaturate(float32(math.Max(value, minTweenSaturation))) },
+	"reduction":     func(value float64) Operation { return NewReduce(float32(value)) },
+	"swirl":         func(value float64) Operation { return NewSwirl(0.5, 0.5, 0.5, value) },
+	"bulge":         func(value float64) Operation { return NewBulge(0.5, 0.5, 0.5, value) },
+	"wave":          func(value float64) Operation { return NewWave(value, 60) },
+	"fisheye":       func(value float64) Operation { return NewFisheye(0.5, 0.5, value) },
+	"kaleidoscope":  func(value float64) Operation { return NewKaleidoscope(int(math.Round(value)), 0.5, 0.5) },
+	"gaussianNoise": func(value float64) Operation { return NewGaussianNoise(value, 0) },
+	"filmGrain":     func(value float64) Operation { return NewFilmGrain(value, 0) },
+	"vignette":      func(value float64) Operation { return NewVignette(value, DefaultVignetteRadius) },
+}
+
+// TweenOperations returns the sorted names of the operations Tween can animate.
+func TweenOperations() []string {
+	names := make([]string, 0, len(tweenOperations))
+	for name := range tweenOperations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Tween runs an operation once per frame, moving its main parameter evenly from From to To,
+// i.e the saturation sweeping from 0 to 5. Operation is one of TweenOperations.
+type Tween struct {
+	Animation
+	Operation string
+	From      float64
+	To        float64
+}
+
+func (t *Tween) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	build, ok := tweenOperations[t.Operation]
+	if !ok {
+		return nil, fmt.Errorf("cannot animate operation %s, expected one of %v", t.Operation, TweenOperations())
+	}
+
+	return nil, t.render(input, func(base gocv.Mat, idx int) (*gocv.Mat, error) {
+		value := t.From + (t.To-t.From)*t.progress(idx)
+		return build(value).Run(&base)
+	})
+}
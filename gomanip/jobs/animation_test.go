@@ -0,0 +1,164 @@
+package jobs_test
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func decodeGIF(t *testing.T, encoded []byte) *gif.GIF {
+	t.Helper()
+
+	decoded, err := gif.DecodeAll(bytes.NewReader(encoded))
+	assert.NoError(t, err)
+	return decoded
+}
+
+func TestAnimations(t *testing.T) {
+
+	small := gradientImage(200, 120)
+	defer small.Close()
+	large := gradientImage(1000, 600)
+	defer large.Close()
+
+	tests := []struct {
+		name      string
+		wantError bool
+		op        jobs.Animated
+	}{
+		{name: "zoom", op: jobs.NewZoom(10, 5, 2, 0.3, 0.6)},
+		{name: "spin", op: jobs.NewSpin(8, 4, -1.5)},
+		{name: "shake", op: jobs.NewShake(6, 2, 0.05, "[GOPHER INTENSIFIES]", 3)},
+		{name: "tween saturation", op: jobs.NewTween(12, 5, "saturate", 0, 5)},
+		{name: "tween kaleidoscope", op: jobs.NewTween(5, 10, "kaleidoscope", 2, 12)},
+		{name: "Handle too few frames", wantError: true, op: jobs.NewZoom(1, 5, 2, 0.5, 0.5)},
+		{name: "Handle too many frames", wantError: true, op: jobs.NewSpin(61, 5, 1)},
+		{name: "Handle invalid delay", wantError: true, op: jobs.NewSpin(10, 0, 1)},
+		{name: "Handle invalid zoom scale", wantError: true, op: jobs.NewZoom(10, 5, 1, 0.5, 0.5)},
+		{name: "Handle invalid zoom center", wantError: true, op: jobs.NewZoom(10, 5, 2, 1.5, 0.5)},
+		{name: "Handle invalid spin turns", wantError: true, op: jobs.NewSpin(10, 5, 0)},
+		{name: "Handle invalid shake amount", wantError: true, op: jobs.NewShake(10, 5, 0.5, "", 1)},
+		{name: "Handle unknown tween operation", wantError: true, op: jobs.NewTween(10, 5, "invert", 0, 1)},
+		{name: "Handle invalid tween values", wantError: true, op: jobs.NewTween(10, 5, "vignette", 0.5, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, input := range []*gocv.Mat{&small, &large} {
+				result, err := tt.op.Run(input)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				decoded := decodeGIF(t, tt.op.Encoded())
+				frames, delay := len(decoded.Image), decoded.Delay[0]
+				assert.Greater(t, frames, 1)
+				assert.Equal(t, frames, len(decoded.Delay))
+				for _, frameDelay := range decoded.Delay {
+					assert.Equal(t, delay, frameDelay)
+				}
+
+				// large images are scaled down to fit the GIF
+				bounds := decoded.Image[0].Bounds()
+				assert.LessOrEqual(t, max(bounds.Dx(), bounds.Dy()), 480)
+				assert.InDelta(t, float64(input.Cols())/float64(input.Rows()), float64(bounds.Dx())/float64(bounds.Dy()), 0.01)
+			}
+
+			_, err := tt.op.Run(nil)
+			assert.Error(t, err)
+		})
+	}
+
+	assert.Equal(t, jobs.AlphaDiscard, jobs.AlphaOf(jobs.NewSpin(10, 5, 1)))
+}
+
+func TestAnimationFrameSettings(t *testing.T) {
+
+	original := gradientImage(64, 64)
+	defer original.Close()
+
+	zoom := jobs.NewZoom(17, 9, 3, 0.5, 0.5)
+	_, err := zoom.Run(&original)
+	assert.NoError(t, err)
+
+	decoded := decodeGIF(t, zoom.Encoded())
+	assert.Len(t, decoded.Image, 17)
+	assert.Equal(t, 9, decoded.Delay[0])
+	assert.Equal(t, 0, decoded.LoopCount)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), decoded.Image[0].Bounds())
+}
+
+func TestAnimationSolidColors(t *testing.T) {
+
+	for _, solid := range []color.RGBA{{A: 255}, {R: 255, G: 255, B: 255, A: 255}, {R: 255, A: 255}} {
+		mat := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(float64(solid.B), float64(solid.G), float64(solid.R), 0), 32, 32, gocv.MatTypeCV8UC3)
+
+		zoom := jobs.NewZoom(3, 5, 2, 0.5, 0.5)
+		_, err := zoom.Run(&mat)
+		assert.NoError(t, err)
+
+		// colors in the palette are never dithered
+		for _, frame := range decodeGIF(t, zoom.Encoded()).Image {
+			for _, point := range []image.Point{{0, 0}, {13, 7}, {31, 31}} {
+				assert.Equal(t, solid, color.RGBAModel.Convert(frame.At(point.X, point.Y)))
+			}
+		}
+
+		mat.Close()
+	}
+}
+
+func TestShakeIsSeeded(t *testing.T) {
+
+	original := gradientImage(100, 80)
+	defer original.Close()
+
+	render := func(seed int64) []byte {
+		shake := jobs.NewShake(5, 3, 0.1, "shaky", seed)
+		_, err := shake.Run(&original)
+		assert.NoError(t, err)
+		return shake.Encoded()
+	}
+
+	assert.Equal(t, render(8), render(8))
+	assert.NotEqual(t, render(8), render(9))
+}
+
+// colorSpread is the mean difference between the largest and smallest color channel of every pixel.
+func colorSpread(frame image.Image) float64 {
+
+	total := 0.0
+	bounds := frame.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixel := color.RGBAModel.Convert(frame.At(x, y)).(color.RGBA)
+			total += float64(max(pixel.R, pixel.G, pixel.B) - min(pixel.R, pixel.G, pixel.B))
+		}
+	}
+
+	return total / float64(bounds.Dx()*bounds.Dy())
+}
+
+func TestTweenSweepsParameter(t *testing.T) {
+
+	original := gradientImage(120, 80)
+	defer original.Close()
+
+	tween := jobs.NewTween(6, 5, "saturate", 0, 3)
+	_, err := tween.Run(&original)
+	assert.NoError(t, err)
+
+	decoded := decodeGIF(t, tween.Encoded())
+	assert.Len(t, decoded.Image, 6)
+
+	first, last := colorSpread(decoded.Image[0]), colorSpread(decoded.Image[5])
+	assert.Less(t, first, 40.0)
+	assert.Greater(t, last, first*2)
+}
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math"
+	"strings"
+)
+
+const (
+	// DefaultRamp goes from the sparsest to the densest character, bright pixels get dense characters
+	DefaultRamp     = " .:-=+*#%@"
+	maxAsciiColumns = 500
+	// characters are about twice as tall as they are wide, so every text row covers two pixel rows
+	characterAspect = 0.5
+)
+
+type emojiColor struct {
+	emoji   string
+	b, g, r float64
+}
+
+// emojiPalette holds the colored square emoji, each with the color it is drawn in.
+var emojiPalette = []emojiColor{
+	{emoji: "⬛", b: 0, g: 0, r: 0},
+	{emoji: "⬜", b: 255, g: 255, r: 255},
+	{emoji: "🟥", b: 49, g: 46, r: 221},
+	{emoji: "🟧", b: 12, g: 140, r: 244},
+	{emoji: "🟨", b: 46, g: 204, r: 253},
+	{emoji: "🟩", b: 89, g: 176, r: 120},
+	{emoji: "🟦", b: 233, g: 172, r: 85},
+	{emoji: "🟪", b: 201, g: 86, r: 170},
+	{emoji: "🟫", b: 56, g: 89, r: 193},
+}
+
+// AsciiArt renders an image as text, Columns characters wide. With Emoji set every character is the colored
+// square emoji closest to that part of the image, otherwise brightness is mapped onto the characters of Ramp.
+// Run only fills in Lines and returns a nil image.
+type AsciiArt struct {
+	Columns int
+	Ramp    string
+	Emoji   bool
+
+	// Lines is filled in by Run with one string per row of text.
+	Lines []string
+}
+
+func (a *AsciiArt) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	if a.Columns <= 0 || a.Columns > maxAsciiColumns {
+		return nil, fmt.Errorf("expected columns to be between 1 and %d, got %d", maxAsciiColumns, a.Columns)
+	}
+
+	ramp := []rune(a.Ramp)
+	if !a.Emoji && len(ramp) == 0 {
+		return nil, errors.New("character ramp must not be empty")
+	}
+
+	aspect := characterAspect
+	channels := 1
+	if a.Emoji {
+		// emoji are square
+		aspect = 1
+		channels = 3
+	}
+
+	converted, err := convertChannels(*input, channels)
+	if err != nil {
+		return nil, err
+	}
+	defer converted.Close()
+
+	rows := max(1, int(math.Round(float64(a.Columns)*float64(input.Rows())/float64(input.Cols())*aspect)))
+
+	cells := gocv.NewMat()
+	defer cells.Close()
+	if err := gocv.Resize(converted, &cells, image.Point{X: a.Columns, Y: rows}, 0, 0, gocv.InterpolationArea); err != nil {
+		return nil, err
+	}
+
+	a.Lines = make([]string, rows)
+
+	for row := range rows {
+		var line strings.Builder
+		for col := range a.Columns {
+			if a.Emoji {
+				pixel := cells.GetVecbAt(row, col)
+				line.WriteString(closestEmoji(float64(pixel[0]), float64(pixel[1]), float64(pixel[2])))
+				continue
+			}
+			brightness := int(cells.GetUCharAt(row, col))
+			line.WriteRune(ramp[brightness*len(ramp)/256])
+		}
+		a.Lines[row] = line.String()
+	}
+
+	return nil, nil
+}
+
+func (_ *AsciiArt) Alpha() AlphaBehaviour { return AlphaDiscard }
+
+func closestEmoji(b, g, r float64) string {
+
+	closest := emojiPalette[0]
+	closestDistance := math.Inf(1)
+
+	for _, candidate := range emojiPalette {
+		distance := (candidate.b-b)*(candidate.b-b) + (candidate.g-g)*(candidate.g-g) + (candidate.r-r)*(candidate.r-r)
+		if distance < closestDistance {
+			closest = candidate
+			closestDistance = distance
+		}
+	}
+
+	return closest.emoji
+}
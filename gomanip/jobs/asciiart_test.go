@@ -0,0 +1,98 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestAsciiArt(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.AsciiArt
+	}{
+		{
+			name:      "test with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.AsciiArt{Columns: 80, Ramp: jobs.DefaultRamp},
+		},
+		{
+			name:      "test emoji with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.AsciiArt{Columns: 30, Emoji: true},
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.AsciiArt{Columns: 80, Ramp: jobs.DefaultRamp},
+		},
+		{
+			name:      "Handle invalid columns",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.AsciiArt{Columns: 0, Ramp: jobs.DefaultRamp},
+		},
+		{
+			name:      "Handle empty ramp",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.AsciiArt{Columns: 80},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+				op := tt.op
+				result, err := op.Run(image)
+				assert.Nil(t, result)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				assert.NotEmpty(t, op.Lines)
+				for _, line := range op.Lines {
+					assert.Equal(t, tt.op.Columns, utf8.RuneCountInString(line))
+				}
+			}
+		})
+	}
+}
+
+func TestAsciiArtRendering(t *testing.T) {
+
+	// black on the left, white on the right
+	mat := gocv.NewMatWithSize(40, 80, gocv.MatTypeCV8UC3)
+	defer mat.Close()
+	gocv.Rectangle(&mat, image.Rect(0, 0, 40, 40), color.RGBA{}, -1)
+	gocv.Rectangle(&mat, image.Rect(40, 0, 80, 40), color.RGBA{R: 255, G: 255, B: 255}, -1)
+
+	art := jobs.NewAsciiArt(4, " #", false)
+	_, err := art.Run(&mat)
+	assert.NoError(t, err)
+	// 4 columns over a 2:1 image with half height characters is a single row
+	assert.Equal(t, []string{"  ##"}, art.Lines)
+
+	emoji := jobs.NewAsciiArt(2, "", true)
+	_, err = emoji.Run(&mat)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"⬛⬜"}, emoji.Lines)
+
+	red := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	defer red.Close()
+	red.SetTo(gocv.NewScalar(0, 0, 255, 0))
+	_, err = emoji.Run(&red)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"🟥🟥", "🟥🟥"}, emoji.Lines)
+}
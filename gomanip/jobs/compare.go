@@ -0,0 +1,304 @@
+package jobs
+
+import (
+	"errors"
+	"gocv.io/x/gocv"
+	"image"
+)
+
+const (
+	// constants from the original SSIM paper for 8 bit images, (0.01*255)^2 and (0.03*255)^2
+	ssimC1 = 6.5025
+	ssimC2 = 58.5225
+	// heatmapWeight is how strongly the heatmap covers the first image
+	heatmapWeight = 0.6
+)
+
+// HashDistances holds the Hamming distance between the hashes of two images, 0 means the hashes are identical.
+type HashDistances struct {
+	AHash int `json:"aHash"`
+	DHash int `json:"dHash"`
+	PHash int `json:"pHash"`
+}
+
+type Similarity struct {
+	// SSIM is the mean structural similarity, 1 for identical images.
+	SSIM float64 `json:"ssim"`
+	// PSNR is the peak signal to noise ratio in decibels, identical images report about 361 rather than infinity.
+	PSNR          float64       `json:"psnr"`
+	MSE           float64       `json:"mse"`
+	HashDistances HashDistances `json:"hashDistances"`
+}
+
+// Compare measures how similar the input is to Other. Other is resized to the input's size and both
+// are compared as 3 channel images. When Heatmap is set Run returns the input with the differences
+// highlighted, otherwise it only fills in Result and returns a nil image.
+type Compare struct {
+	Other   *gocv.Mat
+	Heatmap bool
+
+	// Result is filled in by Run.
+	Result Similarity
+}
+
+// HeldMats returns Other, which the comparison reads besides its input.
+func (c *Compare) HeldMats() []*gocv.Mat {
+	if c.Other == nil {
+		return nil
+	}
+	return []*gocv.Mat{c.Other}
+}
+
+func (c *Compare) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	if c.Other == nil || c.Other.Empty() {
+		return nil, errors.New("image to compare against is empty")
+	}
+
+	first, err := convertChannels(*input, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer first.Close()
+
+	second, err := convertChannels(*c.Other, 3)
+	if err != nil {
+		return nil, err
+	}
+	defer second.Close()
+
+	if second.Rows() != first.Rows() || second.Cols() != first.Cols() {
+		if err := gocv.Resize(second, &second, image.Point{X: first.Cols(), Y: first.Rows()}, 0, 0, gocv.InterpolationArea); err != nil {
+			return nil, err
+		}
+	}
+
+	c.Result = Similarity{PSNR: gocv.PSNR(first, second)}
+
+	c.Result.MSE, err = meanSquaredError(first, second)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Result.SSIM, err = structuralSimilarity(first, second)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Result.HashDistances, err = hashDistances(first, second)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.Heatmap {
+		return nil, nil
+	}
+
+	return differenceHeatmap(first, second)
+}
+
+// Alpha is AlphaDiscard, both images are compared and drawn without their alpha channel.
+func (_ *Compare) Alpha() AlphaBehaviour { return AlphaDiscard }
+
+func (_ *Compare) Priority() Priority { return PriorityLow }
+
+// meanChannels averages the per channel mean of a 3 channel image.
+func meanChannels(mat gocv.Mat) float64 {
+	mean := mat.Mean()
+	return (mean.Val1 + mean.Val2 + mean.Val3) / 3
+}
+
+func meanSquaredError(first, second gocv.Mat) (float64, error) {
+
+	a := gocv.NewMat()
+	defer a.Close()
+	b := gocv.NewMat()
+	defer b.Close()
+
+	if err := first.ConvertTo(&a, gocv.MatTypeCV64FC3); err != nil {
+		return 0, err
+	}
+	if err := second.ConvertTo(&b, gocv.MatTypeCV64FC3); err != nil {
+		return 0, err
+	}
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	if err := gocv.Subtract(a, b, &diff); err != nil {
+		return 0, err
+	}
+	if err := gocv.Multiply(diff, diff, &diff); err != nil {
+		return 0, err
+	}
+
+	return meanChannels(diff), nil
+}
+
+// structuralSimilarity computes the mean SSIM over every channel using an 11x11 gaussian window.
+func structuralSimilarity(first, second gocv.Mat) (float64, error) {
+
+	var mats []*gocv.Mat
+	defer func() {
+		for _, mat := range mats {
+			mat.Close()
+		}
+	}()
+	newMat := func() *gocv.Mat {
+		mat := gocv.NewMat()
+		mats = append(mats, &mat)
+		return &mat
+	}
+	blur := func(src gocv.Mat) (*gocv.Mat, error) {
+		dst := newMat()
+		return dst, gocv.GaussianBlur(src, dst, image.Pt(11, 11), 1.5, 1.5, gocv.BorderDefault)
+	}
+	product := func(a, b gocv.Mat) (*gocv.Mat, error) {
+		dst := newMat()
+		return dst, gocv.Multiply(a, b, dst)
+	}
+
+	a, b := newMat(), newMat()
+	if err := first.ConvertTo(a, gocv.MatTypeCV64FC3); err != nil {
+		return 0, err
+	}
+	if err := second.ConvertTo(b, gocv.MatTypeCV64FC3); err != nil {
+		return 0, err
+	}
+
+	var err error
+	var muA, muB, muAA, muBB, muAB, aa, bb, ab, sigmaA, sigmaB, sigmaAB *gocv.Mat
+
+	if muA, err = blur(*a); err != nil {
+		return 0, err
+	}
+	if muB, err = blur(*b); err != nil {
+		return 0, err
+	}
+	if muAA, err = product(*muA, *muA); err != nil {
+		return 0, err
+	}
+	if muBB, err = product(*muB, *muB); err != nil {
+		return 0, err
+	}
+	if muAB, err = product(*muA, *muB); err != nil {
+		return 0, err
+	}
+	if aa, err = product(*a, *a); err != nil {
+		return 0, err
+	}
+	if bb, err = product(*b, *b); err != nil {
+		return 0, err
+	}
+	if ab, err = product(*a, *b); err != nil {
+		return 0, err
+	}
+	if sigmaA, err = blur(*aa); err != nil {
+		return 0, err
+	}
+	if sigmaB, err = blur(*bb); err != nil {
+		return 0, err
+	}
+	if sigmaAB, err = blur(*ab); err != nil {
+		return 0, err
+	}
+	if err = gocv.Subtract(*sigmaA, *muAA, sigmaA); err != nil {
+		return 0, err
+	}
+	if err = gocv.Subtract(*sigmaB, *muBB, sigmaB); err != nil {
+		return 0, err
+	}
+	if err = gocv.Subtract(*sigmaAB, *muAB, sigmaAB); err != nil {
+		return 0, err
+	}
+
+	// numerator: (2 muA muB + C1)(2 sigmaAB + C2), AddWeighted is used as its gamma is added to every channel
+	if err = gocv.AddWeighted(*muAB, 2, *muAB, 0, ssimC1, muAB); err != nil {
+		return 0, err
+	}
+	if err = gocv.AddWeighted(*sigmaAB, 2, *sigmaAB, 0, ssimC2, sigmaAB); err != nil {
+		return 0, err
+	}
+	numerator, err := product(*muAB, *sigmaAB)
+	if err != nil {
+		return 0, err
+	}
+
+	// denominator: (muA^2 + muB^2 + C1)(sigmaA^2 + sigmaB^2 + C2)
+	means, variances := newMat(), newMat()
+	if err = gocv.AddWeighted(*muAA, 1, *muBB, 1, ssimC1, means); err != nil {
+		return 0, err
+	}
+	if err = gocv.AddWeighted(*sigmaA, 1, *sigmaB, 1, ssimC2, variances); err != nil {
+		return 0, err
+	}
+	denominator, err := product(*means, *variances)
+	if err != nil {
+		return 0, err
+	}
+
+	ssimMap := newMat()
+	if err = gocv.Divide(*numerator, *denominator, ssimMap); err != nil {
+		return 0, err
+	}
+
+	return meanChannels(*ssimMap), nil
+}
+
+func hashDistances(first, second gocv.Mat) (HashDistances, error) {
+
+	hashes := []func(gocv.Mat) (uint64, error){AverageHash, DifferenceHash, PerceptualHash}
+	distances := make([]int, len(hashes))
+
+	for idx, hash := range hashes {
+		a, err := hash(first)
+		if err != nil {
+			return HashDistances{}, err
+		}
+		b, err := hash(second)
+		if err != nil {
+			return HashDistances{}, err
+		}
+		distances[idx] = HammingDistance(a, b)
+	}
+
+	return HashDistances{AHash: distances[0], DHash: distances[1], PHash: distances[2]}, nil
+}
+
+// differenceHeatmap colors the per pixel difference from blue (unchanged) to red (most changed)
+// and blends it over the first image.
+func differenceHeatmap(first, second gocv.Mat) (*gocv.Mat, error) {
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	if err := gocv.AbsDiff(first, second, &diff); err != nil {
+		return nil, err
+	}
+
+	gray, err := convertChannels(diff, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	if err := gocv.Normalize(gray, &gray, 0, 255, gocv.NormMinMax); err != nil {
+		return nil, err
+	}
+
+	colored := gocv.NewMat()
+	defer colored.Close()
+	if err := gocv.ApplyColorMap(gray, &colored, gocv.ColormapJet); err != nil {
+		return nil, err
+	}
+
+	result := gocv.NewMat()
+	if err := gocv.AddWeighted(first, 1-heatmapWeight, colored, heatmapWeight, 0, &result); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
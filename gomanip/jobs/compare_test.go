@@ -0,0 +1,139 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"testing"
+)
+
+// similarity compares two images so tests can check an operation's output approximately.
+func similarity(t *testing.T, first, second *gocv.Mat) jobs.Similarity {
+	t.Helper()
+
+	compare := jobs.NewCompare(second, false)
+	result, err := compare.Run(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Nil(t, result)
+
+	return compare.Result
+}
+
+func TestCompare(t *testing.T) {
+
+	original := gradientImage(320, 240)
+	defer original.Close()
+
+	inverted := gocv.NewMat()
+	defer inverted.Close()
+	gocv.BitwiseNot(original, &inverted)
+
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+	gocv.GaussianBlur(original, &blurred, image.Pt(5, 5), 0, 0, gocv.BorderDefault)
+
+	smaller := gocv.NewMat()
+	defer smaller.Close()
+	gocv.Resize(original, &smaller, image.Pt(160, 120), 0, 0, gocv.InterpolationArea)
+
+	withAlpha := gocv.NewMat()
+	defer withAlpha.Close()
+	gocv.CvtColor(original, &withAlpha, gocv.ColorBGRToBGRA)
+
+	identical := similarity(t, &original, &original)
+	assert.InDelta(t, 1.0, identical.SSIM, 1e-6)
+	assert.Zero(t, identical.MSE)
+	assert.Greater(t, identical.PSNR, 100.0)
+	assert.Equal(t, jobs.HashDistances{}, identical.HashDistances)
+
+	alpha := similarity(t, &withAlpha, &original)
+	assert.InDelta(t, 1.0, alpha.SSIM, 1e-6)
+
+	blur := similarity(t, &original, &blurred)
+	assert.Greater(t, blur.SSIM, 0.8)
+	assert.Less(t, blur.SSIM, 1.0)
+	assert.Greater(t, blur.MSE, 0.0)
+
+	resized := similarity(t, &original, &smaller)
+	assert.Greater(t, resized.SSIM, 0.8)
+	assert.LessOrEqual(t, resized.HashDistances.PHash, 4)
+
+	opposite := similarity(t, &original, &inverted)
+	assert.Less(t, opposite.SSIM, blur.SSIM)
+	assert.Less(t, opposite.PSNR, blur.PSNR)
+	assert.Greater(t, opposite.HashDistances.AHash, 24)
+}
+
+func TestCompareHeatmap(t *testing.T) {
+
+	original := gradientImage(320, 240)
+	defer original.Close()
+
+	inverted := gocv.NewMat()
+	defer inverted.Close()
+	gocv.BitwiseNot(original, &inverted)
+
+	empty := gocv.NewMat()
+	defer empty.Close()
+
+	tests := []struct {
+		name      string
+		input     *gocv.Mat
+		other     *gocv.Mat
+		wantError bool
+	}{
+		{name: "heatmap of identical images", input: &original, other: &original},
+		{name: "heatmap of different images", input: &original, other: &inverted},
+		{name: "Handle Nil image case", input: nil, other: &original, wantError: true},
+		{name: "Handle missing other image", input: &original, other: nil, wantError: true},
+		{name: "Handle empty other image", input: &original, other: &empty, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compare := jobs.NewCompare(tt.other, true)
+			result, err := compare.Run(tt.input)
+			assert.Equal(t, tt.wantError, err != nil)
+			if err != nil {
+				return
+			}
+			defer result.Close()
+
+			assert.Equal(t, original.Rows(), result.Rows())
+			assert.Equal(t, original.Cols(), result.Cols())
+			assert.Equal(t, 3, result.Channels())
+		})
+	}
+}
+
+func TestOperationOutputsApproximately(t *testing.T) {
+
+	original := gradientImage(256, 256)
+	defer original.Close()
+
+	// inverting twice gives back the original
+	inverted, err := jobs.NewInvert().Run(&original)
+	assert.NoError(t, err)
+	defer inverted.Close()
+	restored, err := jobs.NewInvert().Run(inverted)
+	assert.NoError(t, err)
+	defer restored.Close()
+	assert.InDelta(t, 1.0, similarity(t, &original, restored).SSIM, 1e-6)
+
+	// reducing less keeps more of the original
+	slightlyReduced, err := jobs.NewReduce(0.9).Run(&original)
+	assert.NoError(t, err)
+	defer slightlyReduced.Close()
+	heavilyReduced, err := jobs.NewReduce(0.1).Run(&original)
+	assert.NoError(t, err)
+	defer heavilyReduced.Close()
+
+	slight := similarity(t, &original, slightlyReduced)
+	heavy := similarity(t, &original, heavilyReduced)
+	assert.Greater(t, slight.SSIM, 0.8)
+	assert.Greater(t, slight.SSIM, heavy.SSIM)
+	assert.Greater(t, slight.PSNR, heavy.PSNR)
+}
@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"math"
+	"time"
+)
+
+// The cost model estimates how long an operation runs from the size of its input and its parameters.
+// The rates are rough per pixel timings on a single core, they only need to be close enough to turn
+// away jobs that can not finish in time, not to predict exact durations.
+const (
+	defaultNanosPerPixel = 10.0
+	// morphology runs once per iteration, its cost grows with the kernel width
+	morphologyNanosPerPixel = 1.0
+	// small kernels are applied directly, larger ones through the DFT which no longer grows with the kernel
+	filterNanosPerTap      = 0.5
+	maxFilterNanosPerPixel = 60.0
+	// every tile of a shuffle is copied into a new Mat
+	shuffleTileCost       = 2 * time.Microsecond
+	cartoonNanosPerPixel  = 150.0
+	pencilNanosPerPixel   = 100.0
+	oilPaintNanosPerPixel = 600.0
+	comicNanosPerPixel    = 200.0
+	// rendering and dithering one frame of an animation
+	frameNanosPerPixel   = 100.0
+	exportNanosPerPixel  = 200.0
+	detectNanosPerPixel  = 300.0
+	analyzeNanosPerPixel = 150.0
+	compareNanosPerPixel = 100.0
+)
+
+// Estimated is implemented by operations whose cost depends on more than a flat rate per pixel.
+type Estimated interface {
+	Estimate(rows, cols int) time.Duration
+}
+
+// EstimateOf returns how long op is expected to run on an image of rows by cols pixels.
+func EstimateOf(op Operation, rows, cols int) time.Duration {
+	if estimated, ok := op.(Estimated); ok {
+		return estimated.Estimate(rows, cols)
+	}
+	return perPixel(rows, cols, defaultNanosPerPixel)
+}
+
+func perPixel(rows, cols int, nanos float64) time.Duration {
+	return time.Duration(float64(rows) * float64(cols) * nanos)
+}
+
+// filterNanos is the per pixel cost of filtering one channel with a kernel of the given size.
+func filterNanos(kernelRows, kernelCols int) float64 {
+	return math.Min(float64(kernelRows*kernelCols)*filterNanosPerTap, maxFilterNanosPerPixel)
+}
+
+func (m *Morphology) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, morphologyNanosPerPixel*float64(m.KernelSize*max(m.Iterations, 1)))
+}
+
+// Estimate covers one kernel per color channel.
+func (r *RandomFilter) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, 3*filterNanos(r.KernelSize, r.KernelSize))
+}
+
+// Estimate covers the given kernels, presets are small kernels applied to every color channel.
+func (cv *Convolve) Estimate(rows, cols int) time.Duration {
+	if len(cv.Kernels) == 0 {
+		return perPixel(rows, cols, 3*filterNanos(3, 3))
+	}
+
+	nanos := 0.0
+	for _, kernel := range cv.Kernels {
+		if len(kernel) > 0 {
+			nanos += filterNanos(len(kernel), len(kernel[0]))
+		}
+	}
+	// a single kernel is applied to every color channel
+	if len(cv.Kernels) == 1 {
+		nanos *= 3
+	}
+
+	return perPixel(rows, cols, nanos)
+}
+
+func (s *Shuffle) Estimate(rows, cols int) time.Duration {
+	tiles := max(s.Partitions, s.Rows*s.Cols)
+	return perPixel(rows, cols, defaultNanosPerPixel) + time.Duration(tiles)*shuffleTileCost
+}
+
+func (_ *Cartoon) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, cartoonNanosPerPixel)
+}
+
+func (_ *PencilSketch) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, pencilNanosPerPixel)
+}
+
+func (_ *OilPaint) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, oilPaintNanosPerPixel)
+}
+
+func (_ *Comic) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, comicNanosPerPixel)
+}
+
+// Estimate covers rendering every frame, which are scaled down to fit maxAnimationSide first.
+func (a *Animation) Estimate(rows, cols int) time.Duration {
+	scale := math.Min(1, float64(maxAnimationSide)/float64(max(rows, cols, 1)))
+	frameRows, frameCols := int(float64(rows)*scale), int(float64(cols)*scale)
+	return time.Duration(a.Frames) * perPixel(frameRows, frameCols, frameNanosPerPixel)
+}
+
+func (_ *Export) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, exportNanosPerPixel)
+}
+
+func (_ *Detect) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, detectNanosPerPixel)
+}
+
+func (_ *Analyze) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, analyzeNanosPerPixel)
+}
+
+func (_ *Compare) Estimate(rows, cols int) time.Duration {
+	return perPixel(rows, cols, compareNanosPerPixel)
+}
+
+// Estimate is the sum of every step, each step is estimated on the size of the input.
+func (p *Pipeline) Estimate(rows, cols int) time.Duration {
+	var total time.Duration
+	for _, step := range p.Steps {
+		total += EstimateOf(step, rows, cols)
+	}
+	return total
+}
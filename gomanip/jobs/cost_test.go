@@ -0,0 +1,64 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goManip/jobs"
+)
+
+func TestEstimateOf(t *testing.T) {
+	small, large := 100, 1000
+
+	// every operation costs more on a larger image
+	operations := []jobs.Operation{
+		jobs.NewInvert(),
+		jobs.NewMorphology(3, 2, jobs.Dilate),
+		jobs.NewRandomFilter(5, -1, 1, true),
+		jobs.NewConvolve(nil, "sharpen"),
+		jobs.NewShuffle(4),
+		jobs.NewSpin(10, 5, 1),
+		jobs.NewPipeline(jobs.NewInvert(), jobs.NewMirror(jobs.MirrorLeft)),
+	}
+	for _, op := range operations {
+		assert.Greater(t, jobs.EstimateOf(op, large, large), jobs.EstimateOf(op, small, small), jobs.NameOf(op))
+	}
+
+	// and more with heavier parameters
+	assert.Greater(t,
+		jobs.EstimateOf(jobs.NewMorphology(9, 5, jobs.Dilate), large, large),
+		jobs.EstimateOf(jobs.NewMorphology(3, 1, jobs.Dilate), large, large))
+	assert.Greater(t,
+		jobs.EstimateOf(jobs.NewRandomFilter(9, -1, 1, true), large, large),
+		jobs.EstimateOf(jobs.NewRandomFilter(3, -1, 1, true), large, large))
+	assert.Greater(t,
+		jobs.EstimateOf(jobs.NewShuffle(400), large, large),
+		jobs.EstimateOf(jobs.NewShuffle(4), large, large))
+	assert.Greater(t,
+		jobs.EstimateOf(jobs.NewSpin(40, 5, 1), large, large),
+		jobs.EstimateOf(jobs.NewSpin(10, 5, 1), large, large))
+
+	// large kernels go through the DFT, which stops growing with the kernel
+	assert.Equal(t,
+		jobs.EstimateOf(jobs.NewRandomFilter(31, -1, 1, true), large, large),
+		jobs.EstimateOf(jobs.NewRandomFilter(61, -1, 1, true), large, large))
+
+	// animation frames are scaled down first
+	assert.Equal(t,
+		jobs.EstimateOf(jobs.NewSpin(10, 5, 1), 4800, 4800),
+		jobs.EstimateOf(jobs.NewSpin(10, 5, 1), 480, 480))
+
+	// a pipeline costs as much as its steps together
+	invert, convolve := jobs.NewInvert(), jobs.NewConvolve(nil, "sharpen")
+	assert.Equal(t,
+		jobs.EstimateOf(invert, large, large)+jobs.EstimateOf(convolve, large, large),
+		jobs.EstimateOf(jobs.NewPipeline(invert, convolve), large, large))
+}
+
+func TestNameOf(t *testing.T) {
+	assert.Equal(t, "invert", jobs.NameOf(jobs.NewInvert()))
+	assert.Equal(t, "randomFilter", jobs.NameOf(jobs.NewRandomFilter(3, -1, 1, true)))
+	assert.Equal(t, "spin", jobs.NameOf(jobs.NewSpin(10, 5, 1)))
+	assert.Equal(t, "pipeline", jobs.NameOf(jobs.NewPipeline(jobs.NewInvert())))
+}
@@ -0,0 +1,267 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"sync"
+)
+
+type DetectAction string
+
+const (
+	DetectNone     DetectAction = ""
+	DetectDraw     DetectAction = "draw"
+	DetectBlur     DetectAction = "blur"
+	DetectPixelate DetectAction = "pixelate"
+	DetectOverlay  DetectAction = "overlay"
+)
+
+// Box is a detected region in pixel coordinates.
+type Box struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+func (b Box) rect() image.Rectangle {
+	return image.Rect(b.X, b.Y, b.X+b.Width, b.Y+b.Height)
+}
+
+// Detect finds objects with a Haar cascade and optionally edits each detected region.
+// With no action Run only fills in Boxes and returns a nil image.
+type Detect struct {
+	CascadePath  string
+	OverlayPath  string
+	Action       DetectAction
+	ScaleFactor  float64
+	MinNeighbors int
+
+	// Boxes is filled in by Run with the detected regions.
+	Boxes []Box
+}
+
+func (d *Detect) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil {
+		return nil, errors.New("input image is empty")
+	}
+
+	if d.CascadePath == "" {
+		return nil, errors.New("a cascade is required")
+	}
+
+	if d.ScaleFactor <= 1.0 {
+		return nil, fmt.Errorf("expected scale factor to be greater than 1, got %0.2f", d.ScaleFactor)
+	}
+
+	if d.MinNeighbors < 0 {
+		return nil, fmt.Errorf("expected min neighbors to be greater than or equal to 0, got %d", d.MinNeighbors)
+	}
+
+	switch d.Action {
+	case DetectNone, DetectDraw, DetectBlur, DetectPixelate:
+	case DetectOverlay:
+		if d.OverlayPath == "" {
+			return nil, errors.New("an overlay is required to paste onto detected regions")
+		}
+	default:
+		return nil, fmt.Errorf("invalid detect action %s", d.Action)
+	}
+
+	classifier, err := loadCascade(d.CascadePath)
+	if err != nil {
+		return nil, err
+	}
+
+	gray, err := convertChannels(*input, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	gocv.EqualizeHist(gray, &gray)
+
+	rects := classifier.detect(gray, d.ScaleFactor, d.MinNeighbors)
+
+	d.Boxes = make([]Box, len(rects))
+	for i, rect := range rects {
+		d.Boxes[i] = Box{X: rect.Min.X, Y: rect.Min.Y, Width: rect.Dx(), Height: rect.Dy()}
+	}
+
+	if d.Action == DetectNone {
+		return nil, nil
+	}
+
+	result := input.Clone()
+
+	if err := d.apply(&result); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// cascade is a loaded classifier. A classifier is not safe for concurrent use, so the jobs detecting with it take turns.
+type cascade struct {
+	mu         sync.Mutex
+	classifier gocv.CascadeClassifier
+}
+
+// cascades holds every cascade loaded so far by its path, they are kept for as long as the server runs.
+var cascades = struct {
+	sync.Mutex
+	loaded map[string]*cascade
+}{loaded: map[string]*cascade{}}
+
+// loadCascade returns the cascade at path, it is only read from disk the first time. Failed loads are not kept,
+// a cascade that is fixed later is loaded by the next job.
+func loadCascade(path string) (*cascade, error) {
+	cascades.Lock()
+	defer cascades.Unlock()
+
+	if loaded, ok := cascades.loaded[path]; ok {
+		return loaded, nil
+	}
+
+	classifier := gocv.NewCascadeClassifier()
+	if !classifier.Load(path) {
+		classifier.Close()
+		return nil, fmt.Errorf("failed to load cascade %s", path)
+	}
+
+	loaded := &cascade{classifier: classifier}
+	cascades.loaded[path] = loaded
+	return loaded, nil
+}
+
+func (c *cascade) detect(gray gocv.Mat, scaleFactor float64, minNeighbors int) []image.Rectangle {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.classifier.DetectMultiScaleWithParams(gray, scaleFactor, minNeighbors, 0, image.Point{}, image.Point{})
+}
+
+// Alpha is AlphaReplace, drawn boxes and overlays are opaque while blurring and pixelating move alpha along.
+func (_ *Detect) Alpha() AlphaBehaviour { return AlphaReplace }
+
+func (_ *Detect) Priority() Priority { return PriorityLow }
+
+// apply runs the action on every detected region of the image.
+func (d *Detect) apply(img *gocv.Mat) error {
+
+	var overlay gocv.Mat
+
+	if d.Action == DetectOverlay {
+		overlay = gocv.IMRead(d.OverlayPath, gocv.IMReadUnchanged)
+		if overlay.Empty() {
+			overlay.Close()
+			return fmt.Errorf("failed to load overlay %s", d.OverlayPath)
+		}
+		defer overlay.Close()
+	}
+
+	for _, box := range d.Boxes {
+
+		if d.Action == DetectDraw {
+			thickness := 2
+			gocv.Rectangle(img, box.rect(), color.RGBA{0, 255, 0, 255}, thickness)
+			continue
+		}
+
+		roi := img.Region(box.rect())
+
+		var err error
+		switch d.Action {
+		case DetectBlur:
+			err = blurRegion(&roi)
+		case DetectPixelate:
+			err = pixelateRegion(&roi)
+		case DetectOverlay:
+			err = pasteOverlay(&roi, overlay)
+		}
+
+		roi.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// blurRegion blurs a region strongly enough to hide faces, scaling the blur with its size.
+func blurRegion(roi *gocv.Mat) error {
+
+	ksize := max(roi.Cols(), roi.Rows())/4*2 + 1
+
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+
+	if err := gocv.GaussianBlur(*roi, &blurred, image.Point{X: ksize, Y: ksize}, 0, 0, gocv.BorderDefault); err != nil {
+		return err
+	}
+
+	return blurred.CopyTo(roi)
+}
+
+// pixelateRegion replaces a region with a coarse grid of blocks.
+func pixelateRegion(roi *gocv.Mat) error {
+
+	blocks := 12
+
+	small := gocv.NewMat()
+	defer small.Close()
+
+	size := image.Point{X: max(roi.Cols()/blocks, 1), Y: max(roi.Rows()/blocks, 1)}
+	if err := gocv.Resize(*roi, &small, size, 0, 0, gocv.InterpolationLinear); err != nil {
+		return err
+	}
+
+	pixelated := gocv.NewMat()
+	defer pixelated.Close()
+
+	if err := gocv.Resize(small, &pixelated, image.Point{X: roi.Cols(), Y: roi.Rows()}, 0, 0, gocv.InterpolationNearestNeighbor); err != nil {
+		return err
+	}
+
+	return pixelated.CopyTo(roi)
+}
+
+// pasteOverlay stretches the overlay over the region. Transparent overlay pixels are skipped,
+// so overlays like sunglasses only cover the parts of the region they draw on.
+func pasteOverlay(roi *gocv.Mat, overlay gocv.Mat) error {
+
+	resized := gocv.NewMat()
+	defer resized.Close()
+
+	if err := gocv.Resize(overlay, &resized, image.Point{X: roi.Cols(), Y: roi.Rows()}, 0, 0, gocv.InterpolationLinear); err != nil {
+		return err
+	}
+
+	mask := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 0, 0, 0), roi.Rows(), roi.Cols(), gocv.MatTypeCV8UC1)
+	defer mask.Close()
+
+	if resized.Channels() == 4 {
+		alpha := gocv.NewMat()
+		defer alpha.Close()
+
+		if err := gocv.ExtractChannel(resized, &alpha, 3); err != nil {
+			return err
+		}
+
+		gocv.Threshold(alpha, &mask, 127, 255, gocv.ThresholdBinary)
+	}
+
+	converted, err := convertChannels(resized, roi.Channels())
+	if err != nil {
+		return err
+	}
+	defer converted.Close()
+
+	return converted.CopyToWithMask(roi, mask)
+}
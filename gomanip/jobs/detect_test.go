@@ -0,0 +1,177 @@
+package jobs
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gocv.io/x/gocv"
+	"image"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// frontalFaceCascade ships with OpenCV, and is copied into the test image.
+const frontalFaceCascade = "/usr/local/share/opencv4/haarcascades/haarcascade_frontalface_default.xml"
+
+func TestDetectValidation(t *testing.T) {
+
+	testImage := gocv.NewMatWithSize(64, 64, gocv.MatTypeCV8UC3)
+	defer testImage.Close()
+
+	tests := []struct {
+		name  string
+		image *gocv.Mat
+		op    Detect
+	}{
+		{
+			name:  "Handle Nil image case",
+			image: nil,
+			op:    Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1},
+		},
+		{
+			name:  "Handle missing cascade",
+			image: &testImage,
+			op:    Detect{ScaleFactor: 1.1},
+		},
+		{
+			name:  "Handle cascade that does not exist",
+			image: &testImage,
+			op:    Detect{CascadePath: filepath.Join(t.TempDir(), "missing.xml"), ScaleFactor: 1.1},
+		},
+		{
+			name:  "Handle scale factor <= 1",
+			image: &testImage,
+			op:    Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.0},
+		},
+		{
+			name:  "Handle negative min neighbors",
+			image: &testImage,
+			op:    Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, MinNeighbors: -1},
+		},
+		{
+			name:  "Handle invalid action",
+			image: &testImage,
+			op:    Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, Action: "explode"},
+		},
+		{
+			name:  "Handle overlay action without overlay",
+			image: &testImage,
+			op:    Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, Action: DetectOverlay},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.op.Run(tt.image)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+
+	if _, err := os.Stat(frontalFaceCascade); err != nil {
+		t.Skipf("cascade not available: %v", err)
+	}
+
+	grayImage := gocv.NewMatWithSize(128, 128, gocv.MatTypeCV8UC1)
+	defer grayImage.Close()
+	colorImage := gocv.NewMatWithSize(128, 96, gocv.MatTypeCV8UC3)
+	defer colorImage.Close()
+	alphaImage := gocv.NewMatWithSize(96, 128, gocv.MatTypeCV8UC4)
+	defer alphaImage.Close()
+
+	for _, img := range []*gocv.Mat{&grayImage, &colorImage, &alphaImage} {
+
+		report := Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, MinNeighbors: 3}
+		result, err := report.Run(img)
+		assert.Nil(t, err)
+		assert.Nil(t, result, "expected no image when no action is given")
+		assert.NotNil(t, report.Boxes)
+
+		draw := Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, MinNeighbors: 3, Action: DetectDraw}
+		result, err = draw.Run(img)
+		assert.Nil(t, err)
+		if assert.NotNil(t, result) {
+			assert.Equal(t, img.Rows(), result.Rows())
+			assert.Equal(t, img.Cols(), result.Cols())
+			assert.Equal(t, img.Channels(), result.Channels())
+			result.Close()
+		}
+	}
+}
+
+func TestDetectSharesCascade(t *testing.T) {
+
+	if _, err := os.Stat(frontalFaceCascade); err != nil {
+		t.Skipf("cascade not available: %v", err)
+	}
+
+	first, err := loadCascade(frontalFaceCascade)
+	assert.Nil(t, err)
+	second, err := loadCascade(frontalFaceCascade)
+	assert.Nil(t, err)
+	assert.Same(t, first, second, "expected the cascade to be loaded once")
+
+	img := gocv.NewMatWithSize(128, 128, gocv.MatTypeCV8UC3)
+	defer img.Close()
+
+	// run with -race, jobs on several workers detect with the same classifier
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			detect := Detect{CascadePath: frontalFaceCascade, ScaleFactor: 1.1, MinNeighbors: 3}
+			_, err := detect.Run(&img)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestDetectActions(t *testing.T) {
+
+	overlay := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 255, 255), 10, 20, gocv.MatTypeCV8UC4)
+	overlayPath := filepath.Join(t.TempDir(), "overlay.png")
+	assert.True(t, gocv.IMWrite(overlayPath, overlay))
+	overlay.Close()
+
+	boxes := []Box{{X: 4, Y: 4, Width: 24, Height: 16}, {X: 30, Y: 20, Width: 3, Height: 3}}
+
+	tests := []struct {
+		name    string
+		matType gocv.MatType
+		op      Detect
+	}{
+		{name: "draw", matType: gocv.MatTypeCV8UC3, op: Detect{Action: DetectDraw, Boxes: boxes}},
+		{name: "blur", matType: gocv.MatTypeCV8UC3, op: Detect{Action: DetectBlur, Boxes: boxes}},
+		{name: "pixelate gray", matType: gocv.MatTypeCV8UC1, op: Detect{Action: DetectPixelate, Boxes: boxes}},
+		{name: "pixelate", matType: gocv.MatTypeCV8UC3, op: Detect{Action: DetectPixelate, Boxes: boxes}},
+		{name: "overlay", matType: gocv.MatTypeCV8UC3, op: Detect{Action: DetectOverlay, OverlayPath: overlayPath, Boxes: boxes}},
+		{name: "overlay with alpha", matType: gocv.MatTypeCV8UC4, op: Detect{Action: DetectOverlay, OverlayPath: overlayPath, Boxes: boxes}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := gocv.NewMatWithSize(48, 48, tt.matType)
+			defer img.Close()
+			rng := gocv.TheRNG()
+			rng.Fill(&img, gocv.RNGDistUniform, 0, 255, false)
+
+			assert.Nil(t, tt.op.apply(&img))
+
+			if tt.op.Action == DetectOverlay {
+				// the overlay is opaque red, so the first box must now be red
+				region := img.Region(image.Rect(4, 4, 28, 20))
+				assert.Equal(t, 255.0, region.Mean().Val3)
+				region.Close()
+			}
+		})
+	}
+
+	missing := Detect{Action: DetectOverlay, OverlayPath: filepath.Join(t.TempDir(), "missing.png"), Boxes: boxes}
+	img := gocv.NewMatWithSize(48, 48, gocv.MatTypeCV8UC3)
+	defer img.Close()
+	assert.Error(t, missing.apply(&img))
+}
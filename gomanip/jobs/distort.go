@@ -0,0 +1,203 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image/color"
+	"math"
+)
+
+// sourceMapping gives the coordinates in the input that a pixel of the output is sampled from.
+type sourceMapping func(x, y float64) (float64, float64)
+
+// remap builds the sampling maps for mapping and warps the input with them.
+func remap(input gocv.Mat, mapping sourceMapping) (*gocv.Mat, error) {
+
+	rows, cols := input.Rows(), input.Cols()
+
+	mapX := gocv.NewMatWithSize(rows, cols, gocv.MatTypeCV32F)
+	defer mapX.Close()
+	mapY := gocv.NewMatWithSize(rows, cols, gocv.MatTypeCV32F)
+	defer mapY.Close()
+
+	xs, err := mapX.DataPtrFloat32()
+	if err != nil {
+		return nil, err
+	}
+	ys, err := mapY.DataPtrFloat32()
+	if err != nil {
+		return nil, err
+	}
+
+	for row := range rows {
+		for col := range cols {
+			sx, sy := mapping(float64(col), float64(row))
+			xs[row*cols+col] = float32(sx)
+			ys[row*cols+col] = float32(sy)
+		}
+	}
+
+	result := gocv.NewMat()
+	if err := gocv.Remap(input, &result, &mapX, &mapY, gocv.InterpolationLinear, gocv.BorderReflect, color.RGBA{}); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// validateCenter checks the x and y percentages of an effect's center.
+func validateCenter(input *gocv.Mat, x, y float64) error {
+
+	if input == nil || input.Empty() {
+		return errors.New("input image is empty")
+	}
+
+	if x < 0.0 || y < 0.0 || x > 1.0 || y > 1.0 {
+		return fmt.Errorf("expected x and y percentages to be between 0 and 1, got %0.2f, %0.2f", x, y)
+	}
+
+	return nil
+}
+
+// Swirl rotates the pixels within Radius of the center, by Strength radians at the center fading to nothing at the edge.
+// X and Y are percentages along the width and height, Radius is a percentage of the smaller side.
+type Swirl struct {
+	X        float64
+	Y        float64
+	Radius   float64
+	Strength float64
+}
+
+func (s *Swirl) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateCenter(input, s.X, s.Y); err != nil {
+		return nil, err
+	}
+
+	if s.Radius <= 0.0 || s.Radius > 1.0 {
+		return nil, fmt.Errorf("expected radius to be greater than 0 and at most 1, got %0.2f", s.Radius)
+	}
+
+	cx, cy := s.X*float64(input.Cols()), s.Y*float64(input.Rows())
+	radius := s.Radius * float64(min(input.Rows(), input.Cols()))
+
+	return remap(*input, func(x, y float64) (float64, float64) {
+		dx, dy := x-cx, y-cy
+		distance := math.Hypot(dx, dy)
+		if distance >= radius {
+			return x, y
+		}
+
+		falloff := 1 - distance/radius
+		angle := s.Strength * falloff * falloff
+		sin, cos := math.Sincos(angle)
+
+		return cx + dx*cos - dy*sin, cy + dx*sin + dy*cos
+	})
+}
+
+func (_ *Swirl) Alpha() AlphaBehaviour { return AlphaTransform }
+
+// Bulge magnifies the area within Radius of the center when Strength is positive, and pinches it when negative.
+// X and Y are percentages along the width and height, Radius is a percentage of the smaller side.
+type Bulge struct {
+	X        float64
+	Y        float64
+	Radius   float64
+	Strength float64
+}
+
+func (b *Bulge) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateCenter(input, b.X, b.Y); err != nil {
+		return nil, err
+	}
+
+	if b.Radius <= 0.0 || b.Radius > 1.0 {
+		return nil, fmt.Errorf("expected radius to be greater than 0 and at most 1, got %0.2f", b.Radius)
+	}
+
+	if b.Strength < -1.0 || b.Strength > 1.0 || b.Strength == 0.0 {
+		return nil, fmt.Errorf("expected strength to be between -1 and 1 and not 0, got %0.2f", b.Strength)
+	}
+
+	cx, cy := b.X*float64(input.Cols()), b.Y*float64(input.Rows())
+	radius := b.Radius * float64(min(input.Rows(), input.Cols()))
+
+	return remap(*input, func(x, y float64) (float64, float64) {
+		dx, dy := x-cx, y-cy
+		distance := math.Hypot(dx, dy)
+		if distance >= radius {
+			return x, y
+		}
+
+		// sampling closer to the center magnifies it, the scale reaches 1 at the edge so there is no seam
+		falloff := 1 - distance/radius
+		scale := 1 - b.Strength*falloff*falloff
+
+		return cx + dx*scale, cy + dy*scale
+	})
+}
+
+func (_ *Bulge) Alpha() AlphaBehaviour { return AlphaTransform }
+
+// Wave displaces every pixel along a sine wave, rows shift horizontally and columns vertically.
+// Amplitude and Wavelength are in pixels.
+type Wave struct {
+	Amplitude  float64
+	Wavelength float64
+}
+
+func (w *Wave) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	if w.Amplitude <= 0.0 || w.Wavelength <= 0.0 {
+		return nil, fmt.Errorf("expected amplitude and wavelength to be greater than 0, got %0.2f and %0.2f", w.Amplitude, w.Wavelength)
+	}
+
+	frequency := 2 * math.Pi / w.Wavelength
+
+	return remap(*input, func(x, y float64) (float64, float64) {
+		return x + w.Amplitude*math.Sin(y*frequency), y + w.Amplitude*math.Sin(x*frequency)
+	})
+}
+
+func (_ *Wave) Alpha() AlphaBehaviour { return AlphaTransform }
+
+// Fisheye applies barrel distortion around the center, magnifying it while squeezing the edges.
+// A negative Strength gives pincushion distortion instead. X and Y are percentages along the width and height.
+type Fisheye struct {
+	X        float64
+	Y        float64
+	Strength float64
+}
+
+func (f *Fisheye) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateCenter(input, f.X, f.Y); err != nil {
+		return nil, err
+	}
+
+	if f.Strength <= -1.0 || f.Strength > 1.0 || f.Strength == 0.0 {
+		return nil, fmt.Errorf("expected strength to be greater than -1, at most 1 and not 0, got %0.2f", f.Strength)
+	}
+
+	cx, cy := f.X*float64(input.Cols()), f.Y*float64(input.Rows())
+	// distances are normalized by half the diagonal so the corners stay in place
+	norm := math.Hypot(float64(input.Cols()), float64(input.Rows())) / 2
+
+	return remap(*input, func(x, y float64) (float64, float64) {
+		dx, dy := (x-cx)/norm, (y-cy)/norm
+		r2 := dx*dx + dy*dy
+		scale := (1 + f.Strength*r2) / (1 + f.Strength)
+
+		return cx + dx*scale*norm, cy + dy*scale*norm
+	})
+}
+
+func (_ *Fisheye) Alpha() AlphaBehaviour { return AlphaTransform }
@@ -0,0 +1,145 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"testing"
+)
+
+func TestDistortions(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.Operation
+	}{
+		{
+			name:      "swirl with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewSwirl(0.5, 0.5, 0.5, 4),
+		},
+		{
+			name:      "bulge with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewBulge(0.3, 0.6, 0.4, 0.8),
+		},
+		{
+			name:      "pinch with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewBulge(0.5, 0.5, 1, -1),
+		},
+		{
+			name:      "wave with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewWave(10, 60),
+		},
+		{
+			name:      "fisheye with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewFisheye(0.5, 0.5, 0.5),
+		},
+		{
+			name:      "pincushion with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewFisheye(0, 1, -0.5),
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.NewSwirl(0.5, 0.5, 0.5, 4),
+		},
+		{
+			name:      "Handle swirl center outside the image",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewSwirl(1.5, 0.5, 0.5, 4),
+		},
+		{
+			name:      "Handle swirl invalid radius",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewSwirl(0.5, 0.5, 0, 4),
+		},
+		{
+			name:      "Handle bulge invalid strength",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewBulge(0.5, 0.5, 0.5, 2),
+		},
+		{
+			name:      "Handle wave invalid wavelength",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewWave(10, 0),
+		},
+		{
+			name:      "Handle fisheye invalid strength",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewFisheye(0.5, 0.5, -1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+				result, err := tt.op.Run(image)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				assert.Equal(t, image.Rows(), result.Rows())
+				assert.Equal(t, image.Cols(), result.Cols())
+				assert.Equal(t, image.Channels(), result.Channels())
+				result.Close()
+			}
+		})
+	}
+}
+
+func TestDistortionsKeepOutsideRadius(t *testing.T) {
+
+	original := gradientImage(200, 200)
+	defer original.Close()
+
+	// the corner is outside a quarter radius around the middle
+	corner := image.Rect(0, 0, 50, 50)
+	originalCorner := original.Region(corner)
+	defer originalCorner.Close()
+
+	for _, op := range []jobs.Operation{jobs.NewSwirl(0.5, 0.5, 0.25, 6), jobs.NewBulge(0.5, 0.5, 0.25, 1)} {
+		result, err := op.Run(&original)
+		assert.NoError(t, err)
+
+		resultCorner := result.Region(corner)
+		assert.InDelta(t, 1.0, similarity(t, &originalCorner, &resultCorner).SSIM, 1e-6)
+		assert.Less(t, similarity(t, &original, result).SSIM, 1.0)
+
+		resultCorner.Close()
+		result.Close()
+	}
+}
+
+func TestSubtleDistortionsStayClose(t *testing.T) {
+
+	original := gradientImage(200, 200)
+	defer original.Close()
+
+	for _, op := range []jobs.Operation{jobs.NewWave(0.5, 100), jobs.NewFisheye(0.5, 0.5, 0.02)} {
+		result, err := op.Run(&original)
+		assert.NoError(t, err)
+		assert.Greater(t, similarity(t, &original, result).SSIM, 0.9)
+		result.Close()
+	}
+}
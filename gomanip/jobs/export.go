@@ -0,0 +1,185 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math"
+)
+
+// ExportTarget is the size and file size an exported image has to fit within.
+type ExportTarget struct {
+	Width    int
+	Height   int
+	MaxBytes int
+}
+
+// ExportTargets are the limits Discord places on uploaded emoji and stickers.
+var ExportTargets = map[string]ExportTarget{
+	"emoji":   {Width: 128, Height: 128, MaxBytes: 256 * 1024},
+	"sticker": {Width: 320, Height: 320, MaxBytes: 512 * 1024},
+}
+
+type FitMode string
+
+const (
+	// FitPad scales the whole image into the target, padding the rest with transparent pixels.
+	FitPad FitMode = "pad"
+	// FitCrop scales the image to cover the target and crops off what does not fit around the center.
+	FitCrop FitMode = "crop"
+)
+
+const (
+	// exported images are never shrunk below this to fit the byte budget
+	minExportSide = 32
+	// each step shrinks the image to this fraction of its size
+	exportShrinkStep = 0.8
+)
+
+// exportLevels are the color levels per channel tried in turn, fewer levels compress better.
+var exportLevels = []int{256, 64, 32, 16, 8}
+
+// Export fits an image to Target, first scaling it with Fit, then reducing colors and finally shrinking it
+// until the png is no larger than Target.MaxBytes. Run fills in PNG and returns a nil image.
+type Export struct {
+	Target ExportTarget
+	Fit    FitMode
+
+	// PNG is filled in by Run with the encoded image.
+	PNG []byte
+}
+
+func (e *Export) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	if gocv.MatType(input.Type()&7) != gocv.MatTypeCV8U {
+		return nil, errors.New("expected an 8 bit image")
+	}
+
+	if e.Target.Width <= 0 || e.Target.Height <= 0 || e.Target.MaxBytes <= 0 {
+		return nil, fmt.Errorf("expected a positive target size and byte budget, got %dx%d and %d bytes", e.Target.Width, e.Target.Height, e.Target.MaxBytes)
+	}
+
+	if e.Fit != FitPad && e.Fit != FitCrop {
+		return nil, fmt.Errorf("invalid fit %s", e.Fit)
+	}
+
+	bgra, err := convertChannels(*input, 4)
+	if err != nil {
+		return nil, err
+	}
+	defer bgra.Close()
+
+	width, height := e.Target.Width, e.Target.Height
+
+	for {
+		fitted, err := fitToSize(bgra, width, height, e.Fit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, levels := range exportLevels {
+			encoded, err := encodePosterized(fitted, levels)
+			if err != nil {
+				fitted.Close()
+				return nil, err
+			}
+
+			if len(encoded) <= e.Target.MaxBytes {
+				fitted.Close()
+				e.PNG = encoded
+				return nil, nil
+			}
+		}
+
+		fitted.Close()
+
+		width, height = int(float64(width)*exportShrinkStep), int(float64(height)*exportShrinkStep)
+		if min(width, height) < minExportSide {
+			return nil, fmt.Errorf("cannot fit the image into %d bytes", e.Target.MaxBytes)
+		}
+	}
+}
+
+// Alpha is AlphaReplace, padding is transparent.
+func (_ *Export) Alpha() AlphaBehaviour { return AlphaReplace }
+
+func (_ *Export) Priority() Priority { return PriorityLow }
+
+// fitToSize scales a 4 channel image to exactly width by height, padding or cropping depending on fit.
+func fitToSize(input gocv.Mat, width, height int, fit FitMode) (gocv.Mat, error) {
+
+	scaleX, scaleY := float64(width)/float64(input.Cols()), float64(height)/float64(input.Rows())
+	scale := math.Min(scaleX, scaleY)
+	if fit == FitCrop {
+		scale = math.Max(scaleX, scaleY)
+	}
+
+	size := image.Pt(max(1, int(math.Round(float64(input.Cols())*scale))), max(1, int(math.Round(float64(input.Rows())*scale))))
+
+	interpolation := gocv.InterpolationArea
+	if scale > 1 {
+		interpolation = gocv.InterpolationCubic
+	}
+
+	scaled := gocv.NewMat()
+	defer scaled.Close()
+	if err := gocv.Resize(input, &scaled, size, 0, 0, interpolation); err != nil {
+		return gocv.Mat{}, err
+	}
+
+	if fit == FitCrop {
+		left, top := (size.X-width)/2, (size.Y-height)/2
+		region := scaled.Region(image.Rect(left, top, left+width, top+height))
+		defer region.Close()
+		return region.Clone(), nil
+	}
+
+	canvas := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(0, 0, 0, 0), height, width, gocv.MatTypeCV8UC4)
+	left, top := (width-size.X)/2, (height-size.Y)/2
+	region := canvas.Region(image.Rect(left, top, left+size.X, top+size.Y))
+	defer region.Close()
+
+	if err := scaled.CopyTo(&region); err != nil {
+		canvas.Close()
+		return gocv.Mat{}, err
+	}
+
+	return canvas, nil
+}
+
+// encodePosterized reduces the color channels of a 4 channel image to the given number of levels
+// and encodes it as a maximally compressed png.
+func encodePosterized(input gocv.Mat, levels int) ([]byte, error) {
+
+	posterized := input.Clone()
+	defer posterized.Close()
+
+	if levels < 256 {
+		data, err := posterized.DataPtrUint8()
+		if err != nil {
+			return nil, err
+		}
+
+		step := 255 / float64(levels-1)
+		for idx := range data {
+			// the alpha channel is left alone
+			if idx%4 == 3 {
+				continue
+			}
+			data[idx] = uint8(math.Round(math.Round(float64(data[idx])/step) * step))
+		}
+	}
+
+	buffer, err := gocv.IMEncodeWithParams(gocv.PNGFileExt, posterized, []int{gocv.IMWritePngCompression, 9})
+	if err != nil {
+		return nil, err
+	}
+	defer buffer.Close()
+
+	return append([]byte(nil), buffer.GetBytes()...), nil
+}
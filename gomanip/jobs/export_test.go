@@ -0,0 +1,119 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"testing"
+)
+
+// decodePNG decodes an exported png keeping its alpha channel.
+func decodePNG(t *testing.T, encoded []byte) gocv.Mat {
+	t.Helper()
+
+	decoded, err := gocv.IMDecode(encoded, gocv.IMReadUnchanged)
+	assert.NoError(t, err)
+	assert.False(t, decoded.Empty())
+	return decoded
+}
+
+func TestExportFit(t *testing.T) {
+
+	wide := gradientImage(300, 100)
+	defer wide.Close()
+
+	padded, err := jobs.NewExport("emoji", jobs.FitPad)
+	assert.NoError(t, err)
+	_, err = padded.Run(&wide)
+	assert.NoError(t, err)
+
+	result := decodePNG(t, padded.PNG)
+	assert.Equal(t, 128, result.Cols())
+	assert.Equal(t, 128, result.Rows())
+	assert.Equal(t, 4, result.Channels())
+	// the wide image is letterboxed, so the top and bottom are transparent
+	assert.Equal(t, uint8(0), result.GetVecbAt(0, 64)[3])
+	assert.Equal(t, uint8(0), result.GetVecbAt(127, 64)[3])
+	assert.Equal(t, uint8(255), result.GetVecbAt(64, 64)[3])
+	result.Close()
+
+	cropped, err := jobs.NewExport("sticker", jobs.FitCrop)
+	assert.NoError(t, err)
+	_, err = cropped.Run(&wide)
+	assert.NoError(t, err)
+
+	result = decodePNG(t, cropped.PNG)
+	assert.Equal(t, 320, result.Cols())
+	assert.Equal(t, 320, result.Rows())
+	for _, point := range [][2]int{{0, 0}, {319, 0}, {0, 319}, {160, 160}} {
+		assert.Equal(t, uint8(255), result.GetVecbAt(point[0], point[1])[3])
+	}
+	result.Close()
+}
+
+func TestExportByteBudget(t *testing.T) {
+
+	// noise barely compresses, so it has to lose colors and shrink to fit
+	gocv.SetRNGSeed(7)
+	noise := gocv.NewMatWithSize(400, 400, gocv.MatTypeCV8UC3)
+	defer noise.Close()
+	rng := gocv.TheRNG()
+	rng.Fill(&noise, gocv.RNGDistUniform, 0, 255, false)
+
+	budget := 20 * 1024
+	export := &jobs.Export{Target: jobs.ExportTarget{Width: 320, Height: 320, MaxBytes: budget}, Fit: jobs.FitCrop}
+	_, err := export.Run(&noise)
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, len(export.PNG), budget)
+
+	result := decodePNG(t, export.PNG)
+	assert.Less(t, result.Cols(), 320)
+	assert.Equal(t, result.Cols(), result.Rows())
+	assert.GreaterOrEqual(t, result.Cols(), 32)
+	result.Close()
+
+	// a small image that already fits is kept as it is
+	gradient := gradientImage(128, 128)
+	defer gradient.Close()
+	export = &jobs.Export{Target: jobs.ExportTarget{Width: 128, Height: 128, MaxBytes: 256 * 1024}, Fit: jobs.FitPad}
+	_, err = export.Run(&gradient)
+	assert.NoError(t, err)
+
+	result = decodePNG(t, export.PNG)
+	defer result.Close()
+	assert.Equal(t, 128, result.Cols())
+
+	bgr := gocv.NewMat()
+	defer bgr.Close()
+	gocv.CvtColor(result, &bgr, gocv.ColorBGRAToBGR)
+	assert.True(t, identical(t, &gradient, &bgr))
+}
+
+func TestExportErrors(t *testing.T) {
+
+	original := gradientImage(64, 64)
+	defer original.Close()
+
+	_, err := jobs.NewExport("banner", jobs.FitPad)
+	assert.Error(t, err)
+
+	tests := []struct {
+		name  string
+		image *gocv.Mat
+		op    *jobs.Export
+	}{
+		{name: "Handle Nil image case", image: nil, op: &jobs.Export{Target: jobs.ExportTargets["emoji"], Fit: jobs.FitPad}},
+		{name: "Handle invalid fit", image: &original, op: &jobs.Export{Target: jobs.ExportTargets["emoji"], Fit: "stretch"}},
+		{name: "Handle invalid target", image: &original, op: &jobs.Export{Target: jobs.ExportTarget{Width: 0, Height: 10, MaxBytes: 10}, Fit: jobs.FitPad}},
+		{name: "Handle budget that cannot be met", image: &original, op: &jobs.Export{Target: jobs.ExportTarget{Width: 64, Height: 64, MaxBytes: 10}, Fit: jobs.FitPad}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.op.Run(tt.image)
+			assert.Nil(t, result)
+			assert.Error(t, err)
+			assert.Nil(t, tt.op.PNG)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math/bits"
+	"sort"
+)
+
+// ImageHashes holds the 64 bit perceptual hashes of an image as hex strings.
+// Similar images have hashes with a small Hamming distance.
+type ImageHashes struct {
+	AHash string `json:"aHash"`
+	DHash string `json:"dHash"`
+	PHash string `json:"pHash"`
+}
+
+// HammingDistance counts the bits that differ between two hashes.
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FormatHash formats a hash the way it appears in ImageHashes.
+func FormatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// grayThumbnail shrinks the input to a single channel cols by rows thumbnail.
+func grayThumbnail(input gocv.Mat, cols, rows int) (gocv.Mat, error) {
+
+	if input.Empty() {
+		return gocv.Mat{}, errors.New("input image is empty")
+	}
+
+	gray, err := convertChannels(input, 1)
+	if err != nil {
+		return gocv.Mat{}, err
+	}
+	defer gray.Close()
+
+	thumbnail := gocv.NewMat()
+	if err := gocv.Resize(gray, &thumbnail, image.Point{X: cols, Y: rows}, 0, 0, gocv.InterpolationArea); err != nil {
+		thumbnail.Close()
+		return gocv.Mat{}, err
+	}
+
+	return thumbnail, nil
+}
+
+// AverageHash sets a bit for every pixel of an 8x8 thumbnail that is brighter than the thumbnail's mean.
+func AverageHash(input gocv.Mat) (uint64, error) {
+
+	thumbnail, err := grayThumbnail(input, 8, 8)
+	if err != nil {
+		return 0, err
+	}
+	defer thumbnail.Close()
+
+	mean := thumbnail.Mean().Val1
+
+	var hash uint64
+	for row := range 8 {
+		for col := range 8 {
+			hash <<= 1
+			if float64(thumbnail.GetUCharAt(row, col)) > mean {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash, nil
+}
+
+// DifferenceHash sets a bit for every pixel of a 9x8 thumbnail that is brighter than its right neighbour.
+func DifferenceHash(input gocv.Mat) (uint64, error) {
+
+	thumbnail, err := grayThumbnail(input, 9, 8)
+	if err != nil {
+		return 0, err
+	}
+	defer thumbnail.Close()
+
+	var hash uint64
+	for row := range 8 {
+		for col := range 8 {
+			hash <<= 1
+			if thumbnail.GetUCharAt(row, col) > thumbnail.GetUCharAt(row, col+1) {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash, nil
+}
+
+// PerceptualHash sets a bit for each of the 8x8 lowest frequency DCT coefficients of a 32x32 thumbnail
+// that is above their median, which makes it robust against scaling, compression and small edits.
+func PerceptualHash(input gocv.Mat) (uint64, error) {
+
+	thumbnail, err := grayThumbnail(input, 32, 32)
+	if err != nil {
+		return 0, err
+	}
+	defer thumbnail.Close()
+
+	floats := gocv.NewMat()
+	defer floats.Close()
+	if err := thumbnail.ConvertTo(&floats, gocv.MatTypeCV32F); err != nil {
+		return 0, err
+	}
+
+	dct := gocv.NewMat()
+	defer dct.Close()
+	if err := gocv.DCT(floats, &dct, gocv.DftForward); err != nil {
+		return 0, err
+	}
+
+	coefficients := make([]float32, 0, 64)
+	for row := range 8 {
+		for col := range 8 {
+			coefficients = append(coefficients, dct.GetFloatAt(row, col))
+		}
+	}
+
+	// the DC term is the average brightness, and would skew the median
+	sorted := append([]float32{}, coefficients[1:]...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	median := sorted[len(sorted)/2]
+
+	var hash uint64
+	for _, coefficient := range coefficients {
+		hash <<= 1
+		if coefficient > median {
+			hash |= 1
+		}
+	}
+
+	return hash, nil
+}
+
+// computeHashes runs every hash on the input.
+func computeHashes(input gocv.Mat) (ImageHashes, error) {
+
+	aHash, err := AverageHash(input)
+	if err != nil {
+		return ImageHashes{}, err
+	}
+
+	dHash, err := DifferenceHash(input)
+	if err != nil {
+		return ImageHashes{}, err
+	}
+
+	pHash, err := PerceptualHash(input)
+	if err != nil {
+		return ImageHashes{}, err
+	}
+
+	return ImageHashes{AHash: FormatHash(aHash), DHash: FormatHash(dHash), PHash: FormatHash(pHash)}, nil
+}
@@ -0,0 +1,74 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"image/color"
+	"testing"
+)
+
+// gradientImage draws a horizontal gradient with a bright square, enough structure for every hash to pick up.
+func gradientImage(width, height int) gocv.Mat {
+	mat := gocv.NewMatWithSize(height, width, gocv.MatTypeCV8UC3)
+	for col := range width {
+		shade := uint8(col * 255 / width)
+		gocv.Line(&mat, image.Pt(col, 0), image.Pt(col, height-1), color.RGBA{R: shade, G: shade / 2, B: 255 - shade}, 1)
+	}
+	gocv.Rectangle(&mat, image.Rect(width/4, height/4, width/2, height/2), color.RGBA{R: 255, G: 255, B: 255}, -1)
+	return mat
+}
+
+func TestHammingDistance(t *testing.T) {
+	assert.Equal(t, 0, jobs.HammingDistance(0xff, 0xff))
+	assert.Equal(t, 8, jobs.HammingDistance(0xff, 0x00))
+	assert.Equal(t, 64, jobs.HammingDistance(0, ^uint64(0)))
+	assert.Equal(t, "00000000000000ff", jobs.FormatHash(0xff))
+}
+
+func TestHashes(t *testing.T) {
+	hashes := []struct {
+		name string
+		hash func(gocv.Mat) (uint64, error)
+	}{
+		{name: "average hash", hash: jobs.AverageHash},
+		{name: "difference hash", hash: jobs.DifferenceHash},
+		{name: "perceptual hash", hash: jobs.PerceptualHash},
+	}
+
+	original := gradientImage(320, 240)
+	defer original.Close()
+
+	resized := gocv.NewMat()
+	defer resized.Close()
+	gocv.Resize(original, &resized, image.Pt(160, 120), 0, 0, gocv.InterpolationArea)
+
+	inverted := gocv.NewMat()
+	defer inverted.Close()
+	gocv.BitwiseNot(original, &inverted)
+
+	for _, tt := range hashes {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.hash(original)
+			assert.NoError(t, err)
+
+			again, err := tt.hash(original)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, jobs.HammingDistance(hash, again))
+
+			resizedHash, err := tt.hash(resized)
+			assert.NoError(t, err)
+			assert.LessOrEqual(t, jobs.HammingDistance(hash, resizedHash), 4)
+
+			invertedHash, err := tt.hash(inverted)
+			assert.NoError(t, err)
+			assert.Greater(t, jobs.HammingDistance(hash, invertedHash), 24)
+
+			empty := gocv.NewMat()
+			defer empty.Close()
+			_, err = tt.hash(empty)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -3,6 +3,8 @@ package jobs
 import (
 	"context"
 	"gocv.io/x/gocv"
+	"sync"
+	"time"
 )
 
 type Result struct {
@@ -14,12 +16,37 @@ type JobRequest struct {
 	Job    *Job
 	Result chan *Result
 	Ctx    context.Context
+	// Queued is when the request was made, the time until a worker picks it up is its queue wait
+	Queued time.Time
+	// Started is closed once a worker starts running the job
+	Started   chan struct{}
+	startOnce sync.Once
+	// Done is closed once the job no longer reads its Mats, either because it ran or because it was dropped
+	Done     chan struct{}
+	doneOnce sync.Once
+}
+
+// Start closes Started, it can be called more than once.
+func (j *JobRequest) Start() {
+	j.startOnce.Do(func() {
+		close(j.Started)
+	})
+}
+
+// Finish closes Done, it can be called more than once.
+func (j *JobRequest) Finish() {
+	j.doneOnce.Do(func() {
+		close(j.Done)
+	})
 }
 
 func NewJobRequest(job *Job, ctx context.Context) *JobRequest {
 	return &JobRequest{
-		Job:    job,
-		Result: make(chan *Result, 1),
-		Ctx:    ctx,
+		Job:     job,
+		Result:  make(chan *Result, 1),
+		Ctx:     ctx,
+		Queued:  time.Now(),
+		Started: make(chan struct{}),
+		Done:    make(chan struct{}),
 	}
 }
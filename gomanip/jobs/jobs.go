@@ -2,6 +2,9 @@ package jobs
 
 import (
 	"gocv.io/x/gocv"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -9,6 +12,20 @@ type Operation interface {
 	Run(input *gocv.Mat) (*gocv.Mat, error)
 }
 
+// NameOf returns the name of op's type starting in lower case, i.e randomFilter.
+func NameOf(op Operation) string {
+	opType := reflect.TypeOf(op)
+	for opType != nil && opType.Kind() == reflect.Pointer {
+		opType = opType.Elem()
+	}
+	if opType == nil || opType.Name() == "" {
+		return "operation"
+	}
+
+	name := opType.Name()
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
 func NewJob(id uint32, operation Operation, image *gocv.Mat) *Job {
 
 	return &Job{jobId: id, operation: operation, inputImage: image}
@@ -24,17 +41,72 @@ type Job struct {
 	startTime   time.Time
 	endTime     time.Time
 	elapsedTime time.Duration
+	panicErr    *PanicError
+	leakedMats  int
 }
 
-func (j *Job) Process() (*gocv.Mat, error) {
+// Process runs the job's operation. A panicking operation does not take the worker down, the job
+// fails with a *PanicError instead. Its input image is left to its owner like that of any other job.
+func (j *Job) Process() (result *gocv.Mat, err error) {
+	if MatTracking {
+		done := trackMats(j)
+		// deferred first so it runs last, after a panic was turned into the result
+		defer func() { done(result) }()
+	}
+
 	j.startTime = time.Now()
-	result, err := j.operation.Run(j.inputImage)
 
-	j.elapsedTime = time.Since(j.startTime)
+	defer func() {
+		j.elapsedTime = time.Since(j.startTime)
+
+		j.endTime = time.Now()
 
-	j.endTime = time.Now()
+		if value := recover(); value != nil {
+			j.panicErr = newPanicError(j, value)
+			result, err = nil, j.panicErr
+		}
+	}()
+
+	return j.operation.Run(j.inputImage)
+}
+
+// LeakedMats is how many Mats the job's operation left open, it is only counted when MatTracking is set.
+func (j *Job) LeakedMats() int {
+	return j.leakedMats
+}
+
+// Mats returns the input of the job and any Mat its operation holds, the job reads them until it finished.
+func (j *Job) Mats() []*gocv.Mat {
+	var mats []*gocv.Mat
+	if j.inputImage != nil {
+		mats = append(mats, j.inputImage)
+	}
+	if holder, ok := j.operation.(MatHolder); ok {
+		mats = append(mats, holder.HeldMats()...)
+	}
+	return mats
+}
+
+// HoldsMat reports whether mat is one of the job's Mats rather than a new one made by its operation.
+func (j *Job) HoldsMat(mat *gocv.Mat) bool {
+	return slices.Contains(j.Mats(), mat)
+}
+
+// Panic returns what the job's operation panicked with, or nil when it did not panic.
+func (j *Job) Panic() *PanicError {
+	return j.panicErr
+}
+
+func (j *Job) GetOperation() Operation {
+	return j.operation
+}
 
-	return result, err
+// Estimate is how long the job's operation is expected to run on its input.
+func (j *Job) Estimate() time.Duration {
+	if j.inputImage == nil {
+		return 0
+	}
+	return EstimateOf(j.operation, j.inputImage.Rows(), j.inputImage.Cols())
 }
 
 func (j *Job) GetJobId() uint32 {
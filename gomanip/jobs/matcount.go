@@ -0,0 +1,10 @@
+//go:build !matprofile
+
+package jobs
+
+// MatTracking is set when built with -tags matprofile, every job then counts the Mats its operation leaks.
+const MatTracking = false
+
+func liveMats() int {
+	return 0
+}
@@ -0,0 +1,12 @@
+//go:build matprofile
+
+package jobs
+
+import "gocv.io/x/gocv"
+
+// MatTracking is set when built with -tags matprofile, every job then counts the Mats its operation leaks.
+const MatTracking = true
+
+func liveMats() int {
+	return gocv.MatProfile.Count()
+}
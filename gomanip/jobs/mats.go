@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"gocv.io/x/gocv"
+)
+
+// MatHolder is implemented by operations that read Mats of their own besides the job's input, i.e the second image of a comparison.
+type MatHolder interface {
+	HeldMats() []*gocv.Mat
+}
+
+// MatLeaks counts the Mats an operation left open, only tracked when MatTracking is set.
+type MatLeaks struct {
+	Jobs       uint64 `json:"jobs"`
+	LeakyJobs  uint64 `json:"leakyJobs"`
+	LeakedMats int64  `json:"leakedMats"`
+}
+
+var matTracking = struct {
+	// run makes jobs take turns while tracking, the Mat count is process wide so a
+	// job running next to another would see the other's Mats as its own
+	run   sync.Mutex
+	mu    sync.Mutex
+	leaks map[string]MatLeaks
+}{leaks: map[string]MatLeaks{}}
+
+// MatLeakStats returns the leaks of every operation that ran since the process started, by operation name.
+func MatLeakStats() map[string]MatLeaks {
+	matTracking.mu.Lock()
+	defer matTracking.mu.Unlock()
+
+	stats := make(map[string]MatLeaks, len(matTracking.leaks))
+	for name, leaks := range matTracking.leaks {
+		stats[name] = leaks
+	}
+	return stats
+}
+
+// trackMats starts counting the Mats of job, the returned function ends the count once the job returned result.
+func trackMats(job *Job) func(result *gocv.Mat) {
+	matTracking.run.Lock()
+	before := liveMats()
+
+	return func(result *gocv.Mat) {
+		defer matTracking.run.Unlock()
+
+		expected := before
+		// the result is handed to the caller, it only counts when it is a new Mat
+		if result != nil && result != job.inputImage && !result.Closed() {
+			expected++
+		}
+
+		job.leakedMats = liveMats() - expected
+		recordLeaks(NameOf(job.operation), job.leakedMats)
+	}
+}
+
+func recordLeaks(operation string, leaked int) {
+	matTracking.mu.Lock()
+	leaks := matTracking.leaks[operation]
+	leaks.Jobs++
+	if leaked > 0 {
+		leaks.LeakyJobs++
+		leaks.LeakedMats += int64(leaked)
+	}
+	matTracking.leaks[operation] = leaks
+	matTracking.mu.Unlock()
+
+	if leaked > 0 {
+		log.Warn().Str("operation", operation).Int("mats", leaked).Msg("Operation leaked Mats")
+	}
+}
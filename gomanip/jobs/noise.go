@@ -0,0 +1,275 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math"
+	"math/rand"
+)
+
+// DefaultVignetteRadius is the radius used by the old photo preset.
+const DefaultVignetteRadius = 0.4
+
+// noiseRNG returns a generator for seed, a zero seed picks a random one.
+func noiseRNG(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// colorChannels is the number of channels noise is applied to, the alpha channel of a 4 channel image is left alone.
+func colorChannels(channels int) int {
+	if channels == 4 {
+		return 3
+	}
+	return channels
+}
+
+// validateNoiseInput checks that the input is an 8 bit image, which the noise operations write to directly.
+func validateNoiseInput(input *gocv.Mat) error {
+
+	if input == nil || input.Empty() {
+		return errors.New("input image is empty")
+	}
+
+	if gocv.MatType(input.Type()&7) != gocv.MatTypeCV8U {
+		return errors.New("expected an 8 bit image")
+	}
+
+	return nil
+}
+
+// floatPerChannel returns a zeroed float Mat with the size and channels of input, along with its data.
+func floatPerChannel(input gocv.Mat) (gocv.Mat, []float32, error) {
+
+	mat := gocv.Zeros(input.Rows(), input.Cols(), gocv.MatTypeCV32F+gocv.MatType((input.Channels()-1)<<3))
+	data, err := mat.DataPtrFloat32()
+	if err != nil {
+		mat.Close()
+		return gocv.Mat{}, nil, err
+	}
+
+	return mat, data, nil
+}
+
+// combineFloat adds other to the input when multiply is false and multiplies by it otherwise,
+// saturating the result back to the input's type.
+func combineFloat(input gocv.Mat, other gocv.Mat, multiply bool) (*gocv.Mat, error) {
+
+	converted := gocv.NewMat()
+	defer converted.Close()
+	if err := input.ConvertTo(&converted, other.Type()); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if multiply {
+		err = gocv.Multiply(converted, other, &converted)
+	} else {
+		err = gocv.Add(converted, other, &converted)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := gocv.NewMat()
+	if err := converted.ConvertTo(&result, input.Type()); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// GaussianNoise adds independent gaussian noise with standard deviation Sigma to every color channel.
+// The same Seed gives the same noise, a zero Seed picks a random one.
+type GaussianNoise struct {
+	Sigma float64
+	Seed  int64
+}
+
+func (g *GaussianNoise) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateNoiseInput(input); err != nil {
+		return nil, err
+	}
+
+	if g.Sigma <= 0.0 {
+		return nil, fmt.Errorf("expected sigma to be greater than 0, got %0.2f", g.Sigma)
+	}
+
+	noise, data, err := floatPerChannel(*input)
+	if err != nil {
+		return nil, err
+	}
+	defer noise.Close()
+
+	rng := noiseRNG(g.Seed)
+	channels, colors := input.Channels(), colorChannels(input.Channels())
+
+	for pixel := 0; pixel < len(data); pixel += channels {
+		for channel := range colors {
+			data[pixel+channel] = float32(rng.NormFloat64() * g.Sigma)
+		}
+	}
+
+	return combineFloat(*input, noise, false)
+}
+
+func (_ *GaussianNoise) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+// SaltAndPepper turns a random Amount of the pixels (between 0 and 1) black or white.
+type SaltAndPepper struct {
+	Amount float64
+	Seed   int64
+}
+
+func (s *SaltAndPepper) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateNoiseInput(input); err != nil {
+		return nil, err
+	}
+
+	if s.Amount <= 0.0 || s.Amount > 1.0 {
+		return nil, fmt.Errorf("expected amount to be greater than 0 and at most 1, got %0.2f", s.Amount)
+	}
+
+	result := input.Clone()
+	data, err := result.DataPtrUint8()
+	if err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	rng := noiseRNG(s.Seed)
+	channels, colors := input.Channels(), colorChannels(input.Channels())
+
+	for pixel := 0; pixel < len(data); pixel += channels {
+		if rng.Float64() >= s.Amount {
+			continue
+		}
+
+		value := uint8(0)
+		if rng.Intn(2) == 1 {
+			value = 255
+		}
+		for channel := range colors {
+			data[pixel+channel] = value
+		}
+	}
+
+	return &result, nil
+}
+
+func (_ *SaltAndPepper) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+// FilmGrain adds slightly blurred monochrome noise, the same for every color channel. Strength is between 0 and 1.
+type FilmGrain struct {
+	Strength float64
+	Seed     int64
+}
+
+func (f *FilmGrain) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateNoiseInput(input); err != nil {
+		return nil, err
+	}
+
+	if f.Strength <= 0.0 || f.Strength > 1.0 {
+		return nil, fmt.Errorf("expected strength to be greater than 0 and at most 1, got %0.2f", f.Strength)
+	}
+
+	grain := gocv.NewMatWithSize(input.Rows(), input.Cols(), gocv.MatTypeCV32F)
+	defer grain.Close()
+	grainData, err := grain.DataPtrFloat32()
+	if err != nil {
+		return nil, err
+	}
+
+	rng := noiseRNG(f.Seed)
+	// at full strength the grain has a standard deviation of 40 levels
+	sigma := f.Strength * 40
+	for idx := range grainData {
+		grainData[idx] = float32(rng.NormFloat64() * sigma)
+	}
+
+	// a small blur clumps neighbouring grains together like silver halide crystals
+	if err := gocv.GaussianBlur(grain, &grain, image.Pt(3, 3), 0.8, 0.8, gocv.BorderReflect); err != nil {
+		return nil, err
+	}
+
+	noise, data, err := floatPerChannel(*input)
+	if err != nil {
+		return nil, err
+	}
+	defer noise.Close()
+
+	channels, colors := input.Channels(), colorChannels(input.Channels())
+	for idx, value := range grainData {
+		for channel := range colors {
+			data[idx*channels+channel] = value
+		}
+	}
+
+	return combineFloat(*input, noise, false)
+}
+
+func (_ *FilmGrain) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+// Vignette darkens the image towards its corners. Pixels closer to the center than Radius (a percentage of
+// half the diagonal) are untouched, from there the darkening increases smoothly up to Strength at the corners.
+type Vignette struct {
+	Strength float64
+	Radius   float64
+}
+
+func (v *Vignette) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateNoiseInput(input); err != nil {
+		return nil, err
+	}
+
+	if v.Strength <= 0.0 || v.Strength > 1.0 {
+		return nil, fmt.Errorf("expected strength to be greater than 0 and at most 1, got %0.2f", v.Strength)
+	}
+
+	if v.Radius < 0.0 || v.Radius >= 1.0 {
+		return nil, fmt.Errorf("expected radius to be at least 0 and less than 1, got %0.2f", v.Radius)
+	}
+
+	factors, data, err := floatPerChannel(*input)
+	if err != nil {
+		return nil, err
+	}
+	defer factors.Close()
+
+	rows, cols := input.Rows(), input.Cols()
+	channels, colors := input.Channels(), colorChannels(input.Channels())
+	cx, cy := float64(cols-1)/2, float64(rows-1)/2
+	halfDiagonal := math.Max(math.Hypot(cx, cy), 1)
+
+	for row := range rows {
+		for col := range cols {
+			distance := math.Hypot(float64(col)-cx, float64(row)-cy) / halfDiagonal
+
+			// smoothstep from the radius out to the corners
+			t := math.Max(0, math.Min(1, (distance-v.Radius)/(1-v.Radius)))
+			factor := float32(1 - v.Strength*t*t*(3-2*t))
+
+			pixel := (row*cols + col) * channels
+			for channel := range channels {
+				data[pixel+channel] = 1
+			}
+			for channel := range colors {
+				data[pixel+channel] = factor
+			}
+		}
+	}
+
+	return combineFloat(*input, factors, true)
+}
+
+func (_ *Vignette) Alpha() AlphaBehaviour { return AlphaPreserve }
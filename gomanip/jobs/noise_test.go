@@ -0,0 +1,247 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"testing"
+)
+
+func TestNoise(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.Operation
+	}{
+		{
+			name:      "gaussian noise with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewGaussianNoise(20, 1),
+		},
+		{
+			name:      "salt and pepper with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewSaltAndPepper(0.05, 1),
+		},
+		{
+			name:      "film grain with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewFilmGrain(0.5, 1),
+		},
+		{
+			name:      "vignette with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewVignette(0.6, 0.4),
+		},
+		{
+			name:      "old photo pipeline with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewOldPhoto(0.5, 0.4, 0.6, 1),
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.NewGaussianNoise(20, 1),
+		},
+		{
+			name:      "Handle gaussian noise invalid sigma",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewGaussianNoise(0, 1),
+		},
+		{
+			name:      "Handle salt and pepper invalid amount",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewSaltAndPepper(1.5, 1),
+		},
+		{
+			name:      "Handle film grain invalid strength",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewFilmGrain(-0.5, 1),
+		},
+		{
+			name:      "Handle vignette invalid radius",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewVignette(0.5, 1),
+		},
+		{
+			name:      "Handle empty pipeline",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewPipeline(),
+		},
+		{
+			name:      "Handle failing pipeline step",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewPipeline(jobs.NewFilmGrain(0.4, 1), jobs.NewVignette(0, 0.4)),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+				result, err := tt.op.Run(image)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				assert.Equal(t, image.Rows(), result.Rows())
+				assert.Equal(t, image.Cols(), result.Cols())
+				assert.Equal(t, image.Channels(), result.Channels())
+				result.Close()
+			}
+		})
+	}
+}
+
+// identical checks whether a and b have exactly the same pixels.
+func identical(t *testing.T, a, b *gocv.Mat) bool {
+	t.Helper()
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	gocv.AbsDiff(*a, *b, &diff)
+
+	flat := diff.Reshape(1, 0)
+	defer flat.Close()
+
+	return gocv.CountNonZero(flat) == 0
+}
+
+func TestNoiseIsSeeded(t *testing.T) {
+
+	original := gradientImage(120, 80)
+	defer original.Close()
+
+	seeded := []func(seed int64) jobs.Operation{
+		func(seed int64) jobs.Operation { return jobs.NewGaussianNoise(20, seed) },
+		func(seed int64) jobs.Operation { return jobs.NewSaltAndPepper(0.1, seed) },
+		func(seed int64) jobs.Operation { return jobs.NewFilmGrain(0.5, seed) },
+	}
+
+	for _, newOp := range seeded {
+		first, err := newOp(42).Run(&original)
+		assert.NoError(t, err)
+		second, err := newOp(42).Run(&original)
+		assert.NoError(t, err)
+		other, err := newOp(43).Run(&original)
+		assert.NoError(t, err)
+
+		assert.True(t, identical(t, first, second))
+		assert.False(t, identical(t, first, other))
+		assert.False(t, identical(t, first, &original))
+
+		first.Close()
+		second.Close()
+		other.Close()
+	}
+}
+
+func TestNoiseKeepsAlpha(t *testing.T) {
+
+	original := gradientImage(64, 48)
+	defer original.Close()
+
+	withAlpha := gocv.NewMat()
+	defer withAlpha.Close()
+	gocv.CvtColor(original, &withAlpha, gocv.ColorBGRToBGRA)
+
+	channels := gocv.Split(withAlpha)
+	channels[3].SetTo(gocv.NewScalar(128, 0, 0, 0))
+	gocv.Merge(channels, &withAlpha)
+	for _, channel := range channels {
+		channel.Close()
+	}
+
+	ops := []jobs.Operation{
+		jobs.NewGaussianNoise(40, 1),
+		jobs.NewSaltAndPepper(0.5, 1),
+		jobs.NewFilmGrain(1, 1),
+		jobs.NewVignette(1, 0),
+	}
+
+	for _, op := range ops {
+		result, err := op.Run(&withAlpha)
+		assert.NoError(t, err)
+		assert.Equal(t, 4, result.Channels())
+
+		alpha := gocv.NewMat()
+		gocv.ExtractChannel(*result, &alpha, 3)
+		minVal, maxVal, _, _ := gocv.MinMaxLoc(alpha)
+		assert.Equal(t, float32(128), minVal)
+		assert.Equal(t, float32(128), maxVal)
+
+		alpha.Close()
+		result.Close()
+	}
+}
+
+func TestSaltAndPepperAmount(t *testing.T) {
+
+	gray := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(128, 128, 128, 0), 200, 200, gocv.MatTypeCV8UC3)
+	defer gray.Close()
+
+	result, err := jobs.NewSaltAndPepper(0.2, 5).Run(&gray)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	single := gocv.NewMat()
+	defer single.Close()
+	gocv.ExtractChannel(*result, &single, 0)
+
+	unchanged := gocv.NewMat()
+	defer unchanged.Close()
+	gocv.InRangeWithScalar(single, gocv.NewScalar(128, 0, 0, 0), gocv.NewScalar(128, 0, 0, 0), &unchanged)
+
+	affected := 1 - float64(gocv.CountNonZero(unchanged))/float64(200*200)
+	assert.InDelta(t, 0.2, affected, 0.02)
+}
+
+func TestVignette(t *testing.T) {
+
+	white := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 255, 255, 0), 101, 101, gocv.MatTypeCV8UC3)
+	defer white.Close()
+
+	result, err := jobs.NewVignette(0.8, 0.4).Run(&white)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	// the center is inside the radius, the corners get the full strength
+	assert.Equal(t, uint8(255), result.GetVecbAt(50, 50)[0])
+	assert.InDelta(t, 51, int(result.GetVecbAt(0, 0)[0]), 1)
+	assert.InDelta(t, 51, int(result.GetVecbAt(100, 100)[2]), 1)
+	assert.Less(t, result.GetVecbAt(50, 0)[1], uint8(255))
+}
+
+func TestPipelineMatchesSteps(t *testing.T) {
+
+	original := gradientImage(120, 80)
+	defer original.Close()
+
+	grained, err := jobs.NewFilmGrain(0.5, 9).Run(&original)
+	assert.NoError(t, err)
+	defer grained.Close()
+
+	expected, err := jobs.NewVignette(0.6, 0.3).Run(grained)
+	assert.NoError(t, err)
+	defer expected.Close()
+
+	result, err := jobs.NewPipeline(jobs.NewFilmGrain(0.5, 9), jobs.NewVignette(0.6, 0.3)).Run(&original)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	assert.True(t, identical(t, expected, result))
+}
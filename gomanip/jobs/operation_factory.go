@@ -1,5 +1,11 @@
 package jobs
 
+import (
+	"fmt"
+	"gocv.io/x/gocv"
+	"image/color"
+)
+
 func NewInvert() Operation {
 	return &Invert{}
 }
@@ -28,13 +34,168 @@ func NewAddText(text string, fontScale, xPercentage, yPercentage float64) Operat
 
 }
 
-func NewRandomFilter(kernelSize, min, max int, normalize bool) Operation {
+func NewRandomFilter(kernelSize, min, max int, normalize bool) *RandomFilter {
 
 	return &RandomFilter{KernelSize: kernelSize, Min: min, Max: max, Normalize: normalize}
 
 }
 
+func NewConvolve(kernels []Kernel, preset string) Operation {
+
+	return &Convolve{Kernels: kernels, Preset: preset}
+
+}
+
 func NewShuffle(partitions int) Operation {
 
 	return &Shuffle{Partitions: partitions}
 }
+
+func NewGridShuffle(rows, cols, swaps int, rotate, flip bool) *Shuffle {
+
+	return &Shuffle{Rows: rows, Cols: cols, Swaps: swaps, Rotate: rotate, Flip: flip}
+}
+
+func NewDetect(cascadePath string, action DetectAction, scaleFactor float64, minNeighbors int) *Detect {
+
+	return &Detect{CascadePath: cascadePath, Action: action, ScaleFactor: scaleFactor, MinNeighbors: minNeighbors}
+}
+
+func NewQRDecode() *QRDecode {
+
+	return &QRDecode{}
+}
+
+func NewAnalyze(colors, bins int) *Analyze {
+
+	return &Analyze{Colors: colors, Bins: bins}
+}
+
+func NewCompare(other *gocv.Mat, heatmap bool) *Compare {
+
+	return &Compare{Other: other, Heatmap: heatmap}
+}
+
+func NewAsciiArt(columns int, ramp string, emoji bool) *AsciiArt {
+
+	return &AsciiArt{Columns: columns, Ramp: ramp, Emoji: emoji}
+}
+
+// NewStylization returns the operation for the given style.
+func NewStylization(style Style, intensity float64) (Operation, error) {
+
+	switch style {
+	case StyleCartoon:
+		return &Cartoon{Intensity: intensity}, nil
+	case StyleSketch:
+		return &PencilSketch{Intensity: intensity}, nil
+	case StyleOilPaint:
+		return &OilPaint{Intensity: intensity}, nil
+	case StyleEmboss:
+		return &Emboss{Intensity: intensity}, nil
+	case StyleComic:
+		return &Comic{Intensity: intensity}, nil
+	}
+
+	return nil, fmt.Errorf("unknown style %s", style)
+}
+
+func NewSwirl(xPercentage, yPercentage, radius, strength float64) Operation {
+
+	return &Swirl{X: xPercentage, Y: yPercentage, Radius: radius, Strength: strength}
+}
+
+func NewBulge(xPercentage, yPercentage, radius, strength float64) Operation {
+
+	return &Bulge{X: xPercentage, Y: yPercentage, Radius: radius, Strength: strength}
+}
+
+func NewWave(amplitude, wavelength float64) Operation {
+
+	return &Wave{Amplitude: amplitude, Wavelength: wavelength}
+}
+
+func NewFisheye(xPercentage, yPercentage, strength float64) Operation {
+
+	return &Fisheye{X: xPercentage, Y: yPercentage, Strength: strength}
+}
+
+func NewMirror(side MirrorSide) Operation {
+
+	return &Mirror{Side: side}
+}
+
+func NewKaleidoscope(segments int, xPercentage, yPercentage float64) Operation {
+
+	return &Kaleidoscope{Segments: segments, X: xPercentage, Y: yPercentage}
+}
+
+func NewGaussianNoise(sigma float64, seed int64) Operation {
+
+	return &GaussianNoise{Sigma: sigma, Seed: seed}
+}
+
+func NewSaltAndPepper(amount float64, seed int64) Operation {
+
+	return &SaltAndPepper{Amount: amount, Seed: seed}
+}
+
+func NewFilmGrain(strength float64, seed int64) Operation {
+
+	return &FilmGrain{Strength: strength, Seed: seed}
+}
+
+func NewVignette(strength, radius float64) Operation {
+
+	return &Vignette{Strength: strength, Radius: radius}
+}
+
+func NewPipeline(steps ...Operation) Operation {
+
+	return &Pipeline{Steps: steps}
+}
+
+// NewOldPhoto reduces the quality of the image, then adds film grain and a vignette.
+func NewOldPhoto(quality float32, grain, vignette float64, seed int64) Operation {
+
+	return NewPipeline(
+		NewReduce(quality),
+		NewFilmGrain(grain, seed),
+		NewVignette(vignette, DefaultVignetteRadius),
+	)
+}
+
+func NewColorKey(key color.RGBA, auto bool, tolerance int) Operation {
+
+	return &ColorKey{Key: key, Auto: auto, Tolerance: tolerance}
+}
+
+func NewZoom(frames, delay int, scale, xPercentage, yPercentage float64) *Zoom {
+
+	return &Zoom{Animation: Animation{Frames: frames, Delay: delay}, Scale: scale, X: xPercentage, Y: yPercentage}
+}
+
+func NewSpin(frames, delay int, turns float64) *Spin {
+
+	return &Spin{Animation: Animation{Frames: frames, Delay: delay}, Turns: turns}
+}
+
+func NewShake(frames, delay int, amount float64, caption string, seed int64) *Shake {
+
+	return &Shake{Animation: Animation{Frames: frames, Delay: delay}, Amount: amount, Caption: caption, Seed: seed}
+}
+
+func NewTween(frames, delay int, operation string, from, to float64) *Tween {
+
+	return &Tween{Animation: Animation{Frames: frames, Delay: delay}, Operation: operation, From: from, To: to}
+}
+
+func NewExport(target string, fit FitMode) (*Export, error) {
+
+	exportTarget, ok := ExportTargets[target]
+	if !ok {
+		return nil, fmt.Errorf("unknown export target %s", target)
+	}
+
+	return &Export{Target: exportTarget, Fit: fit}, nil
+}
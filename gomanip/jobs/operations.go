@@ -20,6 +20,96 @@ func min(a, b int) int {
 	return b
 }
 
+// channelConversions maps a source and destination channel count to the color conversion between them.
+var channelConversions = map[[2]int]gocv.ColorConversionCode{
+	{1, 3}: gocv.ColorGrayToBGR,
+	{1, 4}: gocv.ColorGrayToBGRA,
+	{3, 1}: gocv.ColorBGRToGray,
+	{3, 4}: gocv.ColorBGRToBGRA,
+	{4, 1}: gocv.ColorBGRAToGray,
+	{4, 3}: gocv.ColorBGRAToBGR,
+}
+
+// convertChannels returns a copy of src with the given number of channels (1, 3 or 4).
+func convertChannels(src gocv.Mat, channels int) (gocv.Mat, error) {
+
+	if src.Channels() == channels {
+		return src.Clone(), nil
+	}
+
+	code, ok := channelConversions[[2]int{src.Channels(), channels}]
+	if !ok {
+		return gocv.Mat{}, fmt.Errorf("cannot convert a %d channel image to %d channels", src.Channels(), channels)
+	}
+
+	converted := gocv.NewMat()
+	if err := gocv.CvtColor(src, &converted, code); err != nil {
+		converted.Close()
+		return gocv.Mat{}, err
+	}
+
+	return converted, nil
+}
+
+// Kernel is a 2D convolution kernel, indexed by row then column.
+type Kernel [][]float32
+
+// MaxKernelSize is the most rows and columns a kernel may have, the cost of a convolution grows with the kernel's area.
+const MaxKernelSize = 31
+
+// ValidateKernel checks that a kernel is a rectangle of at most MaxKernelSize rows and columns.
+func ValidateKernel(k Kernel) error {
+
+	if len(k) == 0 || len(k[0]) == 0 {
+		return errors.New("kernel must not be empty")
+	}
+
+	if len(k) > MaxKernelSize || len(k[0]) > MaxKernelSize {
+		return fmt.Errorf("expected a kernel of at most %dx%d, got %dx%d", MaxKernelSize, MaxKernelSize, len(k), len(k[0]))
+	}
+
+	for row := range k {
+		if len(k[row]) != len(k[0]) {
+			return fmt.Errorf("expected every kernel row to have %d values, row %d has %d", len(k[0]), row, len(k[row]))
+		}
+	}
+
+	return nil
+}
+
+// kernelToMat copies a kernel into a single channel float Mat for use with Filter2D.
+func kernelToMat(k Kernel) (gocv.Mat, error) {
+
+	if err := ValidateKernel(k); err != nil {
+		return gocv.Mat{}, err
+	}
+
+	mat := gocv.NewMatWithSize(len(k), len(k[0]), gocv.MatTypeCV32F)
+
+	for row := range k {
+		for col, val := range k[row] {
+			mat.SetFloatAt(row, col, val)
+		}
+	}
+
+	return mat, nil
+}
+
+// matToKernel copies a single channel float Mat into a Kernel.
+func matToKernel(mat gocv.Mat) Kernel {
+
+	k := make(Kernel, mat.Rows())
+
+	for row := range k {
+		k[row] = make([]float32, mat.Cols())
+		for col := range k[row] {
+			k[row][col] = mat.GetFloatAt(row, col)
+		}
+	}
+
+	return k
+}
+
 type Invert struct{}
 
 func (_ *Invert) Run(input *gocv.Mat) (*gocv.Mat, error) {
@@ -28,17 +118,22 @@ func (_ *Invert) Run(input *gocv.Mat) (*gocv.Mat, error) {
 		return nil, errors.New("input image is empty")
 	}
 
-	white := gocv.NewMatWithSizeFromScalar(gocv.Scalar{255.0, 255.0, 255.0, 255.0}, input.Rows(), input.Cols(), input.Type())
+	return preserveAlpha(input, func(colors *gocv.Mat) (*gocv.Mat, error) {
+
+		white := gocv.NewMatWithSizeFromScalar(gocv.Scalar{255.0, 255.0, 255.0, 255.0}, colors.Rows(), colors.Cols(), colors.Type())
 
-	inverted := gocv.NewMat()
+		inverted := gocv.NewMat()
 
-	gocv.Subtract(white, *input, &inverted)
+		gocv.Subtract(white, *colors, &inverted)
 
-	white.Close()
+		white.Close()
 
-	return &inverted, nil
+		return &inverted, nil
+	})
 }
 
+func (_ *Invert) Alpha() AlphaBehaviour { return AlphaPreserve }
+
 type Saturate struct {
 	Value float32
 }
@@ -53,23 +148,24 @@ func (s *Saturate) Run(input *gocv.Mat) (*gocv.Mat, error) {
 		return nil, fmt.Errorf("expected saturation value to be greater than 0, got %f", s.Value)
 	}
 
-	hsvImage := gocv.NewMat()
-
-	expectedChannels := 3
+	return preserveAlpha(input, s.saturate)
+}
 
-	if input.Channels() != expectedChannels {
-		converted := gocv.NewMat()
-		defer converted.Close()
+func (_ *Saturate) Alpha() AlphaBehaviour { return AlphaPreserve }
 
-		err := gocv.CvtColor(*input, &converted, gocv.ColorGrayToBGR)
-		if err != nil {
-			return nil, err
-		}
+// saturate scales the saturation of a 1 or 3 channel image, gray images are returned with 3 channels.
+func (s *Saturate) saturate(input *gocv.Mat) (*gocv.Mat, error) {
 
-		*input = converted.Clone()
+	bgr, err := convertChannels(*input, 3)
+	if err != nil {
+		return nil, err
 	}
+	defer bgr.Close()
+
+	hsvImage := gocv.NewMat()
+	defer hsvImage.Close()
 
-	err := gocv.CvtColor(*input, &hsvImage, gocv.ColorBGRToHLSFull)
+	err = gocv.CvtColor(bgr, &hsvImage, gocv.ColorBGRToHLSFull)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert to HSV: %v", err)
@@ -84,7 +180,6 @@ func (s *Saturate) Run(input *gocv.Mat) (*gocv.Mat, error) {
 	saturated := gocv.NewMat()
 
 	defer func() {
-		hsvImage.Close()
 		hue.Close()
 		light.Close()
 		sat.Close()
@@ -100,7 +195,10 @@ func (s *Saturate) Run(input *gocv.Mat) (*gocv.Mat, error) {
 
 	imgSaturated := gocv.NewMat()
 
-	gocv.CvtColor(saturated, &imgSaturated, gocv.ColorHLSToBGR)
+	if err := gocv.CvtColor(saturated, &imgSaturated, gocv.ColorHLSToBGRFull); err != nil {
+		imgSaturated.Close()
+		return nil, fmt.Errorf("failed to convert back from HSV: %v", err)
+	}
 
 	return &imgSaturated, nil
 
@@ -121,14 +219,20 @@ func (e *EdgeDetect) Run(input *gocv.Mat) (*gocv.Mat, error) {
 		return nil, fmt.Errorf("expected t_lower and t_higher to be greater than or equal to 0, got %0.2f and %0.2f", e.TLower, e.THigher)
 	}
 
-	edges := gocv.NewMat()
+	// the edges of a transparent image are returned as gray colors under its original alpha channel
+	return preserveAlpha(input, func(colors *gocv.Mat) (*gocv.Mat, error) {
+
+		edges := gocv.NewMat()
 
-	gocv.Canny(*input, &edges, e.TLower, e.THigher)
+		gocv.Canny(*colors, &edges, e.TLower, e.THigher)
 
-	return &edges, nil
+		return &edges, nil
+	})
 
 }
 
+func (_ *EdgeDetect) Alpha() AlphaBehaviour { return AlphaPreserve }
+
 type Choice string
 
 const (
@@ -152,24 +256,31 @@ func (m *Morphology) Run(input *gocv.Mat) (*gocv.Mat, error) {
 		return nil, fmt.Errorf("expected kernel size and iterations to be greater than 0, got %d and %d", m.KernelSize, m.Iterations)
 	}
 
-	kernel := gocv.GetStructuringElement(gocv.MorphRect, image.Point{X: m.KernelSize, Y: m.KernelSize})
-	morphedImage := gocv.NewMat()
-
+	var morph func(src gocv.Mat, dst *gocv.Mat, kernel gocv.Mat) error
 	switch m.Op {
 	case Dilate:
-		gocv.Dilate(*input, &morphedImage, kernel)
+		morph = gocv.Dilate
 	case Erode:
-		gocv.Erode(*input, &morphedImage, kernel)
+		morph = gocv.Erode
 	default:
-
-		defer morphedImage.Close()
 		return nil, errors.New("invalid morphology operation")
 	}
 
+	kernel := gocv.GetStructuringElement(gocv.MorphRect, image.Point{X: m.KernelSize, Y: m.KernelSize})
+	defer kernel.Close()
+
+	morphedImage := gocv.NewMat()
+	if err := morph(*input, &morphedImage, kernel); err != nil {
+		morphedImage.Close()
+		return nil, err
+	}
+
 	return &morphedImage, nil
 
 }
 
+func (_ *Morphology) Alpha() AlphaBehaviour { return AlphaTransform }
+
 type Reduce struct {
 	Quality float32
 }
@@ -191,12 +302,14 @@ func (r *Reduce) Run(input *gocv.Mat) (*gocv.Mat, error) {
 
 	gocv.Resize(*input, &resizedImage, image.Point{}, float64(r.Quality), float64(r.Quality), gocv.InterpolationNearestNeighbor)
 
-	gocv.Resize(resizedImage, &reducedImage, image.Point{X: input.Rows(), Y: input.Cols()}, 0.0, 0.0, gocv.InterpolationNearestNeighbor)
+	gocv.Resize(resizedImage, &reducedImage, image.Point{X: input.Cols(), Y: input.Rows()}, 0.0, 0.0, gocv.InterpolationNearestNeighbor)
 
 	return &reducedImage, nil
 
 }
 
+func (_ *Reduce) Alpha() AlphaBehaviour { return AlphaTransform }
+
 type AddText struct {
 	Text      string
 	FontScale float64
@@ -231,16 +344,25 @@ func (a *AddText) Run(input *gocv.Mat) (*gocv.Mat, error) {
 	thickness := 1
 	lineType := gocv.LineAA
 
-	gocv.PutTextWithParams(input, a.Text, image.Point{X: xPos, Y: yPos}, gocv.FontHersheyPlain, a.FontScale, color.RGBA{255, 255, 255, 255}, thickness, lineType, false)
+	// draw on a copy, the input belongs to the caller and every other operation returns a new image
+	result := input.Clone()
+	gocv.PutTextWithParams(&result, a.Text, image.Point{X: xPos, Y: yPos}, gocv.FontHersheyPlain, a.FontScale, color.RGBA{255, 255, 255, 255}, thickness, lineType, false)
 
-	return input, nil
+	return &result, nil
 }
 
+// Alpha is AlphaReplace, the text is drawn opaque onto transparent images.
+func (_ *AddText) Alpha() AlphaBehaviour { return AlphaReplace }
+
 type RandomFilter struct {
 	KernelSize int
 	Min        int
 	Max        int
 	Normalize  bool
+
+	// Kernels is filled in by Run with the kernel generated for each channel,
+	// so the same filter can be applied again with Convolve.
+	Kernels []Kernel
 }
 
 func (r *RandomFilter) Run(input *gocv.Mat) (*gocv.Mat, error) {
@@ -257,6 +379,17 @@ func (r *RandomFilter) Run(input *gocv.Mat) (*gocv.Mat, error) {
 
 	}
 
+	return preserveAlpha(input, r.filter)
+}
+
+// Alpha is AlphaPreserve, transparent images only get a kernel for each color channel.
+func (_ *RandomFilter) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (_ *RandomFilter) Priority() Priority { return PriorityLow }
+
+// filter generates and applies a random kernel for every channel of the input.
+func (r *RandomFilter) filter(input *gocv.Mat) (*gocv.Mat, error) {
+
 	kernels := make([]gocv.Mat, input.Channels())
 
 	for i := 0; i < input.Channels(); i++ {
@@ -266,14 +399,25 @@ func (r *RandomFilter) Run(input *gocv.Mat) (*gocv.Mat, error) {
 	gocv.SetRNGSeed(int(time.Now().UnixNano()))
 	rng := gocv.TheRNG()
 
+	r.Kernels = make([]Kernel, len(kernels))
+
 	for i := range kernels {
 		rng.Fill(&kernels[i], gocv.RNGDistUniform, float64(r.Min), float64(r.Max), false)
 
 		if r.Normalize {
 			gocv.Normalize(kernels[i], &kernels[i], 1, 0, gocv.NormL2)
 		}
+
+		r.Kernels[i] = matToKernel(kernels[i])
 	}
 
+	return filterChannels(input, kernels)
+}
+
+// filterChannels convolves each channel of the input with the kernel at the same index,
+// closing the kernels once they have been applied.
+func filterChannels(input *gocv.Mat, kernels []gocv.Mat) (*gocv.Mat, error) {
+
 	ddepth := -1
 
 	channels := gocv.Split(*input)
@@ -304,86 +448,308 @@ func (r *RandomFilter) Run(input *gocv.Mat) (*gocv.Mat, error) {
 	return &filteredImage, nil
 }
 
-type Shuffle struct {
-	Partitions int
+var presetKernels = map[string]Kernel{
+	"sharpen": {
+		{0, -1, 0},
+		{-1, 5, -1},
+		{0, -1, 0},
+	},
+	"emboss": {
+		{-2, -1, 0},
+		{-1, 1, 1},
+		{0, 1, 2},
+	},
+	"outline": {
+		{-1, -1, -1},
+		{-1, 8, -1},
+		{-1, -1, -1},
+	},
+	"box": {
+		{1.0 / 9, 1.0 / 9, 1.0 / 9},
+		{1.0 / 9, 1.0 / 9, 1.0 / 9},
+		{1.0 / 9, 1.0 / 9, 1.0 / 9},
+	},
 }
 
-func (s *Shuffle) Run(input *gocv.Mat) (*gocv.Mat, error) {
+// Convolve applies user supplied kernels to an image. Kernels holds either a single kernel
+// shared by every channel or one kernel per channel, Preset selects one of the built-in kernels instead.
+type Convolve struct {
+	Kernels []Kernel
+	Preset  string
+}
+
+func (cv *Convolve) Run(input *gocv.Mat) (*gocv.Mat, error) {
 
 	if input == nil {
 
 		return nil, errors.New("input image is empty")
 
 	}
+
+	kernels := cv.Kernels
+
+	if cv.Preset != "" {
+
+		if len(kernels) > 0 {
+			return nil, errors.New("expected either a preset or kernels, got both")
+		}
+
+		preset, ok := presetKernels[cv.Preset]
+		if !ok {
+			return nil, fmt.Errorf("unknown kernel preset %s", cv.Preset)
+		}
+
+		kernels = []Kernel{preset}
+	}
+
+	return preserveAlpha(input, func(colors *gocv.Mat) (*gocv.Mat, error) {
+		return convolve(colors, kernels)
+	})
+}
+
+// Alpha is AlphaPreserve, so kernels are given per color channel for transparent images.
+func (_ *Convolve) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (_ *Convolve) Priority() Priority { return PriorityLow }
+
+// convolve applies either one kernel to every channel of the input or one kernel per channel.
+func convolve(input *gocv.Mat, kernels []Kernel) (*gocv.Mat, error) {
+
+	if len(kernels) != 1 && len(kernels) != input.Channels() {
+		return nil, fmt.Errorf("expected 1 kernel or 1 per channel (%d), got %d", input.Channels(), len(kernels))
+	}
+
+	kernelMats := make([]gocv.Mat, input.Channels())
+
+	for i := range kernelMats {
+
+		kernel := kernels[0]
+		if len(kernels) > 1 {
+			kernel = kernels[i]
+		}
+
+		mat, err := kernelToMat(kernel)
+		if err != nil {
+			for _, prev := range kernelMats[:i] {
+				prev.Close()
+			}
+			return nil, err
+		}
+
+		kernelMats[i] = mat
+	}
+
+	return filterChannels(input, kernelMats)
+}
+
+type Shuffle struct {
+	Partitions int
+	Rows       int
+	Cols       int
+	Swaps      int
+	Rotate     bool
+	Flip       bool
+
+	// Layout is filled in by Run and records how the tiles were rearranged.
+	Layout ShuffleLayout
+}
+
+// ShuffleLayout describes a shuffled image so the original can be reconstructed:
+// the tile at grid position i (row major) came from source tile Permutation[i],
+// was turned Rotations[i] quarter turns clockwise and then mirrored horizontally if Flips[i] is set.
+type ShuffleLayout struct {
+	Rows        int    `json:"rows"`
+	Cols        int    `json:"cols"`
+	Permutation []int  `json:"permutation"`
+	Rotations   []int  `json:"rotations"`
+	Flips       []bool `json:"flips"`
+}
+
+var quarterTurns = []gocv.RotateFlag{
+	1: gocv.Rotate90Clockwise,
+	2: gocv.Rotate180Clockwise,
+	3: gocv.Rotate90CounterClockwise,
+}
+
+// grid returns the number of tile rows and columns to split the input into.
+// An explicit Rows/Cols pair takes precedence over Partitions, which is turned into a near square grid.
+func (s *Shuffle) grid(input *gocv.Mat) (int, int, error) {
+
+	if s.Rows > 0 || s.Cols > 0 {
+
+		if s.Rows <= 0 || s.Cols <= 0 || s.Rows*s.Cols <= 1 {
+			return 0, 0, fmt.Errorf("expected rows and cols to be greater than 0 and give more than 1 tile, got %d and %d", s.Rows, s.Cols)
+		}
+
+		if s.Rows > input.Rows() || s.Cols > input.Cols() {
+			return 0, 0, fmt.Errorf("cannot fit %d by %d tiles in a %d by %d image", s.Rows, s.Cols, input.Rows(), input.Cols())
+		}
+
+		return s.Rows, s.Cols, nil
+	}
+
 	if s.Partitions <= 1 {
 
-		return nil, fmt.Errorf("expected partitions to be greater than 1, got %d", s.Partitions)
+		return 0, 0, fmt.Errorf("expected partitions to be greater than 1, got %d", s.Partitions)
 
 	}
 
 	if s.Partitions >= input.Rows()*input.Cols() {
-		return nil, fmt.Errorf("cannot fit %d partitions in a %d by %d image", s.Partitions, input.Rows(), input.Cols())
+		return 0, 0, fmt.Errorf("cannot fit %d partitions in a %d by %d image", s.Partitions, input.Rows(), input.Cols())
 	}
 
-	rows := input.Rows()
-	cols := input.Cols()
-	dataType := input.Type()
-
 	partRowsFlr := math.Floor(math.Sqrt(float64(s.Partitions)))
 	partColsFlr := math.Floor(float64(s.Partitions) / partRowsFlr)
 
 	partRows := int(partRowsFlr)
 	partCols := int(partColsFlr)
 
-	sliceWidth := cols / partCols
-	sliceHeight := rows / partRows
+	if partRows > input.Rows() || partCols > input.Cols() {
+		return 0, 0, fmt.Errorf("cannot fit %d partitions in a %d by %d image", s.Partitions, input.Rows(), input.Cols())
+	}
+
+	return partRows, partCols, nil
+}
+
+// permutation returns a random tile ordering. With Swaps set only that many random pairs
+// are exchanged, which leaves most of the image in place.
+func (s *Shuffle) permutation(tiles int) []int {
 
-	var slices []gocv.Mat
+	perm := make([]int, tiles)
+	for i := range perm {
+		perm[i] = i
+	}
 
-	for r := range partRows {
-		for c := range partCols {
+	if s.Swaps == 0 {
+		rand.Shuffle(tiles, func(i, j int) {
+			perm[i], perm[j] = perm[j], perm[i]
+		})
+		return perm
+	}
 
-			rowRange := r * sliceHeight
-			colRange := c * sliceWidth
+	for range s.Swaps {
+		i := rand.IntN(tiles)
+		j := rand.IntN(tiles - 1)
+		if j >= i {
+			j++
+		}
+		perm[i], perm[j] = perm[j], perm[i]
+	}
 
-			rowStart := rowRange
-			rowEnd := min(rowRange+sliceHeight, rows)
+	return perm
+}
 
-			colStart := colRange
-			colEnd := min(colRange+sliceWidth, cols)
+func (s *Shuffle) Run(input *gocv.Mat) (*gocv.Mat, error) {
 
-			roiRect := image.Rect(colStart, rowStart, colEnd, rowEnd)
+	if input == nil {
 
-			imgSlice := input.Region(roiRect)
-			slices = append(slices, imgSlice)
-		}
+		return nil, errors.New("input image is empty")
 
 	}
 
-	rand.Shuffle(len(slices), func(i, j int) {
-		slices[i], slices[j] = slices[j], slices[i]
-	})
+	if s.Swaps < 0 {
+		return nil, fmt.Errorf("expected swaps to be greater than or equal to 0, got %d", s.Swaps)
+	}
 
-	newHeight := min(partRows*sliceHeight, rows)
-	newWidth := min(partCols*sliceWidth, cols)
+	partRows, partCols, err := s.grid(input)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := input.Rows()
+	cols := input.Cols()
 
-	shuffledImage := gocv.NewMatWithSize(newHeight, newWidth, dataType)
+	// tile edges are spread over the whole image so the remainder pixels are
+	// shared between tiles instead of being cropped off.
+	tileRect := func(idx int) image.Rectangle {
+		r, c := idx/partCols, idx%partCols
+		return image.Rect(c*cols/partCols, r*rows/partRows, (c+1)*cols/partCols, (r+1)*rows/partRows)
+	}
 
-	for idx, slice := range slices {
+	// quarter turns would change the shape of non square tiles, so only half turns are used for those
+	squareTiles := rows/partRows == cols/partCols
 
-		rowIdx := idx / partCols
-		colIdx := idx % partCols
+	tiles := partRows * partCols
+	s.Layout = ShuffleLayout{
+		Rows:        partRows,
+		Cols:        partCols,
+		Permutation: s.permutation(tiles),
+		Rotations:   make([]int, tiles),
+		Flips:       make([]bool, tiles),
+	}
 
-		rowStart := rowIdx * sliceHeight
-		colStart := colIdx * sliceWidth
+	shuffledImage := gocv.NewMatWithSize(rows, cols, input.Type())
+
+	for idx, srcIdx := range s.Layout.Permutation {
+
+		if s.Rotate {
+			if squareTiles {
+				s.Layout.Rotations[idx] = rand.IntN(4)
+			} else {
+				s.Layout.Rotations[idx] = 2 * rand.IntN(2)
+			}
+		}
+
+		if s.Flip {
+			s.Layout.Flips[idx] = rand.IntN(2) == 1
+		}
 
-		sliceRows := slice.Rows()
-		sliceCols := slice.Cols()
-		roiRect := image.Rect(colStart, rowStart, colStart+sliceCols, rowStart+sliceRows)
-		roi := shuffledImage.Region(roiRect)
-		slice.CopyTo(&roi)
+		dstRect := tileRect(idx)
+
+		tile := input.Region(tileRect(srcIdx))
+		err := s.transformTile(&tile, s.Layout.Rotations[idx], s.Layout.Flips[idx], dstRect.Size())
+		if err != nil {
+			tile.Close()
+			shuffledImage.Close()
+			return nil, err
+		}
+
+		roi := shuffledImage.Region(dstRect)
+		tile.CopyTo(&roi)
 		roi.Close()
+		tile.Close()
 	}
 
 	return &shuffledImage, nil
 }
+
+func (_ *Shuffle) Alpha() AlphaBehaviour { return AlphaTransform }
+
+// transformTile rotates and flips a tile in place, then stretches it to fit its destination,
+// which may differ from the source tile by a pixel where the image did not divide evenly.
+func (s *Shuffle) transformTile(tile *gocv.Mat, rotation int, flip bool, size image.Point) error {
+
+	replace := func(apply func(dst *gocv.Mat) error) error {
+		transformed := gocv.NewMat()
+		if err := apply(&transformed); err != nil {
+			transformed.Close()
+			return err
+		}
+		tile.Close()
+		*tile = transformed
+		return nil
+	}
+
+	if rotation != 0 {
+		err := replace(func(dst *gocv.Mat) error { return gocv.Rotate(*tile, dst, quarterTurns[rotation]) })
+		if err != nil {
+			return err
+		}
+	}
+
+	if flip {
+		horizontal := 1
+		err := replace(func(dst *gocv.Mat) error { return gocv.Flip(*tile, dst, horizontal) })
+		if err != nil {
+			return err
+		}
+	}
+
+	if tile.Cols() != size.X || tile.Rows() != size.Y {
+		return replace(func(dst *gocv.Mat) error {
+			return gocv.Resize(*tile, dst, size, 0, 0, gocv.InterpolationNearestNeighbor)
+		})
+	}
+
+	return nil
+}
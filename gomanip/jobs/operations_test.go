@@ -2,7 +2,9 @@ package jobs_test
 
 import (
 	"goManip/jobs"
+	"goManip/mattest"
 	"gocv.io/x/gocv"
+	"image/color"
 	"testing"
 )
 
@@ -293,13 +295,21 @@ func TestReduce(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			for _, image := range tt.images {
 
-				_, err := tt.op.Run(image)
+				result, err := tt.op.Run(image)
 
 				if tt.wantError && err == nil {
 					t.Errorf("Test: %s, expected error but got nil", tt.name)
 				} else if !tt.wantError && err != nil {
 					t.Errorf("Test: %s, error = %v, wantErr %v", tt.name, err.Error(), tt.wantError)
 				}
+				if err != nil {
+					continue
+				}
+
+				if result.Rows() != image.Rows() || result.Cols() != image.Cols() {
+					t.Errorf("Test: %s, expected a %d by %d result, got %d by %d", tt.name, image.Cols(), image.Rows(), result.Cols(), result.Rows())
+				}
+				result.Close()
 			}
 
 		})
@@ -470,6 +480,158 @@ func TestRandomFilter(t *testing.T) {
 
 }
 
+func TestRandomFilterExportsKernels(t *testing.T) {
+
+	op := jobs.RandomFilter{KernelSize: 5, Min: -2, Max: 2, Normalize: true}
+
+	for _, image := range testImages {
+
+		filtered, err := op.Run(image)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		if len(op.Kernels) != image.Channels() {
+			t.Fatalf("expected %d kernels, got %d", image.Channels(), len(op.Kernels))
+		}
+
+		for _, kernel := range op.Kernels {
+			if len(kernel) != op.KernelSize || len(kernel[0]) != op.KernelSize {
+				t.Fatalf("expected a %dx%d kernel, got %dx%d", op.KernelSize, op.KernelSize, len(kernel), len(kernel[0]))
+			}
+		}
+
+		// applying the exported kernels again must give the same image
+		reapplied, err := (&jobs.Convolve{Kernels: op.Kernels}).Run(image)
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+
+		diff := gocv.NewMat()
+		gocv.AbsDiff(*filtered, *reapplied, &diff)
+		flat := diff.Reshape(1, 0)
+		if gocv.CountNonZero(flat) != 0 {
+			t.Errorf("reapplying exported kernels gave a different image")
+		}
+
+		flat.Close()
+		diff.Close()
+		filtered.Close()
+		reapplied.Close()
+	}
+}
+
+// squareKernel is a box kernel of size x size.
+func squareKernel(size int) jobs.Kernel {
+	kernel := make(jobs.Kernel, size)
+	for row := range kernel {
+		kernel[row] = make([]float32, size)
+		for col := range kernel[row] {
+			kernel[row][col] = 1 / float32(size*size)
+		}
+	}
+	return kernel
+}
+
+func TestConvolve(t *testing.T) {
+
+	grayTestImage := gocv.NewMatWithSize(64, 64, gocv.MatTypeCV8UC1)
+	defer grayTestImage.Close()
+
+	identity := jobs.Kernel{{0, 0, 0}, {0, 1, 0}, {0, 0, 0}}
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.Convolve
+	}{
+		{
+			name:      "test shared kernel",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{identity}},
+		},
+		{
+			name:      "test kernel per channel",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{identity, {{1, 1}, {1, 1}}, {{0.5}}}},
+		},
+		{
+			name:      "test presets",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Convolve{Preset: "emboss"},
+		},
+		{
+			name:      "test single channel image",
+			wantError: false,
+			images:    []*gocv.Mat{&grayTestImage},
+			op:        jobs.Convolve{Preset: "outline"},
+		},
+		{
+			name:      "Handle unknown preset",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Preset: "blurple"},
+		},
+		{
+			name:      "Handle preset and kernels",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Preset: "box", Kernels: []jobs.Kernel{identity}},
+		},
+		{
+			name:      "Handle wrong number of kernels",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{identity, identity}},
+		},
+		{
+			name:      "Handle ragged kernel",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{{{1, 2}, {3}}}},
+		},
+		{
+			name:      "Handle kernel larger than the max",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{squareKernel(jobs.MaxKernelSize + 1)}},
+		},
+		{
+			name:      "Handle empty kernel",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Convolve{Kernels: []jobs.Kernel{{}}},
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.Convolve{Preset: "box"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+
+				_, err := tt.op.Run(image)
+
+				if tt.wantError && err == nil {
+					t.Errorf("Test: %s, expected error but got nil", tt.name)
+				} else if !tt.wantError && err != nil {
+					t.Errorf("Test: %s, error = %v, wantErr %v", tt.name, err.Error(), tt.wantError)
+				}
+			}
+
+		})
+	}
+
+}
+
 func TestShuffle(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -495,6 +657,36 @@ func TestShuffle(t *testing.T) {
 			images:    testImages,
 			op:        jobs.Shuffle{Partitions: 9999999999999},
 		},
+		{
+			name:      "test explicit grid with rotation and flips",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Shuffle{Rows: 3, Cols: 7, Rotate: true, Flip: true},
+		},
+		{
+			name:      "test swapping a few pairs",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.Shuffle{Rows: 4, Cols: 4, Swaps: 2},
+		},
+		{
+			name:      "Handle rows without cols",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Shuffle{Rows: 4},
+		},
+		{
+			name:      "Handle grid larger than the image",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Shuffle{Rows: 5000, Cols: 2},
+		},
+		{
+			name:      "Handle negative swaps",
+			wantError: true,
+			images:    testImages,
+			op:        jobs.Shuffle{Partitions: 10, Swaps: -1},
+		},
 		{
 			name:      "Handle Nil image case",
 			wantError: true,
@@ -520,3 +712,118 @@ func TestShuffle(t *testing.T) {
 	}
 
 }
+
+func TestShuffleKeepsSizeAndRecordsLayout(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         jobs.Shuffle
+		rows, cols int
+	}{
+		{
+			name: "partitions",
+			op:   jobs.Shuffle{Partitions: 10},
+			rows: 3,
+			cols: 3,
+		},
+		{
+			name: "uneven grid with rotations",
+			op:   jobs.Shuffle{Rows: 7, Cols: 3, Rotate: true, Flip: true},
+			rows: 7,
+			cols: 3,
+		},
+		{
+			name: "single swap",
+			op:   jobs.Shuffle{Rows: 5, Cols: 5, Swaps: 1},
+			rows: 5,
+			cols: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range testImages {
+
+				result, err := tt.op.Run(image)
+				if err != nil {
+					t.Fatalf("Test: %s, unexpected error %v", tt.name, err)
+				}
+
+				if result.Rows() != image.Rows() || result.Cols() != image.Cols() {
+					t.Errorf("Test: %s, expected a %dx%d image, got %dx%d", tt.name, image.Rows(), image.Cols(), result.Rows(), result.Cols())
+				}
+				result.Close()
+
+				layout := tt.op.Layout
+				if layout.Rows != tt.rows || layout.Cols != tt.cols {
+					t.Errorf("Test: %s, expected a %dx%d grid, got %dx%d", tt.name, tt.rows, tt.cols, layout.Rows, layout.Cols)
+				}
+
+				seen := make(map[int]bool)
+				moved := 0
+				for idx, src := range layout.Permutation {
+					seen[src] = true
+					if idx != src {
+						moved++
+					}
+				}
+
+				if len(seen) != tt.rows*tt.cols {
+					t.Errorf("Test: %s, permutation %v is not a permutation of %d tiles", tt.name, layout.Permutation, tt.rows*tt.cols)
+				}
+
+				if tt.op.Swaps > 0 && moved > 2*tt.op.Swaps {
+					t.Errorf("Test: %s, expected at most %d tiles to move, %d moved", tt.name, 2*tt.op.Swaps, moved)
+				}
+			}
+		})
+	}
+}
+
+func TestOperationsFreeMats(t *testing.T) {
+	mattest.SkipWithoutTracking(t)
+
+	other := gocv.NewMatWithSize(48, 48, gocv.MatTypeCV8UC3)
+	defer other.Close()
+
+	cartoon, _ := jobs.NewStylization(jobs.StyleCartoon, 0.5)
+	emoji, _ := jobs.NewExport("emoji", jobs.FitPad)
+
+	operations := []struct {
+		name string
+		op   jobs.Operation
+	}{
+		{name: "invert", op: jobs.NewInvert()},
+		{name: "saturate", op: jobs.NewSaturate(1.5)},
+		{name: "invalid saturate", op: jobs.NewSaturate(-1)},
+		{name: "edge detection", op: jobs.NewEdgeDetection(50, 150)},
+		{name: "morphology", op: jobs.NewMorphology(3, 2, jobs.Dilate)},
+		{name: "reduce", op: jobs.NewReduce(0.3)},
+		{name: "add text", op: jobs.NewAddText("text", 1, 0.5, 0.5)},
+		{name: "random filter", op: jobs.NewRandomFilter(3, -2, 2, true)},
+		{name: "shuffle", op: jobs.NewShuffle(4)},
+		{name: "cartoon", op: cartoon},
+		{name: "swirl", op: jobs.NewSwirl(0.5, 0.5, 0.5, 4)},
+		{name: "kaleidoscope", op: jobs.NewKaleidoscope(6, 0.5, 0.5)},
+		{name: "old photo", op: jobs.NewOldPhoto(0.5, 0.4, 0.6, 1)},
+		{name: "color key", op: jobs.NewColorKey(color.RGBA{}, true, 20)},
+		{name: "pipeline", op: jobs.NewPipeline(jobs.NewInvert(), jobs.NewMirror(jobs.MirrorLeft), jobs.NewReduce(0.5))},
+		{name: "failing pipeline", op: jobs.NewPipeline(jobs.NewInvert(), jobs.NewSaturate(-1))},
+		{name: "spin", op: jobs.NewSpin(4, 5, 1)},
+		{name: "export", op: emoji},
+		{name: "analyze", op: jobs.NewAnalyze(4, 8)},
+		{name: "compare", op: jobs.NewCompare(&other, true)},
+		{name: "ascii art", op: jobs.NewAsciiArt(16, "", false)},
+	}
+
+	colorImage := gocv.NewMatWithSize(64, 96, gocv.MatTypeCV8UC3)
+	defer colorImage.Close()
+	alphaImage := gocv.NewMatWithSize(64, 96, gocv.MatTypeCV8UC4)
+	defer alphaImage.Close()
+
+	for _, tt := range operations {
+		t.Run(tt.name, func(t *testing.T) {
+			mattest.AssertNoLeaks(t, tt.op, &colorImage)
+			mattest.AssertNoLeaks(t, tt.op, &alphaImage)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+)
+
+// ErrInternal marks failures caused by a bug in gomanip rather than by the request.
+var ErrInternal = errors.New("internal error")
+
+// maxPanicParams limits how much of an operation's parameters is kept, kernels and frames can be large.
+const maxPanicParams = 1024
+
+// PanicError is returned for a job whose operation panicked, it holds what is needed to reproduce the panic.
+type PanicError struct {
+	JobId     uint32
+	Operation string
+	// Params are the operation's fields as printed by %+v
+	Params string
+	Value  any
+	Stack  []byte
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("%s: %s panicked in job %d", ErrInternal, p.Operation, p.JobId)
+}
+
+func (p *PanicError) Unwrap() error {
+	return ErrInternal
+}
+
+func newPanicError(job *Job, value any) *PanicError {
+	params := fmt.Sprintf("%+v", job.operation)
+	if len(params) > maxPanicParams {
+		params = params[:maxPanicParams] + "..."
+	}
+
+	return &PanicError{
+		JobId:     job.jobId,
+		Operation: NameOf(job.operation),
+		Params:    params,
+		Value:     value,
+		Stack:     debug.Stack(),
+	}
+}
@@ -0,0 +1,71 @@
+package jobs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gocv.io/x/gocv"
+
+	"goManip/jobs"
+)
+
+// mockOperationPanic fails the way a bad region or an out of range index would.
+type mockOperationPanic struct {
+	Region int
+}
+
+func (m mockOperationPanic) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	var regions []int
+	_ = regions[m.Region]
+	return input, nil
+}
+
+func TestJobPanic(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation jobs.Operation
+		expected  string
+	}{
+		{name: "Operation", operation: mockOperationPanic{Region: 3}, expected: "mockOperationPanic"},
+		{name: "Pipeline step", operation: jobs.NewPipeline(jobs.NewInvert(), mockOperationPanic{Region: 3}), expected: "pipeline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+			defer image.Close()
+
+			job := jobs.NewJob(7, tt.operation, &image)
+			result, err := job.Process()
+
+			assert.Nil(t, result)
+			assert.ErrorIs(t, err, jobs.ErrInternal)
+
+			var panicErr *jobs.PanicError
+			assert.True(t, errors.As(err, &panicErr))
+			assert.Equal(t, job.Panic(), panicErr)
+			assert.EqualValues(t, 7, panicErr.JobId)
+			assert.Equal(t, tt.expected, panicErr.Operation)
+			assert.Contains(t, panicErr.Params, "Region:3")
+			assert.NotNil(t, panicErr.Value)
+			assert.Contains(t, string(panicErr.Stack), "mockOperationPanic")
+
+			// the input belongs to the caller, who releases it like the input of any other job
+			assert.False(t, image.Closed())
+			assert.False(t, job.GetEndTime().IsZero())
+		})
+	}
+}
+
+func TestJobWithoutPanic(t *testing.T) {
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	job := jobs.NewJob(1, jobs.NewInvert(), &image)
+	result, err := job.Process()
+	assert.NoError(t, err)
+	assert.Nil(t, job.Panic())
+	assert.False(t, image.Closed())
+	result.Close()
+}
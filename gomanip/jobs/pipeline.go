@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+)
+
+// Pipeline runs Steps one after another, each step gets the result of the previous one.
+// Intermediate results are closed once the next step is done with them, the input is never closed.
+// Every finished step is reported as progress.
+type Pipeline struct {
+	progressReporter
+	Steps []Operation
+}
+
+func (p *Pipeline) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil {
+		return nil, errors.New("input image is empty")
+	}
+
+	if len(p.Steps) == 0 {
+		return nil, errors.New("expected at least one pipeline step")
+	}
+
+	current := input
+	completed := false
+	defer func() {
+		// a panicking step would otherwise leak the image of the step before it,
+		// after an error it is already closed and closing it again does nothing
+		if !completed && current != input {
+			current.Close()
+		}
+	}()
+
+	for idx, step := range p.Steps {
+		next, err := step.Run(current)
+
+		if current != input && current != next {
+			current.Close()
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("pipeline step %d: %w", idx+1, err)
+		}
+
+		if next == nil {
+			return nil, fmt.Errorf("pipeline step %d did not return an image", idx+1)
+		}
+
+		current = next
+		p.advance(idx+1, len(p.Steps), UnitStep)
+	}
+
+	completed = true
+	return current, nil
+}
+
+// Alpha is the strongest alpha behaviour of any step, i.e a pipeline that distorts and then adds noise transforms alpha.
+func (p *Pipeline) Alpha() AlphaBehaviour {
+
+	behaviour := AlphaPreserve
+	for _, step := range p.Steps {
+		behaviour = max(behaviour, AlphaOf(step))
+	}
+
+	return behaviour
+}
+
+// Priority is the lowest priority of any step, a pipeline is as expensive as its most expensive step.
+func (p *Pipeline) Priority() Priority {
+
+	priority := PriorityNormal
+	for _, step := range p.Steps {
+		if stepPriority := PriorityOf(step); stepPriority < priority {
+			priority = stepPriority
+		}
+	}
+
+	return priority
+}
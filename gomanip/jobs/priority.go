@@ -0,0 +1,57 @@
+package jobs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Priority is the lane a job is queued in, jobs in higher lanes are picked more often
+// but every lane keeps getting a share of the workers.
+type Priority int
+
+const (
+	// PriorityLow is for expensive operations, so a burst of them cannot hold up quick ones.
+	PriorityLow Priority = iota
+	// PriorityNormal is the default for operations that do not define a priority.
+	PriorityNormal
+	// PriorityHigh is only used when a client asks for it.
+	PriorityHigh
+)
+
+// Priorities lists every priority from the lowest to the highest.
+var Priorities = []Priority{PriorityLow, PriorityNormal, PriorityHigh}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+// ParsePriority returns the priority named by name, i.e "low".
+func ParsePriority(name string) (Priority, error) {
+	for _, priority := range Priorities {
+		if strings.EqualFold(name, priority.String()) {
+			return priority, nil
+		}
+	}
+	return PriorityNormal, fmt.Errorf("unknown priority %s, expected one of low, normal or high", name)
+}
+
+// Prioritized is implemented by operations that are queued outside the normal lane.
+type Prioritized interface {
+	Priority() Priority
+}
+
+// PriorityOf returns the priority of op, operations that do not define one are queued as PriorityNormal.
+func PriorityOf(op Operation) Priority {
+	if prioritized, ok := op.(Prioritized); ok {
+		return prioritized.Priority()
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,44 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"goManip/jobs"
+)
+
+func TestPriorityOf(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation jobs.Operation
+		expected  jobs.Priority
+	}{
+		{name: "Cheap operations are normal", operation: jobs.NewInvert(), expected: jobs.PriorityNormal},
+		{name: "Random filters are low", operation: jobs.NewRandomFilter(5, -10, 10, true), expected: jobs.PriorityLow},
+		{name: "Animations are low", operation: jobs.NewSpin(10, 5, 1), expected: jobs.PriorityLow},
+		{name: "Cheap pipelines are normal", operation: jobs.NewPipeline(jobs.NewInvert(), jobs.NewMirror(jobs.MirrorLeft)), expected: jobs.PriorityNormal},
+		{name: "Pipelines take their most expensive step", operation: jobs.NewPipeline(jobs.NewInvert(), jobs.NewConvolve(nil, "sharpen")), expected: jobs.PriorityLow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, jobs.PriorityOf(tt.operation))
+		})
+	}
+}
+
+func TestParsePriority(t *testing.T) {
+	for _, priority := range jobs.Priorities {
+		parsed, err := jobs.ParsePriority(priority.String())
+		assert.NoError(t, err)
+		assert.Equal(t, priority, parsed)
+	}
+
+	parsed, err := jobs.ParsePriority("HIGH")
+	assert.NoError(t, err)
+	assert.Equal(t, jobs.PriorityHigh, parsed)
+
+	_, err = jobs.ParsePriority("urgent")
+	assert.Error(t, err)
+}
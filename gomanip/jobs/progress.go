@@ -0,0 +1,49 @@
+package jobs
+
+// Progress is how much of an operation is done, i.e frame 3 of 20.
+type Progress struct {
+	Done  int    `json:"done"`
+	Total int    `json:"total"`
+	Unit  string `json:"unit"`
+}
+
+// Units of work operations report their progress in.
+const (
+	UnitFrame = "frame"
+	UnitStep  = "step"
+	UnitImage = "image"
+)
+
+// ProgressFunc is told whenever an operation finished another unit of work. It is called on the worker
+// running the operation, so it must not block.
+type ProgressFunc func(progress Progress)
+
+// Progressive is implemented by operations that take long enough to report how far they got, i.e animations and pipelines.
+type Progressive interface {
+	ReportProgress(report ProgressFunc)
+}
+
+// ReportProgress has the job's operation report its progress to report, it returns false when the operation does not report any.
+func (j *Job) ReportProgress(report ProgressFunc) bool {
+	progressive, ok := j.operation.(Progressive)
+	if ok {
+		progressive.ReportProgress(report)
+	}
+	return ok
+}
+
+// progressReporter is embedded by Progressive operations, reporting progress does nothing until it is asked for.
+type progressReporter struct {
+	report ProgressFunc
+}
+
+func (p *progressReporter) ReportProgress(report ProgressFunc) {
+	p.report = report
+}
+
+// advance reports that done of total units are finished.
+func (p *progressReporter) advance(done, total int, unit string) {
+	if p.report != nil {
+		p.report(Progress{Done: done, Total: total, Unit: unit})
+	}
+}
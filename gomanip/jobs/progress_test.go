@@ -0,0 +1,80 @@
+package jobs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+)
+
+func TestProgress(t *testing.T) {
+
+	original := gradientImage(60, 40)
+	defer original.Close()
+
+	frames := func(total int) []jobs.Progress {
+		progress := make([]jobs.Progress, total)
+		for idx := range total {
+			progress[idx] = jobs.Progress{Done: idx + 1, Total: total, Unit: jobs.UnitFrame}
+		}
+		return progress
+	}
+
+	tests := []struct {
+		name         string
+		op           jobs.Operation
+		wantProgress []jobs.Progress
+	}{
+		{name: "zoom", op: jobs.NewZoom(4, 5, 2, 0.5, 0.5), wantProgress: frames(4)},
+		{name: "tween", op: jobs.NewTween(3, 5, "swirl", 0, 4), wantProgress: frames(3)},
+		{
+			name: "pipeline",
+			op:   jobs.NewPipeline(jobs.NewInvert(), jobs.NewMirror(jobs.MirrorLeft)),
+			wantProgress: []jobs.Progress{
+				{Done: 1, Total: 2, Unit: jobs.UnitStep},
+				{Done: 2, Total: 2, Unit: jobs.UnitStep},
+			},
+		},
+		{
+			// steps after a failing one are not reported
+			name:         "failing pipeline",
+			op:           jobs.NewPipeline(jobs.NewInvert(), jobs.NewSaturate(-1), jobs.NewInvert()),
+			wantProgress: []jobs.Progress{{Done: 1, Total: 3, Unit: jobs.UnitStep}},
+		},
+		{name: "operation without progress", op: jobs.NewInvert()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := original.Clone()
+			defer input.Close()
+
+			var progress []jobs.Progress
+			job := jobs.NewJob(1, tt.op, &input)
+			reports := job.ReportProgress(func(p jobs.Progress) {
+				progress = append(progress, p)
+			})
+			assert.Equal(t, tt.wantProgress != nil, reports)
+
+			result, _ := job.Process()
+			if result != nil && result != &input {
+				result.Close()
+			}
+
+			assert.Equal(t, tt.wantProgress, progress)
+		})
+	}
+}
+
+func TestProgressIsOptional(t *testing.T) {
+
+	input := gocv.NewMatWithSize(20, 20, gocv.MatTypeCV8UC3)
+	defer input.Close()
+
+	// operations run the same when nobody asked for their progress
+	zoom := jobs.NewZoom(3, 5, 2, 0.5, 0.5)
+	_, err := zoom.Run(&input)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, zoom.Encoded())
+}
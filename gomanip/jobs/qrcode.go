@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skip2/go-qrcode"
+	"gocv.io/x/gocv"
+	"image"
+)
+
+const (
+	minQRSize = 32
+	maxQRSize = 2048
+)
+
+var qrRecoveryLevels = map[string]qrcode.RecoveryLevel{
+	"low":     qrcode.Low,
+	"medium":  qrcode.Medium,
+	"high":    qrcode.High,
+	"highest": qrcode.Highest,
+}
+
+type QRPoint struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+}
+
+// QRCode is a decoded QR code, Points holds its four corners in pixel coordinates.
+type QRCode struct {
+	Text   string    `json:"text"`
+	Points []QRPoint `json:"points"`
+}
+
+// QRDecode finds and decodes every QR code in an image. Run only fills in Codes and returns a nil image.
+type QRDecode struct {
+	// Codes is filled in by Run with the decoded QR codes.
+	Codes []QRCode
+}
+
+func (q *QRDecode) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil {
+		return nil, errors.New("input image is empty")
+	}
+
+	gray, err := convertChannels(*input, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	detector := gocv.NewQRCodeDetector()
+	defer detector.Close()
+
+	points := gocv.NewMat()
+	defer points.Close()
+
+	q.Codes = []QRCode{}
+
+	if !detector.DetectMulti(gray, &points) || points.Empty() {
+		return nil, nil
+	}
+
+	// each row holds the four corners of one code
+	corners := points.Reshape(2, points.Total()/4)
+	defer corners.Close()
+
+	for row := range corners.Rows() {
+
+		code := QRCode{Points: make([]QRPoint, 4)}
+		for col := range code.Points {
+			vec := corners.GetVecfAt(row, col)
+			code.Points[col] = QRPoint{X: vec[0], Y: vec[1]}
+		}
+
+		code.Text = decodeQRRegion(&detector, gray, code.Points)
+		q.Codes = append(q.Codes, code)
+	}
+
+	return nil, nil
+}
+
+func (_ *QRDecode) Alpha() AlphaBehaviour { return AlphaDiscard }
+
+// decodeQRRegion decodes the code inside the given corners by cropping around them, so that
+// the detector only sees that one code. Codes that cannot be decoded give an empty string.
+func decodeQRRegion(detector *gocv.QRCodeDetector, gray gocv.Mat, corners []QRPoint) string {
+
+	bounds := image.Rectangle{Min: image.Pt(gray.Cols(), gray.Rows())}
+	for _, p := range corners {
+		bounds.Min.X = min(bounds.Min.X, int(p.X))
+		bounds.Min.Y = min(bounds.Min.Y, int(p.Y))
+		bounds.Max.X = max(bounds.Max.X, int(p.X)+1)
+		bounds.Max.Y = max(bounds.Max.Y, int(p.Y)+1)
+	}
+
+	// the detector needs the quiet zone around the code
+	pad := max(bounds.Dx(), bounds.Dy()) / 8
+	bounds = bounds.Inset(-pad).Intersect(image.Rect(0, 0, gray.Cols(), gray.Rows()))
+
+	if bounds.Empty() {
+		return ""
+	}
+
+	region := gray.Region(bounds)
+	defer region.Close()
+
+	crop := region.Clone()
+	defer crop.Close()
+
+	points := gocv.NewMat()
+	defer points.Close()
+	straight := gocv.NewMat()
+	defer straight.Close()
+
+	return detector.DetectAndDecode(crop, &points, &straight)
+}
+
+// EncodeQRCode renders text as a size by size png QR code. Level is the error correction level,
+// one of low, medium, high or highest.
+func EncodeQRCode(text, level string, size int) ([]byte, error) {
+
+	if text == "" {
+		return nil, errors.New("must be given a non-empty string")
+	}
+
+	recovery, ok := qrRecoveryLevels[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid error correction level %s", level)
+	}
+
+	if size < minQRSize || size > maxQRSize {
+		return nil, fmt.Errorf("expected size to be between %d and %d, got %d", minQRSize, maxQRSize, size)
+	}
+
+	return qrcode.Encode(text, recovery, size)
+}
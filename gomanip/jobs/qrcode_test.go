@@ -0,0 +1,103 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"testing"
+)
+
+func encodeQRMat(t *testing.T, text string, size int) gocv.Mat {
+	png, err := jobs.EncodeQRCode(text, "medium", size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mat, err := gocv.IMDecode(png, gocv.IMReadColor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mat
+}
+
+func TestEncodeQRCode(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		level   string
+		size    int
+		wantErr bool
+	}{
+		{name: "valid", text: "hello", level: "low", size: 128},
+		{name: "valid highest", text: "hello", level: "highest", size: 2048},
+		{name: "Handle empty text", text: "", level: "low", size: 128, wantErr: true},
+		{name: "Handle invalid level", text: "hello", level: "extreme", size: 128, wantErr: true},
+		{name: "Handle size too small", text: "hello", level: "low", size: 8, wantErr: true},
+		{name: "Handle size too large", text: "hello", level: "low", size: 4096, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			png, err := jobs.EncodeQRCode(tt.text, tt.level, tt.size)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.NotEmpty(t, png)
+			}
+		})
+	}
+}
+
+func TestQRDecode(t *testing.T) {
+
+	single := encodeQRMat(t, "game night at 8", 256)
+	defer single.Close()
+
+	// two different codes side by side
+	left := encodeQRMat(t, "left", 256)
+	defer left.Close()
+	right := encodeQRMat(t, "right", 256)
+	defer right.Close()
+	pair := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 255, 255, 0), 256, 512, gocv.MatTypeCV8UC3)
+	defer pair.Close()
+	leftRoi := pair.Region(image.Rect(0, 0, 256, 256))
+	left.CopyTo(&leftRoi)
+	leftRoi.Close()
+	rightRoi := pair.Region(image.Rect(256, 0, 512, 256))
+	right.CopyTo(&rightRoi)
+	rightRoi.Close()
+
+	blank := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(255, 255, 255, 0), 128, 128, gocv.MatTypeCV8UC3)
+	defer blank.Close()
+
+	tests := []struct {
+		name     string
+		image    *gocv.Mat
+		wantErr  bool
+		expected []string
+	}{
+		{name: "single code", image: &single, expected: []string{"game night at 8"}},
+		{name: "two codes", image: &pair, expected: []string{"left", "right"}},
+		{name: "no codes", image: &blank, expected: []string{}},
+		{name: "Handle Nil image case", image: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decode := jobs.NewQRDecode()
+			result, err := decode.Run(tt.image)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Nil(t, result)
+			if tt.wantErr {
+				return
+			}
+
+			texts := []string{}
+			for _, code := range decode.Codes {
+				texts = append(texts, code.Text)
+				assert.Len(t, code.Points, 4)
+			}
+			assert.ElementsMatch(t, tt.expected, texts)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math"
+)
+
+type Style string
+
+const (
+	StyleCartoon  Style = "cartoon"
+	StyleSketch   Style = "sketch"
+	StyleOilPaint Style = "oilpaint"
+	StyleEmboss   Style = "emboss"
+	StyleComic    Style = "comic"
+)
+
+// stylizeInput validates the shared arguments of the stylizations and converts the input to BGR.
+func stylizeInput(input *gocv.Mat, intensity float64) (gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return gocv.Mat{}, errors.New("input image is empty")
+	}
+
+	if intensity <= 0.0 || intensity > 1.0 {
+		return gocv.Mat{}, fmt.Errorf("expected intensity to be greater than 0 and at most 1, got %0.2f", intensity)
+	}
+
+	return convertChannels(*input, 3)
+}
+
+// scaleIntensity maps an intensity between 0 and 1 linearly onto low to high.
+func scaleIntensity(intensity float64, low, high int) int {
+	return low + int(math.Round(intensity*float64(high-low)))
+}
+
+// keepOutsideEdges blacks out every pixel of image where edges is set.
+func keepOutsideEdges(image gocv.Mat, edges gocv.Mat) (*gocv.Mat, error) {
+
+	mask := gocv.NewMat()
+	defer mask.Close()
+	if err := gocv.CvtColor(edges, &mask, gocv.ColorGrayToBGR); err != nil {
+		return nil, err
+	}
+
+	result := gocv.NewMat()
+	if err := gocv.BitwiseAnd(image, mask, &result); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Cartoon flattens colors with repeated bilateral filtering and draws dark outlines found by adaptive thresholding.
+type Cartoon struct {
+	Intensity float64
+}
+
+func (c *Cartoon) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	return preserveAlpha(input, c.stylize)
+}
+
+func (_ *Cartoon) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (_ *Cartoon) Priority() Priority { return PriorityLow }
+
+func (c *Cartoon) stylize(input *gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := stylizeInput(input, c.Intensity)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	smooth := bgr.Clone()
+	defer smooth.Close()
+	filtered := gocv.NewMat()
+	defer filtered.Close()
+
+	for range scaleIntensity(c.Intensity, 1, 5) {
+		if err := gocv.BilateralFilter(smooth, &filtered, 9, 75, 75); err != nil {
+			return nil, err
+		}
+		smooth, filtered = filtered, smooth
+	}
+
+	gray, err := convertChannels(bgr, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	if err := gocv.MedianBlur(gray, &gray, 7); err != nil {
+		return nil, err
+	}
+
+	// pixels clearly darker than their neighbourhood become black outlines, a lower offset finds more of them
+	outlines := gocv.NewMat()
+	defer outlines.Close()
+	offset := float32(10 - scaleIntensity(c.Intensity, 0, 8))
+	if err := gocv.AdaptiveThreshold(gray, &outlines, 255, gocv.AdaptiveThresholdMean, gocv.ThresholdBinary, 9, offset); err != nil {
+		return nil, err
+	}
+
+	return keepOutsideEdges(smooth, outlines)
+}
+
+// PencilSketch color dodges the grayscale image with its blurred negative, leaving dark strokes along edges.
+type PencilSketch struct {
+	Intensity float64
+}
+
+func (p *PencilSketch) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	return preserveAlpha(input, p.stylize)
+}
+
+func (_ *PencilSketch) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (p *PencilSketch) stylize(input *gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := stylizeInput(input, p.Intensity)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	gray, err := convertChannels(bgr, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	negative := gocv.NewMat()
+	defer negative.Close()
+	gocv.BitwiseNot(gray, &negative)
+
+	// a wider blur gives thicker, darker strokes
+	sigma := 1 + p.Intensity*20
+	if err := gocv.GaussianBlur(negative, &negative, image.Point{}, sigma, sigma, gocv.BorderDefault); err != nil {
+		return nil, err
+	}
+
+	// color dodge: gray * 256 / (255 - blurred negative)
+	numerator := gocv.NewMat()
+	defer numerator.Close()
+	if err := gray.ConvertToWithParams(&numerator, gocv.MatTypeCV32F, 256, 0); err != nil {
+		return nil, err
+	}
+
+	denominator := gocv.NewMat()
+	defer denominator.Close()
+	if err := negative.ConvertToWithParams(&denominator, gocv.MatTypeCV32F, -1, 256); err != nil {
+		return nil, err
+	}
+
+	dodged := gocv.NewMat()
+	defer dodged.Close()
+	if err := gocv.Divide(numerator, denominator, &dodged); err != nil {
+		return nil, err
+	}
+
+	sketch := gocv.NewMat()
+	defer sketch.Close()
+	if err := dodged.ConvertTo(&sketch, gocv.MatTypeCV8U); err != nil {
+		return nil, err
+	}
+
+	result, err := convertChannels(sketch, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// OilPaint imitates brush strokes by median blurring and then opening the image with a round kernel.
+type OilPaint struct {
+	Intensity float64
+}
+
+func (o *OilPaint) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	return preserveAlpha(input, o.stylize)
+}
+
+func (_ *OilPaint) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (_ *OilPaint) Priority() Priority { return PriorityLow }
+
+func (o *OilPaint) stylize(input *gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := stylizeInput(input, o.Intensity)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	radius := scaleIntensity(o.Intensity, 1, 6)
+
+	blurred := gocv.NewMat()
+	defer blurred.Close()
+	if err := gocv.MedianBlur(bgr, &blurred, 2*radius+1); err != nil {
+		return nil, err
+	}
+
+	brush := gocv.GetStructuringElement(gocv.MorphEllipse, image.Point{X: radius + 1, Y: radius + 1})
+	defer brush.Close()
+
+	result := gocv.NewMat()
+	if err := gocv.MorphologyEx(blurred, &result, gocv.MorphOpen, brush); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Emboss applies the emboss kernel, Intensity blends it with the identity kernel.
+type Emboss struct {
+	Intensity float64
+}
+
+func (e *Emboss) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	return preserveAlpha(input, e.stylize)
+}
+
+func (_ *Emboss) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (e *Emboss) stylize(input *gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := stylizeInput(input, e.Intensity)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	emboss := presetKernels["emboss"]
+	center := len(emboss) / 2
+	kernel := make(Kernel, len(emboss))
+	for row := range emboss {
+		kernel[row] = make([]float32, len(emboss[row]))
+		for col, value := range emboss[row] {
+			kernel[row][col] = value * float32(e.Intensity)
+		}
+	}
+	// the center of the emboss kernel is 1, keeping it means the image keeps its brightness
+	kernel[center][center] = 1
+
+	kernelMat, err := kernelToMat(kernel)
+	if err != nil {
+		return nil, err
+	}
+	defer kernelMat.Close()
+
+	result := gocv.NewMat()
+	if err := gocv.Filter2D(bgr, &result, -1, kernelMat, image.Point{X: -1, Y: -1}, 0, gocv.BorderDefault); err != nil {
+		result.Close()
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Comic posterizes the image to a few levels per channel and outlines edges in black.
+// A higher Intensity uses fewer levels and thicker outlines.
+type Comic struct {
+	Intensity float64
+}
+
+func (c *Comic) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	return preserveAlpha(input, c.stylize)
+}
+
+func (_ *Comic) Alpha() AlphaBehaviour { return AlphaPreserve }
+
+func (_ *Comic) Priority() Priority { return PriorityLow }
+
+func (c *Comic) stylize(input *gocv.Mat) (*gocv.Mat, error) {
+
+	bgr, err := stylizeInput(input, c.Intensity)
+	if err != nil {
+		return nil, err
+	}
+	defer bgr.Close()
+
+	levels := 8 - scaleIntensity(c.Intensity, 0, 6)
+
+	lut := gocv.NewMatWithSize(1, 256, gocv.MatTypeCV8U)
+	defer lut.Close()
+	for value := range 256 {
+		level := value * levels / 256
+		lut.SetUCharAt(0, value, uint8(level*255/(levels-1)))
+	}
+
+	posterized := gocv.NewMat()
+	defer posterized.Close()
+	if err := gocv.LUT(bgr, lut, &posterized); err != nil {
+		return nil, err
+	}
+
+	gray, err := convertChannels(bgr, 1)
+	if err != nil {
+		return nil, err
+	}
+	defer gray.Close()
+
+	if err := gocv.MedianBlur(gray, &gray, 5); err != nil {
+		return nil, err
+	}
+
+	edges := gocv.NewMat()
+	defer edges.Close()
+	if err := gocv.Canny(gray, &edges, 50, 150); err != nil {
+		return nil, err
+	}
+
+	thickness := scaleIntensity(c.Intensity, 1, 3)
+	pen := gocv.GetStructuringElement(gocv.MorphRect, image.Point{X: thickness, Y: thickness})
+	defer pen.Close()
+	if err := gocv.Dilate(edges, &edges, pen); err != nil {
+		return nil, err
+	}
+
+	gocv.BitwiseNot(edges, &edges)
+
+	return keepOutsideEdges(posterized, edges)
+}
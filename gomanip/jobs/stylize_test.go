@@ -0,0 +1,140 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"testing"
+)
+
+var styles = []jobs.Style{jobs.StyleCartoon, jobs.StyleSketch, jobs.StyleOilPaint, jobs.StyleEmboss, jobs.StyleComic}
+
+func TestStylize(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		intensity float64
+	}{
+		{
+			name:      "test with various image sizes",
+			wantError: false,
+			images:    testImages,
+			intensity: 0.5,
+		},
+		{
+			name:      "test with full intensity",
+			wantError: false,
+			images:    testImages[:3],
+			intensity: 1.0,
+		},
+		{
+			name:      "test with low intensity",
+			wantError: false,
+			images:    testImages[:3],
+			intensity: 0.01,
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			intensity: 0.5,
+		},
+		{
+			name:      "Handle zero intensity",
+			wantError: true,
+			images:    testImages[:1],
+			intensity: 0,
+		},
+		{
+			name:      "Handle intensity above 1",
+			wantError: true,
+			images:    testImages[:1],
+			intensity: 1.5,
+		},
+	}
+
+	for _, style := range styles {
+		for _, tt := range tests {
+			t.Run(string(style)+" "+tt.name, func(t *testing.T) {
+				op, err := jobs.NewStylization(style, tt.intensity)
+				assert.NoError(t, err)
+
+				for _, image := range tt.images {
+					result, err := op.Run(image)
+					assert.Equal(t, tt.wantError, err != nil)
+					if err != nil {
+						continue
+					}
+
+					assert.Equal(t, image.Rows(), result.Rows())
+					assert.Equal(t, image.Cols(), result.Cols())
+					assert.Equal(t, 3, result.Channels())
+					result.Close()
+				}
+			})
+		}
+	}
+}
+
+func TestNewStylizationUnknownStyle(t *testing.T) {
+	_, err := jobs.NewStylization("watercolor", 0.5)
+	assert.Error(t, err)
+}
+
+func TestStylizeOtherChannels(t *testing.T) {
+
+	gray := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(128, 0, 0, 0), 32, 48, gocv.MatTypeCV8UC1)
+	defer gray.Close()
+	bgra := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(10, 120, 240, 255), 32, 48, gocv.MatTypeCV8UC4)
+	defer bgra.Close()
+
+	for _, style := range styles {
+		op, err := jobs.NewStylization(style, 0.5)
+		assert.NoError(t, err)
+
+		for _, image := range []*gocv.Mat{&gray, &bgra} {
+			result, err := op.Run(image)
+			assert.NoError(t, err, style)
+			assert.Equal(t, 3, result.Channels(), style)
+			result.Close()
+		}
+	}
+}
+
+func TestStylizeResults(t *testing.T) {
+
+	flat := gocv.NewMatWithSizeFromScalar(gocv.NewScalar(100, 150, 200, 0), 40, 40, gocv.MatTypeCV8UC3)
+	defer flat.Close()
+
+	// the emboss kernel sums to 1, so a flat image is unchanged
+	emboss := jobs.Emboss{Intensity: 1}
+	embossed, err := emboss.Run(&flat)
+	assert.NoError(t, err)
+	defer embossed.Close()
+	assert.InDelta(t, 1.0, similarity(t, &flat, embossed).SSIM, 1e-6)
+
+	// dodging a flat image with its own negative leaves a blank sheet
+	sketch := jobs.PencilSketch{Intensity: 0.5}
+	sketched, err := sketch.Run(&flat)
+	assert.NoError(t, err)
+	defer sketched.Close()
+	mean := sketched.Mean()
+	assert.Greater(t, mean.Val1, 250.0)
+
+	// at full intensity the comic look only has two levels per channel
+	original := gradientImage(128, 96)
+	defer original.Close()
+	comic := jobs.Comic{Intensity: 1}
+	posterized, err := comic.Run(&original)
+	assert.NoError(t, err)
+	defer posterized.Close()
+	for row := range posterized.Rows() {
+		for col := range posterized.Cols() {
+			for _, value := range posterized.GetVecbAt(row, col) {
+				assert.Contains(t, []uint8{0, 255}, value)
+			}
+		}
+	}
+}
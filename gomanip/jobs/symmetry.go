@@ -0,0 +1,153 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"gocv.io/x/gocv"
+	"image"
+	"math"
+)
+
+type MirrorSide string
+
+const (
+	MirrorLeft        MirrorSide = "left"
+	MirrorRight       MirrorSide = "right"
+	MirrorTop         MirrorSide = "top"
+	MirrorBottom      MirrorSide = "bottom"
+	MirrorTopLeft     MirrorSide = "topleft"
+	MirrorTopRight    MirrorSide = "topright"
+	MirrorBottomLeft  MirrorSide = "bottomleft"
+	MirrorBottomRight MirrorSide = "bottomright"
+)
+
+const (
+	minKaleidoscopeSegments = 2
+	maxKaleidoscopeSegments = 64
+)
+
+// mirrorSteps lists the halves each side is built from, quadrants mirror horizontally and then vertically.
+var mirrorSteps = map[MirrorSide][]MirrorSide{
+	MirrorLeft:        {MirrorLeft},
+	MirrorRight:       {MirrorRight},
+	MirrorTop:         {MirrorTop},
+	MirrorBottom:      {MirrorBottom},
+	MirrorTopLeft:     {MirrorLeft, MirrorTop},
+	MirrorTopRight:    {MirrorRight, MirrorTop},
+	MirrorBottomLeft:  {MirrorLeft, MirrorBottom},
+	MirrorBottomRight: {MirrorRight, MirrorBottom},
+}
+
+// Mirror reflects one half of the image onto the other, Side names the half that is kept.
+// The quadrant sides keep one quadrant and reflect it onto the other three.
+type Mirror struct {
+	Side MirrorSide
+}
+
+func (m *Mirror) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if input == nil || input.Empty() {
+		return nil, errors.New("input image is empty")
+	}
+
+	steps, ok := mirrorSteps[m.Side]
+	if !ok {
+		return nil, fmt.Errorf("unknown side %s", m.Side)
+	}
+
+	result := input.Clone()
+
+	for _, side := range steps {
+		if err := mirrorHalf(&result, side); err != nil {
+			result.Close()
+			return nil, err
+		}
+	}
+
+	return &result, nil
+}
+
+func (_ *Mirror) Alpha() AlphaBehaviour { return AlphaTransform }
+
+// mirrorHalf flips the kept half of mat in place onto the opposite half, the middle row or column
+// of an odd sized image is left alone.
+func mirrorHalf(mat *gocv.Mat, side MirrorSide) error {
+
+	rows, cols := mat.Rows(), mat.Cols()
+	halfCols, halfRows := cols/2, rows/2
+
+	var kept, replaced gocv.Mat
+	flipCode := 1
+
+	switch side {
+	case MirrorLeft:
+		kept = mat.Region(image.Rect(0, 0, halfCols, rows))
+		replaced = mat.Region(image.Rect(cols-halfCols, 0, cols, rows))
+	case MirrorRight:
+		kept = mat.Region(image.Rect(cols-halfCols, 0, cols, rows))
+		replaced = mat.Region(image.Rect(0, 0, halfCols, rows))
+	case MirrorTop:
+		kept = mat.Region(image.Rect(0, 0, cols, halfRows))
+		replaced = mat.Region(image.Rect(0, rows-halfRows, cols, rows))
+		flipCode = 0
+	case MirrorBottom:
+		kept = mat.Region(image.Rect(0, rows-halfRows, cols, rows))
+		replaced = mat.Region(image.Rect(0, 0, cols, halfRows))
+		flipCode = 0
+	default:
+		return fmt.Errorf("unknown side %s", side)
+	}
+	defer kept.Close()
+	defer replaced.Close()
+
+	if kept.Empty() {
+		// a single row or column has nothing to mirror
+		return nil
+	}
+
+	flipped := gocv.NewMat()
+	defer flipped.Close()
+	if err := gocv.Flip(kept, &flipped, flipCode); err != nil {
+		return err
+	}
+
+	return flipped.CopyTo(&replaced)
+}
+
+// Kaleidoscope splits the image into Segments wedges around the center and fills every wedge with
+// the reflected contents of the first one. X and Y are percentages along the width and height.
+type Kaleidoscope struct {
+	Segments int
+	X        float64
+	Y        float64
+}
+
+func (k *Kaleidoscope) Run(input *gocv.Mat) (*gocv.Mat, error) {
+
+	if err := validateCenter(input, k.X, k.Y); err != nil {
+		return nil, err
+	}
+
+	if k.Segments < minKaleidoscopeSegments || k.Segments > maxKaleidoscopeSegments {
+		return nil, fmt.Errorf("expected segments to be between %d and %d, got %d", minKaleidoscopeSegments, maxKaleidoscopeSegments, k.Segments)
+	}
+
+	cx, cy := k.X*float64(input.Cols()), k.Y*float64(input.Rows())
+	wedge := 2 * math.Pi / float64(k.Segments)
+
+	return remap(*input, func(x, y float64) (float64, float64) {
+		dx, dy := x-cx, y-cy
+		distance := math.Hypot(dx, dy)
+
+		// fold the angle into the first wedge, mirroring every other wedge so neighbours meet seamlessly
+		angle := math.Mod(math.Atan2(dy, dx)+2*math.Pi, 2*wedge)
+		if angle > wedge {
+			angle = 2*wedge - angle
+		}
+
+		sin, cos := math.Sincos(angle)
+		return cx + distance*cos, cy + distance*sin
+	})
+}
+
+func (_ *Kaleidoscope) Alpha() AlphaBehaviour { return AlphaTransform }
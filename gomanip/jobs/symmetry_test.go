@@ -0,0 +1,176 @@
+package jobs_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
+	"gocv.io/x/gocv"
+	"image"
+	"testing"
+)
+
+func TestMirror(t *testing.T) {
+
+	sides := []jobs.MirrorSide{
+		jobs.MirrorLeft, jobs.MirrorRight, jobs.MirrorTop, jobs.MirrorBottom,
+		jobs.MirrorTopLeft, jobs.MirrorTopRight, jobs.MirrorBottomLeft, jobs.MirrorBottomRight,
+	}
+
+	for _, side := range sides {
+		t.Run(string(side), func(t *testing.T) {
+			for _, image := range testImages {
+				result, err := jobs.NewMirror(side).Run(image)
+				assert.NoError(t, err)
+				assert.Equal(t, image.Rows(), result.Rows())
+				assert.Equal(t, image.Cols(), result.Cols())
+				assert.Equal(t, image.Channels(), result.Channels())
+				result.Close()
+			}
+		})
+	}
+
+	_, err := jobs.NewMirror(jobs.MirrorLeft).Run(nil)
+	assert.Error(t, err)
+
+	_, err = jobs.NewMirror("diagonal").Run(testImages[0])
+	assert.Error(t, err)
+}
+
+// isSymmetric checks whether flipping mat with flipCode leaves it unchanged.
+func isSymmetric(t *testing.T, mat *gocv.Mat, flipCode int) bool {
+	t.Helper()
+
+	flipped := gocv.NewMat()
+	defer flipped.Close()
+	gocv.Flip(*mat, &flipped, flipCode)
+
+	diff := gocv.NewMat()
+	defer diff.Close()
+	gocv.AbsDiff(*mat, flipped, &diff)
+
+	gray := gocv.NewMat()
+	defer gray.Close()
+	gocv.CvtColor(diff, &gray, gocv.ColorBGRToGray)
+
+	return gocv.CountNonZero(gray) == 0
+}
+
+func TestMirrorSymmetry(t *testing.T) {
+
+	tests := []struct {
+		name       string
+		side       jobs.MirrorSide
+		horizontal bool
+		vertical   bool
+		kept       image.Rectangle
+	}{
+		{name: "left", side: jobs.MirrorLeft, horizontal: true, kept: image.Rect(0, 0, 60, 81)},
+		{name: "right", side: jobs.MirrorRight, horizontal: true, kept: image.Rect(61, 0, 121, 81)},
+		{name: "top", side: jobs.MirrorTop, vertical: true, kept: image.Rect(0, 0, 121, 40)},
+		{name: "bottom", side: jobs.MirrorBottom, vertical: true, kept: image.Rect(0, 41, 121, 81)},
+		{name: "top left quadrant", side: jobs.MirrorTopLeft, horizontal: true, vertical: true, kept: image.Rect(0, 0, 60, 40)},
+		{name: "bottom right quadrant", side: jobs.MirrorBottomRight, horizontal: true, vertical: true, kept: image.Rect(61, 41, 121, 81)},
+	}
+
+	// odd sizes, the middle row and column stay as they are
+	original := gradientImage(121, 81)
+	defer original.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := jobs.NewMirror(tt.side).Run(&original)
+			assert.NoError(t, err)
+			defer result.Close()
+
+			if tt.horizontal {
+				assert.True(t, isSymmetric(t, result, 1))
+			}
+			if tt.vertical {
+				assert.True(t, isSymmetric(t, result, 0))
+			}
+
+			originalKept := original.Region(tt.kept)
+			defer originalKept.Close()
+			resultKept := result.Region(tt.kept)
+			defer resultKept.Close()
+			assert.Zero(t, similarity(t, &originalKept, &resultKept).MSE)
+		})
+	}
+}
+
+func TestKaleidoscope(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		wantError bool
+		images    []*gocv.Mat
+		op        jobs.Operation
+	}{
+		{
+			name:      "test with various image sizes",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewKaleidoscope(6, 0.5, 0.5),
+		},
+		{
+			name:      "test off center with odd segments",
+			wantError: false,
+			images:    testImages,
+			op:        jobs.NewKaleidoscope(7, 0.2, 0.8),
+		},
+		{
+			name:      "Handle Nil image case",
+			wantError: true,
+			images:    []*gocv.Mat{nil},
+			op:        jobs.NewKaleidoscope(6, 0.5, 0.5),
+		},
+		{
+			name:      "Handle too few segments",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewKaleidoscope(1, 0.5, 0.5),
+		},
+		{
+			name:      "Handle center outside the image",
+			wantError: true,
+			images:    testImages[:1],
+			op:        jobs.NewKaleidoscope(6, -0.1, 0.5),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, image := range tt.images {
+				result, err := tt.op.Run(image)
+				assert.Equal(t, tt.wantError, err != nil)
+				if err != nil {
+					continue
+				}
+
+				assert.Equal(t, image.Rows(), result.Rows())
+				assert.Equal(t, image.Cols(), result.Cols())
+				result.Close()
+			}
+		})
+	}
+}
+
+func TestKaleidoscopeRepeatsFirstWedge(t *testing.T) {
+
+	original := gradientImage(200, 200)
+	defer original.Close()
+
+	result, err := jobs.NewKaleidoscope(4, 0.5, 0.5).Run(&original)
+	assert.NoError(t, err)
+	defer result.Close()
+
+	// the first wedge is the bottom right quadrant, it is kept
+	wedge := image.Rect(110, 110, 200, 200)
+	originalWedge := original.Region(wedge)
+	defer originalWedge.Close()
+	resultWedge := result.Region(wedge)
+	defer resultWedge.Close()
+	assert.Greater(t, similarity(t, &originalWedge, &resultWedge).SSIM, 0.95)
+
+	// and mirrored into the others
+	assert.Greater(t, similarity(t, result, &original).MSE, 0.0)
+}
@@ -2,13 +2,16 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
-	"runtime"
-	"sync"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,8 +20,14 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"gocv.io/x/gocv"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
 	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/config"
+	gomanipErrors "goManip/errors"
+	"goManip/grpcapi"
 	"goManip/jobs"
 	"goManip/util"
 	"goManip/worker"
@@ -30,6 +39,24 @@ import (
 
 
 
+// operationFailed reports a failed job, only errors of the operation itself are a bad request. Jobs that panicked,
+// ran out of time or were never run because of the server or the key's quota are reported with their own status.
+func operationFailed(c echo.Context, message string, err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrInternal):
+		return gomanipErrors.ReturnJsonError(c, http.StatusInternalServerError, message+": "+err.Error())
+	case errors.Is(err, auth.ErrQuotaExceeded):
+		return gomanipErrors.ReturnJsonError(c, http.StatusTooManyRequests, message+": "+err.Error())
+	case errors.Is(err, JobDispatch.ErrTooExpensive):
+		return gomanipErrors.ReturnJsonError(c, http.StatusUnprocessableEntity, message+": "+err.Error())
+	case errors.Is(err, JobDispatch.ErrTimeout):
+		return gomanipErrors.ReturnJsonError(c, http.StatusGatewayTimeout, message+": "+err.Error())
+	case errors.Is(err, JobDispatch.ErrDispatcherClosed):
+		return gomanipErrors.ReturnJsonError(c, http.StatusServiceUnavailable, message+": "+err.Error())
+	}
+	return c.String(http.StatusBadRequest, message+": "+err.Error())
+}
+
 func getDispatcher(c echo.Context) *JobDispatch.JobDispatcher {
 	return c.Get("jobDispatcher").(*JobDispatch.JobDispatcher)
 }
@@ -44,15 +71,73 @@ func handleImageOperation(
 		log.Error().Err(err).Msg("Failed to read image")
 		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
 	}
+	defer JobDispatch.ReleaseImage(image)
+
+	resultImage, err := processFunc(image)
+	if err != nil {
+		log.Error().Err(err).Msg("Image processing failed")
+		return operationFailed(c, "Image processing failed", err)
+	}
+
+	return util.WriteImage(c, "image/png", resultImage)
+}
+
+// handleAnimation renders an animated GIF from the uploaded image.
+func handleAnimation(c echo.Context, jobDispatcher *JobDispatch.JobDispatcher, animation jobs.Animated) error {
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	animated, err := JobDispatch.EnqueueAnimation(jobDispatcher, image, animation)
+	if err != nil {
+		log.Error().Err(err).Msg("Animation failed")
+		return operationFailed(c, "Animation failed", err)
+	}
+
+	return c.Blob(http.StatusOK, "image/gif", animated)
+}
+
+// jigsawResponse is returned by the shuffle endpoint in jigsaw mode,
+// the image is base64 encoded in the json.
+type jigsawResponse struct {
+	jobs.ShuffleLayout
+	Image []byte `json:"image"`
+}
+
+// kernelsResponse is returned by the random filter endpoint when the kernels are exported,
+// they can be passed back to the convolve endpoint to apply the same filter again.
+type kernelsResponse struct {
+	Kernels []jobs.Kernel `json:"kernels"`
+	Image   []byte        `json:"image"`
+}
+
+// handleImageReportOperation works like handleImageOperation, but responds with the JSON value
+// built by report from the encoded result image instead of the raw image bytes.
+func handleImageReportOperation(
+	c echo.Context,
+	processFunc func(image *gocv.Mat) (*gocv.NativeByteBuffer, error),
+	report func(image []byte) any,
+) error {
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
 
 	resultImage, err := processFunc(image)
 	if err != nil {
 		log.Error().Err(err).Msg("Image processing failed")
-		return c.String(http.StatusBadRequest, "Image processing failed: "+err.Error())
+		return operationFailed(c, "Image processing failed", err)
 	}
 	defer resultImage.Close()
 
-	return c.Blob(http.StatusOK, "image/png", resultImage.GetBytes())
+	return c.JSON(http.StatusOK, report(resultImage.GetBytes()))
 }
 
 func InvertEndpoint(c echo.Context) error {
@@ -142,126 +227,1221 @@ func ReduceEndpoint(c echo.Context) error {
 	})
 }
 
-func AddTextEndpoint(c echo.Context) error {
+func StylizeEndpoint(c echo.Context) error {
 	jobDispatcher := getDispatcher(c)
 	if jobDispatcher == nil {
 		log.Error().Msg("Job dispatcher is not present in the context")
 		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
 	}
 
-	text, fontScale, xPerc, yPerc, err := util.ParseAddText(c)
+	style, intensity, err := util.ParseStylize(c)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse add text")
-		return c.String(http.StatusBadRequest, "Failed to parse add text: "+err.Error())
+		log.Error().Err(err).Msg("Failed to parse stylize")
+		return c.String(http.StatusBadRequest, "Failed to parse stylize: "+err.Error())
 	}
 
 	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-		return JobDispatch.EnqueueAddText(jobDispatcher, image, text, fontScale, xPerc, yPerc)
+		return JobDispatch.EnqueueStylize(jobDispatcher, image, style, intensity)
+	})
+}
+
+func SwirlEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	xPerc, yPerc, radius, strength, err := util.ParseSwirl(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse swirl")
+		return c.String(http.StatusBadRequest, "Failed to parse swirl: "+err.Error())
+	}
 
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueSwirl(jobDispatcher, image, xPerc, yPerc, radius, strength)
 	})
 }
 
-func RandomFilterEndpoint(c echo.Context) error {
+func BulgeEndpoint(c echo.Context) error {
 	jobDispatcher := getDispatcher(c)
 	if jobDispatcher == nil {
 		log.Error().Msg("Job dispatcher is not present in the context")
 		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
 	}
 
-	minVal, maxVal, kernelSize, normalize, err := util.ParseRandomFilter(c)
+	xPerc, yPerc, radius, strength, err := util.ParseBulge(c)
+
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse random filter")
-		return c.String(http.StatusBadRequest, "Failed to parse random filter: "+err.Error())
+		log.Error().Err(err).Msg("Failed to parse bulge")
+		return c.String(http.StatusBadRequest, "Failed to parse bulge: "+err.Error())
 	}
 
 	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-		return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, minVal, maxVal, kernelSize, normalize)
+		return JobDispatch.EnqueueBulge(jobDispatcher, image, xPerc, yPerc, radius, strength)
 	})
 }
-func ShuffleEndpoint(c echo.Context) error {
+
+func WaveEndpoint(c echo.Context) error {
 	jobDispatcher := getDispatcher(c)
 	if jobDispatcher == nil {
 		log.Error().Msg("Job dispatcher is not present in the context")
 		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
 	}
 
-	partitions, err := util.ParseShuffle(c)
+	amplitude, wavelength, err := util.ParseWave(c)
+
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to parse shuffle")
-		return c.String(http.StatusBadRequest, "Failed to parse shuffle: "+err.Error())
+		log.Error().Err(err).Msg("Failed to parse wave")
+		return c.String(http.StatusBadRequest, "Failed to parse wave: "+err.Error())
 	}
 
 	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
-		return JobDispatch.EnqueueShuffle(jobDispatcher, image, partitions)
+		return JobDispatch.EnqueueWave(jobDispatcher, image, amplitude, wavelength)
 	})
 }
 
-func initRouting(e *echo.Echo, jobDispatcher *JobDispatch.JobDispatcher) {
-	e.Use(gomanipMiddleware.JobDispatcherMiddleware(jobDispatcher))
-	e.Use(gomanipMiddleware.FileTypeVerifyMiddleware())
-	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
-		LogStatus: true,
-		LogURI:    true,
-		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-			log.Info().
-				Str("URI", v.URI).
-				Int("status", v.Status).
-				Str("method", c.Request().Method).
-				Str("Time", v.StartTime.String()).
-				Msg("Request")
-			return nil
-		},
-	}))
+func FisheyeEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	xPerc, yPerc, strength, err := util.ParseFisheye(c)
 
-	e.POST("/invert/", InvertEndpoint)
-	e.POST("/saturate/", SaturateEndpoint)
-	e.POST("/edgeDetection/", EdgeDetectionEndpoint)
-	e.POST("/morphology/", MorphologyEndpoint)
-	e.POST("/reduction/", ReduceEndpoint)
-	e.POST("/text/", AddTextEndpoint)
-	e.POST("/randomFilter/", RandomFilterEndpoint)
-	e.POST("/shuffle/", ShuffleEndpoint)
-	e.Logger.Fatal(e.Start(":8080"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse fisheye")
+		return c.String(http.StatusBadRequest, "Failed to parse fisheye: "+err.Error())
+	}
 
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueFisheye(jobDispatcher, image, xPerc, yPerc, strength)
+	})
 }
 
-func GraceFullShutdown(jobDispatcher *JobDispatch.JobDispatcher, wg *sync.WaitGroup, cancel context.CancelFunc) {
-	log.Info().Msg("Closing worker request channels")
-	jobDispatcher.Close()
-	log.Info().Msg("Stopping Workers")
-	cancel()
-	wg.Wait()
+func MirrorEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	side, err := util.ParseMirror(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse mirror")
+		return c.String(http.StatusBadRequest, "Failed to parse mirror: "+err.Error())
+	}
 
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueMirror(jobDispatcher, image, side)
+	})
 }
 
-func main() {
-	prettyPrint := flag.Bool("pretty_print", false, "Enable pretty print output")
-	numWorkers := flag.Int("num_workers", runtime.NumCPU(), "Number of workers")
-	flag.Parse()
+func KaleidoscopeEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
 
-	if *prettyPrint {
-		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	segments, xPerc, yPerc, err := util.ParseKaleidoscope(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse kaleidoscope")
+		return c.String(http.StatusBadRequest, "Failed to parse kaleidoscope: "+err.Error())
 	}
-	jobReqs := make(chan *jobs.JobRequest, *numWorkers)
-	maxTime := time.Second * 10
-	jobDispatcher := JobDispatch.NewJobDispatcher(jobReqs, maxTime)
-	wg := &sync.WaitGroup{}
-	ctx, cancel := context.WithCancel(context.Background())
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
-	go func() {
-		<-c
-		GraceFullShutdown(jobDispatcher, wg, cancel)
-		os.Exit(0)
-	}()
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueKaleidoscope(jobDispatcher, image, segments, xPerc, yPerc)
+	})
+}
+
+func GaussianNoiseEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	sigma, seed, err := util.ParseGaussianNoise(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse gaussian noise")
+		return c.String(http.StatusBadRequest, "Failed to parse gaussian noise: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueGaussianNoise(jobDispatcher, image, sigma, seed)
+	})
+}
+
+func SaltAndPepperEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	amount, seed, err := util.ParseSaltAndPepper(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse salt and pepper")
+		return c.String(http.StatusBadRequest, "Failed to parse salt and pepper: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueSaltAndPepper(jobDispatcher, image, amount, seed)
+	})
+}
+
+func FilmGrainEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	strength, seed, err := util.ParseFilmGrain(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse film grain")
+		return c.String(http.StatusBadRequest, "Failed to parse film grain: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueFilmGrain(jobDispatcher, image, strength, seed)
+	})
+}
+
+func VignetteEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	strength, radius, err := util.ParseVignette(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse vignette")
+		return c.String(http.StatusBadRequest, "Failed to parse vignette: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueVignette(jobDispatcher, image, strength, radius)
+	})
+}
+
+func OldPhotoEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	quality, grain, vignette, seed, err := util.ParseOldPhoto(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse old photo")
+		return c.String(http.StatusBadRequest, "Failed to parse old photo: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueOldPhoto(jobDispatcher, image, quality, grain, vignette, seed)
+	})
+}
+
+func ColorKeyEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	key, auto, tolerance, err := util.ParseColorKey(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse color key")
+		return c.String(http.StatusBadRequest, "Failed to parse color key: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueColorKey(jobDispatcher, image, key, auto, tolerance)
+	})
+}
+
+func ZoomEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	animation, scale, xPerc, yPerc, err := util.ParseZoom(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse zoom")
+		return c.String(http.StatusBadRequest, "Failed to parse zoom: "+err.Error())
+	}
+
+	return handleAnimation(c, jobDispatcher, jobs.NewZoom(animation.Frames, animation.Delay, scale, xPerc, yPerc))
+}
+
+func SpinEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	animation, turns, err := util.ParseSpin(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse spin")
+		return c.String(http.StatusBadRequest, "Failed to parse spin: "+err.Error())
+	}
+
+	return handleAnimation(c, jobDispatcher, jobs.NewSpin(animation.Frames, animation.Delay, turns))
+}
+
+func ShakeEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	animation, amount, caption, seed, err := util.ParseShake(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse shake")
+		return c.String(http.StatusBadRequest, "Failed to parse shake: "+err.Error())
+	}
+
+	return handleAnimation(c, jobDispatcher, jobs.NewShake(animation.Frames, animation.Delay, amount, caption, seed))
+}
+
+func TweenEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	animation, operation, from, to, err := util.ParseTween(c)
 
-	for workerId := range *numWorkers {
-		log.Info().Msgf("Starting worker #%d", workerId+1)
-		wg.Add(1)
-		go worker.Worker(ctx, workerId+1, jobReqs, wg)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse tween")
+		return c.String(http.StatusBadRequest, "Failed to parse tween: "+err.Error())
 	}
+
+	return handleAnimation(c, jobDispatcher, jobs.NewTween(animation.Frames, animation.Delay, operation, from, to))
+}
+
+func ExportEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	target, fit, err := util.ParseExport(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse export")
+		return c.String(http.StatusBadRequest, "Failed to parse export: "+err.Error())
+	}
+
+	export, err := jobs.NewExport(target, fit)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse export")
+		return c.String(http.StatusBadRequest, "Failed to parse export: "+err.Error())
+	}
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	exported, err := JobDispatch.EnqueueExport(jobDispatcher, image, export)
+	if err != nil {
+		log.Error().Err(err).Msg("Export failed")
+		return operationFailed(c, "Export failed", err)
+	}
+
+	return c.Blob(http.StatusOK, "image/png", exported)
+}
+
+func AddTextEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	text, fontScale, xPerc, yPerc, err := util.ParseAddText(c)
+
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse add text")
+		return c.String(http.StatusBadRequest, "Failed to parse add text: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueAddText(jobDispatcher, image, text, fontScale, xPerc, yPerc)
+
+	})
+}
+
+func RandomFilterEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	minVal, maxVal, kernelSize, normalize, err := util.ParseRandomFilter(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse random filter")
+		return c.String(http.StatusBadRequest, "Failed to parse random filter: "+err.Error())
+	}
+
+	filter := jobs.NewRandomFilter(kernelSize, minVal, maxVal, normalize)
+
+	processFunc := func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueRandomFilter(jobDispatcher, image, filter)
+	}
+
+	if !util.ParseExportKernels(c) {
+		return handleImageOperation(c, processFunc)
+	}
+
+	return handleImageReportOperation(c, processFunc, func(image []byte) any {
+		return &kernelsResponse{Kernels: filter.Kernels, Image: image}
+	})
+}
+
+func ConvolveEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	kernels, preset, err := util.ParseConvolve(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse convolve")
+		return c.String(http.StatusBadRequest, "Failed to parse convolve: "+err.Error())
+	}
+
+	return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueConvolve(jobDispatcher, image, kernels, preset)
+	})
+}
+
+func ShuffleEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	params, err := util.ParseShuffle(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse shuffle")
+		return c.String(http.StatusBadRequest, "Failed to parse shuffle: "+err.Error())
+	}
+
+	shuffle := jobs.NewGridShuffle(params.Rows, params.Cols, params.Swaps, params.Rotate, params.Flip)
+	shuffle.Partitions = params.Partitions
+
+	processFunc := func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+		return JobDispatch.EnqueueShuffle(jobDispatcher, image, shuffle)
+	}
+
+	if !params.Jigsaw {
+		return handleImageOperation(c, processFunc)
+	}
+
+	// jigsaw mode returns the layout with the image so a puzzle can be checked against it
+	return handleImageReportOperation(c, processFunc, func(image []byte) any {
+		return &jigsawResponse{ShuffleLayout: shuffle.Layout, Image: image}
+	})
+}
+
+type detectResponse struct {
+	Boxes []jobs.Box `json:"boxes"`
+}
+
+func DetectEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	params, err := util.ParseDetect(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse detect")
+		return c.String(http.StatusBadRequest, "Failed to parse detect: "+err.Error())
+	}
+
+	cascadePath, ok := c.Get("cascades").(*util.AssetDir).Path(params.Cascade)
+	if !ok {
+		return c.String(http.StatusBadRequest, "Unknown cascade: "+params.Cascade)
+	}
+
+	detect := jobs.NewDetect(cascadePath, jobs.DetectAction(params.Action), params.ScaleFactor, params.MinNeighbors)
+
+	if params.Overlay != "" {
+		overlayPath, ok := c.Get("overlays").(*util.AssetDir).Path(params.Overlay)
+		if !ok {
+			return c.String(http.StatusBadRequest, "Unknown overlay: "+params.Overlay)
+		}
+		detect.OverlayPath = overlayPath
+	}
+
+	if detect.Action != jobs.DetectNone {
+		return handleImageOperation(c, func(image *gocv.Mat) (*gocv.NativeByteBuffer, error) {
+			return JobDispatch.EnqueueDetect(jobDispatcher, image, detect)
+		})
+	}
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	if _, err := JobDispatch.EnqueueDetect(jobDispatcher, image, detect); err != nil {
+		log.Error().Err(err).Msg("Detection failed")
+		return operationFailed(c, "Detection failed", err)
+	}
+
+	return c.JSON(http.StatusOK, &detectResponse{Boxes: detect.Boxes})
+}
+
+type qrDecodeResponse struct {
+	Codes []jobs.QRCode `json:"codes"`
+}
+
+func QRDecodeEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	decode := jobs.NewQRDecode()
+	if err := JobDispatch.EnqueueQRDecode(jobDispatcher, image, decode); err != nil {
+		log.Error().Err(err).Msg("QR code decoding failed")
+		return operationFailed(c, "QR code decoding failed", err)
+	}
+
+	return c.JSON(http.StatusOK, &qrDecodeResponse{Codes: decode.Codes})
+}
+
+func AnalyzeEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	colors, bins, err := util.ParseAnalyze(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse analyze")
+		return c.String(http.StatusBadRequest, "Failed to parse analyze: "+err.Error())
+	}
+
+	analyze := jobs.NewAnalyze(colors, bins)
+	if err := JobDispatch.EnqueueAnalyze(jobDispatcher, image, analyze); err != nil {
+		log.Error().Err(err).Msg("Analysis failed")
+		return operationFailed(c, "Analysis failed", err)
+	}
+
+	return c.JSON(http.StatusOK, &analyze.Result)
+}
+
+// compareResponse is returned by the compare endpoint when a heatmap is requested,
+// the heatmap is base64 encoded in the json.
+type compareResponse struct {
+	jobs.Similarity
+	Heatmap []byte `json:"heatmap"`
+}
+
+// CompareEndpoint compares the first and second images of a multipart form.
+func CompareEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	first, err := util.GetImageFromForm(c, "first")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(first)
+
+	second, err := util.GetImageFromForm(c, "second")
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(second)
+
+	compare := jobs.NewCompare(second, util.ParseHeatmap(c))
+	heatmap, err := JobDispatch.EnqueueCompare(jobDispatcher, first, compare)
+	if err != nil {
+		log.Error().Err(err).Msg("Comparison failed")
+		return operationFailed(c, "Comparison failed", err)
+	}
+
+	if heatmap == nil {
+		return c.JSON(http.StatusOK, &compare.Result)
+	}
+	defer heatmap.Close()
+
+	return c.JSON(http.StatusOK, &compareResponse{Similarity: compare.Result, Heatmap: heatmap.GetBytes()})
+}
+
+type asciiArtResponse struct {
+	Columns int      `json:"columns"`
+	Rows    int      `json:"rows"`
+	Lines   []string `json:"lines"`
+}
+
+func AsciiArtEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read image")
+		return c.String(http.StatusBadRequest, "Failed to read image: "+err.Error())
+	}
+	defer JobDispatch.ReleaseImage(image)
+
+	columns, ramp, emoji, asJSON, err := util.ParseAsciiArt(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse ascii art")
+		return c.String(http.StatusBadRequest, "Failed to parse ascii art: "+err.Error())
+	}
+
+	art := jobs.NewAsciiArt(columns, ramp, emoji)
+	if err := JobDispatch.EnqueueAsciiArt(jobDispatcher, image, art); err != nil {
+		log.Error().Err(err).Msg("Ascii art rendering failed")
+		return operationFailed(c, "Ascii art rendering failed", err)
+	}
+
+	if asJSON {
+		return c.JSON(http.StatusOK, &asciiArtResponse{Columns: columns, Rows: len(art.Lines), Lines: art.Lines})
+	}
+
+	return c.String(http.StatusOK, strings.Join(art.Lines, "\n"))
+}
+
+// batchOperation parses the parameters of an operation from the request once and returns a function
+// building a fresh operation for every image of the batch.
+type batchOperation func(c echo.Context) (func() jobs.Operation, error)
+
+// batchOperations are the operations a batch can run, named after their endpoints.
+// Every step of a pipeline reads its parameters from the same query.
+var batchOperations = map[string]batchOperation{
+	"invert": func(c echo.Context) (func() jobs.Operation, error) {
+		return jobs.NewInvert, nil
+	},
+	"saturate": func(c echo.Context) (func() jobs.Operation, error) {
+		saturation, err := util.ParseSaturation(c)
+		return func() jobs.Operation { return jobs.NewSaturate(saturation) }, err
+	},
+	"edgeDetection": func(c echo.Context) (func() jobs.Operation, error) {
+		lower, higher, err := util.ParseEdgeDetection(c)
+		return func() jobs.Operation { return jobs.NewEdgeDetection(lower, higher) }, err
+	},
+	"morphology": func(c echo.Context) (func() jobs.Operation, error) {
+		morphType, kernelSize, iterations, err := util.ParseMorphology(c)
+		return func() jobs.Operation { return jobs.NewMorphology(kernelSize, iterations, jobs.Choice(morphType)) }, err
+	},
+	"reduction": func(c echo.Context) (func() jobs.Operation, error) {
+		quality, err := util.ParseReduce(c)
+		return func() jobs.Operation { return jobs.NewReduce(quality) }, err
+	},
+	"text": func(c echo.Context) (func() jobs.Operation, error) {
+		text, fontScale, xPerc, yPerc, err := util.ParseAddText(c)
+		return func() jobs.Operation { return jobs.NewAddText(text, fontScale, xPerc, yPerc) }, err
+	},
+	"randomFilter": func(c echo.Context) (func() jobs.Operation, error) {
+		minVal, maxVal, kernelSize, normalize, err := util.ParseRandomFilter(c)
+		return func() jobs.Operation { return jobs.NewRandomFilter(kernelSize, minVal, maxVal, normalize) }, err
+	},
+	"shuffle": func(c echo.Context) (func() jobs.Operation, error) {
+		// a batch only returns images, so a jigsaw layout has nowhere to go
+		params, err := util.ParseShuffle(c)
+		return func() jobs.Operation {
+			shuffle := jobs.NewGridShuffle(params.Rows, params.Cols, params.Swaps, params.Rotate, params.Flip)
+			shuffle.Partitions = params.Partitions
+			return shuffle
+		}, err
+	},
+	"convolve": func(c echo.Context) (func() jobs.Operation, error) {
+		kernels, preset, err := util.ParseConvolve(c)
+		return func() jobs.Operation { return jobs.NewConvolve(kernels, preset) }, err
+	},
+	"stylize": func(c echo.Context) (func() jobs.Operation, error) {
+		style, intensity, err := util.ParseStylize(c)
+		if err != nil {
+			return nil, err
+		}
+		// build one up front so an unknown style is reported before any image is processed
+		if _, err := jobs.NewStylization(style, intensity); err != nil {
+			return nil, err
+		}
+		return func() jobs.Operation {
+			op, _ := jobs.NewStylization(style, intensity)
+			return op
+		}, nil
+	},
+	"swirl": func(c echo.Context) (func() jobs.Operation, error) {
+		xPerc, yPerc, radius, strength, err := util.ParseSwirl(c)
+		return func() jobs.Operation { return jobs.NewSwirl(xPerc, yPerc, radius, strength) }, err
+	},
+	"bulge": func(c echo.Context) (func() jobs.Operation, error) {
+		xPerc, yPerc, radius, strength, err := util.ParseBulge(c)
+		return func() jobs.Operation { return jobs.NewBulge(xPerc, yPerc, radius, strength) }, err
+	},
+	"wave": func(c echo.Context) (func() jobs.Operation, error) {
+		amplitude, wavelength, err := util.ParseWave(c)
+		return func() jobs.Operation { return jobs.NewWave(amplitude, wavelength) }, err
+	},
+	"fisheye": func(c echo.Context) (func() jobs.Operation, error) {
+		xPerc, yPerc, strength, err := util.ParseFisheye(c)
+		return func() jobs.Operation { return jobs.NewFisheye(xPerc, yPerc, strength) }, err
+	},
+	"mirror": func(c echo.Context) (func() jobs.Operation, error) {
+		side, err := util.ParseMirror(c)
+		return func() jobs.Operation { return jobs.NewMirror(side) }, err
+	},
+	"kaleidoscope": func(c echo.Context) (func() jobs.Operation, error) {
+		segments, xPerc, yPerc, err := util.ParseKaleidoscope(c)
+		return func() jobs.Operation { return jobs.NewKaleidoscope(segments, xPerc, yPerc) }, err
+	},
+	"gaussianNoise": func(c echo.Context) (func() jobs.Operation, error) {
+		sigma, seed, err := util.ParseGaussianNoise(c)
+		return func() jobs.Operation { return jobs.NewGaussianNoise(sigma, seed) }, err
+	},
+	"saltAndPepper": func(c echo.Context) (func() jobs.Operation, error) {
+		amount, seed, err := util.ParseSaltAndPepper(c)
+		return func() jobs.Operation { return jobs.NewSaltAndPepper(amount, seed) }, err
+	},
+	"filmGrain": func(c echo.Context) (func() jobs.Operation, error) {
+		strength, seed, err := util.ParseFilmGrain(c)
+		return func() jobs.Operation { return jobs.NewFilmGrain(strength, seed) }, err
+	},
+	"vignette": func(c echo.Context) (func() jobs.Operation, error) {
+		strength, radius, err := util.ParseVignette(c)
+		return func() jobs.Operation { return jobs.NewVignette(strength, radius) }, err
+	},
+	"oldPhoto": func(c echo.Context) (func() jobs.Operation, error) {
+		quality, grain, vignette, seed, err := util.ParseOldPhoto(c)
+		return func() jobs.Operation { return jobs.NewOldPhoto(quality, grain, vignette, seed) }, err
+	},
+	"removeBackground": func(c echo.Context) (func() jobs.Operation, error) {
+		key, auto, tolerance, err := util.ParseColorKey(c)
+		return func() jobs.Operation { return jobs.NewColorKey(key, auto, tolerance) }, err
+	},
+}
+
+// newBatchOperation builds the operation for a batch, more than one operation runs as a pipeline.
+func newBatchOperation(c echo.Context, names []string) (func() jobs.Operation, error) {
+	steps := make([]func() jobs.Operation, len(names))
+	for idx, name := range names {
+		parse, ok := batchOperations[name]
+		if !ok {
+			return nil, fmt.Errorf("operation %s can not be used in a batch", name)
+		}
+
+		step, err := parse(c)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		steps[idx] = step
+	}
+
+	if len(steps) == 1 {
+		return steps[0], nil
+	}
+
+	return func() jobs.Operation {
+		pipeline := make([]jobs.Operation, len(steps))
+		for idx, step := range steps {
+			pipeline[idx] = step()
+		}
+		return jobs.NewPipeline(pipeline...)
+	}, nil
+}
+
+// BatchEndpoint runs an operation, or a pipeline of them, over every image of a zip archive or multipart form.
+// The response holds a manifest with a result per image, images that fail do not fail the rest of the batch.
+func BatchEndpoint(c echo.Context) error {
+	jobDispatcher := getDispatcher(c)
+	if jobDispatcher == nil {
+		log.Error().Msg("Job dispatcher is not present in the context")
+		return c.String(http.StatusInternalServerError, "failed to get job dispatcher")
+	}
+
+	operations, format, err := util.ParseBatch(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse batch")
+		return c.String(http.StatusBadRequest, "Failed to parse batch: "+err.Error())
+	}
+
+	// the key has to allow every operation of the batch, not only the batch itself
+	if key := gomanipMiddleware.APIKey(c); key != nil {
+		for _, name := range operations {
+			if !key.Allows(name) {
+				log.Error().Str("key", key.Name).Str("operation", name).Msg("batch used an operation its key does not allow")
+				return gomanipErrors.ReturnJsonError(c, http.StatusForbidden, fmt.Sprintf("%s: %s", name, auth.ErrForbidden))
+			}
+		}
+	}
+
+	newOperation, err := newBatchOperation(c, operations)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse batch")
+		return c.String(http.StatusBadRequest, "Failed to parse batch: "+err.Error())
+	}
+
+	files, err := util.GetImagesFromBatch(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read batch")
+		return c.String(http.StatusBadRequest, "Failed to read batch: "+err.Error())
+	}
+	defer func() {
+		for _, file := range files {
+			JobDispatch.ReleaseImage(file.Image)
+		}
+	}()
+
+	// only the images that could be read are processed, the rest keep their read error
+	images := make([]*gocv.Mat, 0, len(files))
+	for _, file := range files {
+		if file.Error == nil {
+			images = append(images, file.Image)
+		}
+	}
+	results := JobDispatch.EnqueueBatch(jobDispatcher, images, newOperation)
+
+	entries := make([]util.BatchEntry, len(files))
+	processed := 0
+	for idx, file := range files {
+		entries[idx].Name = file.Name
+		if file.Error != nil {
+			entries[idx].Error = file.Error
+			continue
+		}
+		entries[idx].Image = results[processed].Image
+		entries[idx].Error = results[processed].Error
+		processed++
+	}
+
+	// the response is already under way once writing fails, all that is left is to log it
+	if err := util.WriteBatch(c, format, entries); err != nil {
+		log.Error().Err(err).Msg("Failed to write batch")
+	}
+	return nil
+}
+
+// QREncodeEndpoint renders a QR code, it takes no image so it is served outside the worker pool.
+func QREncodeEndpoint(c echo.Context) error {
+	text, level, size, err := util.ParseQREncode(c)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to parse qr encode")
+		return c.String(http.StatusBadRequest, "Failed to parse qr encode: "+err.Error())
+	}
+
+	qrCode, err := jobs.EncodeQRCode(text, level, size)
+	if err != nil {
+		log.Error().Err(err).Msg("QR code encoding failed")
+		return operationFailed(c, "QR code encoding failed", err)
+	}
+
+	return c.Blob(http.StatusOK, "image/png", qrCode)
+}
+
+const (
+	// trackedRetention is how long the events of a finished request can still be subscribed to
+	trackedRetention = time.Minute
+	// eventKeepAlive is how often an idle event stream is written to, so proxies do not close it
+	eventKeepAlive = 15 * time.Second
+)
+
+// route is an endpoint that can be turned on and off in the config, named by its path without slashes.
+type route struct {
+	path    string
+	handler echo.HandlerFunc
+	// image routes check the file type of the uploaded image
+	image      bool
+	middleware []echo.MiddlewareFunc
+}
+
+var routes = []route{
+	{path: "/invert/", handler: InvertEndpoint, image: true},
+	{path: "/saturate/", handler: SaturateEndpoint, image: true},
+	{path: "/edgeDetection/", handler: EdgeDetectionEndpoint, image: true},
+	{path: "/morphology/", handler: MorphologyEndpoint, image: true},
+	{path: "/reduction/", handler: ReduceEndpoint, image: true},
+	{path: "/text/", handler: AddTextEndpoint, image: true},
+	{path: "/randomFilter/", handler: RandomFilterEndpoint, image: true},
+	{path: "/shuffle/", handler: ShuffleEndpoint, image: true},
+	{path: "/convolve/", handler: ConvolveEndpoint, image: true},
+	{path: "/stylize/", handler: StylizeEndpoint, image: true},
+	{path: "/swirl/", handler: SwirlEndpoint, image: true},
+	{path: "/bulge/", handler: BulgeEndpoint, image: true},
+	{path: "/wave/", handler: WaveEndpoint, image: true},
+	{path: "/fisheye/", handler: FisheyeEndpoint, image: true},
+	{path: "/mirror/", handler: MirrorEndpoint, image: true},
+	{path: "/kaleidoscope/", handler: KaleidoscopeEndpoint, image: true},
+	{path: "/gaussianNoise/", handler: GaussianNoiseEndpoint, image: true},
+	{path: "/saltAndPepper/", handler: SaltAndPepperEndpoint, image: true},
+	{path: "/filmGrain/", handler: FilmGrainEndpoint, image: true},
+	{path: "/vignette/", handler: VignetteEndpoint, image: true},
+	{path: "/oldPhoto/", handler: OldPhotoEndpoint, image: true},
+	{path: "/removeBackground/", handler: ColorKeyEndpoint, image: true},
+	{path: "/animate/zoom/", handler: ZoomEndpoint, image: true},
+	{path: "/animate/spin/", handler: SpinEndpoint, image: true},
+	{path: "/animate/shake/", handler: ShakeEndpoint, image: true},
+	{path: "/animate/tween/", handler: TweenEndpoint, image: true},
+	{path: "/export/", handler: ExportEndpoint, image: true},
+	{path: "/detect/", handler: DetectEndpoint, image: true},
+	{path: "/qr/decode/", handler: QRDecodeEndpoint, image: true},
+	{path: "/analyze/", handler: AnalyzeEndpoint, image: true},
+	{path: "/ascii/", handler: AsciiArtEndpoint, image: true},
+
+	{path: "/compare/", handler: CompareEndpoint, middleware: []echo.MiddlewareFunc{gomanipMiddleware.FormFileTypeVerifyMiddleware("first", "second")}},
+	{path: "/qr/encode/", handler: QREncodeEndpoint},
+	// batches check the file type of every image themselves, a bad image only fails its own entry
+	{path: "/batch/", handler: BatchEndpoint},
+}
+
+func (r route) name() string {
+	return strings.Trim(r.path, "/")
+}
+
+func routeNames() []string {
+	names := make([]string, len(routes))
+	for idx, r := range routes {
+		names[idx] = r.name()
+	}
+	return names
+}
+
+// initRouting serves the routes on e until the server stops.
+func initRouting(e *echo.Echo, jobDispatcher *JobDispatch.JobDispatcher, pool *worker.Pool, tracker *JobDispatch.Tracker, keys *auth.Keys, cascades, overlays *util.AssetDir, cfg *config.Config) {
+	registerRoutes(e, jobDispatcher, pool, tracker, keys, cascades, overlays, cfg)
+
+	if cfg.TLS.Enabled() {
+		e.Logger.Fatal(e.StartTLS(cfg.ListenAddress, cfg.TLS.CertFile, cfg.TLS.KeyFile))
+	}
+	e.Logger.Fatal(e.Start(cfg.ListenAddress))
+
+}
+
+// registerRoutes adds the middlewares and the enabled routes to e. The middlewares of the operations are added to each
+// route rather than to a group, a group would run them for unknown paths as well.
+func registerRoutes(e *echo.Echo, jobDispatcher *JobDispatch.JobDispatcher, pool *worker.Pool, tracker *JobDispatch.Tracker, keys *auth.Keys, cascades, overlays *util.AssetDir, cfg *config.Config) {
+	e.Use(middleware.BodyLimit(cfg.BodyLimit))
+	e.Use(middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
+		LogStatus: true,
+		LogURI:    true,
+		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
+			log.Info().
+				Str("URI", v.URI).
+				Int("status", v.Status).
+				Str("method", c.Request().Method).
+				Str("Time", v.StartTime.String()).
+				Msg("Request")
+			return nil
+		},
+	}))
+
+	// with api keys configured every request needs one and is scheduled for its key, operations are held to the key's limits
+	var limit []echo.MiddlewareFunc
+	if keys != nil {
+		e.Use(gomanipMiddleware.APIKeyMiddleware(keys))
+		limit = []echo.MiddlewareFunc{gomanipMiddleware.QuotaMiddleware()}
+	} else {
+		log.Warn().Msg("No api keys are configured, every request is let in")
+	}
+	e.Use(gomanipMiddleware.JobDispatcherMiddleware(jobDispatcher))
+	e.Use(gomanipMiddleware.AssetsMiddleware(cascades, overlays))
+
+	// every operation can be followed at /jobs/{id}/events
+	operations := append(limit, gomanipMiddleware.JobTrackingMiddleware(tracker))
+	e.GET("/jobs/:id/events", JobEventsEndpoint(tracker))
+	e.GET("/metrics/workers/", WorkerMetricsEndpoint(pool))
+	if jobs.MatTracking {
+		e.GET("/metrics/mats/", MatMetricsEndpoint)
+	}
+
+	for _, r := range routes {
+		if !cfg.Operations.IsEnabled(r.name()) {
+			log.Info().Str("operation", r.name()).Msg("Operation is disabled")
+			continue
+		}
+		middlewares := slices.Clone(operations)
+		// every endpoint operating on an uploaded image checks its file type
+		if r.image {
+			middlewares = append(middlewares, gomanipMiddleware.FileTypeVerifyMiddleware())
+		}
+		e.POST(r.path, r.handler, append(middlewares, r.middleware...)...)
+	}
+
+	// batches are named after the endpoints, so a disabled endpoint can not be reached through a batch either
+	for name := range batchOperations {
+		if !cfg.Operations.IsEnabled(name) {
+			delete(batchOperations, name)
+		}
+	}
+}
+
+func GraceFullShutdown(jobDispatcher *JobDispatch.JobDispatcher, pool *worker.Pool, grpcServer *grpc.Server, cancel context.CancelFunc) {
+	if grpcServer != nil {
+		log.Info().Msg("Stopping gRPC server")
+		grpcServer.GracefulStop()
+	}
+	log.Info().Msg("Closing worker request channels")
+	jobDispatcher.Close()
+	log.Info().Msg("Stopping Workers")
+	cancel()
+	pool.Wait()
+
+}
+
+// startGRPC serves the gRPC api next to the HTTP server when it has a listen address, it shares the job dispatcher,
+// the enabled operations and the api keys with the HTTP server. The returned server is nil when gRPC is not served.
+func startGRPC(cfg *config.Config, jobDispatcher *JobDispatch.JobDispatcher, keys *auth.Keys) (*grpc.Server, error) {
+	if cfg.GRPCListenAddress == "" {
+		return nil, nil
+	}
+
+	options := []grpc.ServerOption{
+		// the same limit as the http body, an image is the bulk of a message
+		grpc.MaxRecvMsgSize(int(cfg.BodyLimitBytes())),
+	}
+	if cfg.TLS.Enabled() {
+		creds, err := credentials.NewServerTLSFromFile(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	listener, err := net.Listen("tcp", cfg.GRPCListenAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	server := grpc.NewServer(options...)
+	grpcapi.NewServer(jobDispatcher, cfg.Operations.IsEnabled).WithKeys(keys).Register(server)
+
+	go func() {
+		log.Info().Str("address", cfg.GRPCListenAddress).Msg("Serving gRPC")
+		if err := server.Serve(listener); err != nil {
+			log.Error().Err(err).Msg("gRPC server stopped")
+		}
+	}()
+
+	return server, nil
+}
+
+// WorkerMetricsEndpoint reports the size of the worker pool and how often it scaled.
+func WorkerMetricsEndpoint(pool *worker.Pool) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, pool.Stats())
+	}
+}
+
+// JobEventsEndpoint streams the events of the request sent with the job id as server-sent events, ending with its done event.
+// The request may be subscribed to before it arrives and for a while after it finished. Only the client that made it,
+// the api key when keys are configured, may follow it, to other clients it is not found.
+func JobEventsEndpoint(tracker *JobDispatch.Tracker) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		id := c.Param("id")
+		if err := JobDispatch.ValidateJobId(id); err != nil {
+			return gomanipErrors.ReturnJsonError(c, http.StatusBadRequest, err.Error())
+		}
+
+		client := c.Get("jobDispatcher").(*JobDispatch.JobDispatcher).Client()
+		subscription, err := tracker.Subscribe(id, client)
+		if err != nil {
+			return gomanipErrors.ReturnJsonError(c, http.StatusNotFound, err.Error())
+		}
+		defer subscription.Close()
+
+		util.StartEvents(c)
+		keepAlive := time.NewTicker(eventKeepAlive)
+		defer keepAlive.Stop()
+
+		// once writing fails the client is gone, there is nobody left to tell
+		for {
+			select {
+			case event, ok := <-subscription.Events:
+				if !ok {
+					return nil
+				}
+				if err := util.WriteEvent(c, string(event.State), event); err != nil {
+					return nil
+				}
+			case <-keepAlive.C:
+				if err := util.WriteEventComment(c, "keep-alive"); err != nil {
+					return nil
+				}
+			case <-c.Request().Context().Done():
+				return nil
+			}
+		}
+	}
+}
+
+// MatMetricsEndpoint reports the Mats jobs left open, it is only served in builds with the matprofile tag.
+func MatMetricsEndpoint(c echo.Context) error {
+	return c.JSON(http.StatusOK, jobs.MatLeakStats())
+}
+
+// loadConfig reads the config file and environment, the flags given on the command line override both.
+func loadConfig() (*config.Config, error) {
+	defaults := config.Default()
+	configPath := flag.String("config", "", "Yaml file holding the server settings, GOMANIP_ environment variables override it")
+	prettyPrint := flag.Bool("pretty_print", defaults.PrettyPrint, "Enable pretty print output")
+	numWorkers := flag.Int("num_workers", defaults.Workers, "Most workers the pool grows to")
+	minWorkers := flag.Int("min_workers", defaults.MinWorkers, "Workers the pool starts with and never shrinks below")
+	queueDepth := flag.Int("queue_depth", defaults.QueueDepth, "Number of jobs that can wait for a worker, 0 queues 4 per worker")
+	timeout := flag.Duration("timeout", defaults.Timeouts.Default, "Default time a job may take")
+	maxTimeout := flag.Duration("max_timeout", defaults.Timeouts.Max, "Longest time a job may take when a client asks for more time")
+	operationTimeouts := flag.String("operation_timeouts", "", "Comma separated timeouts of single operations as name=default or name=default:max, i.e randomFilter=20s:1m")
+	cascadeDir := flag.String("cascade_dir", defaults.CascadeDir, "Directory containing Haar cascade xml files for detection")
+	overlayDir := flag.String("overlay_dir", defaults.OverlayDir, "Directory containing png overlays to paste onto detected regions")
+	listenAddress := flag.String("listen_address", defaults.ListenAddress, "Host and port to listen on")
+	grpcListenAddress := flag.String("grpc_listen_address", defaults.GRPCListenAddress, "Host and port the gRPC api listens on, it is not served when empty")
+	flag.Parse()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// only flags given explicitly override the config, their defaults would otherwise replace it
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "pretty_print":
+			cfg.PrettyPrint = *prettyPrint
+		case "num_workers":
+			cfg.Workers = *numWorkers
+		case "min_workers":
+			cfg.MinWorkers = *minWorkers
+		case "queue_depth":
+			cfg.QueueDepth = *queueDepth
+		case "timeout":
+			cfg.Timeouts.Default = *timeout
+		case "max_timeout":
+			cfg.Timeouts.Max = *maxTimeout
+		case "operation_timeouts":
+			timeouts, parseErr := config.ParseOperationTimeouts(*operationTimeouts)
+			if parseErr != nil {
+				err = fmt.Errorf("operation_timeouts: %w", parseErr)
+			}
+			cfg.SetOperationTimeouts(timeouts)
+		case "cascade_dir":
+			cfg.CascadeDir = *cascadeDir
+		case "overlay_dir":
+			cfg.OverlayDir = *overlayDir
+		case "listen_address":
+			cfg.ListenAddress = *listenAddress
+		case "grpc_listen_address":
+			cfg.GRPCListenAddress = *grpcListenAddress
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, cfg.Validate(routeNames())
+}
+
+func main() {
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid config")
+	}
+
+	if cfg.PrettyPrint {
+		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	}
+	util.MaxBodyBytes = cfg.BodyLimitBytes()
+
+	cascades, err := util.LoadAssetDir(cfg.CascadeDir, ".xml")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read cascade directory, detection will not be available")
+	}
+	log.Info().Strs("cascades", cascades.Names()).Msg("Loaded cascades")
+
+	overlays, err := util.LoadAssetDir(cfg.OverlayDir, ".png")
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read overlay directory, overlays will not be available")
+	}
+	log.Info().Strs("overlays", overlays.Names()).Msg("Loaded overlays")
+	queueDepth := cfg.QueueDepth
+	if queueDepth <= 0 {
+		queueDepth = cfg.Workers * 4
+	}
+	// the scheduler does the queueing, so jobs are handed to workers one at a time in scheduled order
+	jobReqs := make(chan *jobs.JobRequest)
+	jobDispatcher := JobDispatch.NewJobDispatcher(jobReqs, queueDepth, cfg.Timeouts.Dispatch())
+	ctx, cancel := context.WithCancel(context.Background())
+	pool, err := worker.NewPool(ctx, jobReqs, cfg.Pool(jobDispatcher.QueueWait))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to start workers")
+	}
+	keys := cfg.Keys(time.Now)
+	grpcServer, err := startGRPC(cfg, jobDispatcher, keys)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to start the gRPC server")
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		<-c
+		GraceFullShutdown(jobDispatcher, pool, grpcServer, cancel)
+		os.Exit(0)
+	}()
+
 	e := echo.New()
-	initRouting(e, jobDispatcher)
+	initRouting(e, jobDispatcher, pool, JobDispatch.NewTracker(trackedRetention), keys, cascades, overlays, cfg)
 }
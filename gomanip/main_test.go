@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/config"
+	"goManip/jobs"
+	"goManip/middleware"
+)
+
+func batchContext(query string) echo.Context {
+	req := httptest.NewRequest(http.MethodPost, "/batch/?"+query, nil)
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestBatchOperationParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  jobs.Operation
+	}{
+		{
+			name:  "randomFilter",
+			query: "operation=randomFilter&minVal=-2&maxVal=7&kernelSize=5&normalize=true",
+			want:  jobs.NewRandomFilter(5, -2, 7, true),
+		},
+		{
+			name:  "saturate",
+			query: "operation=saturate&saturation=1.5",
+			want:  jobs.NewSaturate(1.5),
+		},
+		{
+			name:  "shuffle",
+			query: "operation=shuffle&rows=2&cols=3&swaps=4&flip=true&jigsaw=true",
+			want:  jobs.NewGridShuffle(2, 3, 4, false, true),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newOperation, err := batchOperations[tt.name](batchContext(tt.query))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, newOperation())
+		})
+	}
+}
+
+func TestUnknownPath(t *testing.T) {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	dispatcher := JobDispatch.NewJobDispatcher(make(chan *jobs.JobRequest), 1, JobDispatch.Timeouts{})
+	t.Cleanup(dispatcher.Close)
+
+	keys := auth.NewKeys([]auth.KeyConfig{
+		{Name: "invert", Hash: auth.Hash("invert-key"), RateLimit: 1, Burst: 1, Operations: []string{"invert"}},
+	}, time.Now)
+	e := echo.New()
+	registerRoutes(e, dispatcher, nil, JobDispatch.NewTracker(time.Minute), keys, nil, nil, config.Default())
+
+	req := httptest.NewRequest(http.MethodPost, "/unknown/", nil)
+	req.Header.Set(middleware.APIKeyHeader, "invert-key")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// the unknown path did not take a request from the key's rate limit
+	key, _ := keys.Lookup("invert-key")
+	_, ok := key.Allow()
+	assert.True(t, ok)
+}
+
+func TestOperationFailed(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{name: "Invalid parameters", err: errors.New("expected quality to be greater than 0.0"), status: http.StatusBadRequest},
+		{name: "Panic", err: fmt.Errorf("%w: crashed", jobs.ErrInternal), status: http.StatusInternalServerError},
+		{name: "Quota", err: auth.ErrQuotaExceeded, status: http.StatusTooManyRequests},
+		{name: "Too expensive", err: fmt.Errorf("%w: invert", JobDispatch.ErrTooExpensive), status: http.StatusUnprocessableEntity},
+		{name: "Timeout", err: JobDispatch.ErrTimeout, status: http.StatusGatewayTimeout},
+		{name: "Closed", err: JobDispatch.ErrDispatcherClosed, status: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/invert/", nil), rec)
+
+			assert.NoError(t, operationFailed(c, "Image processing failed", tt.err))
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.err.Error())
+		})
+	}
+}
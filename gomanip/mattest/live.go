@@ -0,0 +1,7 @@
+//go:build !matprofile
+
+package mattest
+
+func liveMats() int {
+	return 0
+}
@@ -0,0 +1,9 @@
+//go:build matprofile
+
+package mattest
+
+import "gocv.io/x/gocv"
+
+func liveMats() int {
+	return gocv.MatProfile.Count()
+}
@@ -0,0 +1,55 @@
+// Package mattest checks that operations close every Mat they allocate. Leaks are only counted when the
+// tests are built with the matprofile tag, i.e go test -tags matprofile ./...
+package mattest
+
+import (
+	"testing"
+
+	"gocv.io/x/gocv"
+
+	"goManip/jobs"
+)
+
+// SkipWithoutTracking skips the test when Mats are not tracked.
+func SkipWithoutTracking(t testing.TB) {
+	t.Helper()
+	if !jobs.MatTracking {
+		t.Skip("Mat leaks are only tracked with -tags matprofile")
+	}
+}
+
+// AssertNoLeaks runs op on a copy of input as a job and fails t when the operation left Mats open,
+// whether it succeeded or not. The result and the copy are closed afterwards.
+func AssertNoLeaks(t testing.TB, op jobs.Operation, input *gocv.Mat) bool {
+	t.Helper()
+	SkipWithoutTracking(t)
+
+	clone := input.Clone()
+	defer clone.Close()
+
+	job := jobs.NewJob(0, op, &clone)
+	result, _ := job.Process()
+	if result != nil && !job.HoldsMat(result) {
+		result.Close()
+	}
+
+	if leaked := job.LeakedMats(); leaked != 0 {
+		t.Errorf("%s left %d Mats open", jobs.NameOf(op), leaked)
+		return false
+	}
+	return true
+}
+
+// AssertFreed fails t when fn leaves Mats open that were not open before.
+func AssertFreed(t testing.TB, fn func()) bool {
+	t.Helper()
+	SkipWithoutTracking(t)
+
+	before := liveMats()
+	fn()
+	if leaked := liveMats() - before; leaked != 0 {
+		t.Errorf("%d Mats were left open", leaked)
+		return false
+	}
+	return true
+}
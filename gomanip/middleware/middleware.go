@@ -2,26 +2,206 @@ package middleware
 
 
 import (
+	goerrors "errors"
 	"fmt"
+	"math"
 	"slices"
 	"strings"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/rs/zerolog/log"
 
 	"goManip/JobDispatch"
+	"goManip/auth"
 	"goManip/errors"
+	"goManip/jobs"
+	"goManip/util"
 )
 var (
 	supportedFileTypes = []string{ "image/png", "image/jpeg"}
 )
 
 
+const (
+	// ClientHeader identifies who a request is made for, i.e a guild, clients take turns in the job queue.
+	// Requests without it are grouped by their address, requests with an api key by their key.
+	ClientHeader = "X-Client-ID"
+	// PriorityHeader overrides the priority lane of the request's jobs, one of low, normal or high.
+	// With api keys only keys allowing it may send it.
+	PriorityHeader = "X-Priority"
+	// TimeoutHeader asks for a timeout other than the operation's default, i.e 30s. It is limited by the operation's max timeout.
+	TimeoutHeader = "X-Timeout"
+	// JobIdHeader names the request with an id of the client's choosing, its events can be followed at /jobs/{id}/events while it runs.
+	JobIdHeader = "X-Job-ID"
+	// APIKeyHeader carries the client's api key, it can be sent as an Authorization: Bearer header as well.
+	APIKeyHeader = "X-API-Key"
+)
+
+// JobDispatcherMiddleware gives every request a view of the dispatcher that schedules its jobs
+// for the client and priority named in its headers. A request let in with an api key is scheduled for its key,
+// and may only name a priority if the key allows it. It has to come after APIKeyMiddleware.
 func JobDispatcherMiddleware(jobDispatcher *JobDispatch.JobDispatcher) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
-			c.Set("jobDispatcher", jobDispatcher)
+			key := APIKey(c)
+			client := c.Request().Header.Get(ClientHeader)
+			if key != nil && client != "" {
+				// a key may serve several clients, i.e the guilds of a bot, which take turns among themselves
+				client = key.Name + ":" + client
+			} else if key != nil {
+				client = key.Name
+			} else if client == "" {
+				client = c.RealIP()
+			}
+
+			var priority *jobs.Priority
+			if name := c.Request().Header.Get(PriorityHeader); name != "" {
+				if key != nil && !key.AllowsPriority() {
+					log.Error().Str("key", key.Name).Msg("request chose a priority its key does not allow")
+					return errors.ReturnJsonError(c, http.StatusForbidden, auth.ErrPriority.Error())
+				}
+				parsed, err := jobs.ParsePriority(name)
+				if err != nil {
+					log.Error().Err(err).Msg("request had an invalid priority")
+					return errors.ReturnJsonError(c, http.StatusBadRequest, err.Error())
+				}
+				priority = &parsed
+			}
+
+			dispatcher := jobDispatcher.ForClient(client, priority)
+			if value := c.Request().Header.Get(TimeoutHeader); value != "" {
+				timeout, err := time.ParseDuration(value)
+				if err != nil || timeout <= 0 {
+					log.Error().Str("timeout", value).Msg("request had an invalid timeout")
+					return errors.ReturnJsonError(c, http.StatusBadRequest, fmt.Sprintf("invalid timeout %s, expected a positive duration i.e 30s", value))
+				}
+				dispatcher = dispatcher.WithTimeout(timeout)
+			}
+
+			c.Set("jobDispatcher", dispatcher)
+			return next(c)
+		}
+	}
+}
+
+// JobTrackingMiddleware reports the events of requests sent with a job id to tracker, the request is done once
+// its handler returned. It has to come after JobDispatcherMiddleware.
+func JobTrackingMiddleware(tracker *JobDispatch.Tracker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(JobIdHeader)
+			if id == "" {
+				return next(c)
+			}
+
+			dispatcher, finish, err := tracker.Track(c.Get("jobDispatcher").(*JobDispatch.JobDispatcher), id)
+			if goerrors.Is(err, JobDispatch.ErrJobIdInUse) {
+				log.Error().Err(err).Msg("request had a job id that is in use")
+				return errors.ReturnJsonError(c, http.StatusConflict, err.Error())
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("request had an invalid job id")
+				return errors.ReturnJsonError(c, http.StatusBadRequest, err.Error())
+			}
+			c.Set("jobDispatcher", dispatcher)
+
+			err = next(c)
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				if httpErr, ok := err.(*echo.HTTPError); ok {
+					status = httpErr.Code
+				}
+			}
+			finish(status)
+			return err
+		}
+	}
+}
+
+// APIKeyMiddleware only lets in requests sending one of keys, others are answered with 401.
+func APIKeyMiddleware(keys *auth.Keys) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key, err := keys.Lookup(apiKeyOf(c.Request().Header))
+			if err != nil {
+				log.Error().Err(err).Str("address", c.RealIP()).Msg("request was not authenticated")
+				return errors.ReturnJsonError(c, http.StatusUnauthorized, err.Error())
+			}
+
+			c.Set("apiKey", key)
+			return next(c)
+		}
+	}
+}
+
+func apiKeyOf(header http.Header) string {
+	if key := header.Get(APIKeyHeader); key != "" {
+		return key
+	}
+	scheme, token, _ := strings.Cut(header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// APIKey returns the key the request was let in with, nil when the server does not use keys.
+func APIKey(c echo.Context) *auth.Key {
+	key, _ := c.Get("apiKey").(*auth.Key)
+	return key
+}
+
+// QuotaMiddleware holds requests to the limits of their api key. The key has to allow the operation, named by the
+// route's path without slashes, or the request is answered with 403. It is answered with 429 when the key used up its
+// rate limit or daily pixels, otherwise the pixels of its images are charged to the key. It has to come after
+// APIKeyMiddleware and JobDispatcherMiddleware.
+func QuotaMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := APIKey(c)
+			if key == nil {
+				return next(c)
+			}
+
+			operation := strings.Trim(c.Path(), "/")
+			if !key.Allows(operation) {
+				log.Error().Str("key", key.Name).Str("operation", operation).Msg("request used an operation its key does not allow")
+				return errors.ReturnJsonError(c, http.StatusForbidden, fmt.Sprintf("%s: %s", operation, auth.ErrForbidden))
+			}
+
+			if wait, ok := key.Allow(); !ok {
+				log.Error().Str("key", key.Name).Msg("request exceeded the rate limit of its key")
+				setRetryAfter(c, wait)
+				return errors.ReturnJsonError(c, http.StatusTooManyRequests, auth.ErrRateLimited.Error())
+			}
+
+			if key.Exhausted() {
+				log.Error().Str("key", key.Name).Msg("request's key used up its daily pixels")
+				setRetryAfter(c, time.Until(key.QuotaReset()))
+				return errors.ReturnJsonError(c, http.StatusTooManyRequests, auth.ErrQuotaExceeded.Error())
+			}
+
+			c.Set("jobDispatcher", c.Get("jobDispatcher").(*JobDispatch.JobDispatcher).WithPixelQuota(key))
+			return next(c)
+		}
+	}
+}
+
+// setRetryAfter tells the client how many seconds to wait before trying again.
+func setRetryAfter(c echo.Context, wait time.Duration) {
+	c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+}
+
+// AssetsMiddleware makes the cascade and overlay directories available to the detection endpoint.
+func AssetsMiddleware(cascades, overlays *util.AssetDir) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("cascades", cascades)
+			c.Set("overlays", overlays)
 			return next(c)
 		}
 	}
@@ -49,4 +229,26 @@ func FileTypeVerifyMiddleware() echo.MiddlewareFunc {
 	}
 }
 
+// FormFileTypeVerifyMiddleware checks the file type of every named file in a multipart form,
+// for endpoints that take more than one image.
+func FormFileTypeVerifyMiddleware(fields ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			for _, field := range fields {
+				header, err := c.FormFile(field)
+				if err != nil {
+					log.Error().Err(err).Msgf("request is missing the %s file", field)
+					return errors.ReturnJsonError(c, http.StatusBadRequest, fmt.Sprintf("missing %s file", field))
+				}
+
+				contentType := header.Header.Get("Content-Type")
+				if !slices.Contains(supportedFileTypes, contentType) {
+					log.Error().Msg(fmt.Sprintf("%s file had content type of %s which is not supported", field, contentType))
+					return errors.ReturnJsonError(c, http.StatusBadRequest, fmt.Sprintf("%s files are not supported", contentType))
+				}
+			}
 
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+
+	"goManip/JobDispatch"
+	"goManip/auth"
+	"goManip/errors"
+	"goManip/jobs"
+	"goManip/middleware"
+)
+
+// newTestServer serves /invert/ and /mirror/ behind the api key middlewares, the handlers answer with the name of
+// the key the request was let in with. /client/ answers with the client its jobs are scheduled for.
+// No jobs are run, the dispatcher is only there for the middlewares to make views of.
+func newTestServer(t *testing.T, keys *auth.Keys) *echo.Echo {
+	t.Cleanup(func() { goleak.VerifyNone(t) })
+	dispatcher := JobDispatch.NewJobDispatcher(make(chan *jobs.JobRequest), 1, JobDispatch.Timeouts{})
+	t.Cleanup(dispatcher.Close)
+
+	e := echo.New()
+	e.Use(middleware.APIKeyMiddleware(keys), middleware.JobDispatcherMiddleware(dispatcher))
+	operations := e.Group("", middleware.QuotaMiddleware())
+	for _, path := range []string{"/invert/", "/mirror/"} {
+		operations.POST(path, func(c echo.Context) error {
+			return c.String(http.StatusOK, middleware.APIKey(c).Name)
+		})
+	}
+	e.POST("/client/", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.Get("jobDispatcher").(*JobDispatch.JobDispatcher).Client())
+	})
+	return e
+}
+
+func request(e *echo.Echo, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func withKey(key string) http.Header {
+	return http.Header{middleware.APIKeyHeader: {key}}
+}
+
+func assertJsonError(t *testing.T, rec *httptest.ResponseRecorder, wantStatus int) {
+	assert.Equal(t, wantStatus, rec.Code)
+
+	var body errors.GomanipError
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, strconv.Itoa(wantStatus), body.Status)
+	assert.NotEmpty(t, body.Detail)
+}
+
+func TestAPIKeyMiddleware(t *testing.T) {
+	e := newTestServer(t, auth.NewKeys([]auth.KeyConfig{{Name: "bot", Hash: auth.Hash("bot-key")}}, time.Now))
+
+	tests := []struct {
+		name       string
+		header     http.Header
+		wantStatus int
+	}{
+		{name: "No key", wantStatus: http.StatusUnauthorized},
+		{name: "Unknown key", header: withKey("guess"), wantStatus: http.StatusUnauthorized},
+		{name: "Hash instead of key", header: withKey(auth.Hash("bot-key")), wantStatus: http.StatusUnauthorized},
+		{name: "Key", header: withKey("bot-key"), wantStatus: http.StatusOK},
+		{name: "Bearer token", header: http.Header{"Authorization": {"Bearer bot-key"}}, wantStatus: http.StatusOK},
+		{name: "Other authorization scheme", header: http.Header{"Authorization": {"Basic bot-key"}}, wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := request(e, "/invert/", tt.header)
+			if tt.wantStatus != http.StatusOK {
+				assertJsonError(t, rec, tt.wantStatus)
+				return
+			}
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "bot", rec.Body.String())
+		})
+	}
+}
+
+func TestQuotaMiddleware(t *testing.T) {
+	keys := auth.NewKeys([]auth.KeyConfig{
+		{Name: "invert", Hash: auth.Hash("invert-key"), Operations: []string{"invert"}},
+		{Name: "slow", Hash: auth.Hash("slow-key"), RateLimit: 0.5},
+		{Name: "small", Hash: auth.Hash("small-key"), DailyPixels: 100},
+	}, time.Now)
+	e := newTestServer(t, keys)
+
+	t.Run("Allowed operation", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(e, "/invert/", withKey("invert-key")).Code)
+	})
+
+	t.Run("Operation the key does not allow", func(t *testing.T) {
+		assertJsonError(t, request(e, "/mirror/", withKey("invert-key")), http.StatusForbidden)
+	})
+
+	t.Run("Rate limit", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(e, "/invert/", withKey("slow-key")).Code)
+
+		rec := request(e, "/invert/", withKey("slow-key"))
+		assertJsonError(t, rec, http.StatusTooManyRequests)
+		assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+	})
+
+	t.Run("Daily pixels", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, request(e, "/invert/", withKey("small-key")).Code)
+
+		small, _ := keys.Lookup("small-key")
+		assert.NoError(t, small.Charge(100))
+		rec := request(e, "/invert/", withKey("small-key"))
+		assertJsonError(t, rec, http.StatusTooManyRequests)
+		assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	})
+}
+
+func TestJobDispatcherMiddlewareWithKey(t *testing.T) {
+	e := newTestServer(t, auth.NewKeys([]auth.KeyConfig{
+		{Name: "bot", Hash: auth.Hash("bot-key")},
+		{Name: "admin", Hash: auth.Hash("admin-key"), AllowPriority: true},
+	}, time.Now))
+
+	t.Run("Client of the key", func(t *testing.T) {
+		rec := request(e, "/client/", withKey("bot-key"))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bot", rec.Body.String())
+	})
+
+	t.Run("Client within the key", func(t *testing.T) {
+		header := withKey("bot-key")
+		header.Set(middleware.ClientHeader, "admin")
+		rec := request(e, "/client/", header)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "bot:admin", rec.Body.String())
+	})
+
+	t.Run("Priority the key does not allow", func(t *testing.T) {
+		header := withKey("bot-key")
+		header.Set(middleware.PriorityHeader, "high")
+		assertJsonError(t, request(e, "/client/", header), http.StatusForbidden)
+	})
+
+	t.Run("Priority the key allows", func(t *testing.T) {
+		header := withKey("admin-key")
+		header.Set(middleware.PriorityHeader, "high")
+		assert.Equal(t, http.StatusOK, request(e, "/client/", header).Code)
+	})
+}
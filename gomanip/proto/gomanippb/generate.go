@@ -0,0 +1,3 @@
+package gomanippb
+
+//go:generate protoc -I .. --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative ../gomanip.proto
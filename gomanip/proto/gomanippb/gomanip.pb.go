@@ -0,0 +1,2615 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: gomanip.proto
+
+package gomanippb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type JobEvent_State int32
+
+const (
+	JobEvent_STATE_UNSPECIFIED JobEvent_State = 0
+	// QUEUED is sent once the job waits for a worker.
+	JobEvent_QUEUED JobEvent_State = 1
+	// STARTED is sent once a worker runs the job.
+	JobEvent_STARTED JobEvent_State = 2
+	// DONE is the last event of a job that succeeded, it holds the image.
+	JobEvent_DONE JobEvent_State = 3
+	// FAILED is the last event of a job that failed, it holds the error.
+	JobEvent_FAILED JobEvent_State = 4
+	// PROGRESS is sent while a job runs for operations that report how far they got, i.e animations and pipelines.
+	JobEvent_PROGRESS JobEvent_State = 5
+)
+
+// Enum value maps for JobEvent_State.
+var (
+	JobEvent_State_name = map[int32]string{
+		0: "STATE_UNSPECIFIED",
+		1: "QUEUED",
+		2: "STARTED",
+		3: "DONE",
+		4: "FAILED",
+		5: "PROGRESS",
+	}
+	JobEvent_State_value = map[string]int32{
+		"STATE_UNSPECIFIED": 0,
+		"QUEUED":            1,
+		"STARTED":           2,
+		"DONE":              3,
+		"FAILED":            4,
+		"PROGRESS":          5,
+	}
+)
+
+func (x JobEvent_State) Enum() *JobEvent_State {
+	p := new(JobEvent_State)
+	*p = x
+	return p
+}
+
+func (x JobEvent_State) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobEvent_State) Descriptor() protoreflect.EnumDescriptor {
+	return file_gomanip_proto_enumTypes[0].Descriptor()
+}
+
+func (JobEvent_State) Type() protoreflect.EnumType {
+	return &file_gomanip_proto_enumTypes[0]
+}
+
+func (x JobEvent_State) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobEvent_State.Descriptor instead.
+func (JobEvent_State) EnumDescriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{5, 0}
+}
+
+type ProcessRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// image is a png or jpeg.
+	Image []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	// operations run in order as a pipeline, at least one is required.
+	Operations    []*Operation `protobuf:"bytes,2,rep,name=operations,proto3" json:"operations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessRequest) Reset() {
+	*x = ProcessRequest{}
+	mi := &file_gomanip_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessRequest) ProtoMessage() {}
+
+func (x *ProcessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessRequest.ProtoReflect.Descriptor instead.
+func (*ProcessRequest) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ProcessRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *ProcessRequest) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+type ProcessResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// image is the encoded png.
+	Image         []byte `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessResponse) Reset() {
+	*x = ProcessResponse{}
+	mi := &file_gomanip_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessResponse) ProtoMessage() {}
+
+func (x *ProcessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessResponse.ProtoReflect.Descriptor instead.
+func (*ProcessResponse) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProcessResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type BatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// operations are only read from the first message.
+	Operations []*Operation `protobuf:"bytes,1,rep,name=operations,proto3" json:"operations,omitempty"`
+	// name identifies the image in the response, it defaults to its index.
+	Name          string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Image         []byte `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchRequest) Reset() {
+	*x = BatchRequest{}
+	mi := &file_gomanip_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchRequest) ProtoMessage() {}
+
+func (x *BatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchRequest.ProtoReflect.Descriptor instead.
+func (*BatchRequest) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BatchRequest) GetOperations() []*Operation {
+	if x != nil {
+		return x.Operations
+	}
+	return nil
+}
+
+func (x *BatchRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BatchRequest) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type BatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// results are in the order the images were sent.
+	Results       []*BatchResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResponse) Reset() {
+	*x = BatchResponse{}
+	mi := &file_gomanip_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResponse) ProtoMessage() {}
+
+func (x *BatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResponse.ProtoReflect.Descriptor instead.
+func (*BatchResponse) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *BatchResponse) GetResults() []*BatchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type BatchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are valid to be assigned to Outcome:
+	//
+	//	*BatchResult_Image
+	//	*BatchResult_Error
+	Outcome       isBatchResult_Outcome `protobuf_oneof:"outcome"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchResult) Reset() {
+	*x = BatchResult{}
+	mi := &file_gomanip_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchResult) ProtoMessage() {}
+
+func (x *BatchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchResult.ProtoReflect.Descriptor instead.
+func (*BatchResult) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *BatchResult) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BatchResult) GetOutcome() isBatchResult_Outcome {
+	if x != nil {
+		return x.Outcome
+	}
+	return nil
+}
+
+func (x *BatchResult) GetImage() []byte {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchResult_Image); ok {
+			return x.Image
+		}
+	}
+	return nil
+}
+
+func (x *BatchResult) GetError() string {
+	if x != nil {
+		if x, ok := x.Outcome.(*BatchResult_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isBatchResult_Outcome interface {
+	isBatchResult_Outcome()
+}
+
+type BatchResult_Image struct {
+	Image []byte `protobuf:"bytes,2,opt,name=image,proto3,oneof"`
+}
+
+type BatchResult_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*BatchResult_Image) isBatchResult_Outcome() {}
+
+func (*BatchResult_Error) isBatchResult_Outcome() {}
+
+type JobEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         uint32                 `protobuf:"varint,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	State         JobEvent_State         `protobuf:"varint,2,opt,name=state,proto3,enum=gomanip.v1.JobEvent_State" json:"state,omitempty"`
+	Image         []byte                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"`
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Progress      *Progress              `protobuf:"bytes,5,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobEvent) Reset() {
+	*x = JobEvent{}
+	mi := &file_gomanip_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobEvent) ProtoMessage() {}
+
+func (x *JobEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobEvent.ProtoReflect.Descriptor instead.
+func (*JobEvent) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *JobEvent) GetJobId() uint32 {
+	if x != nil {
+		return x.JobId
+	}
+	return 0
+}
+
+func (x *JobEvent) GetState() JobEvent_State {
+	if x != nil {
+		return x.State
+	}
+	return JobEvent_STATE_UNSPECIFIED
+}
+
+func (x *JobEvent) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *JobEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *JobEvent) GetProgress() *Progress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+// Progress is how much of a job is done, i.e frame 3 of 20 or step 2 of 3.
+type Progress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Done  uint32                 `protobuf:"varint,1,opt,name=done,proto3" json:"done,omitempty"`
+	Total uint32                 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// unit is frame or step.
+	Unit          string `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Progress) Reset() {
+	*x = Progress{}
+	mi := &file_gomanip_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Progress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Progress) ProtoMessage() {}
+
+func (x *Progress) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Progress.ProtoReflect.Descriptor instead.
+func (*Progress) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Progress) GetDone() uint32 {
+	if x != nil {
+		return x.Done
+	}
+	return 0
+}
+
+func (x *Progress) GetTotal() uint32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *Progress) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+// Operation is one of the operations that can be used in a batch, named after the HTTP endpoints.
+// Unset optional fields take the same defaults as the missing query params of the endpoint.
+type Operation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Operation:
+	//
+	//	*Operation_Invert
+	//	*Operation_Saturate
+	//	*Operation_EdgeDetection
+	//	*Operation_Morphology
+	//	*Operation_Reduction
+	//	*Operation_Text
+	//	*Operation_RandomFilter
+	//	*Operation_Convolve
+	//	*Operation_Stylize
+	//	*Operation_Swirl
+	//	*Operation_Bulge
+	//	*Operation_Wave
+	//	*Operation_Fisheye
+	//	*Operation_Mirror
+	//	*Operation_Kaleidoscope
+	//	*Operation_GaussianNoise
+	//	*Operation_SaltAndPepper
+	//	*Operation_FilmGrain
+	//	*Operation_Vignette
+	//	*Operation_OldPhoto
+	//	*Operation_RemoveBackground
+	//	*Operation_Shuffle
+	Operation     isOperation_Operation `protobuf_oneof:"operation"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_gomanip_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Operation) GetOperation() isOperation_Operation {
+	if x != nil {
+		return x.Operation
+	}
+	return nil
+}
+
+func (x *Operation) GetInvert() *Invert {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Invert); ok {
+			return x.Invert
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetSaturate() *Saturate {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Saturate); ok {
+			return x.Saturate
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetEdgeDetection() *EdgeDetection {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_EdgeDetection); ok {
+			return x.EdgeDetection
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetMorphology() *Morphology {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Morphology); ok {
+			return x.Morphology
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetReduction() *Reduction {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Reduction); ok {
+			return x.Reduction
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetText() *Text {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Text); ok {
+			return x.Text
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetRandomFilter() *RandomFilter {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_RandomFilter); ok {
+			return x.RandomFilter
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetConvolve() *Convolve {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Convolve); ok {
+			return x.Convolve
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetStylize() *Stylize {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Stylize); ok {
+			return x.Stylize
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetSwirl() *Swirl {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Swirl); ok {
+			return x.Swirl
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetBulge() *Bulge {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Bulge); ok {
+			return x.Bulge
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetWave() *Wave {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Wave); ok {
+			return x.Wave
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetFisheye() *Fisheye {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Fisheye); ok {
+			return x.Fisheye
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetMirror() *Mirror {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Mirror); ok {
+			return x.Mirror
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetKaleidoscope() *Kaleidoscope {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Kaleidoscope); ok {
+			return x.Kaleidoscope
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetGaussianNoise() *GaussianNoise {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_GaussianNoise); ok {
+			return x.GaussianNoise
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetSaltAndPepper() *SaltAndPepper {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_SaltAndPepper); ok {
+			return x.SaltAndPepper
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetFilmGrain() *FilmGrain {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_FilmGrain); ok {
+			return x.FilmGrain
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetVignette() *Vignette {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Vignette); ok {
+			return x.Vignette
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetOldPhoto() *OldPhoto {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_OldPhoto); ok {
+			return x.OldPhoto
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetRemoveBackground() *RemoveBackground {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_RemoveBackground); ok {
+			return x.RemoveBackground
+		}
+	}
+	return nil
+}
+
+func (x *Operation) GetShuffle() *Shuffle {
+	if x != nil {
+		if x, ok := x.Operation.(*Operation_Shuffle); ok {
+			return x.Shuffle
+		}
+	}
+	return nil
+}
+
+type isOperation_Operation interface {
+	isOperation_Operation()
+}
+
+type Operation_Invert struct {
+	Invert *Invert `protobuf:"bytes,1,opt,name=invert,proto3,oneof"`
+}
+
+type Operation_Saturate struct {
+	Saturate *Saturate `protobuf:"bytes,2,opt,name=saturate,proto3,oneof"`
+}
+
+type Operation_EdgeDetection struct {
+	EdgeDetection *EdgeDetection `protobuf:"bytes,3,opt,name=edge_detection,json=edgeDetection,proto3,oneof"`
+}
+
+type Operation_Morphology struct {
+	Morphology *Morphology `protobuf:"bytes,4,opt,name=morphology,proto3,oneof"`
+}
+
+type Operation_Reduction struct {
+	Reduction *Reduction `protobuf:"bytes,5,opt,name=reduction,proto3,oneof"`
+}
+
+type Operation_Text struct {
+	Text *Text `protobuf:"bytes,6,opt,name=text,proto3,oneof"`
+}
+
+type Operation_RandomFilter struct {
+	RandomFilter *RandomFilter `protobuf:"bytes,7,opt,name=random_filter,json=randomFilter,proto3,oneof"`
+}
+
+type Operation_Convolve struct {
+	Convolve *Convolve `protobuf:"bytes,8,opt,name=convolve,proto3,oneof"`
+}
+
+type Operation_Stylize struct {
+	Stylize *Stylize `protobuf:"bytes,9,opt,name=stylize,proto3,oneof"`
+}
+
+type Operation_Swirl struct {
+	Swirl *Swirl `protobuf:"bytes,10,opt,name=swirl,proto3,oneof"`
+}
+
+type Operation_Bulge struct {
+	Bulge *Bulge `protobuf:"bytes,11,opt,name=bulge,proto3,oneof"`
+}
+
+type Operation_Wave struct {
+	Wave *Wave `protobuf:"bytes,12,opt,name=wave,proto3,oneof"`
+}
+
+type Operation_Fisheye struct {
+	Fisheye *Fisheye `protobuf:"bytes,13,opt,name=fisheye,proto3,oneof"`
+}
+
+type Operation_Mirror struct {
+	Mirror *Mirror `protobuf:"bytes,14,opt,name=mirror,proto3,oneof"`
+}
+
+type Operation_Kaleidoscope struct {
+	Kaleidoscope *Kaleidoscope `protobuf:"bytes,15,opt,name=kaleidoscope,proto3,oneof"`
+}
+
+type Operation_GaussianNoise struct {
+	GaussianNoise *GaussianNoise `protobuf:"bytes,16,opt,name=gaussian_noise,json=gaussianNoise,proto3,oneof"`
+}
+
+type Operation_SaltAndPepper struct {
+	SaltAndPepper *SaltAndPepper `protobuf:"bytes,17,opt,name=salt_and_pepper,json=saltAndPepper,proto3,oneof"`
+}
+
+type Operation_FilmGrain struct {
+	FilmGrain *FilmGrain `protobuf:"bytes,18,opt,name=film_grain,json=filmGrain,proto3,oneof"`
+}
+
+type Operation_Vignette struct {
+	Vignette *Vignette `protobuf:"bytes,19,opt,name=vignette,proto3,oneof"`
+}
+
+type Operation_OldPhoto struct {
+	OldPhoto *OldPhoto `protobuf:"bytes,20,opt,name=old_photo,json=oldPhoto,proto3,oneof"`
+}
+
+type Operation_RemoveBackground struct {
+	RemoveBackground *RemoveBackground `protobuf:"bytes,21,opt,name=remove_background,json=removeBackground,proto3,oneof"`
+}
+
+type Operation_Shuffle struct {
+	Shuffle *Shuffle `protobuf:"bytes,22,opt,name=shuffle,proto3,oneof"`
+}
+
+func (*Operation_Invert) isOperation_Operation() {}
+
+func (*Operation_Saturate) isOperation_Operation() {}
+
+func (*Operation_EdgeDetection) isOperation_Operation() {}
+
+func (*Operation_Morphology) isOperation_Operation() {}
+
+func (*Operation_Reduction) isOperation_Operation() {}
+
+func (*Operation_Text) isOperation_Operation() {}
+
+func (*Operation_RandomFilter) isOperation_Operation() {}
+
+func (*Operation_Convolve) isOperation_Operation() {}
+
+func (*Operation_Stylize) isOperation_Operation() {}
+
+func (*Operation_Swirl) isOperation_Operation() {}
+
+func (*Operation_Bulge) isOperation_Operation() {}
+
+func (*Operation_Wave) isOperation_Operation() {}
+
+func (*Operation_Fisheye) isOperation_Operation() {}
+
+func (*Operation_Mirror) isOperation_Operation() {}
+
+func (*Operation_Kaleidoscope) isOperation_Operation() {}
+
+func (*Operation_GaussianNoise) isOperation_Operation() {}
+
+func (*Operation_SaltAndPepper) isOperation_Operation() {}
+
+func (*Operation_FilmGrain) isOperation_Operation() {}
+
+func (*Operation_Vignette) isOperation_Operation() {}
+
+func (*Operation_OldPhoto) isOperation_Operation() {}
+
+func (*Operation_RemoveBackground) isOperation_Operation() {}
+
+func (*Operation_Shuffle) isOperation_Operation() {}
+
+type Invert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Invert) Reset() {
+	*x = Invert{}
+	mi := &file_gomanip_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Invert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Invert) ProtoMessage() {}
+
+func (x *Invert) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Invert.ProtoReflect.Descriptor instead.
+func (*Invert) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{8}
+}
+
+type Saturate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Saturation    float32                `protobuf:"fixed32,1,opt,name=saturation,proto3" json:"saturation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Saturate) Reset() {
+	*x = Saturate{}
+	mi := &file_gomanip_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Saturate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Saturate) ProtoMessage() {}
+
+func (x *Saturate) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Saturate.ProtoReflect.Descriptor instead.
+func (*Saturate) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Saturate) GetSaturation() float32 {
+	if x != nil {
+		return x.Saturation
+	}
+	return 0
+}
+
+type EdgeDetection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lower         float32                `protobuf:"fixed32,1,opt,name=lower,proto3" json:"lower,omitempty"`
+	Higher        float32                `protobuf:"fixed32,2,opt,name=higher,proto3" json:"higher,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EdgeDetection) Reset() {
+	*x = EdgeDetection{}
+	mi := &file_gomanip_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EdgeDetection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EdgeDetection) ProtoMessage() {}
+
+func (x *EdgeDetection) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EdgeDetection.ProtoReflect.Descriptor instead.
+func (*EdgeDetection) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EdgeDetection) GetLower() float32 {
+	if x != nil {
+		return x.Lower
+	}
+	return 0
+}
+
+func (x *EdgeDetection) GetHigher() float32 {
+	if x != nil {
+		return x.Higher
+	}
+	return 0
+}
+
+type Morphology struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// type is Dilate or Erode.
+	Type          string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	KernelSize    int32  `protobuf:"varint,2,opt,name=kernel_size,json=kernelSize,proto3" json:"kernel_size,omitempty"`
+	Iterations    int32  `protobuf:"varint,3,opt,name=iterations,proto3" json:"iterations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Morphology) Reset() {
+	*x = Morphology{}
+	mi := &file_gomanip_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Morphology) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Morphology) ProtoMessage() {}
+
+func (x *Morphology) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Morphology.ProtoReflect.Descriptor instead.
+func (*Morphology) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Morphology) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Morphology) GetKernelSize() int32 {
+	if x != nil {
+		return x.KernelSize
+	}
+	return 0
+}
+
+func (x *Morphology) GetIterations() int32 {
+	if x != nil {
+		return x.Iterations
+	}
+	return 0
+}
+
+type Reduction struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Quality       float32                `protobuf:"fixed32,1,opt,name=quality,proto3" json:"quality,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Reduction) Reset() {
+	*x = Reduction{}
+	mi := &file_gomanip_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reduction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reduction) ProtoMessage() {}
+
+func (x *Reduction) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reduction.ProtoReflect.Descriptor instead.
+func (*Reduction) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Reduction) GetQuality() float32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+type Text struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	FontScale     float64                `protobuf:"fixed64,2,opt,name=font_scale,json=fontScale,proto3" json:"font_scale,omitempty"`
+	XPerc         float64                `protobuf:"fixed64,3,opt,name=x_perc,json=xPerc,proto3" json:"x_perc,omitempty"`
+	YPerc         float64                `protobuf:"fixed64,4,opt,name=y_perc,json=yPerc,proto3" json:"y_perc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Text) Reset() {
+	*x = Text{}
+	mi := &file_gomanip_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Text) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Text) ProtoMessage() {}
+
+func (x *Text) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Text.ProtoReflect.Descriptor instead.
+func (*Text) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Text) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Text) GetFontScale() float64 {
+	if x != nil {
+		return x.FontScale
+	}
+	return 0
+}
+
+func (x *Text) GetXPerc() float64 {
+	if x != nil {
+		return x.XPerc
+	}
+	return 0
+}
+
+func (x *Text) GetYPerc() float64 {
+	if x != nil {
+		return x.YPerc
+	}
+	return 0
+}
+
+type RandomFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	KernelSize    int32                  `protobuf:"varint,1,opt,name=kernel_size,json=kernelSize,proto3" json:"kernel_size,omitempty"`
+	MinVal        int32                  `protobuf:"varint,2,opt,name=min_val,json=minVal,proto3" json:"min_val,omitempty"`
+	MaxVal        int32                  `protobuf:"varint,3,opt,name=max_val,json=maxVal,proto3" json:"max_val,omitempty"`
+	Normalize     bool                   `protobuf:"varint,4,opt,name=normalize,proto3" json:"normalize,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RandomFilter) Reset() {
+	*x = RandomFilter{}
+	mi := &file_gomanip_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RandomFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RandomFilter) ProtoMessage() {}
+
+func (x *RandomFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RandomFilter.ProtoReflect.Descriptor instead.
+func (*RandomFilter) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RandomFilter) GetKernelSize() int32 {
+	if x != nil {
+		return x.KernelSize
+	}
+	return 0
+}
+
+func (x *RandomFilter) GetMinVal() int32 {
+	if x != nil {
+		return x.MinVal
+	}
+	return 0
+}
+
+func (x *RandomFilter) GetMaxVal() int32 {
+	if x != nil {
+		return x.MaxVal
+	}
+	return 0
+}
+
+func (x *RandomFilter) GetNormalize() bool {
+	if x != nil {
+		return x.Normalize
+	}
+	return false
+}
+
+type Kernel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rows          []*KernelRow           `protobuf:"bytes,1,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Kernel) Reset() {
+	*x = Kernel{}
+	mi := &file_gomanip_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Kernel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Kernel) ProtoMessage() {}
+
+func (x *Kernel) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Kernel.ProtoReflect.Descriptor instead.
+func (*Kernel) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Kernel) GetRows() []*KernelRow {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+type KernelRow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []float32              `protobuf:"fixed32,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KernelRow) Reset() {
+	*x = KernelRow{}
+	mi := &file_gomanip_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KernelRow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KernelRow) ProtoMessage() {}
+
+func (x *KernelRow) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KernelRow.ProtoReflect.Descriptor instead.
+func (*KernelRow) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *KernelRow) GetValues() []float32 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type Convolve struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// kernels holds one kernel shared by every channel or one kernel per channel, preset is used instead when it is set.
+	Kernels       []*Kernel `protobuf:"bytes,1,rep,name=kernels,proto3" json:"kernels,omitempty"`
+	Preset        string    `protobuf:"bytes,2,opt,name=preset,proto3" json:"preset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Convolve) Reset() {
+	*x = Convolve{}
+	mi := &file_gomanip_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Convolve) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Convolve) ProtoMessage() {}
+
+func (x *Convolve) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Convolve.ProtoReflect.Descriptor instead.
+func (*Convolve) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Convolve) GetKernels() []*Kernel {
+	if x != nil {
+		return x.Kernels
+	}
+	return nil
+}
+
+func (x *Convolve) GetPreset() string {
+	if x != nil {
+		return x.Preset
+	}
+	return ""
+}
+
+type Stylize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Style         string                 `protobuf:"bytes,1,opt,name=style,proto3" json:"style,omitempty"`
+	Intensity     *float64               `protobuf:"fixed64,2,opt,name=intensity,proto3,oneof" json:"intensity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Stylize) Reset() {
+	*x = Stylize{}
+	mi := &file_gomanip_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Stylize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Stylize) ProtoMessage() {}
+
+func (x *Stylize) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Stylize.ProtoReflect.Descriptor instead.
+func (*Stylize) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Stylize) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+func (x *Stylize) GetIntensity() float64 {
+	if x != nil && x.Intensity != nil {
+		return *x.Intensity
+	}
+	return 0
+}
+
+type Swirl struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	XPerc         *float64               `protobuf:"fixed64,1,opt,name=x_perc,json=xPerc,proto3,oneof" json:"x_perc,omitempty"`
+	YPerc         *float64               `protobuf:"fixed64,2,opt,name=y_perc,json=yPerc,proto3,oneof" json:"y_perc,omitempty"`
+	Radius        *float64               `protobuf:"fixed64,3,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	Strength      *float64               `protobuf:"fixed64,4,opt,name=strength,proto3,oneof" json:"strength,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Swirl) Reset() {
+	*x = Swirl{}
+	mi := &file_gomanip_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Swirl) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Swirl) ProtoMessage() {}
+
+func (x *Swirl) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Swirl.ProtoReflect.Descriptor instead.
+func (*Swirl) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Swirl) GetXPerc() float64 {
+	if x != nil && x.XPerc != nil {
+		return *x.XPerc
+	}
+	return 0
+}
+
+func (x *Swirl) GetYPerc() float64 {
+	if x != nil && x.YPerc != nil {
+		return *x.YPerc
+	}
+	return 0
+}
+
+func (x *Swirl) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+func (x *Swirl) GetStrength() float64 {
+	if x != nil && x.Strength != nil {
+		return *x.Strength
+	}
+	return 0
+}
+
+type Bulge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	XPerc         *float64               `protobuf:"fixed64,1,opt,name=x_perc,json=xPerc,proto3,oneof" json:"x_perc,omitempty"`
+	YPerc         *float64               `protobuf:"fixed64,2,opt,name=y_perc,json=yPerc,proto3,oneof" json:"y_perc,omitempty"`
+	Radius        *float64               `protobuf:"fixed64,3,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	Strength      *float64               `protobuf:"fixed64,4,opt,name=strength,proto3,oneof" json:"strength,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Bulge) Reset() {
+	*x = Bulge{}
+	mi := &file_gomanip_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Bulge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bulge) ProtoMessage() {}
+
+func (x *Bulge) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bulge.ProtoReflect.Descriptor instead.
+func (*Bulge) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Bulge) GetXPerc() float64 {
+	if x != nil && x.XPerc != nil {
+		return *x.XPerc
+	}
+	return 0
+}
+
+func (x *Bulge) GetYPerc() float64 {
+	if x != nil && x.YPerc != nil {
+		return *x.YPerc
+	}
+	return 0
+}
+
+func (x *Bulge) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+func (x *Bulge) GetStrength() float64 {
+	if x != nil && x.Strength != nil {
+		return *x.Strength
+	}
+	return 0
+}
+
+type Wave struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amplitude     *float64               `protobuf:"fixed64,1,opt,name=amplitude,proto3,oneof" json:"amplitude,omitempty"`
+	Wavelength    *float64               `protobuf:"fixed64,2,opt,name=wavelength,proto3,oneof" json:"wavelength,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Wave) Reset() {
+	*x = Wave{}
+	mi := &file_gomanip_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wave) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wave) ProtoMessage() {}
+
+func (x *Wave) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wave.ProtoReflect.Descriptor instead.
+func (*Wave) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Wave) GetAmplitude() float64 {
+	if x != nil && x.Amplitude != nil {
+		return *x.Amplitude
+	}
+	return 0
+}
+
+func (x *Wave) GetWavelength() float64 {
+	if x != nil && x.Wavelength != nil {
+		return *x.Wavelength
+	}
+	return 0
+}
+
+type Fisheye struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	XPerc         *float64               `protobuf:"fixed64,1,opt,name=x_perc,json=xPerc,proto3,oneof" json:"x_perc,omitempty"`
+	YPerc         *float64               `protobuf:"fixed64,2,opt,name=y_perc,json=yPerc,proto3,oneof" json:"y_perc,omitempty"`
+	Strength      *float64               `protobuf:"fixed64,3,opt,name=strength,proto3,oneof" json:"strength,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fisheye) Reset() {
+	*x = Fisheye{}
+	mi := &file_gomanip_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fisheye) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fisheye) ProtoMessage() {}
+
+func (x *Fisheye) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fisheye.ProtoReflect.Descriptor instead.
+func (*Fisheye) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Fisheye) GetXPerc() float64 {
+	if x != nil && x.XPerc != nil {
+		return *x.XPerc
+	}
+	return 0
+}
+
+func (x *Fisheye) GetYPerc() float64 {
+	if x != nil && x.YPerc != nil {
+		return *x.YPerc
+	}
+	return 0
+}
+
+func (x *Fisheye) GetStrength() float64 {
+	if x != nil && x.Strength != nil {
+		return *x.Strength
+	}
+	return 0
+}
+
+type Mirror struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Side          string                 `protobuf:"bytes,1,opt,name=side,proto3" json:"side,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mirror) Reset() {
+	*x = Mirror{}
+	mi := &file_gomanip_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mirror) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mirror) ProtoMessage() {}
+
+func (x *Mirror) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mirror.ProtoReflect.Descriptor instead.
+func (*Mirror) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *Mirror) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+type Kaleidoscope struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segments      *int32                 `protobuf:"varint,1,opt,name=segments,proto3,oneof" json:"segments,omitempty"`
+	XPerc         *float64               `protobuf:"fixed64,2,opt,name=x_perc,json=xPerc,proto3,oneof" json:"x_perc,omitempty"`
+	YPerc         *float64               `protobuf:"fixed64,3,opt,name=y_perc,json=yPerc,proto3,oneof" json:"y_perc,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Kaleidoscope) Reset() {
+	*x = Kaleidoscope{}
+	mi := &file_gomanip_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Kaleidoscope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Kaleidoscope) ProtoMessage() {}
+
+func (x *Kaleidoscope) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Kaleidoscope.ProtoReflect.Descriptor instead.
+func (*Kaleidoscope) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Kaleidoscope) GetSegments() int32 {
+	if x != nil && x.Segments != nil {
+		return *x.Segments
+	}
+	return 0
+}
+
+func (x *Kaleidoscope) GetXPerc() float64 {
+	if x != nil && x.XPerc != nil {
+		return *x.XPerc
+	}
+	return 0
+}
+
+func (x *Kaleidoscope) GetYPerc() float64 {
+	if x != nil && x.YPerc != nil {
+		return *x.YPerc
+	}
+	return 0
+}
+
+type GaussianNoise struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sigma *float64               `protobuf:"fixed64,1,opt,name=sigma,proto3,oneof" json:"sigma,omitempty"`
+	// seed 0 picks a random seed.
+	Seed          int64 `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GaussianNoise) Reset() {
+	*x = GaussianNoise{}
+	mi := &file_gomanip_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GaussianNoise) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GaussianNoise) ProtoMessage() {}
+
+func (x *GaussianNoise) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GaussianNoise.ProtoReflect.Descriptor instead.
+func (*GaussianNoise) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GaussianNoise) GetSigma() float64 {
+	if x != nil && x.Sigma != nil {
+		return *x.Sigma
+	}
+	return 0
+}
+
+func (x *GaussianNoise) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type SaltAndPepper struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Amount        *float64               `protobuf:"fixed64,1,opt,name=amount,proto3,oneof" json:"amount,omitempty"`
+	Seed          int64                  `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaltAndPepper) Reset() {
+	*x = SaltAndPepper{}
+	mi := &file_gomanip_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaltAndPepper) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaltAndPepper) ProtoMessage() {}
+
+func (x *SaltAndPepper) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaltAndPepper.ProtoReflect.Descriptor instead.
+func (*SaltAndPepper) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SaltAndPepper) GetAmount() float64 {
+	if x != nil && x.Amount != nil {
+		return *x.Amount
+	}
+	return 0
+}
+
+func (x *SaltAndPepper) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type FilmGrain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Strength      *float64               `protobuf:"fixed64,1,opt,name=strength,proto3,oneof" json:"strength,omitempty"`
+	Seed          int64                  `protobuf:"varint,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FilmGrain) Reset() {
+	*x = FilmGrain{}
+	mi := &file_gomanip_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FilmGrain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilmGrain) ProtoMessage() {}
+
+func (x *FilmGrain) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilmGrain.ProtoReflect.Descriptor instead.
+func (*FilmGrain) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *FilmGrain) GetStrength() float64 {
+	if x != nil && x.Strength != nil {
+		return *x.Strength
+	}
+	return 0
+}
+
+func (x *FilmGrain) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type Vignette struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Strength      *float64               `protobuf:"fixed64,1,opt,name=strength,proto3,oneof" json:"strength,omitempty"`
+	Radius        *float64               `protobuf:"fixed64,2,opt,name=radius,proto3,oneof" json:"radius,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vignette) Reset() {
+	*x = Vignette{}
+	mi := &file_gomanip_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vignette) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vignette) ProtoMessage() {}
+
+func (x *Vignette) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vignette.ProtoReflect.Descriptor instead.
+func (*Vignette) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Vignette) GetStrength() float64 {
+	if x != nil && x.Strength != nil {
+		return *x.Strength
+	}
+	return 0
+}
+
+func (x *Vignette) GetRadius() float64 {
+	if x != nil && x.Radius != nil {
+		return *x.Radius
+	}
+	return 0
+}
+
+type OldPhoto struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Quality       *float32               `protobuf:"fixed32,1,opt,name=quality,proto3,oneof" json:"quality,omitempty"`
+	Grain         *float64               `protobuf:"fixed64,2,opt,name=grain,proto3,oneof" json:"grain,omitempty"`
+	Vignette      *float64               `protobuf:"fixed64,3,opt,name=vignette,proto3,oneof" json:"vignette,omitempty"`
+	Seed          int64                  `protobuf:"varint,4,opt,name=seed,proto3" json:"seed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OldPhoto) Reset() {
+	*x = OldPhoto{}
+	mi := &file_gomanip_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OldPhoto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OldPhoto) ProtoMessage() {}
+
+func (x *OldPhoto) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OldPhoto.ProtoReflect.Descriptor instead.
+func (*OldPhoto) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *OldPhoto) GetQuality() float32 {
+	if x != nil && x.Quality != nil {
+		return *x.Quality
+	}
+	return 0
+}
+
+func (x *OldPhoto) GetGrain() float64 {
+	if x != nil && x.Grain != nil {
+		return *x.Grain
+	}
+	return 0
+}
+
+func (x *OldPhoto) GetVignette() float64 {
+	if x != nil && x.Vignette != nil {
+		return *x.Vignette
+	}
+	return 0
+}
+
+func (x *OldPhoto) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+type Shuffle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// partitions is used when rows and cols are both unset, there is no jigsaw layout since only images are returned.
+	Partitions    int32 `protobuf:"varint,1,opt,name=partitions,proto3" json:"partitions,omitempty"`
+	Rows          int32 `protobuf:"varint,2,opt,name=rows,proto3" json:"rows,omitempty"`
+	Cols          int32 `protobuf:"varint,3,opt,name=cols,proto3" json:"cols,omitempty"`
+	Swaps         int32 `protobuf:"varint,4,opt,name=swaps,proto3" json:"swaps,omitempty"`
+	Rotate        bool  `protobuf:"varint,5,opt,name=rotate,proto3" json:"rotate,omitempty"`
+	Flip          bool  `protobuf:"varint,6,opt,name=flip,proto3" json:"flip,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Shuffle) Reset() {
+	*x = Shuffle{}
+	mi := &file_gomanip_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Shuffle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Shuffle) ProtoMessage() {}
+
+func (x *Shuffle) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Shuffle.ProtoReflect.Descriptor instead.
+func (*Shuffle) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *Shuffle) GetPartitions() int32 {
+	if x != nil {
+		return x.Partitions
+	}
+	return 0
+}
+
+func (x *Shuffle) GetRows() int32 {
+	if x != nil {
+		return x.Rows
+	}
+	return 0
+}
+
+func (x *Shuffle) GetCols() int32 {
+	if x != nil {
+		return x.Cols
+	}
+	return 0
+}
+
+func (x *Shuffle) GetSwaps() int32 {
+	if x != nil {
+		return x.Swaps
+	}
+	return 0
+}
+
+func (x *Shuffle) GetRotate() bool {
+	if x != nil {
+		return x.Rotate
+	}
+	return false
+}
+
+func (x *Shuffle) GetFlip() bool {
+	if x != nil {
+		return x.Flip
+	}
+	return false
+}
+
+type RemoveBackground struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// color is a hex color like #00ff00, without it the color of the top left pixel is removed.
+	Color         string `protobuf:"bytes,1,opt,name=color,proto3" json:"color,omitempty"`
+	Tolerance     *int32 `protobuf:"varint,2,opt,name=tolerance,proto3,oneof" json:"tolerance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveBackground) Reset() {
+	*x = RemoveBackground{}
+	mi := &file_gomanip_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveBackground) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveBackground) ProtoMessage() {}
+
+func (x *RemoveBackground) ProtoReflect() protoreflect.Message {
+	mi := &file_gomanip_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveBackground.ProtoReflect.Descriptor instead.
+func (*RemoveBackground) Descriptor() ([]byte, []int) {
+	return file_gomanip_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *RemoveBackground) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *RemoveBackground) GetTolerance() int32 {
+	if x != nil && x.Tolerance != nil {
+		return *x.Tolerance
+	}
+	return 0
+}
+
+var File_gomanip_proto protoreflect.FileDescriptor
+
+const file_gomanip_proto_rawDesc = "" +
+	"\n" +
+	"\rgomanip.proto\x12\n" +
+	"gomanip.v1\"]\n" +
+	"\x0eProcessRequest\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\x125\n" +
+	"\n" +
+	"operations\x18\x02 \x03(\v2\x15.gomanip.v1.OperationR\n" +
+	"operations\"'\n" +
+	"\x0fProcessResponse\x12\x14\n" +
+	"\x05image\x18\x01 \x01(\fR\x05image\"o\n" +
+	"\fBatchRequest\x125\n" +
+	"\n" +
+	"operations\x18\x01 \x03(\v2\x15.gomanip.v1.OperationR\n" +
+	"operations\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\fR\x05image\"B\n" +
+	"\rBatchResponse\x121\n" +
+	"\aresults\x18\x01 \x03(\v2\x17.gomanip.v1.BatchResultR\aresults\"\\\n" +
+	"\vBatchResult\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x05image\x18\x02 \x01(\fH\x00R\x05image\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\t\n" +
+	"\aoutcome\"\x8e\x02\n" +
+	"\bJobEvent\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\rR\x05jobId\x120\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x1a.gomanip.v1.JobEvent.StateR\x05state\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\fR\x05image\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x120\n" +
+	"\bprogress\x18\x05 \x01(\v2\x14.gomanip.v1.ProgressR\bprogress\"[\n" +
+	"\x05State\x12\x15\n" +
+	"\x11STATE_UNSPECIFIED\x10\x00\x12\n" +
+	"\n" +
+	"\x06QUEUED\x10\x01\x12\v\n" +
+	"\aSTARTED\x10\x02\x12\b\n" +
+	"\x04DONE\x10\x03\x12\n" +
+	"\n" +
+	"\x06FAILED\x10\x04\x12\f\n" +
+	"\bPROGRESS\x10\x05\"H\n" +
+	"\bProgress\x12\x12\n" +
+	"\x04done\x18\x01 \x01(\rR\x04done\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\rR\x05total\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\"\xc2\t\n" +
+	"\tOperation\x12,\n" +
+	"\x06invert\x18\x01 \x01(\v2\x12.gomanip.v1.InvertH\x00R\x06invert\x122\n" +
+	"\bsaturate\x18\x02 \x01(\v2\x14.gomanip.v1.SaturateH\x00R\bsaturate\x12B\n" +
+	"\x0eedge_detection\x18\x03 \x01(\v2\x19.gomanip.v1.EdgeDetectionH\x00R\redgeDetection\x128\n" +
+	"\n" +
+	"morphology\x18\x04 \x01(\v2\x16.gomanip.v1.MorphologyH\x00R\n" +
+	"morphology\x125\n" +
+	"\treduction\x18\x05 \x01(\v2\x15.gomanip.v1.ReductionH\x00R\treduction\x12&\n" +
+	"\x04text\x18\x06 \x01(\v2\x10.gomanip.v1.TextH\x00R\x04text\x12?\n" +
+	"\rrandom_filter\x18\a \x01(\v2\x18.gomanip.v1.RandomFilterH\x00R\frandomFilter\x122\n" +
+	"\bconvolve\x18\b \x01(\v2\x14.gomanip.v1.ConvolveH\x00R\bconvolve\x12/\n" +
+	"\astylize\x18\t \x01(\v2\x13.gomanip.v1.StylizeH\x00R\astylize\x12)\n" +
+	"\x05swirl\x18\n" +
+	" \x01(\v2\x11.gomanip.v1.SwirlH\x00R\x05swirl\x12)\n" +
+	"\x05bulge\x18\v \x01(\v2\x11.gomanip.v1.BulgeH\x00R\x05bulge\x12&\n" +
+	"\x04wave\x18\f \x01(\v2\x10.gomanip.v1.WaveH\x00R\x04wave\x12/\n" +
+	"\afisheye\x18\r \x01(\v2\x13.gomanip.v1.FisheyeH\x00R\afisheye\x12,\n" +
+	"\x06mirror\x18\x0e \x01(\v2\x12.gomanip.v1.MirrorH\x00R\x06mirror\x12>\n" +
+	"\fkaleidoscope\x18\x0f \x01(\v2\x18.gomanip.v1.KaleidoscopeH\x00R\fkaleidoscope\x12B\n" +
+	"\x0egaussian_noise\x18\x10 \x01(\v2\x19.gomanip.v1.GaussianNoiseH\x00R\rgaussianNoise\x12C\n" +
+	"\x0fsalt_and_pepper\x18\x11 \x01(\v2\x19.gomanip.v1.SaltAndPepperH\x00R\rsaltAndPepper\x126\n" +
+	"\n" +
+	"film_grain\x18\x12 \x01(\v2\x15.gomanip.v1.FilmGrainH\x00R\tfilmGrain\x122\n" +
+	"\bvignette\x18\x13 \x01(\v2\x14.gomanip.v1.VignetteH\x00R\bvignette\x123\n" +
+	"\told_photo\x18\x14 \x01(\v2\x14.gomanip.v1.OldPhotoH\x00R\boldPhoto\x12K\n" +
+	"\x11remove_background\x18\x15 \x01(\v2\x1c.gomanip.v1.RemoveBackgroundH\x00R\x10removeBackground\x12/\n" +
+	"\ashuffle\x18\x16 \x01(\v2\x13.gomanip.v1.ShuffleH\x00R\ashuffleB\v\n" +
+	"\toperation\"\b\n" +
+	"\x06Invert\"*\n" +
+	"\bSaturate\x12\x1e\n" +
+	"\n" +
+	"saturation\x18\x01 \x01(\x02R\n" +
+	"saturation\"=\n" +
+	"\rEdgeDetection\x12\x14\n" +
+	"\x05lower\x18\x01 \x01(\x02R\x05lower\x12\x16\n" +
+	"\x06higher\x18\x02 \x01(\x02R\x06higher\"a\n" +
+	"\n" +
+	"Morphology\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x1f\n" +
+	"\vkernel_size\x18\x02 \x01(\x05R\n" +
+	"kernelSize\x12\x1e\n" +
+	"\n" +
+	"iterations\x18\x03 \x01(\x05R\n" +
+	"iterations\"%\n" +
+	"\tReduction\x12\x18\n" +
+	"\aquality\x18\x01 \x01(\x02R\aquality\"g\n" +
+	"\x04Text\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\x1d\n" +
+	"\n" +
+	"font_scale\x18\x02 \x01(\x01R\tfontScale\x12\x15\n" +
+	"\x06x_perc\x18\x03 \x01(\x01R\x05xPerc\x12\x15\n" +
+	"\x06y_perc\x18\x04 \x01(\x01R\x05yPerc\"\x7f\n" +
+	"\fRandomFilter\x12\x1f\n" +
+	"\vkernel_size\x18\x01 \x01(\x05R\n" +
+	"kernelSize\x12\x17\n" +
+	"\amin_val\x18\x02 \x01(\x05R\x06minVal\x12\x17\n" +
+	"\amax_val\x18\x03 \x01(\x05R\x06maxVal\x12\x1c\n" +
+	"\tnormalize\x18\x04 \x01(\bR\tnormalize\"3\n" +
+	"\x06Kernel\x12)\n" +
+	"\x04rows\x18\x01 \x03(\v2\x15.gomanip.v1.KernelRowR\x04rows\"#\n" +
+	"\tKernelRow\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\x02R\x06values\"P\n" +
+	"\bConvolve\x12,\n" +
+	"\akernels\x18\x01 \x03(\v2\x12.gomanip.v1.KernelR\akernels\x12\x16\n" +
+	"\x06preset\x18\x02 \x01(\tR\x06preset\"P\n" +
+	"\aStylize\x12\x14\n" +
+	"\x05style\x18\x01 \x01(\tR\x05style\x12!\n" +
+	"\tintensity\x18\x02 \x01(\x01H\x00R\tintensity\x88\x01\x01B\f\n" +
+	"\n" +
+	"_intensity\"\xab\x01\n" +
+	"\x05Swirl\x12\x1a\n" +
+	"\x06x_perc\x18\x01 \x01(\x01H\x00R\x05xPerc\x88\x01\x01\x12\x1a\n" +
+	"\x06y_perc\x18\x02 \x01(\x01H\x01R\x05yPerc\x88\x01\x01\x12\x1b\n" +
+	"\x06radius\x18\x03 \x01(\x01H\x02R\x06radius\x88\x01\x01\x12\x1f\n" +
+	"\bstrength\x18\x04 \x01(\x01H\x03R\bstrength\x88\x01\x01B\t\n" +
+	"\a_x_percB\t\n" +
+	"\a_y_percB\t\n" +
+	"\a_radiusB\v\n" +
+	"\t_strength\"\xab\x01\n" +
+	"\x05Bulge\x12\x1a\n" +
+	"\x06x_perc\x18\x01 \x01(\x01H\x00R\x05xPerc\x88\x01\x01\x12\x1a\n" +
+	"\x06y_perc\x18\x02 \x01(\x01H\x01R\x05yPerc\x88\x01\x01\x12\x1b\n" +
+	"\x06radius\x18\x03 \x01(\x01H\x02R\x06radius\x88\x01\x01\x12\x1f\n" +
+	"\bstrength\x18\x04 \x01(\x01H\x03R\bstrength\x88\x01\x01B\t\n" +
+	"\a_x_percB\t\n" +
+	"\a_y_percB\t\n" +
+	"\a_radiusB\v\n" +
+	"\t_strength\"k\n" +
+	"\x04Wave\x12!\n" +
+	"\tamplitude\x18\x01 \x01(\x01H\x00R\tamplitude\x88\x01\x01\x12#\n" +
+	"\n" +
+	"wavelength\x18\x02 \x01(\x01H\x01R\n" +
+	"wavelength\x88\x01\x01B\f\n" +
+	"\n" +
+	"_amplitudeB\r\n" +
+	"\v_wavelength\"\x85\x01\n" +
+	"\aFisheye\x12\x1a\n" +
+	"\x06x_perc\x18\x01 \x01(\x01H\x00R\x05xPerc\x88\x01\x01\x12\x1a\n" +
+	"\x06y_perc\x18\x02 \x01(\x01H\x01R\x05yPerc\x88\x01\x01\x12\x1f\n" +
+	"\bstrength\x18\x03 \x01(\x01H\x02R\bstrength\x88\x01\x01B\t\n" +
+	"\a_x_percB\t\n" +
+	"\a_y_percB\v\n" +
+	"\t_strength\"\x1c\n" +
+	"\x06Mirror\x12\x12\n" +
+	"\x04side\x18\x01 \x01(\tR\x04side\"\x8a\x01\n" +
+	"\fKaleidoscope\x12\x1f\n" +
+	"\bsegments\x18\x01 \x01(\x05H\x00R\bsegments\x88\x01\x01\x12\x1a\n" +
+	"\x06x_perc\x18\x02 \x01(\x01H\x01R\x05xPerc\x88\x01\x01\x12\x1a\n" +
+	"\x06y_perc\x18\x03 \x01(\x01H\x02R\x05yPerc\x88\x01\x01B\v\n" +
+	"\t_segmentsB\t\n" +
+	"\a_x_percB\t\n" +
+	"\a_y_perc\"H\n" +
+	"\rGaussianNoise\x12\x19\n" +
+	"\x05sigma\x18\x01 \x01(\x01H\x00R\x05sigma\x88\x01\x01\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x03R\x04seedB\b\n" +
+	"\x06_sigma\"K\n" +
+	"\rSaltAndPepper\x12\x1b\n" +
+	"\x06amount\x18\x01 \x01(\x01H\x00R\x06amount\x88\x01\x01\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x03R\x04seedB\t\n" +
+	"\a_amount\"M\n" +
+	"\tFilmGrain\x12\x1f\n" +
+	"\bstrength\x18\x01 \x01(\x01H\x00R\bstrength\x88\x01\x01\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x03R\x04seedB\v\n" +
+	"\t_strength\"`\n" +
+	"\bVignette\x12\x1f\n" +
+	"\bstrength\x18\x01 \x01(\x01H\x00R\bstrength\x88\x01\x01\x12\x1b\n" +
+	"\x06radius\x18\x02 \x01(\x01H\x01R\x06radius\x88\x01\x01B\v\n" +
+	"\t_strengthB\t\n" +
+	"\a_radius\"\x9c\x01\n" +
+	"\bOldPhoto\x12\x1d\n" +
+	"\aquality\x18\x01 \x01(\x02H\x00R\aquality\x88\x01\x01\x12\x19\n" +
+	"\x05grain\x18\x02 \x01(\x01H\x01R\x05grain\x88\x01\x01\x12\x1f\n" +
+	"\bvignette\x18\x03 \x01(\x01H\x02R\bvignette\x88\x01\x01\x12\x12\n" +
+	"\x04seed\x18\x04 \x01(\x03R\x04seedB\n" +
+	"\n" +
+	"\b_qualityB\b\n" +
+	"\x06_grainB\v\n" +
+	"\t_vignette\"\x93\x01\n" +
+	"\aShuffle\x12\x1e\n" +
+	"\n" +
+	"partitions\x18\x01 \x01(\x05R\n" +
+	"partitions\x12\x12\n" +
+	"\x04rows\x18\x02 \x01(\x05R\x04rows\x12\x12\n" +
+	"\x04cols\x18\x03 \x01(\x05R\x04cols\x12\x14\n" +
+	"\x05swaps\x18\x04 \x01(\x05R\x05swaps\x12\x16\n" +
+	"\x06rotate\x18\x05 \x01(\bR\x06rotate\x12\x12\n" +
+	"\x04flip\x18\x06 \x01(\bR\x04flip\"Y\n" +
+	"\x10RemoveBackground\x12\x14\n" +
+	"\x05color\x18\x01 \x01(\tR\x05color\x12!\n" +
+	"\ttolerance\x18\x02 \x01(\x05H\x00R\ttolerance\x88\x01\x01B\f\n" +
+	"\n" +
+	"_tolerance2\xcb\x01\n" +
+	"\aGoManip\x12B\n" +
+	"\aProcess\x12\x1a.gomanip.v1.ProcessRequest\x1a\x1b.gomanip.v1.ProcessResponse\x12>\n" +
+	"\x05Batch\x12\x18.gomanip.v1.BatchRequest\x1a\x19.gomanip.v1.BatchResponse(\x01\x12<\n" +
+	"\x06Submit\x12\x1a.gomanip.v1.ProcessRequest\x1a\x14.gomanip.v1.JobEvent0\x01B\x19Z\x17goManip/proto/gomanippbb\x06proto3"
+
+var (
+	file_gomanip_proto_rawDescOnce sync.Once
+	file_gomanip_proto_rawDescData []byte
+)
+
+func file_gomanip_proto_rawDescGZIP() []byte {
+	file_gomanip_proto_rawDescOnce.Do(func() {
+		file_gomanip_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_gomanip_proto_rawDesc), len(file_gomanip_proto_rawDesc)))
+	})
+	return file_gomanip_proto_rawDescData
+}
+
+var file_gomanip_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gomanip_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
+var file_gomanip_proto_goTypes = []any{
+	(JobEvent_State)(0),      // 0: gomanip.v1.JobEvent.State
+	(*ProcessRequest)(nil),   // 1: gomanip.v1.ProcessRequest
+	(*ProcessResponse)(nil),  // 2: gomanip.v1.ProcessResponse
+	(*BatchRequest)(nil),     // 3: gomanip.v1.BatchRequest
+	(*BatchResponse)(nil),    // 4: gomanip.v1.BatchResponse
+	(*BatchResult)(nil),      // 5: gomanip.v1.BatchResult
+	(*JobEvent)(nil),         // 6: gomanip.v1.JobEvent
+	(*Progress)(nil),         // 7: gomanip.v1.Progress
+	(*Operation)(nil),        // 8: gomanip.v1.Operation
+	(*Invert)(nil),           // 9: gomanip.v1.Invert
+	(*Saturate)(nil),         // 10: gomanip.v1.Saturate
+	(*EdgeDetection)(nil),    // 11: gomanip.v1.EdgeDetection
+	(*Morphology)(nil),       // 12: gomanip.v1.Morphology
+	(*Reduction)(nil),        // 13: gomanip.v1.Reduction
+	(*Text)(nil),             // 14: gomanip.v1.Text
+	(*RandomFilter)(nil),     // 15: gomanip.v1.RandomFilter
+	(*Kernel)(nil),           // 16: gomanip.v1.Kernel
+	(*KernelRow)(nil),        // 17: gomanip.v1.KernelRow
+	(*Convolve)(nil),         // 18: gomanip.v1.Convolve
+	(*Stylize)(nil),          // 19: gomanip.v1.Stylize
+	(*Swirl)(nil),            // 20: gomanip.v1.Swirl
+	(*Bulge)(nil),            // 21: gomanip.v1.Bulge
+	(*Wave)(nil),             // 22: gomanip.v1.Wave
+	(*Fisheye)(nil),          // 23: gomanip.v1.Fisheye
+	(*Mirror)(nil),           // 24: gomanip.v1.Mirror
+	(*Kaleidoscope)(nil),     // 25: gomanip.v1.Kaleidoscope
+	(*GaussianNoise)(nil),    // 26: gomanip.v1.GaussianNoise
+	(*SaltAndPepper)(nil),    // 27: gomanip.v1.SaltAndPepper
+	(*FilmGrain)(nil),        // 28: gomanip.v1.FilmGrain
+	(*Vignette)(nil),         // 29: gomanip.v1.Vignette
+	(*OldPhoto)(nil),         // 30: gomanip.v1.OldPhoto
+	(*Shuffle)(nil),          // 31: gomanip.v1.Shuffle
+	(*RemoveBackground)(nil), // 32: gomanip.v1.RemoveBackground
+}
+var file_gomanip_proto_depIdxs = []int32{
+	8,  // 0: gomanip.v1.ProcessRequest.operations:type_name -> gomanip.v1.Operation
+	8,  // 1: gomanip.v1.BatchRequest.operations:type_name -> gomanip.v1.Operation
+	5,  // 2: gomanip.v1.BatchResponse.results:type_name -> gomanip.v1.BatchResult
+	0,  // 3: gomanip.v1.JobEvent.state:type_name -> gomanip.v1.JobEvent.State
+	7,  // 4: gomanip.v1.JobEvent.progress:type_name -> gomanip.v1.Progress
+	9,  // 5: gomanip.v1.Operation.invert:type_name -> gomanip.v1.Invert
+	10, // 6: gomanip.v1.Operation.saturate:type_name -> gomanip.v1.Saturate
+	11, // 7: gomanip.v1.Operation.edge_detection:type_name -> gomanip.v1.EdgeDetection
+	12, // 8: gomanip.v1.Operation.morphology:type_name -> gomanip.v1.Morphology
+	13, // 9: gomanip.v1.Operation.reduction:type_name -> gomanip.v1.Reduction
+	14, // 10: gomanip.v1.Operation.text:type_name -> gomanip.v1.Text
+	15, // 11: gomanip.v1.Operation.random_filter:type_name -> gomanip.v1.RandomFilter
+	18, // 12: gomanip.v1.Operation.convolve:type_name -> gomanip.v1.Convolve
+	19, // 13: gomanip.v1.Operation.stylize:type_name -> gomanip.v1.Stylize
+	20, // 14: gomanip.v1.Operation.swirl:type_name -> gomanip.v1.Swirl
+	21, // 15: gomanip.v1.Operation.bulge:type_name -> gomanip.v1.Bulge
+	22, // 16: gomanip.v1.Operation.wave:type_name -> gomanip.v1.Wave
+	23, // 17: gomanip.v1.Operation.fisheye:type_name -> gomanip.v1.Fisheye
+	24, // 18: gomanip.v1.Operation.mirror:type_name -> gomanip.v1.Mirror
+	25, // 19: gomanip.v1.Operation.kaleidoscope:type_name -> gomanip.v1.Kaleidoscope
+	26, // 20: gomanip.v1.Operation.gaussian_noise:type_name -> gomanip.v1.GaussianNoise
+	27, // 21: gomanip.v1.Operation.salt_and_pepper:type_name -> gomanip.v1.SaltAndPepper
+	28, // 22: gomanip.v1.Operation.film_grain:type_name -> gomanip.v1.FilmGrain
+	29, // 23: gomanip.v1.Operation.vignette:type_name -> gomanip.v1.Vignette
+	30, // 24: gomanip.v1.Operation.old_photo:type_name -> gomanip.v1.OldPhoto
+	32, // 25: gomanip.v1.Operation.remove_background:type_name -> gomanip.v1.RemoveBackground
+	31, // 26: gomanip.v1.Operation.shuffle:type_name -> gomanip.v1.Shuffle
+	17, // 27: gomanip.v1.Kernel.rows:type_name -> gomanip.v1.KernelRow
+	16, // 28: gomanip.v1.Convolve.kernels:type_name -> gomanip.v1.Kernel
+	1,  // 29: gomanip.v1.GoManip.Process:input_type -> gomanip.v1.ProcessRequest
+	3,  // 30: gomanip.v1.GoManip.Batch:input_type -> gomanip.v1.BatchRequest
+	1,  // 31: gomanip.v1.GoManip.Submit:input_type -> gomanip.v1.ProcessRequest
+	2,  // 32: gomanip.v1.GoManip.Process:output_type -> gomanip.v1.ProcessResponse
+	4,  // 33: gomanip.v1.GoManip.Batch:output_type -> gomanip.v1.BatchResponse
+	6,  // 34: gomanip.v1.GoManip.Submit:output_type -> gomanip.v1.JobEvent
+	32, // [32:35] is the sub-list for method output_type
+	29, // [29:32] is the sub-list for method input_type
+	29, // [29:29] is the sub-list for extension type_name
+	29, // [29:29] is the sub-list for extension extendee
+	0,  // [0:29] is the sub-list for field type_name
+}
+
+func init() { file_gomanip_proto_init() }
+func file_gomanip_proto_init() {
+	if File_gomanip_proto != nil {
+		return
+	}
+	file_gomanip_proto_msgTypes[4].OneofWrappers = []any{
+		(*BatchResult_Image)(nil),
+		(*BatchResult_Error)(nil),
+	}
+	file_gomanip_proto_msgTypes[7].OneofWrappers = []any{
+		(*Operation_Invert)(nil),
+		(*Operation_Saturate)(nil),
+		(*Operation_EdgeDetection)(nil),
+		(*Operation_Morphology)(nil),
+		(*Operation_Reduction)(nil),
+		(*Operation_Text)(nil),
+		(*Operation_RandomFilter)(nil),
+		(*Operation_Convolve)(nil),
+		(*Operation_Stylize)(nil),
+		(*Operation_Swirl)(nil),
+		(*Operation_Bulge)(nil),
+		(*Operation_Wave)(nil),
+		(*Operation_Fisheye)(nil),
+		(*Operation_Mirror)(nil),
+		(*Operation_Kaleidoscope)(nil),
+		(*Operation_GaussianNoise)(nil),
+		(*Operation_SaltAndPepper)(nil),
+		(*Operation_FilmGrain)(nil),
+		(*Operation_Vignette)(nil),
+		(*Operation_OldPhoto)(nil),
+		(*Operation_RemoveBackground)(nil),
+		(*Operation_Shuffle)(nil),
+	}
+	file_gomanip_proto_msgTypes[18].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[19].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[20].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[21].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[22].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[24].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[25].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[26].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[27].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[28].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[29].OneofWrappers = []any{}
+	file_gomanip_proto_msgTypes[31].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_gomanip_proto_rawDesc), len(file_gomanip_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   32,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_gomanip_proto_goTypes,
+		DependencyIndexes: file_gomanip_proto_depIdxs,
+		EnumInfos:         file_gomanip_proto_enumTypes,
+		MessageInfos:      file_gomanip_proto_msgTypes,
+	}.Build()
+	File_gomanip_proto = out.File
+	file_gomanip_proto_goTypes = nil
+	file_gomanip_proto_depIdxs = nil
+}
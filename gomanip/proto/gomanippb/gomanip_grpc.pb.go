@@ -0,0 +1,214 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: gomanip.proto
+
+package gomanippb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GoManip_Process_FullMethodName = "/gomanip.v1.GoManip/Process"
+	GoManip_Batch_FullMethodName   = "/gomanip.v1.GoManip/Batch"
+	GoManip_Submit_FullMethodName  = "/gomanip.v1.GoManip/Submit"
+)
+
+// GoManipClient is the client API for GoManip service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GoManip runs the image operations of the HTTP API for internal services. It shares the job queue with the
+// HTTP server, so its jobs are scheduled and limited the same way.
+//
+// The metadata keys x-client-id and x-priority work like the HTTP headers of the same name,
+// the deadline of a call takes the place of the X-Timeout header.
+type GoManipClient interface {
+	// Process runs the operations on a single image and returns the png result.
+	Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error)
+	// Batch runs the same operations over every image the client streams, the first message has to name them.
+	// An image that fails does not fail the rest of the batch.
+	Batch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BatchRequest, BatchResponse], error)
+	// Submit runs the operations on a single image as a job, streaming its progress and ending with its result.
+	Submit(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobEvent], error)
+}
+
+type goManipClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGoManipClient(cc grpc.ClientConnInterface) GoManipClient {
+	return &goManipClient{cc}
+}
+
+func (c *goManipClient) Process(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (*ProcessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ProcessResponse)
+	err := c.cc.Invoke(ctx, GoManip_Process_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *goManipClient) Batch(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[BatchRequest, BatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GoManip_ServiceDesc.Streams[0], GoManip_Batch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BatchRequest, BatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoManip_BatchClient = grpc.ClientStreamingClient[BatchRequest, BatchResponse]
+
+func (c *goManipClient) Submit(ctx context.Context, in *ProcessRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[JobEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GoManip_ServiceDesc.Streams[1], GoManip_Submit_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ProcessRequest, JobEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoManip_SubmitClient = grpc.ServerStreamingClient[JobEvent]
+
+// GoManipServer is the server API for GoManip service.
+// All implementations must embed UnimplementedGoManipServer
+// for forward compatibility.
+//
+// GoManip runs the image operations of the HTTP API for internal services. It shares the job queue with the
+// HTTP server, so its jobs are scheduled and limited the same way.
+//
+// The metadata keys x-client-id and x-priority work like the HTTP headers of the same name,
+// the deadline of a call takes the place of the X-Timeout header.
+type GoManipServer interface {
+	// Process runs the operations on a single image and returns the png result.
+	Process(context.Context, *ProcessRequest) (*ProcessResponse, error)
+	// Batch runs the same operations over every image the client streams, the first message has to name them.
+	// An image that fails does not fail the rest of the batch.
+	Batch(grpc.ClientStreamingServer[BatchRequest, BatchResponse]) error
+	// Submit runs the operations on a single image as a job, streaming its progress and ending with its result.
+	Submit(*ProcessRequest, grpc.ServerStreamingServer[JobEvent]) error
+	mustEmbedUnimplementedGoManipServer()
+}
+
+// UnimplementedGoManipServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGoManipServer struct{}
+
+func (UnimplementedGoManipServer) Process(context.Context, *ProcessRequest) (*ProcessResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
+}
+func (UnimplementedGoManipServer) Batch(grpc.ClientStreamingServer[BatchRequest, BatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedGoManipServer) Submit(*ProcessRequest, grpc.ServerStreamingServer[JobEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Submit not implemented")
+}
+func (UnimplementedGoManipServer) mustEmbedUnimplementedGoManipServer() {}
+func (UnimplementedGoManipServer) testEmbeddedByValue()                 {}
+
+// UnsafeGoManipServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GoManipServer will
+// result in compilation errors.
+type UnsafeGoManipServer interface {
+	mustEmbedUnimplementedGoManipServer()
+}
+
+func RegisterGoManipServer(s grpc.ServiceRegistrar, srv GoManipServer) {
+	// If the following call pancis, it indicates UnimplementedGoManipServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GoManip_ServiceDesc, srv)
+}
+
+func _GoManip_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GoManipServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GoManip_Process_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GoManipServer).Process(ctx, req.(*ProcessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GoManip_Batch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(GoManipServer).Batch(&grpc.GenericServerStream[BatchRequest, BatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoManip_BatchServer = grpc.ClientStreamingServer[BatchRequest, BatchResponse]
+
+func _GoManip_Submit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ProcessRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GoManipServer).Submit(m, &grpc.GenericServerStream[ProcessRequest, JobEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GoManip_SubmitServer = grpc.ServerStreamingServer[JobEvent]
+
+// GoManip_ServiceDesc is the grpc.ServiceDesc for GoManip service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GoManip_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gomanip.v1.GoManip",
+	HandlerType: (*GoManipServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Process",
+			Handler:    _GoManip_Process_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Batch",
+			Handler:       _GoManip_Batch_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Submit",
+			Handler:       _GoManip_Submit_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "gomanip.proto",
+}
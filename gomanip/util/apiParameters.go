@@ -1,9 +1,14 @@
 package util
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
+	"goManip/jobs"
+	"image/color"
 	"strconv"
+	"strings"
 )
 
 func ParseSaturation(c echo.Context) (float32, error) {
@@ -110,11 +115,635 @@ func ParseRandomFilter(c echo.Context) (int, int, int, bool, error) {
 	return minVal, maxVal, kernelSize, normalize, nil
 }
 
-func ParseShuffle(c echo.Context) (int, error) {
-	partitionsStr := c.QueryParam("partitions")
-	partitions, err := strconv.Atoi(partitionsStr)
+// ParseExportKernels reports whether the generated kernels should be returned with the image.
+func ParseExportKernels(c echo.Context) bool {
+	return c.QueryParam("exportKernels") == "true"
+}
+
+// ParseConvolve reads the kernels for a convolution. A single kernel shared by all channels is given
+// as json in the kernel param, one kernel per channel in the kernels param, or a named preset in preset.
+// Kernels may have at most jobs.MaxKernelSize rows and columns.
+func ParseConvolve(c echo.Context) ([]jobs.Kernel, string, error) {
+	kernelStr := c.QueryParam("kernel")
+	kernelsStr := c.QueryParam("kernels")
+	preset := c.QueryParam("preset")
+
+	if kernelStr != "" && kernelsStr != "" {
+		return nil, "", errors.New("kernel and kernels cannot be used together")
+	}
+
+	if kernelStr != "" {
+		var kernel jobs.Kernel
+		if err := json.Unmarshal([]byte(kernelStr), &kernel); err != nil {
+			return nil, "", err
+		}
+		if err := jobs.ValidateKernel(kernel); err != nil {
+			return nil, "", err
+		}
+		return []jobs.Kernel{kernel}, preset, nil
+	}
+
+	if kernelsStr != "" {
+		var kernels []jobs.Kernel
+		if err := json.Unmarshal([]byte(kernelsStr), &kernels); err != nil {
+			return nil, "", err
+		}
+		for _, kernel := range kernels {
+			if err := jobs.ValidateKernel(kernel); err != nil {
+				return nil, "", err
+			}
+		}
+		return kernels, preset, nil
+	}
+
+	if preset == "" {
+		return nil, "", errors.New("one of kernel, kernels or preset is required")
+	}
+
+	return nil, preset, nil
+}
+
+type ShuffleParams struct {
+	Partitions int
+	Rows       int
+	Cols       int
+	Swaps      int
+	Rotate     bool
+	Flip       bool
+	Jigsaw     bool
+}
+
+// parseOptionalInt parses an integer query param, returning 0 when it is not present.
+func parseOptionalInt(c echo.Context, name string) (int, error) {
+	valueStr := c.QueryParam(name)
+
+	if valueStr == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(valueStr)
+}
+
+// ParseShuffle reads the shuffle grid either from rows and cols, or from partitions when those are absent.
+func ParseShuffle(c echo.Context) (ShuffleParams, error) {
+	var params ShuffleParams
+	var err error
+
+	params.Rows, err = parseOptionalInt(c, "rows")
+	if err != nil {
+		return ShuffleParams{}, err
+	}
+
+	params.Cols, err = parseOptionalInt(c, "cols")
+	if err != nil {
+		return ShuffleParams{}, err
+	}
+
+	params.Swaps, err = parseOptionalInt(c, "swaps")
+	if err != nil {
+		return ShuffleParams{}, err
+	}
+
+	if params.Rows == 0 && params.Cols == 0 {
+		params.Partitions, err = strconv.Atoi(c.QueryParam("partitions"))
+		if err != nil {
+			return ShuffleParams{}, err
+		}
+	}
+
+	params.Rotate = c.QueryParam("rotate") == "true"
+	params.Flip = c.QueryParam("flip") == "true"
+	params.Jigsaw = c.QueryParam("jigsaw") == "true"
+
+	return params, nil
+}
+
+type DetectParams struct {
+	Cascade      string
+	Action       string
+	Overlay      string
+	ScaleFactor  float64
+	MinNeighbors int
+}
+
+// ParseDetect reads the detection params, scaleFactor and minNeighbors default to 1.1 and 3.
+func ParseDetect(c echo.Context) (DetectParams, error) {
+	params := DetectParams{
+		Cascade:      c.QueryParam("cascade"),
+		Action:       c.QueryParam("action"),
+		Overlay:      c.QueryParam("overlay"),
+		ScaleFactor:  1.1,
+		MinNeighbors: 3,
+	}
+
+	if params.Cascade == "" {
+		return DetectParams{}, errors.New("cascade is required")
+	}
+
+	if scaleStr := c.QueryParam("scaleFactor"); scaleStr != "" {
+		scale, err := strconv.ParseFloat(scaleStr, 64)
+		if err != nil {
+			return DetectParams{}, err
+		}
+		params.ScaleFactor = scale
+	}
+
+	if neighborsStr := c.QueryParam("minNeighbors"); neighborsStr != "" {
+		neighbors, err := strconv.Atoi(neighborsStr)
+		if err != nil {
+			return DetectParams{}, err
+		}
+		params.MinNeighbors = neighbors
+	}
+
+	return params, nil
+}
+
+// ParseQREncode reads the text to encode, the error correction level defaults to medium and the size to 256.
+func ParseQREncode(c echo.Context) (string, string, int, error) {
+	text := c.QueryParam("text")
+	level := c.QueryParam("level")
+
+	if text == "" {
+		return "", "", 0, errors.New("text is required")
+	}
+
+	if level == "" {
+		level = "medium"
+	}
+
+	size := 256
+	if sizeStr := c.QueryParam("size"); sizeStr != "" {
+		parsed, err := strconv.Atoi(sizeStr)
+		if err != nil {
+			return "", "", 0, err
+		}
+		size = parsed
+	}
+
+	return text, level, size, nil
+}
+
+// ParseAnalyze reads how many dominant colors and histogram bins to report, defaulting to 5 colors and 32 bins.
+func ParseAnalyze(c echo.Context) (int, int, error) {
+	colors, err := parseOptionalInt(c, "colors")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	bins, err := parseOptionalInt(c, "bins")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if c.QueryParam("colors") == "" {
+		colors = 5
+	}
+
+	if c.QueryParam("bins") == "" {
+		bins = 32
+	}
+
+	return colors, bins, nil
+}
+
+// ParseHeatmap reports whether a difference heatmap should be returned with the comparison.
+func ParseHeatmap(c echo.Context) bool {
+	return c.QueryParam("heatmap") == "true"
+}
+
+// ParseAsciiArt reads the width in characters (default 80), the character ramp, whether to use emoji and whether
+// the text should be returned as json rather than plain text (format=json).
+func ParseAsciiArt(c echo.Context) (int, string, bool, bool, error) {
+	columns, err := parseOptionalInt(c, "columns")
+	if err != nil {
+		return 0, "", false, false, err
+	}
+
+	if c.QueryParam("columns") == "" {
+		columns = 80
+	}
+
+	ramp := c.QueryParam("ramp")
+	if ramp == "" {
+		ramp = jobs.DefaultRamp
+	}
+
+	emoji := c.QueryParam("emoji") == "true"
+
+	var asJSON bool
+	switch format := c.QueryParam("format"); format {
+	case "", "text":
+	case "json":
+		asJSON = true
+	default:
+		return 0, "", false, false, fmt.Errorf("unknown format %s, expected text or json", format)
+	}
+
+	return columns, ramp, emoji, asJSON, nil
+}
+
+// ParseStylize reads the style and its intensity, which defaults to 0.5.
+func ParseStylize(c echo.Context) (jobs.Style, float64, error) {
+	style := c.QueryParam("style")
+	if style == "" {
+		return "", 0, errors.New("style is required")
+	}
+
+	intensity := 0.5
+	if intensityStr := c.QueryParam("intensity"); intensityStr != "" {
+		parsed, err := strconv.ParseFloat(intensityStr, 64)
+		if err != nil {
+			return "", 0, err
+		}
+		intensity = parsed
+	}
+
+	return jobs.Style(style), intensity, nil
+}
+
+func parseOptionalFloat(c echo.Context, name string, defaultValue float64) (float64, error) {
+	valueStr := c.QueryParam(name)
+
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	return strconv.ParseFloat(valueStr, 64)
+}
+
+// parseCenter reads the xPerc and yPerc percentages of an effect's center, which default to the middle of the image.
+func parseCenter(c echo.Context) (float64, float64, error) {
+	xPerc, err := parseOptionalFloat(c, "xPerc", 0.5)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	yPerc, err := parseOptionalFloat(c, "yPerc", 0.5)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return xPerc, yPerc, nil
+}
+
+// ParseSwirl reads the center, the radius (default 0.5) and the strength in radians (default 4) of a swirl.
+func ParseSwirl(c echo.Context) (float64, float64, float64, float64, error) {
+	xPerc, yPerc, err := parseCenter(c)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	radius, err := parseOptionalFloat(c, "radius", 0.5)
 	if err != nil {
-		return 0, err
+		return 0, 0, 0, 0, err
 	}
-	return partitions, nil
+
+	strength, err := parseOptionalFloat(c, "strength", 4)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	return xPerc, yPerc, radius, strength, nil
+}
+
+// ParseBulge reads the center, the radius (default 0.5) and the strength (default 0.5) of a bulge or pinch.
+func ParseBulge(c echo.Context) (float64, float64, float64, float64, error) {
+	xPerc, yPerc, err := parseCenter(c)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	radius, err := parseOptionalFloat(c, "radius", 0.5)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	strength, err := parseOptionalFloat(c, "strength", 0.5)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	return xPerc, yPerc, radius, strength, nil
+}
+
+// ParseWave reads the amplitude (default 10) and wavelength (default 60) of a wave in pixels.
+func ParseWave(c echo.Context) (float64, float64, error) {
+	amplitude, err := parseOptionalFloat(c, "amplitude", 10)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	wavelength, err := parseOptionalFloat(c, "wavelength", 60)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return amplitude, wavelength, nil
+}
+
+// ParseFisheye reads the center and the strength (default 0.5) of a fisheye.
+func ParseFisheye(c echo.Context) (float64, float64, float64, error) {
+	xPerc, yPerc, err := parseCenter(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	strength, err := parseOptionalFloat(c, "strength", 0.5)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return xPerc, yPerc, strength, nil
+}
+
+// ParseMirror reads the side of the image that is kept and mirrored.
+func ParseMirror(c echo.Context) (jobs.MirrorSide, error) {
+	side := c.QueryParam("side")
+	if side == "" {
+		return "", errors.New("side is required")
+	}
+
+	return jobs.MirrorSide(side), nil
+}
+
+// ParseKaleidoscope reads the number of segments (default 6) and the center of a kaleidoscope.
+func ParseKaleidoscope(c echo.Context) (int, float64, float64, error) {
+	segments, err := parseOptionalInt(c, "segments")
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	if c.QueryParam("segments") == "" {
+		segments = 6
+	}
+
+	xPerc, yPerc, err := parseCenter(c)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	return segments, xPerc, yPerc, nil
+}
+
+// parseSeed reads the seed of a random operation, returning 0 (a random seed) when it is not present.
+func parseSeed(c echo.Context) (int64, error) {
+	seedStr := c.QueryParam("seed")
+
+	if seedStr == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseInt(seedStr, 10, 64)
+}
+
+// ParseGaussianNoise reads the standard deviation (default 20) and the seed of gaussian noise.
+func ParseGaussianNoise(c echo.Context) (float64, int64, error) {
+	sigma, err := parseOptionalFloat(c, "sigma", 20)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	seed, err := parseSeed(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return sigma, seed, nil
+}
+
+// ParseSaltAndPepper reads the share of affected pixels (default 0.05) and the seed of salt and pepper noise.
+func ParseSaltAndPepper(c echo.Context) (float64, int64, error) {
+	amount, err := parseOptionalFloat(c, "amount", 0.05)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	seed, err := parseSeed(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return amount, seed, nil
+}
+
+// ParseFilmGrain reads the strength (default 0.5) and the seed of film grain.
+func ParseFilmGrain(c echo.Context) (float64, int64, error) {
+	strength, err := parseOptionalFloat(c, "strength", 0.5)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	seed, err := parseSeed(c)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return strength, seed, nil
+}
+
+// ParseVignette reads the strength (default 0.6) and the radius (default 0.4) of a vignette.
+func ParseVignette(c echo.Context) (float64, float64, error) {
+	strength, err := parseOptionalFloat(c, "strength", 0.6)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	radius, err := parseOptionalFloat(c, "radius", jobs.DefaultVignetteRadius)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return strength, radius, nil
+}
+
+// ParseOldPhoto reads the quality (default 0.5), grain (default 0.4), vignette (default 0.6) and seed of the old photo preset.
+func ParseOldPhoto(c echo.Context) (float32, float64, float64, int64, error) {
+	quality, err := parseOptionalFloat(c, "quality", 0.5)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	grain, err := parseOptionalFloat(c, "grain", 0.4)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	vignette, err := parseOptionalFloat(c, "vignette", 0.6)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	seed, err := parseSeed(c)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	return float32(quality), grain, vignette, seed, nil
+}
+
+// ParseHexColor parses a hex color like #00ff00 or 00ff00.
+func ParseHexColor(hex string) (color.RGBA, error) {
+	hex = strings.TrimPrefix(hex, "#")
+
+	if len(hex) != 6 {
+		return color.RGBA{}, fmt.Errorf("expected a 6 digit hex color, got %s", hex)
+	}
+
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("invalid hex color %s: %w", hex, err)
+	}
+
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 255}, nil
+}
+
+// ParseColorKey reads the background color to remove and the tolerance (default 30). Without a color
+// the background color is taken from the top left pixel, which is reported by the returned bool.
+func ParseColorKey(c echo.Context) (color.RGBA, bool, int, error) {
+	tolerance, err := parseOptionalInt(c, "tolerance")
+	if err != nil {
+		return color.RGBA{}, false, 0, err
+	}
+
+	if c.QueryParam("tolerance") == "" {
+		tolerance = 30
+	}
+
+	hex := c.QueryParam("color")
+	if hex == "" {
+		return color.RGBA{}, true, tolerance, nil
+	}
+
+	key, err := ParseHexColor(hex)
+	if err != nil {
+		return color.RGBA{}, false, 0, err
+	}
+
+	return key, false, tolerance, nil
+}
+
+// AnimationParams are the frame count and delay shared by the animated endpoints.
+type AnimationParams struct {
+	Frames int
+	// Delay is in hundredths of a second.
+	Delay int
+}
+
+// parseAnimation reads the frame count (default 20) and delay (default 5) of an animation.
+func parseAnimation(c echo.Context) (AnimationParams, error) {
+	params := AnimationParams{Frames: 20, Delay: 5}
+
+	if c.QueryParam("frames") != "" {
+		frames, err := strconv.Atoi(c.QueryParam("frames"))
+		if err != nil {
+			return AnimationParams{}, err
+		}
+		params.Frames = frames
+	}
+
+	if c.QueryParam("delay") != "" {
+		delay, err := strconv.Atoi(c.QueryParam("delay"))
+		if err != nil {
+			return AnimationParams{}, err
+		}
+		params.Delay = delay
+	}
+
+	return params, nil
+}
+
+// ParseZoom reads the animation, the final scale (default 2) and the center of a zoom.
+func ParseZoom(c echo.Context) (AnimationParams, float64, float64, float64, error) {
+	animation, err := parseAnimation(c)
+	if err != nil {
+		return AnimationParams{}, 0, 0, 0, err
+	}
+
+	scale, err := parseOptionalFloat(c, "scale", 2)
+	if err != nil {
+		return AnimationParams{}, 0, 0, 0, err
+	}
+
+	xPerc, yPerc, err := parseCenter(c)
+	if err != nil {
+		return AnimationParams{}, 0, 0, 0, err
+	}
+
+	return animation, scale, xPerc, yPerc, nil
+}
+
+// ParseSpin reads the animation and the number of turns (default 1) of a spin.
+func ParseSpin(c echo.Context) (AnimationParams, float64, error) {
+	animation, err := parseAnimation(c)
+	if err != nil {
+		return AnimationParams{}, 0, err
+	}
+
+	turns, err := parseOptionalFloat(c, "turns", 1)
+	if err != nil {
+		return AnimationParams{}, 0, err
+	}
+
+	return animation, turns, nil
+}
+
+// ParseShake reads the animation, the amount (default 0.03), the caption and the seed of a shake.
+func ParseShake(c echo.Context) (AnimationParams, float64, string, int64, error) {
+	animation, err := parseAnimation(c)
+	if err != nil {
+		return AnimationParams{}, 0, "", 0, err
+	}
+
+	amount, err := parseOptionalFloat(c, "amount", 0.03)
+	if err != nil {
+		return AnimationParams{}, 0, "", 0, err
+	}
+
+	seed, err := parseSeed(c)
+	if err != nil {
+		return AnimationParams{}, 0, "", 0, err
+	}
+
+	return animation, amount, c.QueryParam("caption"), seed, nil
+}
+
+// ParseTween reads the animation, the animated operation and the values its parameter moves between.
+func ParseTween(c echo.Context) (AnimationParams, string, float64, float64, error) {
+	animation, err := parseAnimation(c)
+	if err != nil {
+		return AnimationParams{}, "", 0, 0, err
+	}
+
+	operation := c.QueryParam("operation")
+	if operation == "" {
+		return AnimationParams{}, "", 0, 0, errors.New("operation is required")
+	}
+
+	from, err := strconv.ParseFloat(c.QueryParam("from"), 64)
+	if err != nil {
+		return AnimationParams{}, "", 0, 0, err
+	}
+
+	to, err := strconv.ParseFloat(c.QueryParam("to"), 64)
+	if err != nil {
+		return AnimationParams{}, "", 0, 0, err
+	}
+
+	return animation, operation, from, to, nil
+}
+
+// ParseExport reads the export target (emoji or sticker) and how the image is fitted to it (default pad).
+func ParseExport(c echo.Context) (string, jobs.FitMode, error) {
+	target := c.QueryParam("target")
+	if target == "" {
+		return "", "", errors.New("target is required")
+	}
+
+	fit := jobs.FitMode(c.QueryParam("fit"))
+	if fit == "" {
+		fit = jobs.FitPad
+	}
+
+	return target, fit, nil
 }
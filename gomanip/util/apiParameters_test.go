@@ -1,9 +1,12 @@
 package util_test
 
 import (
+	"encoding/json"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"goManip/jobs"
 	"goManip/util"
+	"image/color"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -441,44 +444,1222 @@ func TestParseRandomFilter(t *testing.T) {
 	}
 }
 
+// onesKernel is a kernel of rows x cols ones, kernelJson the same kernel as json.
+func onesKernel(rows, cols int) jobs.Kernel {
+	kernel := make(jobs.Kernel, rows)
+	for row := range kernel {
+		kernel[row] = make([]float32, cols)
+		for col := range kernel[row] {
+			kernel[row][col] = 1
+		}
+	}
+	return kernel
+}
+
+func kernelJson(rows, cols int) string {
+	encoded, _ := json.Marshal(onesKernel(rows, cols))
+	return string(encoded)
+}
+
+func TestParseConvolve(t *testing.T) {
+	tests := []struct {
+		name            string
+		params          map[string]string
+		wantErr         bool
+		expectedKernels []jobs.Kernel
+		expectedPreset  string
+	}{
+		{
+			name: "valid shared kernel",
+			params: map[string]string{
+				"kernel": "[[0,-1,0],[-1,5,-1],[0,-1,0]]",
+			},
+			wantErr:         false,
+			expectedKernels: []jobs.Kernel{{{0, -1, 0}, {-1, 5, -1}, {0, -1, 0}}},
+		},
+		{
+			name: "valid kernel per channel",
+			params: map[string]string{
+				"kernels": "[[[1]],[[0.5]],[[2]]]",
+			},
+			wantErr:         false,
+			expectedKernels: []jobs.Kernel{{{1}}, {{0.5}}, {{2}}},
+		},
+		{
+			name: "valid preset",
+			params: map[string]string{
+				"preset": "sharpen",
+			},
+			wantErr:        false,
+			expectedPreset: "sharpen",
+		},
+		{
+			name: "invalid kernel json",
+			params: map[string]string{
+				"kernel": "[[1,2],",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid kernels json",
+			params: map[string]string{
+				"kernels": "[[1,2]]",
+			},
+			wantErr: true,
+		},
+		{
+			name: "kernel and kernels",
+			params: map[string]string{
+				"kernel":  "[[1]]",
+				"kernels": "[[[1]]]",
+			},
+			wantErr: true,
+		},
+		{
+			name: "largest kernel",
+			params: map[string]string{
+				"kernel": kernelJson(jobs.MaxKernelSize, jobs.MaxKernelSize),
+			},
+			wantErr:         false,
+			expectedKernels: []jobs.Kernel{onesKernel(jobs.MaxKernelSize, jobs.MaxKernelSize)},
+		},
+		{
+			name: "kernel with too many rows",
+			params: map[string]string{
+				"kernel": kernelJson(jobs.MaxKernelSize+1, 1),
+			},
+			wantErr: true,
+		},
+		{
+			name: "kernel per channel with too many columns",
+			params: map[string]string{
+				"kernels": "[[[1]]," + kernelJson(1, jobs.MaxKernelSize+1) + ",[[1]]]",
+			},
+			wantErr: true,
+		},
+		{
+			name: "ragged kernel",
+			params: map[string]string{
+				"kernel": "[[1,2],[3]]",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing params",
+			params:  map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			kernels, preset, err := util.ParseConvolve(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedKernels, kernels)
+			assert.Equal(t, tt.expectedPreset, preset)
+		})
+	}
+}
+
 func TestParseShuffle(t *testing.T) {
 	tests := []struct {
-		name        string
-		params      map[string]string
-		wantErr     bool
-		expectedVal int
+		name     string
+		params   map[string]string
+		wantErr  bool
+		expected util.ShuffleParams
 	}{
 		{
 			name: "valid partition param",
 			params: map[string]string{
 				"partitions": "3",
 			},
-			wantErr:     false,
-			expectedVal: 3,
+			wantErr:  false,
+			expected: util.ShuffleParams{Partitions: 3},
 		},
 		{
 			name: "invalid partition param",
 			params: map[string]string{
 				"partitions": "abc",
 			},
-			wantErr:     true,
-			expectedVal: 0,
+			wantErr:  true,
+			expected: util.ShuffleParams{},
 		},
 		{
-			name:        "missing partition param",
-			params:      map[string]string{},
-			wantErr:     true,
-			expectedVal: 0,
+			name:     "missing partition param",
+			params:   map[string]string{},
+			wantErr:  true,
+			expected: util.ShuffleParams{},
+		},
+		{
+			name: "valid grid params",
+			params: map[string]string{
+				"rows":   "2",
+				"cols":   "4",
+				"swaps":  "1",
+				"rotate": "true",
+				"flip":   "false",
+				"jigsaw": "true",
+			},
+			wantErr:  false,
+			expected: util.ShuffleParams{Rows: 2, Cols: 4, Swaps: 1, Rotate: true, Jigsaw: true},
+		},
+		{
+			name: "grid params take precedence over partitions",
+			params: map[string]string{
+				"partitions": "abc",
+				"rows":       "3",
+				"cols":       "3",
+			},
+			wantErr:  false,
+			expected: util.ShuffleParams{Rows: 3, Cols: 3},
+		},
+		{
+			name: "invalid rows param",
+			params: map[string]string{
+				"rows": "abc",
+				"cols": "3",
+			},
+			wantErr:  true,
+			expected: util.ShuffleParams{},
+		},
+		{
+			name: "invalid swaps param",
+			params: map[string]string{
+				"partitions": "4",
+				"swaps":      "abc",
+			},
+			wantErr:  true,
+			expected: util.ShuffleParams{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			params, err := util.ParseShuffle(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expected, params)
+
+		})
+	}
+}
+
+func TestParseDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   map[string]string
+		wantErr  bool
+		expected util.DetectParams
+	}{
+		{
+			name: "valid params with defaults",
+			params: map[string]string{
+				"cascade": "haarcascade_frontalface_default",
+			},
+			wantErr:  false,
+			expected: util.DetectParams{Cascade: "haarcascade_frontalface_default", ScaleFactor: 1.1, MinNeighbors: 3},
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"cascade":      "haarcascade_frontalface_default",
+				"action":       "overlay",
+				"overlay":      "sunglasses",
+				"scaleFactor":  "1.3",
+				"minNeighbors": "5",
+			},
+			wantErr:  false,
+			expected: util.DetectParams{Cascade: "haarcascade_frontalface_default", Action: "overlay", Overlay: "sunglasses", ScaleFactor: 1.3, MinNeighbors: 5},
+		},
+		{
+			name:     "missing cascade",
+			params:   map[string]string{"action": "draw"},
+			wantErr:  true,
+			expected: util.DetectParams{},
+		},
+		{
+			name: "invalid scale factor",
+			params: map[string]string{
+				"cascade":     "haarcascade_frontalface_default",
+				"scaleFactor": "big",
+			},
+			wantErr:  true,
+			expected: util.DetectParams{},
+		},
+		{
+			name: "invalid min neighbors",
+			params: map[string]string{
+				"cascade":      "haarcascade_frontalface_default",
+				"minNeighbors": "some",
+			},
+			wantErr:  true,
+			expected: util.DetectParams{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			params, err := util.ParseDetect(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+}
+
+func TestParseQREncode(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]string
+		wantErr       bool
+		expectedText  string
+		expectedLevel string
+		expectedSize  int
+	}{
+		{
+			name: "valid params with defaults",
+			params: map[string]string{
+				"text": "game night at 8",
+			},
+			wantErr:       false,
+			expectedText:  "game night at 8",
+			expectedLevel: "medium",
+			expectedSize:  256,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"text":  "game night at 8",
+				"level": "highest",
+				"size":  "512",
+			},
+			wantErr:       false,
+			expectedText:  "game night at 8",
+			expectedLevel: "highest",
+			expectedSize:  512,
+		},
+		{
+			name: "invalid size",
+			params: map[string]string{
+				"text": "game night at 8",
+				"size": "big",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing text",
+			params:  map[string]string{"size": "128"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			text, level, size, err := util.ParseQREncode(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedText, text)
+			assert.Equal(t, tt.expectedLevel, level)
+			assert.Equal(t, tt.expectedSize, size)
+		})
+	}
+}
+
+func TestParseAnalyze(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         map[string]string
+		wantErr        bool
+		expectedColors int
+		expectedBins   int
+	}{
+		{
+			name:           "defaults",
+			params:         map[string]string{},
+			wantErr:        false,
+			expectedColors: 5,
+			expectedBins:   32,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"colors": "8",
+				"bins":   "256",
+			},
+			wantErr:        false,
+			expectedColors: 8,
+			expectedBins:   256,
+		},
+		{
+			name:    "invalid colors",
+			params:  map[string]string{"colors": "many"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid bins",
+			params:  map[string]string{"bins": "1.5"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			colors, bins, err := util.ParseAnalyze(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedColors, colors)
+			assert.Equal(t, tt.expectedBins, bins)
+		})
+	}
+}
+
+func TestParseAsciiArt(t *testing.T) {
+	tests := []struct {
+		name            string
+		params          map[string]string
+		wantErr         bool
+		expectedColumns int
+		expectedRamp    string
+		expectedEmoji   bool
+		expectedJSON    bool
+	}{
+		{
+			name:            "defaults",
+			params:          map[string]string{},
+			wantErr:         false,
+			expectedColumns: 80,
+			expectedRamp:    jobs.DefaultRamp,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"columns": "40",
+				"ramp":    " ░▒▓█",
+				"emoji":   "true",
+				"format":  "json",
+			},
+			wantErr:         false,
+			expectedColumns: 40,
+			expectedRamp:    " ░▒▓█",
+			expectedEmoji:   true,
+			expectedJSON:    true,
+		},
+		{
+			name:    "invalid columns",
+			params:  map[string]string{"columns": "wide"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid format",
+			params:  map[string]string{"format": "html"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			columns, ramp, emoji, asJSON, err := util.ParseAsciiArt(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedColumns, columns)
+			assert.Equal(t, tt.expectedRamp, ramp)
+			assert.Equal(t, tt.expectedEmoji, emoji)
+			assert.Equal(t, tt.expectedJSON, asJSON)
+		})
+	}
+}
+
+func TestParseStylize(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedStyle     jobs.Style
+		expectedIntensity float64
+	}{
+		{
+			name:              "default intensity",
+			params:            map[string]string{"style": "cartoon"},
+			wantErr:           false,
+			expectedStyle:     jobs.StyleCartoon,
+			expectedIntensity: 0.5,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"style":     "sketch",
+				"intensity": "0.8",
+			},
+			wantErr:           false,
+			expectedStyle:     jobs.StyleSketch,
+			expectedIntensity: 0.8,
+		},
+		{
+			name:    "missing style",
+			params:  map[string]string{"intensity": "0.8"},
+			wantErr: true,
+		},
+		{
+			name: "invalid intensity",
+			params: map[string]string{
+				"style":     "comic",
+				"intensity": "strong",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			style, intensity, err := util.ParseStylize(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedStyle, style)
+			assert.Equal(t, tt.expectedIntensity, intensity)
+		})
+	}
+}
+
+func TestParseSwirlAndBulge(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         map[string]string
+		wantErr        bool
+		expectedX      float64
+		expectedY      float64
+		expectedRadius float64
+		expectedSwirl  float64
+		expectedBulge  float64
+	}{
+		{
+			name:           "defaults",
+			params:         map[string]string{},
+			wantErr:        false,
+			expectedX:      0.5,
+			expectedY:      0.5,
+			expectedRadius: 0.5,
+			expectedSwirl:  4,
+			expectedBulge:  0.5,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"xPerc":    "0.25",
+				"yPerc":    "0.75",
+				"radius":   "0.3",
+				"strength": "-0.8",
+			},
+			wantErr:        false,
+			expectedX:      0.25,
+			expectedY:      0.75,
+			expectedRadius: 0.3,
+			expectedSwirl:  -0.8,
+			expectedBulge:  -0.8,
+		},
+		{
+			name:    "invalid center",
+			params:  map[string]string{"xPerc": "left"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid strength",
+			params:  map[string]string{"strength": "lots"},
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := newTestContext(tt.params)
-			partitions, err := util.ParseShuffle(ctx)
+
+			x, y, radius, strength, err := util.ParseSwirl(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedX, x)
+			assert.Equal(t, tt.expectedY, y)
+			assert.Equal(t, tt.expectedRadius, radius)
+			assert.Equal(t, tt.expectedSwirl, strength)
+
+			x, y, radius, strength, err = util.ParseBulge(ctx)
 			assert.Equal(t, tt.wantErr, err != nil)
-			assert.Equal(t, tt.expectedVal, partitions)
+			assert.Equal(t, tt.expectedX, x)
+			assert.Equal(t, tt.expectedY, y)
+			assert.Equal(t, tt.expectedRadius, radius)
+			assert.Equal(t, tt.expectedBulge, strength)
+		})
+	}
+}
 
+func TestParseWave(t *testing.T) {
+	tests := []struct {
+		name               string
+		params             map[string]string
+		wantErr            bool
+		expectedAmplitude  float64
+		expectedWavelength float64
+	}{
+		{
+			name:               "defaults",
+			params:             map[string]string{},
+			wantErr:            false,
+			expectedAmplitude:  10,
+			expectedWavelength: 60,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"amplitude":  "4.5",
+				"wavelength": "120",
+			},
+			wantErr:            false,
+			expectedAmplitude:  4.5,
+			expectedWavelength: 120,
+		},
+		{
+			name:    "invalid wavelength",
+			params:  map[string]string{"wavelength": "long"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			amplitude, wavelength, err := util.ParseWave(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAmplitude, amplitude)
+			assert.Equal(t, tt.expectedWavelength, wavelength)
+		})
+	}
+}
+
+func TestParseFisheye(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantErr          bool
+		expectedX        float64
+		expectedY        float64
+		expectedStrength float64
+	}{
+		{
+			name:             "defaults",
+			params:           map[string]string{},
+			wantErr:          false,
+			expectedX:        0.5,
+			expectedY:        0.5,
+			expectedStrength: 0.5,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"xPerc":    "0.1",
+				"yPerc":    "0.9",
+				"strength": "-0.3",
+			},
+			wantErr:          false,
+			expectedX:        0.1,
+			expectedY:        0.9,
+			expectedStrength: -0.3,
+		},
+		{
+			name:    "invalid center",
+			params:  map[string]string{"yPerc": "top"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			x, y, strength, err := util.ParseFisheye(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedX, x)
+			assert.Equal(t, tt.expectedY, y)
+			assert.Equal(t, tt.expectedStrength, strength)
+		})
+	}
+}
+
+func TestParseMirror(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       map[string]string
+		wantErr      bool
+		expectedSide jobs.MirrorSide
+	}{
+		{
+			name:         "valid side",
+			params:       map[string]string{"side": "topleft"},
+			wantErr:      false,
+			expectedSide: jobs.MirrorTopLeft,
+		},
+		{
+			name:    "missing side",
+			params:  map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			side, err := util.ParseMirror(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedSide, side)
+		})
+	}
+}
+
+func TestParseKaleidoscope(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantErr          bool
+		expectedSegments int
+		expectedX        float64
+		expectedY        float64
+	}{
+		{
+			name:             "defaults",
+			params:           map[string]string{},
+			wantErr:          false,
+			expectedSegments: 6,
+			expectedX:        0.5,
+			expectedY:        0.5,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"segments": "12",
+				"xPerc":    "0.2",
+				"yPerc":    "0.4",
+			},
+			wantErr:          false,
+			expectedSegments: 12,
+			expectedX:        0.2,
+			expectedY:        0.4,
+		},
+		{
+			name:    "invalid segments",
+			params:  map[string]string{"segments": "six"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			segments, x, y, err := util.ParseKaleidoscope(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedSegments, segments)
+			assert.Equal(t, tt.expectedX, x)
+			assert.Equal(t, tt.expectedY, y)
+		})
+	}
+}
+
+func TestParseGaussianNoise(t *testing.T) {
+	tests := []struct {
+		name          string
+		params        map[string]string
+		wantErr       bool
+		expectedSigma float64
+		expectedSeed  int64
+	}{
+		{
+			name:          "defaults",
+			params:        map[string]string{},
+			wantErr:       false,
+			expectedSigma: 20,
+			expectedSeed:  0,
+		},
+		{
+			name:          "valid params",
+			params:        map[string]string{"sigma": "12.5", "seed": "42"},
+			wantErr:       false,
+			expectedSigma: 12.5,
+			expectedSeed:  42,
+		},
+		{
+			name:    "invalid seed",
+			params:  map[string]string{"seed": "1.5"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			sigma, seed, err := util.ParseGaussianNoise(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedSigma, sigma)
+			assert.Equal(t, tt.expectedSeed, seed)
+		})
+	}
+}
+
+func TestParseSaltAndPepper(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         map[string]string
+		wantErr        bool
+		expectedAmount float64
+		expectedSeed   int64
+	}{
+		{
+			name:           "defaults",
+			params:         map[string]string{},
+			wantErr:        false,
+			expectedAmount: 0.05,
+			expectedSeed:   0,
+		},
+		{
+			name:           "valid params",
+			params:         map[string]string{"amount": "0.2", "seed": "-7"},
+			wantErr:        false,
+			expectedAmount: 0.2,
+			expectedSeed:   -7,
+		},
+		{
+			name:    "invalid amount",
+			params:  map[string]string{"amount": "lots"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			amount, seed, err := util.ParseSaltAndPepper(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAmount, amount)
+			assert.Equal(t, tt.expectedSeed, seed)
+		})
+	}
+}
+
+func TestParseFilmGrain(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantErr          bool
+		expectedStrength float64
+		expectedSeed     int64
+	}{
+		{
+			name:             "defaults",
+			params:           map[string]string{},
+			wantErr:          false,
+			expectedStrength: 0.5,
+			expectedSeed:     0,
+		},
+		{
+			name:             "valid params",
+			params:           map[string]string{"strength": "0.8", "seed": "3"},
+			wantErr:          false,
+			expectedStrength: 0.8,
+			expectedSeed:     3,
+		},
+		{
+			name:    "invalid strength",
+			params:  map[string]string{"strength": "strong"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			strength, seed, err := util.ParseFilmGrain(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedStrength, strength)
+			assert.Equal(t, tt.expectedSeed, seed)
+		})
+	}
+}
+
+func TestParseVignette(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantErr          bool
+		expectedStrength float64
+		expectedRadius   float64
+	}{
+		{
+			name:             "defaults",
+			params:           map[string]string{},
+			wantErr:          false,
+			expectedStrength: 0.6,
+			expectedRadius:   0.4,
+		},
+		{
+			name:             "valid params",
+			params:           map[string]string{"strength": "1", "radius": "0.2"},
+			wantErr:          false,
+			expectedStrength: 1,
+			expectedRadius:   0.2,
+		},
+		{
+			name:    "invalid radius",
+			params:  map[string]string{"radius": "wide"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			strength, radius, err := util.ParseVignette(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedStrength, strength)
+			assert.Equal(t, tt.expectedRadius, radius)
+		})
+	}
+}
+
+func TestParseOldPhoto(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           map[string]string
+		wantErr          bool
+		expectedQuality  float32
+		expectedGrain    float64
+		expectedVignette float64
+		expectedSeed     int64
+	}{
+		{
+			name:             "defaults",
+			params:           map[string]string{},
+			wantErr:          false,
+			expectedQuality:  0.5,
+			expectedGrain:    0.4,
+			expectedVignette: 0.6,
+			expectedSeed:     0,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"quality":  "0.25",
+				"grain":    "0.7",
+				"vignette": "0.9",
+				"seed":     "11",
+			},
+			wantErr:          false,
+			expectedQuality:  0.25,
+			expectedGrain:    0.7,
+			expectedVignette: 0.9,
+			expectedSeed:     11,
+		},
+		{
+			name:    "invalid vignette",
+			params:  map[string]string{"vignette": "dark"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			quality, grain, vignette, seed, err := util.ParseOldPhoto(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedQuality, quality)
+			assert.Equal(t, tt.expectedGrain, grain)
+			assert.Equal(t, tt.expectedVignette, vignette)
+			assert.Equal(t, tt.expectedSeed, seed)
+		})
+	}
+}
+
+func TestParseColorKey(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedKey       color.RGBA
+		expectedAuto      bool
+		expectedTolerance int
+	}{
+		{
+			name:              "defaults",
+			params:            map[string]string{},
+			wantErr:           false,
+			expectedAuto:      true,
+			expectedTolerance: 30,
+		},
+		{
+			name:              "valid params",
+			params:            map[string]string{"color": "#00FF80", "tolerance": "10"},
+			wantErr:           false,
+			expectedKey:       color.RGBA{R: 0, G: 255, B: 128, A: 255},
+			expectedAuto:      false,
+			expectedTolerance: 10,
+		},
+		{
+			name:              "color without hash",
+			params:            map[string]string{"color": "ffffff"},
+			wantErr:           false,
+			expectedKey:       color.RGBA{R: 255, G: 255, B: 255, A: 255},
+			expectedAuto:      false,
+			expectedTolerance: 30,
+		},
+		{
+			name:    "invalid color",
+			params:  map[string]string{"color": "green"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid tolerance",
+			params:  map[string]string{"tolerance": "some"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			key, auto, tolerance, err := util.ParseColorKey(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedKey, key)
+			assert.Equal(t, tt.expectedAuto, auto)
+			assert.Equal(t, tt.expectedTolerance, tolerance)
+		})
+	}
+}
+
+func TestParseZoom(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedAnimation util.AnimationParams
+		expectedScale     float64
+		expectedX         float64
+		expectedY         float64
+	}{
+		{
+			name:              "defaults",
+			params:            map[string]string{},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 20, Delay: 5},
+			expectedScale:     2,
+			expectedX:         0.5,
+			expectedY:         0.5,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"frames": "30",
+				"delay":  "8",
+				"scale":  "3.5",
+				"xPerc":  "0.1",
+				"yPerc":  "0.9",
+			},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 30, Delay: 8},
+			expectedScale:     3.5,
+			expectedX:         0.1,
+			expectedY:         0.9,
+		},
+		{
+			name:    "invalid frames",
+			params:  map[string]string{"frames": "many"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid delay",
+			params:  map[string]string{"delay": "0.5"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			animation, scale, x, y, err := util.ParseZoom(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAnimation, animation)
+			assert.Equal(t, tt.expectedScale, scale)
+			assert.Equal(t, tt.expectedX, x)
+			assert.Equal(t, tt.expectedY, y)
+		})
+	}
+}
+
+func TestParseSpin(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedAnimation util.AnimationParams
+		expectedTurns     float64
+	}{
+		{
+			name:              "defaults",
+			params:            map[string]string{},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 20, Delay: 5},
+			expectedTurns:     1,
+		},
+		{
+			name:              "valid params",
+			params:            map[string]string{"frames": "12", "turns": "-2"},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 12, Delay: 5},
+			expectedTurns:     -2,
+		},
+		{
+			name:    "invalid turns",
+			params:  map[string]string{"turns": "twice"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			animation, turns, err := util.ParseSpin(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAnimation, animation)
+			assert.Equal(t, tt.expectedTurns, turns)
+		})
+	}
+}
+
+func TestParseShake(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedAnimation util.AnimationParams
+		expectedAmount    float64
+		expectedCaption   string
+		expectedSeed      int64
+	}{
+		{
+			name:              "defaults",
+			params:            map[string]string{},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 20, Delay: 5},
+			expectedAmount:    0.03,
+			expectedCaption:   "",
+			expectedSeed:      0,
+		},
+		{
+			name: "valid params",
+			params: map[string]string{
+				"delay":   "2",
+				"amount":  "0.1",
+				"caption": "[gopher intensifies]",
+				"seed":    "5",
+			},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 20, Delay: 2},
+			expectedAmount:    0.1,
+			expectedCaption:   "[gopher intensifies]",
+			expectedSeed:      5,
+		},
+		{
+			name:    "invalid seed",
+			params:  map[string]string{"seed": "random"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			animation, amount, caption, seed, err := util.ParseShake(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAnimation, animation)
+			assert.Equal(t, tt.expectedAmount, amount)
+			assert.Equal(t, tt.expectedCaption, caption)
+			assert.Equal(t, tt.expectedSeed, seed)
+		})
+	}
+}
+
+func TestParseTween(t *testing.T) {
+	tests := []struct {
+		name              string
+		params            map[string]string
+		wantErr           bool
+		expectedAnimation util.AnimationParams
+		expectedOperation string
+		expectedFrom      float64
+		expectedTo        float64
+	}{
+		{
+			name:              "valid params",
+			params:            map[string]string{"operation": "saturate", "from": "0", "to": "5", "frames": "40"},
+			wantErr:           false,
+			expectedAnimation: util.AnimationParams{Frames: 40, Delay: 5},
+			expectedOperation: "saturate",
+			expectedFrom:      0,
+			expectedTo:        5,
+		},
+		{
+			name:    "missing operation",
+			params:  map[string]string{"from": "0", "to": "5"},
+			wantErr: true,
+		},
+		{
+			name:    "missing to",
+			params:  map[string]string{"operation": "swirl", "from": "0"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			animation, operation, from, to, err := util.ParseTween(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedAnimation, animation)
+			assert.Equal(t, tt.expectedOperation, operation)
+			assert.Equal(t, tt.expectedFrom, from)
+			assert.Equal(t, tt.expectedTo, to)
+		})
+	}
+}
+
+func TestParseExport(t *testing.T) {
+	tests := []struct {
+		name           string
+		params         map[string]string
+		wantErr        bool
+		expectedTarget string
+		expectedFit    jobs.FitMode
+	}{
+		{
+			name:           "default fit",
+			params:         map[string]string{"target": "emoji"},
+			wantErr:        false,
+			expectedTarget: "emoji",
+			expectedFit:    jobs.FitPad,
+		},
+		{
+			name:           "valid params",
+			params:         map[string]string{"target": "sticker", "fit": "crop"},
+			wantErr:        false,
+			expectedTarget: "sticker",
+			expectedFit:    jobs.FitCrop,
+		},
+		{
+			name:    "missing target",
+			params:  map[string]string{"fit": "crop"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContext(tt.params)
+			target, fit, err := util.ParseExport(ctx)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.expectedTarget, target)
+			assert.Equal(t, tt.expectedFit, fit)
 		})
 	}
 }
@@ -0,0 +1,50 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AssetDir maps the names (file names without extension) of the files in a local directory to their paths.
+// Requests refer to assets by name, so only files that were present at startup can ever be opened.
+type AssetDir struct {
+	paths map[string]string
+}
+
+// LoadAssetDir indexes every file in dir with the given extension.
+func LoadAssetDir(dir, ext string) (*AssetDir, error) {
+	assets := &AssetDir{paths: make(map[string]string)}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return assets, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ext {
+			continue
+		}
+		name := strings.TrimSuffix(entry.Name(), ext)
+		assets.paths[name] = filepath.Join(dir, entry.Name())
+	}
+
+	return assets, nil
+}
+
+// Path returns the path of the named asset, and false if there is no such asset.
+func (a *AssetDir) Path(name string) (string, bool) {
+	path, ok := a.paths[name]
+	return path, ok
+}
+
+// Names returns the names of all assets in sorted order.
+func (a *AssetDir) Names() []string {
+	names := make([]string, 0, len(a.paths))
+	for name := range a.paths {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,45 @@
+package util_test
+
+import (
+	"github.com/stretchr/testify/assert"
+	"goManip/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadAssetDir(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"frontalface.xml", "eye.xml", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("<xml/>"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "nested.xml"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	assets, err := util.LoadAssetDir(dir, ".xml")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"eye", "frontalface"}, assets.Names())
+
+	path, ok := assets.Path("frontalface")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "frontalface.xml"), path)
+
+	_, ok = assets.Path("notes")
+	assert.False(t, ok)
+
+	_, ok = assets.Path("../frontalface")
+	assert.False(t, ok)
+}
+
+func TestLoadAssetDirMissing(t *testing.T) {
+	assets, err := util.LoadAssetDir(filepath.Join(t.TempDir(), "missing"), ".xml")
+	assert.NotNil(t, err)
+	assert.Empty(t, assets.Names())
+
+	_, ok := assets.Path("anything")
+	assert.False(t, ok)
+}
@@ -0,0 +1,353 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/labstack/echo/v4"
+	"gocv.io/x/gocv"
+)
+
+const (
+	// MaxBatchImages is the most images a single batch request may hold.
+	MaxBatchImages = 16
+	// maxBatchFileBytes limits the size of a single image in a batch, zip entries are checked
+	// after decompression so a small archive cannot expand into something huge.
+	maxBatchFileBytes = 32 << 20
+	// BatchFormField is the multipart field holding the images of a batch.
+	BatchFormField = "images"
+	// batchManifest is the name of the json file describing every result of a batch.
+	batchManifest = "results.json"
+
+	BatchZip       = "zip"
+	BatchMultipart = "multipart"
+)
+
+var (
+	batchImageTypes = []string{"image/png", "image/jpeg"}
+	zipContentTypes = []string{"application/zip", "application/x-zip-compressed"}
+	batchFormats    = []string{BatchZip, BatchMultipart}
+)
+
+// BatchFile is one uploaded image of a batch, Error is set instead of Image when it could not be read.
+type BatchFile struct {
+	Name  string
+	Image *gocv.Mat
+	Error error
+}
+
+// BatchEntry is the processed result for one image of a batch, Error is set instead of Image when it failed.
+type BatchEntry struct {
+	Name  string
+	Image []byte
+	Error error
+}
+
+// batchManifestEntry describes one result in the manifest, File is empty when the image failed.
+type batchManifestEntry struct {
+	Name  string `json:"name"`
+	File  string `json:"file,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ParseBatch returns the operations to run, in order, and the format of the response.
+func ParseBatch(c echo.Context) ([]string, string, error) {
+	operationParam := c.QueryParam("operation")
+	if operationParam == "" {
+		return nil, "", errors.New("operation is required")
+	}
+
+	operations := strings.Split(operationParam, ",")
+	for _, operation := range operations {
+		if strings.TrimSpace(operation) == "" {
+			return nil, "", fmt.Errorf("invalid operation list %q", operationParam)
+		}
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = BatchZip
+	}
+	if !slices.Contains(batchFormats, format) {
+		return nil, "", fmt.Errorf("unknown format %s, expected one of %s", format, strings.Join(batchFormats, ", "))
+	}
+
+	return operations, format, nil
+}
+
+// GetImagesFromBatch reads every image of a batch upload, either a zip archive or a multipart form with the
+// images under BatchFormField. An image that can not be read is returned with its error so the rest of the batch
+// can still be processed, an error is only returned when the upload itself is unusable.
+func GetImagesFromBatch(c echo.Context) ([]BatchFile, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type: %w", err)
+	}
+
+	var files []BatchFile
+	switch {
+	case slices.Contains(zipContentTypes, mediaType):
+		files, err = getImagesFromZip(c)
+	case mediaType == echo.MIMEMultipartForm:
+		files, err = getImagesFromMultipart(c)
+	default:
+		return nil, fmt.Errorf("%s is not supported for batches, send a zip archive or a multipart form", mediaType)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("the batch has no images")
+	}
+
+	return files, nil
+}
+
+func getImagesFromZip(c echo.Context) ([]BatchFile, error) {
+	request := c.Request()
+	defer request.Body.Close()
+
+	body, err := ReadBody(request.Body, request.ContentLength, MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	// every image is decoded before returning, nothing reads the archive afterwards
+	defer PutBuffer(body)
+
+	archive, err := zip.NewReader(bytes.NewReader(body.Bytes()), int64(body.Len()))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	// the entries are counted before any of them is decoded, so a batch that is too large holds no images yet
+	var entries []*zip.File
+	for _, entry := range archive.File {
+		// skip folders and the metadata some archivers add next to the images
+		if entry.FileInfo().IsDir() || strings.HasPrefix(entry.Name, "__MACOSX/") || strings.HasPrefix(path.Base(entry.Name), ".") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) > MaxBatchImages {
+		return nil, fmt.Errorf("batches hold at most %d images", MaxBatchImages)
+	}
+
+	files := make([]BatchFile, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, readZipImage(entry))
+	}
+
+	return files, nil
+}
+
+func readZipImage(entry *zip.File) BatchFile {
+	file := BatchFile{Name: entry.Name}
+
+	reader, err := entry.Open()
+	if err != nil {
+		file.Error = err
+		return file
+	}
+	defer reader.Close()
+
+	imageBytes, err := readLimited(reader, int64(entry.UncompressedSize64))
+	if err != nil {
+		file.Error = err
+		return file
+	}
+	defer PutBuffer(imageBytes)
+
+	// zip entries have no content type, so go by the bytes themselves
+	if contentType := http.DetectContentType(imageBytes.Bytes()); !slices.Contains(batchImageTypes, contentType) {
+		file.Error = fmt.Errorf("%s files are not supported", contentType)
+		return file
+	}
+
+	file.Image, file.Error = DecodeImage(imageBytes.Bytes())
+	return file
+}
+
+func getImagesFromMultipart(c echo.Context) ([]BatchFile, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart form: %w", err)
+	}
+
+	headers := form.File[BatchFormField]
+	if len(headers) > MaxBatchImages {
+		return nil, fmt.Errorf("batches hold at most %d images", MaxBatchImages)
+	}
+
+	files := make([]BatchFile, 0, len(headers))
+	for _, header := range headers {
+		files = append(files, readFormImage(header))
+	}
+
+	return files, nil
+}
+
+func readFormImage(header *multipart.FileHeader) BatchFile {
+	file := BatchFile{Name: header.Filename}
+
+	if contentType := header.Header.Get("Content-Type"); !slices.Contains(batchImageTypes, contentType) {
+		file.Error = fmt.Errorf("%s files are not supported", contentType)
+		return file
+	}
+
+	reader, err := header.Open()
+	if err != nil {
+		file.Error = err
+		return file
+	}
+	defer reader.Close()
+
+	imageBytes, err := readLimited(reader, header.Size)
+	if err != nil {
+		file.Error = err
+		return file
+	}
+	defer PutBuffer(imageBytes)
+
+	file.Image, file.Error = DecodeImage(imageBytes.Bytes())
+	return file
+}
+
+// readLimited reads a single image of a batch into a pooled buffer, the zip header's size is only a hint
+// since the entry is checked again while it is decompressed.
+func readLimited(reader io.Reader, sizeHint int64) (*bytes.Buffer, error) {
+	imageBytes, err := ReadBody(reader, sizeHint, maxBatchFileBytes)
+	if err != nil {
+		return nil, fmt.Errorf("batch image: %w", err)
+	}
+	return imageBytes, nil
+}
+
+// batchFileName names the png for the entry at idx, the index keeps names unique when uploads share a name.
+// Anything but letters, digits, dots, dashes and underscores is replaced so the name is safe in headers and archives.
+func batchFileName(idx int, name string) string {
+	base := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	base = strings.Map(func(r rune) rune {
+		if r < 128 && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("._-", r)) {
+			return r
+		}
+		return '_'
+	}, base)
+	return fmt.Sprintf("%03d-%s.png", idx+1, base)
+}
+
+func batchManifestEntries(entries []BatchEntry) []batchManifestEntry {
+	manifest := make([]batchManifestEntry, len(entries))
+	for idx, entry := range entries {
+		manifest[idx].Name = entry.Name
+		if entry.Error != nil {
+			manifest[idx].Error = entry.Error.Error()
+			continue
+		}
+		manifest[idx].File = batchFileName(idx, entry.Name)
+	}
+	return manifest
+}
+
+// WriteBatch streams the results of a batch to the response in format, the archive is written as it is
+// built rather than buffered first. Once writing started a failure can only cut the response short.
+func WriteBatch(c echo.Context, format string, entries []BatchEntry) error {
+	response := c.Response()
+
+	if format == BatchMultipart {
+		writer := multipart.NewWriter(response)
+		response.Header().Set(echo.HeaderContentType, "multipart/mixed; boundary="+writer.Boundary())
+		response.WriteHeader(http.StatusOK)
+		return EncodeBatchMultipart(writer, entries)
+	}
+
+	response.Header().Set(echo.HeaderContentType, "application/zip")
+	response.WriteHeader(http.StatusOK)
+	return EncodeBatchZip(response, entries)
+}
+
+// EncodeBatchZip packs the results of a batch into a zip archive written to w, results.json lists every entry
+// with either the file holding its image or the reason it failed.
+func EncodeBatchZip(w io.Writer, entries []BatchEntry) error {
+	archive := zip.NewWriter(w)
+
+	manifest, err := json.Marshal(batchManifestEntries(entries))
+	if err != nil {
+		return err
+	}
+
+	writer, err := archive.Create(batchManifest)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(manifest); err != nil {
+		return err
+	}
+
+	for idx, entry := range entries {
+		if entry.Error != nil {
+			continue
+		}
+
+		// pngs are already compressed, deflating them again only costs time
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: batchFileName(idx, entry.Name), Method: zip.Store})
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(entry.Image); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// EncodeBatchMultipart writes the results of a batch as a multipart/mixed body, the first part is the
+// results.json manifest followed by one part per processed image. The content type has to name the writer's boundary.
+func EncodeBatchMultipart(writer *multipart.Writer, entries []BatchEntry) error {
+	manifest, err := json.Marshal(batchManifestEntries(entries))
+	if err != nil {
+		return err
+	}
+
+	if err := writeBatchPart(writer, batchManifest, echo.MIMEApplicationJSON, manifest); err != nil {
+		return err
+	}
+
+	for idx, entry := range entries {
+		if entry.Error != nil {
+			continue
+		}
+		if err := writeBatchPart(writer, batchFileName(idx, entry.Name), "image/png", entry.Image); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func writeBatchPart(writer *multipart.Writer, name, contentType string, data []byte) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	header.Set("Content-Type", contentType)
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	_, err = part.Write(data)
+	return err
+}
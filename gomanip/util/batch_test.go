@@ -0,0 +1,298 @@
+package util_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gocv.io/x/gocv"
+
+	"goManip/mattest"
+	"goManip/util"
+)
+
+type batchUpload struct {
+	name        string
+	contentType string
+	data        []byte
+}
+
+func encodedTestImage(t *testing.T, width int) []byte {
+	image := gocv.NewMatWithSize(8, width, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoded.Close()
+
+	return bytes.Clone(encoded.GetBytes())
+}
+
+func newTestContextWithZip(uploads []batchUpload) echo.Context {
+	body := &bytes.Buffer{}
+	archive := zip.NewWriter(body)
+	for _, upload := range uploads {
+		writer, err := archive.Create(upload.name)
+		if err != nil {
+			panic(err)
+		}
+		writer.Write(upload.data)
+	}
+	archive.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", "application/zip")
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func newTestContextWithBatchForm(uploads []batchUpload) echo.Context {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for _, upload := range uploads {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, util.BatchFormField, upload.name))
+		header.Set("Content-Type", upload.contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			panic(err)
+		}
+		part.Write(upload.data)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return echo.New().NewContext(req, httptest.NewRecorder())
+}
+
+func TestParseBatch(t *testing.T) {
+	tests := []struct {
+		name               string
+		params             map[string]string
+		expectedOperations []string
+		expectedFormat     string
+		wantErr            bool
+	}{
+		{
+			name:               "Single operation defaults to zip",
+			params:             map[string]string{"operation": "invert"},
+			expectedOperations: []string{"invert"},
+			expectedFormat:     util.BatchZip,
+		},
+		{
+			name:               "Pipeline as multipart",
+			params:             map[string]string{"operation": "reduction,filmGrain", "format": "multipart"},
+			expectedOperations: []string{"reduction", "filmGrain"},
+			expectedFormat:     util.BatchMultipart,
+		},
+		{
+			name:    "Missing operation",
+			params:  map[string]string{"format": "zip"},
+			wantErr: true,
+		},
+		{
+			name:    "Empty pipeline step",
+			params:  map[string]string{"operation": "invert,,mirror"},
+			wantErr: true,
+		},
+		{
+			name:    "Unknown format",
+			params:  map[string]string{"operation": "invert", "format": "tar"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestContext(tt.params)
+			operations, format, err := util.ParseBatch(c)
+
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				assert.Equal(t, tt.expectedOperations, operations)
+				assert.Equal(t, tt.expectedFormat, format)
+			}
+		})
+	}
+}
+
+func TestGetImagesFromBatch(t *testing.T) {
+	small, large := encodedTestImage(t, 10), encodedTestImage(t, 20)
+
+	uploads := []batchUpload{
+		{name: "small.png", contentType: "image/png", data: small},
+		{name: "notes.txt", contentType: "text/plain", data: []byte("not an image")},
+		{name: "large.png", contentType: "image/png", data: large},
+		{name: "broken.png", contentType: "image/png", data: small[:16]},
+	}
+
+	tests := []struct {
+		name string
+		ctx  echo.Context
+	}{
+		{name: "Zip archive", ctx: newTestContextWithZip(uploads)},
+		{name: "Multipart form", ctx: newTestContextWithBatchForm(uploads)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := util.GetImagesFromBatch(tt.ctx)
+			assert.NoError(t, err)
+			assert.Len(t, files, len(uploads))
+
+			for idx, file := range files {
+				assert.Equal(t, uploads[idx].name, file.Name)
+				if file.Image != nil {
+					defer file.Image.Close()
+				}
+			}
+
+			assert.NoError(t, files[0].Error)
+			assert.Equal(t, 10, files[0].Image.Cols())
+			assert.Error(t, files[1].Error)
+			assert.NoError(t, files[2].Error)
+			assert.Equal(t, 20, files[2].Image.Cols())
+			assert.Error(t, files[3].Error)
+		})
+	}
+}
+
+func TestGetImagesFromBatchErrors(t *testing.T) {
+	image := encodedTestImage(t, 10)
+
+	tooMany := make([]batchUpload, util.MaxBatchImages+1)
+	for idx := range tooMany {
+		tooMany[idx] = batchUpload{name: fmt.Sprintf("%d.png", idx), contentType: "image/png", data: image}
+	}
+
+	invalidZip := newTestContextInvalidImage("application/zip")
+	wrongType := newTestContextInvalidImage("image/png")
+
+	tests := []struct {
+		name string
+		ctx  echo.Context
+	}{
+		{name: "Too many zipped images", ctx: newTestContextWithZip(tooMany)},
+		{name: "Too many form images", ctx: newTestContextWithBatchForm(tooMany)},
+		{name: "Empty zip", ctx: newTestContextWithZip(nil)},
+		{name: "Zip of folders only", ctx: newTestContextWithZip([]batchUpload{{name: "images/"}})},
+		{name: "Invalid zip", ctx: invalidZip},
+		{name: "Single image", ctx: wrongType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := util.GetImagesFromBatch(tt.ctx)
+			assert.Error(t, err)
+			assert.Nil(t, files)
+		})
+	}
+}
+
+func TestGetImagesFromZipWithTooManyImages(t *testing.T) {
+	image := encodedTestImage(t, 10)
+	tooMany := make([]batchUpload, util.MaxBatchImages+1)
+	for idx := range tooMany {
+		tooMany[idx] = batchUpload{name: fmt.Sprintf("%d.png", idx), data: image}
+	}
+
+	// none of the images is decoded, so none of them can be left open
+	mattest.AssertFreed(t, func() {
+		files, err := util.GetImagesFromBatch(newTestContextWithZip(tooMany))
+		assert.ErrorContains(t, err, "at most")
+		assert.Nil(t, files)
+	})
+}
+
+type manifestEntry struct {
+	Name  string `json:"name"`
+	File  string `json:"file"`
+	Error string `json:"error"`
+}
+
+var testBatchEntries = []util.BatchEntry{
+	{Name: "cat.png", Image: []byte("cat")},
+	{Name: "dog.jpg", Error: errors.New("operation failed")},
+	{Name: "photos/cat.png", Image: []byte("another cat")},
+	{Name: `we"ird name.png`, Image: []byte("weird")},
+}
+
+var expectedManifest = []manifestEntry{
+	{Name: "cat.png", File: "001-cat.png"},
+	{Name: "dog.jpg", Error: "operation failed"},
+	{Name: "photos/cat.png", File: "003-cat.png"},
+	{Name: `we"ird name.png`, File: "004-we_ird_name.png"},
+}
+
+func TestEncodeBatchZip(t *testing.T) {
+	var body bytes.Buffer
+	assert.NoError(t, util.EncodeBatchZip(&body, testBatchEntries))
+
+	archive, err := zip.NewReader(bytes.NewReader(body.Bytes()), int64(body.Len()))
+	assert.NoError(t, err)
+
+	contents := map[string][]byte{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		contents[file.Name], err = io.ReadAll(reader)
+		assert.NoError(t, err)
+		reader.Close()
+	}
+
+	var manifest []manifestEntry
+	assert.NoError(t, json.Unmarshal(contents["results.json"], &manifest))
+	assert.Equal(t, expectedManifest, manifest)
+
+	assert.Len(t, contents, 4)
+	assert.Equal(t, []byte("cat"), contents["001-cat.png"])
+	assert.Equal(t, []byte("another cat"), contents["003-cat.png"])
+	assert.Equal(t, []byte("weird"), contents["004-we_ird_name.png"])
+}
+
+func TestEncodeBatchMultipart(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+	assert.NoError(t, util.WriteBatch(c, util.BatchMultipart, testBatchEntries))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mediaType, params, err := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(rec.Body, params["boundary"])
+
+	var names []string
+	contents := map[string][]byte{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		names = append(names, part.FileName())
+		contents[part.FileName()], err = io.ReadAll(part)
+		assert.NoError(t, err)
+	}
+
+	// the manifest comes first so clients can read it before the images
+	assert.Equal(t, []string{"results.json", "001-cat.png", "003-cat.png", "004-we_ird_name.png"}, names)
+
+	var manifest []manifestEntry
+	assert.NoError(t, json.Unmarshal(contents["results.json"], &manifest))
+	assert.Equal(t, expectedManifest, manifest)
+	assert.Equal(t, []byte("another cat"), contents["003-cat.png"])
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/labstack/echo/v4"
+	"gocv.io/x/gocv"
+)
+
+const (
+	// maxPooledBuffer drops buffers that grew beyond it instead of pooling them, so a single
+	// huge upload does not keep its memory alive for the rest of the process.
+	maxPooledBuffer = 64 << 20
+	// maxSizeHint caps how much is allocated up front for a body of a given Content-Length,
+	// the header comes from the client and a larger body still grows the buffer as it is read.
+	maxSizeHint = 16 << 20
+)
+
+// MaxBodyBytes bounds every image read from a request, main sets it to the configured body limit.
+var MaxBodyBytes int64 = 32 << 20
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
+// GetBuffer returns an empty buffer from the pool, give it back with PutBuffer once its bytes are no longer used.
+func GetBuffer() *bytes.Buffer {
+	buffer := bufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+// PutBuffer returns a buffer to the pool, nothing may read its bytes afterwards.
+func PutBuffer(buffer *bytes.Buffer) {
+	if buffer == nil || buffer.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buffer)
+}
+
+// ReadBody reads reader into a pooled buffer, sizeHint is the expected size or -1 when it is unknown.
+// More than limit bytes are an error. The buffer has to be given back with PutBuffer.
+func ReadBody(reader io.Reader, sizeHint, limit int64) (*bytes.Buffer, error) {
+	if sizeHint > limit {
+		return nil, fmt.Errorf("images must be at most %d bytes", limit)
+	}
+
+	buffer := GetBuffer()
+	if sizeHint > 0 {
+		// one extra byte lets ReadFrom see the end of the body without growing again
+		buffer.Grow(int(min(sizeHint, maxSizeHint)) + 1)
+	}
+
+	if _, err := buffer.ReadFrom(io.LimitReader(reader, limit+1)); err != nil {
+		PutBuffer(buffer)
+		return nil, err
+	}
+	if int64(buffer.Len()) > limit {
+		PutBuffer(buffer)
+		return nil, fmt.Errorf("images must be at most %d bytes", limit)
+	}
+
+	return buffer, nil
+}
+
+// WriteImage writes an encoded image as the response and closes it. The bytes are written straight from
+// OpenCV's memory, they are not copied into a Go slice first.
+func WriteImage(c echo.Context, contentType string, image *gocv.NativeByteBuffer) error {
+	defer image.Close()
+
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, contentType)
+	header.Set(echo.HeaderContentLength, strconv.Itoa(image.Len()))
+	c.Response().WriteHeader(http.StatusOK)
+
+	_, err := c.Response().Write(image.GetBytes())
+	return err
+}
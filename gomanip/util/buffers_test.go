@@ -0,0 +1,164 @@
+package util_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gocv.io/x/gocv"
+
+	"goManip/util"
+)
+
+func TestReadBody(t *testing.T) {
+	body := bytes.Repeat([]byte("image"), 100)
+
+	tests := []struct {
+		name     string
+		sizeHint int64
+		limit    int64
+		wantErr  bool
+	}{
+		{name: "Known size", sizeHint: int64(len(body)), limit: 1000},
+		{name: "Unknown size", sizeHint: -1, limit: 1000},
+		{name: "Wrong size", sizeHint: 10, limit: 1000},
+		{name: "Exactly the limit", sizeHint: -1, limit: int64(len(body))},
+		{name: "Too large", sizeHint: -1, limit: 100, wantErr: true},
+		{name: "Announced too large", sizeHint: 2000, limit: 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer, err := util.ReadBody(bytes.NewReader(body), tt.sizeHint, tt.limit)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if err == nil {
+				assert.Equal(t, body, buffer.Bytes())
+				util.PutBuffer(buffer)
+			}
+		})
+	}
+}
+
+func TestWriteImage(t *testing.T) {
+	image := gocv.NewMatWithSize(20, 30, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	assert.NoError(t, err)
+	expected := bytes.Clone(encoded.GetBytes())
+
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+	assert.NoError(t, util.WriteImage(c, "image/png", encoded))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+	assert.Equal(t, expected, rec.Body.Bytes())
+}
+
+// benchmarkImage is the encoded png every benchmark request uploads.
+func benchmarkImage(b *testing.B) []byte {
+	image := gocv.NewMatWithSize(1080, 1920, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer encoded.Close()
+	return bytes.Clone(encoded.GetBytes())
+}
+
+// handleBuffered is how requests were handled before the body was streamed into pooled buffers,
+// it is kept to compare allocations against.
+func handleBuffered(c echo.Context) error {
+	imageBytes, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+	image, err := gocv.IMDecode(imageBytes, gocv.IMReadUnchanged)
+	if err != nil {
+		return err
+	}
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	if err != nil {
+		return err
+	}
+	defer encoded.Close()
+	return c.Blob(http.StatusOK, "image/png", append([]byte(nil), encoded.GetBytes()...))
+}
+
+func handleStreamed(c echo.Context) error {
+	image, err := util.GetImageFromBody(c)
+	if err != nil {
+		return err
+	}
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, *image)
+	if err != nil {
+		return err
+	}
+	return util.WriteImage(c, "image/png", encoded)
+}
+
+// BenchmarkImageRequest compares the allocations of a request uploading and downloading an image,
+// run it with go test -bench ImageRequest -benchmem ./util/
+func BenchmarkImageRequest(b *testing.B) {
+	body := benchmarkImage(b)
+	e := echo.New()
+
+	handlers := map[string]echo.HandlerFunc{
+		"Buffered": handleBuffered,
+		"Streamed": handleStreamed,
+	}
+
+	for name, handler := range handlers {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(body)))
+			for range b.N {
+				req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+				// clients streaming their upload do not announce its size
+				req.ContentLength = -1
+				c := e.NewContext(req, httptest.NewRecorder())
+				if err := handler(c); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkReadBody compares only reading the body, without OpenCV's share of the request.
+func BenchmarkReadBody(b *testing.B) {
+	body := benchmarkImage(b)
+
+	b.Run("ReadAll", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(body)))
+		for range b.N {
+			if _, err := io.ReadAll(bytes.NewReader(body)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(body)))
+		for range b.N {
+			buffer, err := util.ReadBody(bytes.NewReader(body), -1, util.MaxBodyBytes)
+			if err != nil {
+				b.Fatal(err)
+			}
+			util.PutBuffer(buffer)
+		}
+	})
+}
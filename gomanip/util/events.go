@@ -0,0 +1,42 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StartEvents answers the request with a stream of server-sent events.
+func StartEvents(c echo.Context) {
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, "text/event-stream")
+	header.Set(echo.HeaderCacheControl, "no-cache")
+	header.Set(echo.HeaderConnection, "keep-alive")
+	c.Response().WriteHeader(http.StatusOK)
+	c.Response().Flush()
+}
+
+// WriteEvent sends a server-sent event named name with data encoded as json.
+func WriteEvent(c echo.Context, name string, data any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Response(), "event: %s\ndata: %s\n\n", name, encoded); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
+
+// WriteEventComment sends a comment, clients ignore it but it keeps proxies from closing an idle stream.
+func WriteEventComment(c echo.Context, comment string) error {
+	if _, err := fmt.Fprintf(c.Response(), ": %s\n\n", comment); err != nil {
+		return err
+	}
+	c.Response().Flush()
+	return nil
+}
@@ -1,9 +1,10 @@
 package util
 
 import (
+	"errors"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"gocv.io/x/gocv"
-	"io"
 )
 
 func bytesToMat(data []byte) (gocv.Mat, error) {
@@ -11,15 +12,69 @@ func bytesToMat(data []byte) (gocv.Mat, error) {
 	return mat, err
 }
 
+// DecodeImage decodes a png or jpeg, an image that can not be decoded is an error.
+func DecodeImage(imageBytes []byte) (*gocv.Mat, error) {
+	mat, err := bytesToMat(imageBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if mat.Empty() {
+		mat.Close()
+		return nil, errors.New("could not decode image")
+	}
+
+	return &mat, nil
+}
+
 func GetImageFromBody(c echo.Context) (*gocv.Mat, error) {
-	imageBytes, err := io.ReadAll(c.Request().Body)
-	defer c.Request().Body.Close()
+	request := c.Request()
+	defer request.Body.Close()
 
+	buffer, err := ReadBody(request.Body, request.ContentLength, MaxBodyBytes)
 	if err != nil {
 		return nil, err
 	}
+	// the decoded Mat holds its own copy of the pixels, the buffer can be reused right after
+	defer PutBuffer(buffer)
 
-	mat, err := bytesToMat(imageBytes)
+	mat, err := bytesToMat(buffer.Bytes())
+	if err != nil {
+		mat.Close()
+		return nil, err
+	}
+
+	return &mat, nil
+}
+
+// GetImageFromForm reads the image uploaded under name in a multipart form.
+func GetImageFromForm(c echo.Context, name string) (*gocv.Mat, error) {
+	header, err := c.FormFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("missing form file %s: %w", name, err)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buffer, err := ReadBody(file, header.Size, MaxBodyBytes)
+	if err != nil {
+		return nil, err
+	}
+	defer PutBuffer(buffer)
+
+	mat, err := bytesToMat(buffer.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	if mat.Empty() {
+		mat.Close()
+		return nil, fmt.Errorf("could not decode form file %s", name)
+	}
 
-	return &mat, err
+	return &mat, nil
 }
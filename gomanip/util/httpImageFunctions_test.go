@@ -2,12 +2,15 @@ package util_test
 
 import (
 	"bytes"
+	"fmt"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"goManip/util"
 	"gocv.io/x/gocv"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/textproto"
 	"testing"
 )
 
@@ -144,3 +147,80 @@ func TestGetImageFromBodyInvalidImage(t *testing.T) {
 	_, err := util.GetImageFromBody(ctx)
 	assert.NotNil(t, err)
 }
+
+func newTestContextWithForm(files map[string][]byte, contentType string) echo.Context {
+
+	e := echo.New()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, data := range files {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s.png"`, name, name))
+		header.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			panic(err)
+		}
+		part.Write(data)
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	return e.NewContext(req, rec)
+}
+
+func TestGetImageFromForm(t *testing.T) {
+	image := gocv.NewMatWithSize(120, 80, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	encoded, err := gocv.IMEncode(gocv.PNGFileExt, image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer encoded.Close()
+
+	tests := []struct {
+		name      string
+		files     map[string][]byte
+		field     string
+		wantError bool
+	}{
+		{
+			name:      "Test with image",
+			files:     map[string][]byte{"first": encoded.GetBytes()},
+			field:     "first",
+			wantError: false,
+		},
+		{
+			name:      "Handle missing file",
+			files:     map[string][]byte{"first": encoded.GetBytes()},
+			field:     "second",
+			wantError: true,
+		},
+		{
+			name:      "Handle invalid image",
+			files:     map[string][]byte{"first": []byte("not an image")},
+			field:     "first",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := newTestContextWithForm(tt.files, "image/png")
+
+			result, err := util.GetImageFromForm(ctx, tt.field)
+			assert.Equal(t, tt.wantError, err != nil)
+			if result != nil {
+				defer result.Close()
+			}
+			if !tt.wantError {
+				assert.True(t, isEqual(result, &image))
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gocv.io/x/gocv"
+
+	"goManip/jobs"
+)
+
+// PoolConfig bounds the size of a Pool and decides when it grows and shrinks.
+type PoolConfig struct {
+	MinWorkers int
+	MaxWorkers int
+	// ScaleUpWait adds a worker when a job waited longer than this for one
+	ScaleUpWait time.Duration
+	// IdleTimeout removes a worker that had no job for this long
+	IdleTimeout time.Duration
+	// Interval is how often the pool checks whether it has to grow
+	Interval time.Duration
+	// OpenCVThreads is how many threads a single OpenCV call may use, 0 splits the cores between MaxWorkers
+	// so a full pool does not run more threads than there are cores
+	OpenCVThreads int
+	// QueueWait optionally reports how long the oldest queued job has been waiting. Without it the pool only sees
+	// the wait of jobs once a worker picks them up, which does not happen while every worker is stuck on a slow job.
+	QueueWait func() time.Duration
+}
+
+func (c PoolConfig) Validate() error {
+	if c.MinWorkers < 1 {
+		return fmt.Errorf("expected at least 1 worker, got %d", c.MinWorkers)
+	}
+	if c.MaxWorkers < c.MinWorkers {
+		return fmt.Errorf("expected the max workers %d to be at least the min workers %d", c.MaxWorkers, c.MinWorkers)
+	}
+	if c.ScaleUpWait <= 0 || c.IdleTimeout <= 0 || c.Interval <= 0 {
+		return errors.New("expected the scale up wait, idle timeout and interval to be positive")
+	}
+	if c.OpenCVThreads < 0 {
+		return fmt.Errorf("expected 0 or more OpenCV threads, got %d", c.OpenCVThreads)
+	}
+	return nil
+}
+
+// openCVThreads is how many threads each worker's OpenCV calls may use.
+func (c PoolConfig) openCVThreads() int {
+	if c.OpenCVThreads > 0 {
+		return c.OpenCVThreads
+	}
+	return max(runtime.NumCPU()/c.MaxWorkers, 1)
+}
+
+// PoolStats describes the pool and how it scaled since it started.
+type PoolStats struct {
+	Workers       int    `json:"workers"`
+	MinWorkers    int    `json:"minWorkers"`
+	MaxWorkers    int    `json:"maxWorkers"`
+	Busy          int    `json:"busy"`
+	OpenCVThreads int    `json:"openCVThreads"`
+	ScaleUps      uint64 `json:"scaleUps"`
+	ScaleDowns    uint64 `json:"scaleDowns"`
+	// Restarts counts the workers replaced after their job panicked
+	Restarts    uint64 `json:"restarts"`
+	JobsStarted uint64 `json:"jobsStarted"`
+	// MaxWaitMs is the longest a job waited for a worker during the last interval
+	MaxWaitMs int64 `json:"maxWaitMs"`
+}
+
+// Pool runs between MinWorkers and MaxWorkers workers reading jobRequests. It adds a worker whenever jobs wait
+// longer than ScaleUpWait, and a worker without a job for IdleTimeout stops while there are more than MinWorkers.
+type Pool struct {
+	config      PoolConfig
+	jobRequests <-chan *jobs.JobRequest
+	shutdown    context.Context
+	wg          sync.WaitGroup
+	threads     int
+
+	mu      sync.Mutex
+	workers int
+	busy    int
+	nextId  int
+	// maxWait is the longest wait of the jobs picked up since the last check
+	maxWait     time.Duration
+	lastMaxWait time.Duration
+	scaleUps    uint64
+	scaleDowns  uint64
+	restarts    uint64
+	started     uint64
+	// closed is set once jobRequests is closed, workers are not replaced after that
+	closed bool
+}
+
+// NewPool starts MinWorkers workers reading jobRequests, they stop when jobRequests is closed or shutdown is done.
+func NewPool(shutdown context.Context, jobRequests <-chan *jobs.JobRequest, config PoolConfig) (*Pool, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		config:      config,
+		jobRequests: jobRequests,
+		shutdown:    shutdown,
+		threads:     config.openCVThreads(),
+	}
+
+	// OpenCV's thread setting is process wide, every worker runs with the same share of the cores
+	gocv.SetNumThreads(p.threads)
+	log.Info().
+		Int("min workers", config.MinWorkers).
+		Int("max workers", config.MaxWorkers).
+		Int("OpenCV threads", p.threads).
+		Msg("Starting worker pool")
+
+	p.mu.Lock()
+	for range config.MinWorkers {
+		p.add()
+	}
+	p.mu.Unlock()
+
+	p.wg.Add(1)
+	go p.monitor()
+
+	return p, nil
+}
+
+// Wait blocks until every worker stopped.
+func (p *Pool) Wait() {
+	p.wg.Wait()
+}
+
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return PoolStats{
+		Workers:       p.workers,
+		MinWorkers:    p.config.MinWorkers,
+		MaxWorkers:    p.config.MaxWorkers,
+		Busy:          p.busy,
+		OpenCVThreads: p.threads,
+		ScaleUps:      p.scaleUps,
+		ScaleDowns:    p.scaleDowns,
+		Restarts:      p.restarts,
+		JobsStarted:   p.started,
+		MaxWaitMs:     p.lastMaxWait.Milliseconds(),
+	}
+}
+
+// add starts a worker, p.mu has to be held.
+func (p *Pool) add() {
+	p.workers++
+	p.nextId++
+	p.wg.Add(1)
+	go p.run(p.nextId)
+}
+
+func (p *Pool) monitor() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.shutdown.Done():
+			return
+		case <-ticker.C:
+			if !p.check() {
+				return
+			}
+		}
+	}
+}
+
+// check adds a worker when jobs waited too long since the last check, it returns false once the pool is closed.
+func (p *Pool) check() bool {
+	var queueWait time.Duration
+	if p.config.QueueWait != nil {
+		queueWait = p.config.QueueWait()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return false
+	}
+
+	wait := max(p.maxWait, queueWait)
+	p.lastMaxWait = wait
+	p.maxWait = 0
+
+	if wait > p.config.ScaleUpWait && p.workers < p.config.MaxWorkers {
+		p.scaleUps++
+		p.add()
+		log.Info().
+			Int("workers", p.workers).
+			Str("wait", wait.String()).
+			Uint64("scale ups", p.scaleUps).
+			Msg("Scaled worker pool up")
+	}
+
+	return true
+}
+
+// retire stops the worker when there are more than MinWorkers, it returns whether the worker should stop.
+func (p *Pool) retire(workerId int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.workers <= p.config.MinWorkers {
+		return false
+	}
+
+	p.workers--
+	p.scaleDowns++
+	log.Info().
+		Int("worker", workerId).
+		Int("workers", p.workers).
+		Uint64("scale downs", p.scaleDowns).
+		Msg("Scaled worker pool down")
+	return true
+}
+
+// restart replaces a worker whose job panicked with a fresh one, the pool keeps its size.
+func (p *Pool) restart(workerId int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.restarts++
+	p.workers--
+	p.add()
+	log.Warn().
+		Int("worker", workerId).
+		Int("replacement", p.nextId).
+		Uint64("restarts", p.restarts).
+		Msg("Restarted worker after a panic")
+}
+
+func (p *Pool) pickedUp(jobRequest *jobs.JobRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.busy++
+	p.started++
+	p.maxWait = max(p.maxWait, time.Since(jobRequest.Queued))
+}
+
+func (p *Pool) done() {
+	p.mu.Lock()
+	p.busy--
+	p.mu.Unlock()
+}
+
+// stop removes a worker that stopped for any reason other than being idle.
+func (p *Pool) stop(closed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.workers--
+	p.closed = p.closed || closed
+}
+
+func (p *Pool) run(workerId int) {
+	defer p.wg.Done()
+
+	idle := time.NewTimer(p.config.IdleTimeout)
+	defer idle.Stop()
+
+	for {
+		select {
+		case <-p.shutdown.Done():
+			p.stop(false)
+			return
+
+		case jobRequest, ok := <-p.jobRequests:
+			if !ok {
+				p.stop(true)
+				return
+			}
+
+			p.pickedUp(jobRequest)
+			if !process(p.shutdown, workerId, jobRequest) {
+				p.done()
+				p.stop(false)
+				return
+			}
+			p.done()
+			if jobRequest.Job.Panic() != nil {
+				p.restart(workerId)
+				return
+			}
+			idle.Reset(p.config.IdleTimeout)
+
+		case <-idle.C:
+			if p.retire(workerId) {
+				return
+			}
+			idle.Reset(p.config.IdleTimeout)
+		}
+	}
+}
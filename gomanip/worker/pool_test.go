@@ -0,0 +1,217 @@
+package worker_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"gocv.io/x/gocv"
+
+	"goManip/jobs"
+	"goManip/worker"
+)
+
+// mockOperationSlow stands in for an expensive operation, it keeps its worker busy for delay.
+type mockOperationSlow struct {
+	delay time.Duration
+}
+
+func (m mockOperationSlow) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	time.Sleep(m.delay)
+	result := input.Clone()
+	return &result, nil
+}
+
+var testPoolConfig = worker.PoolConfig{
+	MinWorkers:  1,
+	MaxWorkers:  4,
+	ScaleUpWait: 20 * time.Millisecond,
+	IdleTimeout: time.Minute,
+	Interval:    10 * time.Millisecond,
+}
+
+// runSlowJobs sends count slow jobs at once and waits for all of their results.
+func runSlowJobs(t *testing.T, jobReqs chan<- *jobs.JobRequest, count int, delay time.Duration) {
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	wg := &sync.WaitGroup{}
+	for idx := range count {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			request := jobs.NewJobRequest(jobs.NewJob(uint32(idx), mockOperationSlow{delay: delay}, &image), context.Background())
+			jobReqs <- request
+			result := <-request.Result
+			assert.NoError(t, result.Error)
+			result.Image.Close()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPoolScalesUp(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	jobReqs := make(chan *jobs.JobRequest)
+	pool, err := worker.NewPool(context.Background(), jobReqs, testPoolConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, pool.Stats().Workers)
+
+	// one worker would take 16 * 50ms, the jobs waiting for it make the pool grow
+	start := time.Now()
+	runSlowJobs(t, jobReqs, 16, 50*time.Millisecond)
+	assert.Less(t, time.Since(start), 16*50*time.Millisecond)
+
+	stats := pool.Stats()
+	assert.Equal(t, testPoolConfig.MaxWorkers, stats.Workers)
+	assert.EqualValues(t, testPoolConfig.MaxWorkers-1, stats.ScaleUps)
+	assert.EqualValues(t, 16, stats.JobsStarted)
+	assert.Zero(t, stats.Busy)
+
+	close(jobReqs)
+	pool.Wait()
+	assert.Zero(t, pool.Stats().Workers)
+}
+
+func TestPoolScalesDown(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	config := testPoolConfig
+	config.IdleTimeout = 50 * time.Millisecond
+
+	jobReqs := make(chan *jobs.JobRequest)
+	pool, err := worker.NewPool(context.Background(), jobReqs, config)
+	assert.NoError(t, err)
+
+	runSlowJobs(t, jobReqs, 16, 50*time.Millisecond)
+	assert.Greater(t, pool.Stats().ScaleUps, uint64(0))
+
+	// once idle, the pool shrinks back to its min and stays there
+	assert.Eventually(t, func() bool {
+		return pool.Stats().Workers == config.MinWorkers
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(2 * config.IdleTimeout)
+
+	stats := pool.Stats()
+	assert.Equal(t, config.MinWorkers, stats.Workers)
+	assert.Equal(t, stats.ScaleUps, stats.ScaleDowns)
+
+	close(jobReqs)
+	pool.Wait()
+}
+
+func TestPoolQueueWait(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	// every worker is stuck on a slow job so none picks up the waiting ones, only the queue shows they wait
+	waiting := &atomic.Bool{}
+	config := testPoolConfig
+	config.QueueWait = func() time.Duration {
+		if waiting.Load() {
+			return time.Second
+		}
+		return 0
+	}
+
+	jobReqs := make(chan *jobs.JobRequest)
+	pool, err := worker.NewPool(context.Background(), jobReqs, config)
+	assert.NoError(t, err)
+
+	// nothing waits, so the pool stays at its min
+	time.Sleep(5 * config.Interval)
+	assert.Equal(t, config.MinWorkers, pool.Stats().Workers)
+
+	waiting.Store(true)
+	assert.Eventually(t, func() bool {
+		return pool.Stats().Workers == config.MaxWorkers
+	}, time.Second, 5*time.Millisecond)
+
+	// it never grows beyond its max
+	time.Sleep(5 * config.Interval)
+	stats := pool.Stats()
+	assert.Equal(t, config.MaxWorkers, stats.Workers)
+	assert.EqualValues(t, config.MaxWorkers-config.MinWorkers, stats.ScaleUps)
+	assert.EqualValues(t, 1000, stats.MaxWaitMs)
+
+	close(jobReqs)
+	pool.Wait()
+}
+
+func TestPoolShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	config := testPoolConfig
+	config.MinWorkers = 2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool, err := worker.NewPool(ctx, make(chan *jobs.JobRequest), config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pool.Stats().Workers)
+
+	cancel()
+	pool.Wait()
+	assert.Zero(t, pool.Stats().Workers)
+}
+
+func TestPoolConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(config *worker.PoolConfig)
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(config *worker.PoolConfig) {}},
+		{name: "Fixed size", modify: func(config *worker.PoolConfig) { config.MinWorkers = config.MaxWorkers }},
+		{name: "No workers", modify: func(config *worker.PoolConfig) { config.MinWorkers = 0 }, wantErr: true},
+		{name: "Max below min", modify: func(config *worker.PoolConfig) { config.MaxWorkers = 0 }, wantErr: true},
+		{name: "No interval", modify: func(config *worker.PoolConfig) { config.Interval = 0 }, wantErr: true},
+		{name: "Negative OpenCV threads", modify: func(config *worker.PoolConfig) { config.OpenCVThreads = -1 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testPoolConfig
+			tt.modify(&config)
+			assert.Equal(t, tt.wantErr, config.Validate() != nil)
+		})
+	}
+}
+
+// mockOperationPanic crashes the way a bad region in an operation would.
+type mockOperationPanic struct{}
+
+func (m mockOperationPanic) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	var regions []int
+	_ = regions[1]
+	return input, nil
+}
+
+func TestPoolRestartsAfterPanic(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	jobReqs := make(chan *jobs.JobRequest)
+	pool, err := worker.NewPool(context.Background(), jobReqs, testPoolConfig)
+	assert.NoError(t, err)
+
+	image := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer image.Close()
+
+	request := jobs.NewJobRequest(jobs.NewJob(1, mockOperationPanic{}, &image), context.Background())
+	jobReqs <- request
+	result := <-request.Result
+	assert.Nil(t, result.Image)
+	assert.ErrorIs(t, result.Error, jobs.ErrInternal)
+
+	// the replacement worker takes the next job and the pool keeps its size
+	runSlowJobs(t, jobReqs, 1, time.Millisecond)
+	stats := pool.Stats()
+	assert.EqualValues(t, 1, stats.Restarts)
+	assert.Equal(t, testPoolConfig.MinWorkers, stats.Workers)
+
+	close(jobReqs)
+	pool.Wait()
+}
@@ -11,39 +11,61 @@ func Worker(shutdown context.Context, workerId int, jobRequests <-chan *jobs.Job
 	defer wg.Done()
 
 	for jobRequest := range jobRequests {
-
-		job := jobRequest.Job
-		log.Info().Msgf("Worker %d: Starting job: %d", workerId, job.GetJobId())
-		result, err := job.Process()
-		select {
-
-		case <-shutdown.Done():
-			log.Info().Int("Worker", workerId).Msg("Worker shutdown")
+		if !process(shutdown, workerId, jobRequest) {
 			return
+		}
+	}
+}
 
-		// if the job timed out, no goroutine is waiting for a result.
-		// we don't need to send anything
-		case <-jobRequest.Ctx.Done():
-			result.Close()
+// process runs the job of jobRequest and sends its result, it returns false when the worker has to shut down.
+func process(shutdown context.Context, workerId int, jobRequest *jobs.JobRequest) bool {
+	job := jobRequest.Job
+	log.Info().Msgf("Worker %d: Starting job: %d", workerId, job.GetJobId())
+	jobRequest.Start()
+	result, err := job.Process()
+	// the job's Mats may be released from here on
+	jobRequest.Finish()
+	if panicErr := job.Panic(); panicErr != nil {
+		log.Error().
+			Int("Worker", workerId).
+			Uint32("job", panicErr.JobId).
+			Str("operation", panicErr.Operation).
+			Str("params", panicErr.Params).
+			Interface("panic", panicErr.Value).
+			Str("stack", string(panicErr.Stack)).
+			Msg("Operation panicked")
+	}
+	select {
 
-		default:
+	case <-shutdown.Done():
+		log.Info().Int("Worker", workerId).Msg("Worker shutdown")
+		return false
 
-			if err != nil {
-				log.Error().
-					Str("Start time", job.GetStartTime().String()).
-					Str("End time", job.GetEndTime().String()).
-					Msgf("Worker %d Failed: %s", workerId, err.Error())
-			} else {
-				log.Info().
-					Str("Start time", job.GetStartTime().String()).
-					Str("End time", job.GetEndTime().String()).
-					Int("Duration (ns)", job.GetTimeElapsed()).
-					Msgf("Worker %d Completed", workerId)
-			}
+	// if the job timed out, no goroutine is waiting for a result.
+	// we don't need to send anything
+	case <-jobRequest.Ctx.Done():
+		if result != nil && !job.HoldsMat(result) {
+			result.Close()
+		}
 
-			jobRequest.Result <- &jobs.Result{Image: result, Error: err}
+	default:
 
+		if err != nil {
+			log.Error().
+				Str("Start time", job.GetStartTime().String()).
+				Str("End time", job.GetEndTime().String()).
+				Msgf("Worker %d Failed: %s", workerId, err.Error())
+		} else {
+			log.Info().
+				Str("Start time", job.GetStartTime().String()).
+				Str("End time", job.GetEndTime().String()).
+				Int("Duration (ns)", job.GetTimeElapsed()).
+				Msgf("Worker %d Completed", workerId)
 		}
 
+		jobRequest.Result <- &jobs.Result{Image: result, Error: err}
+
 	}
+
+	return true
 }
@@ -138,3 +138,42 @@ func TestWorkerCancel(t *testing.T) {
 	close(jobReqs)
 	wg.Wait()
 }
+
+type MockOperationPanic struct{}
+
+func (m MockOperationPanic) Run(input *gocv.Mat) (*gocv.Mat, error) {
+	var regions []int
+	_ = regions[1]
+	return input, nil
+}
+
+func TestWorkerPanic(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	wg := &sync.WaitGroup{}
+	jobReqs := make(chan *jobs.JobRequest)
+	wg.Add(1)
+	go worker.Worker(context.Background(), 0, jobReqs, wg)
+
+	testImage := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer testImage.Close()
+
+	panicking := jobs.NewJobRequest(jobs.NewJob(0, MockOperationPanic{}, &testImage), context.Background())
+	jobReqs <- panicking
+	result := <-panicking.Result
+	if !errors.Is(result.Error, jobs.ErrInternal) {
+		t.Errorf("TestWorkerPanic() expected an internal error, got %v", result.Error)
+	}
+
+	// the worker survives the panic and takes the next job
+	other := gocv.NewMatWithSize(8, 8, gocv.MatTypeCV8UC3)
+	defer other.Close()
+	next := jobs.NewJobRequest(jobs.NewJob(1, MockOperationSuccess{}, &other), context.Background())
+	jobReqs <- next
+	if result := <-next.Result; result.Error != nil {
+		t.Errorf("TestWorkerPanic() expected the next job to succeed, got %v", result.Error)
+	}
+
+	close(jobReqs)
+	wg.Wait()
+}